@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -20,17 +23,25 @@ import (
 	"github.com/bluenviron/mediamtx/internal/auth"
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/onvif"
+	"github.com/bluenviron/mediamtx/internal/playback"
 	"github.com/bluenviron/mediamtx/internal/protocols/httpp"
 	"github.com/bluenviron/mediamtx/internal/recordstore"
 	"github.com/bluenviron/mediamtx/internal/restrictnetwork"
 	"github.com/bluenviron/mediamtx/internal/servers/hls"
 	"github.com/bluenviron/mediamtx/internal/servers/rtmp"
+	"github.com/bluenviron/mediamtx/internal/servers/rtppush"
 	"github.com/bluenviron/mediamtx/internal/servers/rtsp"
 	"github.com/bluenviron/mediamtx/internal/servers/srt"
 	"github.com/bluenviron/mediamtx/internal/servers/webrtc"
 )
 
+const (
+	ptzRequestTimeout = 10 * time.Second
+)
+
 func interfaceIsEmpty(i interface{}) bool {
 	return reflect.ValueOf(i).Kind() != reflect.Ptr || reflect.ValueOf(i).IsNil()
 }
@@ -77,16 +88,89 @@ func recordingsOfPath(
 	return ret
 }
 
+func recordingMatchesOfPath(
+	pathConf *conf.Path,
+	pathName string,
+	start time.Time,
+	end time.Time,
+) *defs.APIRecordingMatch {
+	segments, err := recordstore.FindSegments(pathConf, pathName, &start, &end)
+	if err != nil {
+		return nil
+	}
+
+	ret := &defs.APIRecordingMatch{
+		Name:     pathName,
+		Segments: make([]*defs.APIRecordingMatchSegment, 0, len(segments)),
+	}
+
+	for _, seg := range segments {
+		fi, err2 := os.Stat(seg.Fpath)
+		if err2 != nil {
+			continue
+		}
+
+		codecs, err2 := playback.SegmentCodecs(seg.Fpath, pathConf.RecordFormat)
+		if err2 != nil {
+			codecs = []string{}
+		}
+
+		ret.Segments = append(ret.Segments, &defs.APIRecordingMatchSegment{
+			Start:  seg.Start,
+			Size:   uint64(fi.Size()),
+			Codecs: codecs,
+		})
+	}
+
+	if len(ret.Segments) == 0 {
+		return nil
+	}
+
+	return ret
+}
+
+func parseDryRun(ctx *gin.Context) (bool, error) {
+	v := ctx.Query("dryRun")
+	if v == "" {
+		return false, nil
+	}
+
+	dryRun, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid 'dryRun' parameter: %w", err)
+	}
+
+	return dryRun, nil
+}
+
+func kickDryRun(id uuid.UUID, pathName string) *defs.APIDryRun {
+	ret := &defs.APIDryRun{
+		KickedSessions:   []uuid.UUID{id},
+		ChangedPathConfs: []string{},
+		AffectedPaths:    []string{},
+	}
+
+	if pathName != "" {
+		ret.AffectedPaths = append(ret.AffectedPaths, pathName)
+	}
+
+	return ret
+}
+
 // PathManager contains methods used by the API and Metrics server.
 type PathManager interface {
 	APIPathsList() (*defs.APIPathList, error)
 	APIPathsGet(string) (*defs.APIPath, error)
+	APIPathsDebug(string) (*defs.APIPathDebug, error)
+	APIPathsHealth() (*defs.APIPathHealthList, error)
+	APIPathsMute(string, *defs.APIPathMuteReq) (*defs.APIPathMute, error)
 }
 
 // HLSServer contains methods used by the API and Metrics server.
 type HLSServer interface {
 	APIMuxersList() (*defs.APIHLSMuxerList, error)
 	APIMuxersGet(string) (*defs.APIHLSMuxer, error)
+	APISessionsKick(uuid.UUID) error
 }
 
 // RTSPServer contains methods used by the API and Metrics server.
@@ -119,6 +203,14 @@ type WebRTCServer interface {
 	APISessionsKick(uuid.UUID) error
 }
 
+// RTPPushServer contains methods used by the API.
+type RTPPushServer interface {
+	APISessionsCreate(string, []byte, string) (*defs.APIRTPPushSession, []byte, error)
+	APISessionsList() (*defs.APIRTPPushSessionList, error)
+	APISessionsGet(uuid.UUID) (*defs.APIRTPPushSession, error)
+	APISessionsKick(uuid.UUID) error
+}
+
 type apiAuthManager interface {
 	Authenticate(req *auth.Request) error
 }
@@ -130,24 +222,26 @@ type apiParent interface {
 
 // API is an API server.
 type API struct {
-	Address        string
-	Encryption     bool
-	ServerKey      string
-	ServerCert     string
-	AllowOrigin    string
-	TrustedProxies conf.IPNetworks
-	ReadTimeout    conf.Duration
-	Conf           *conf.Conf
-	AuthManager    apiAuthManager
-	PathManager    PathManager
-	RTSPServer     RTSPServer
-	RTSPSServer    RTSPServer
-	RTMPServer     RTMPServer
-	RTMPSServer    RTMPServer
-	HLSServer      HLSServer
-	WebRTCServer   WebRTCServer
-	SRTServer      SRTServer
-	Parent         apiParent
+	Address         string
+	Encryption      bool
+	ServerKey       string
+	ServerCert      string
+	AllowOrigin     string
+	TrustedProxies  conf.IPNetworks
+	ReadTimeout     conf.Duration
+	Conf            *conf.Conf
+	AuthManager     apiAuthManager
+	PathManager     PathManager
+	RTSPServer      RTSPServer
+	RTSPSServer     RTSPServer
+	RTMPServer      RTMPServer
+	RTMPSServer     RTMPServer
+	HLSServer       HLSServer
+	WebRTCServer    WebRTCServer
+	SRTServer       SRTServer
+	RTPPushServer   RTPPushServer
+	ExternalCmdPool *externalcmd.Pool
+	Parent          apiParent
 
 	httpServer *httpp.Server
 	mutex      sync.RWMutex
@@ -163,6 +257,10 @@ func (a *API) Initialize() error {
 
 	group := router.Group("/v3")
 
+	group.GET("/config/schema", a.onConfigSchema)
+
+	group.POST("/auth/tokens/sign", a.onAuthTokensSign)
+
 	group.GET("/config/global/get", a.onConfigGlobalGet)
 	group.PATCH("/config/global/patch", a.onConfigGlobalPatch)
 
@@ -177,11 +275,15 @@ func (a *API) Initialize() error {
 	group.DELETE("/config/paths/delete/*name", a.onConfigPathsDelete)
 
 	group.GET("/paths/list", a.onPathsList)
+	group.GET("/paths/health", a.onPathsHealth)
 	group.GET("/paths/get/*name", a.onPathsGet)
+	group.GET("/paths/debug/*name", a.onPathsDebug)
+	group.POST("/paths/mute/*name", a.onPathsMute)
 
 	if !interfaceIsEmpty(a.HLSServer) {
 		group.GET("/hlsmuxers/list", a.onHLSMuxersList)
 		group.GET("/hlsmuxers/get/*name", a.onHLSMuxersGet)
+		group.POST("/hlssessions/kick/:id", a.onHLSSessionsKick)
 	}
 
 	if !interfaceIsEmpty(a.RTSPServer) {
@@ -224,9 +326,25 @@ func (a *API) Initialize() error {
 		group.POST("/srtconns/kick/:id", a.onSRTConnsKick)
 	}
 
+	if !interfaceIsEmpty(a.RTPPushServer) {
+		group.POST("/rtppushsessions/create/*name", a.onRTPPushSessionsCreate)
+		group.GET("/rtppushsessions/list", a.onRTPPushSessionsList)
+		group.GET("/rtppushsessions/get/:id", a.onRTPPushSessionsGet)
+		group.POST("/rtppushsessions/kick/:id", a.onRTPPushSessionsKick)
+	}
+
 	group.GET("/recordings/list", a.onRecordingsList)
 	group.GET("/recordings/get/*name", a.onRecordingsGet)
+	group.GET("/recordings/find", a.onRecordingsFind)
 	group.DELETE("/recordings/deletesegment", a.onRecordingDeleteSegment)
+	group.POST("/recordings/importsegment", a.onRecordingImportSegment)
+
+	group.GET("/externalcmds/list", a.onExternalCmdsList)
+
+	group.GET("/ptz/presets/*name", a.onPTZPresets)
+	group.POST("/ptz/continuousmove/*name", a.onPTZContinuousMove)
+	group.POST("/ptz/gotopreset/*name", a.onPTZGotoPreset)
+	group.POST("/ptz/stop/*name", a.onPTZStop)
 
 	network, address := restrictnetwork.Restrict("tcp", a.Address)
 
@@ -265,10 +383,18 @@ func (a *API) writeError(ctx *gin.Context, status int, err error) {
 	// show error in logs
 	a.Log(logger.Error, err.Error())
 
-	// add error to response
-	ctx.JSON(status, &defs.APIError{
+	res := &defs.APIError{
 		Error: err.Error(),
-	})
+	}
+
+	// add the name of the invalid field, if available
+	var verr *conf.ValidationError
+	if errors.As(err, &verr) {
+		res.Field = verr.FieldPath()
+	}
+
+	// add error to response
+	ctx.JSON(status, res)
 }
 
 func (a *API) middlewareOrigin(ctx *gin.Context) {
@@ -308,6 +434,99 @@ func (a *API) middlewareAuth(ctx *gin.Context) {
 	}
 }
 
+func (a *API) onConfigSchema(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, conf.GenerateSchema())
+}
+
+func (a *API) onAuthTokensSign(ctx *gin.Context) {
+	var req defs.APIAuthTokenSignReq
+	err := json.NewDecoder(ctx.Request.Body).Decode(&req)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	switch req.Action {
+	case conf.AuthActionPublish, conf.AuthActionRead, conf.AuthActionPlayback:
+	default:
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid action"))
+		return
+	}
+
+	if req.Duration <= 0 {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid duration"))
+		return
+	}
+
+	a.mutex.RLock()
+	secret := a.Conf.AuthTokenSecret
+	a.mutex.RUnlock()
+
+	if secret == "" {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("signed tokens are disabled, set 'authTokenSecret' to enable them"))
+		return
+	}
+
+	expiration := time.Now().Add(time.Duration(req.Duration))
+
+	token, err := auth.SignToken(secret, auth.TokenClaims{
+		Path:       req.Path,
+		Action:     req.Action,
+		Expiration: expiration.Unix(),
+	})
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &defs.APIAuthToken{
+		Token:      token,
+		Expiration: expiration,
+	})
+}
+
+// configDryRun reports what would change if newConf was applied.
+func (a *API) configDryRun(newConf *conf.Conf) *defs.APIDryRun {
+	ret := &defs.APIDryRun{
+		KickedSessions:   []uuid.UUID{},
+		ChangedGlobal:    !reflect.DeepEqual(a.Conf.Global(), newConf.Global()),
+		ChangedPathConfs: []string{},
+		AffectedPaths:    []string{},
+	}
+
+	changed := make(map[string]struct{})
+
+	for _, name := range sortedKeys(a.Conf.Paths) {
+		if newPathConf, ok := newConf.Paths[name]; !ok || !newPathConf.Equal(a.Conf.Paths[name]) {
+			changed[name] = struct{}{}
+		}
+	}
+
+	for _, name := range sortedKeys(newConf.Paths) {
+		if _, ok := a.Conf.Paths[name]; !ok {
+			changed[name] = struct{}{}
+		}
+	}
+
+	for name := range changed {
+		ret.ChangedPathConfs = append(ret.ChangedPathConfs, name)
+	}
+	sort.Strings(ret.ChangedPathConfs)
+
+	if !interfaceIsEmpty(a.PathManager) {
+		data, err := a.PathManager.APIPathsList()
+		if err == nil {
+			for _, pa := range data.Items {
+				if _, ok := changed[pa.ConfName]; ok {
+					ret.AffectedPaths = append(ret.AffectedPaths, pa.Name)
+				}
+			}
+		}
+	}
+
+	return ret
+}
+
 func (a *API) onConfigGlobalGet(ctx *gin.Context) {
 	a.mutex.RLock()
 	c := a.Conf
@@ -324,6 +543,12 @@ func (a *API) onConfigGlobalPatch(ctx *gin.Context) {
 		return
 	}
 
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -337,6 +562,11 @@ func (a *API) onConfigGlobalPatch(ctx *gin.Context) {
 		return
 	}
 
+	if dryRun {
+		ctx.JSON(http.StatusOK, a.configDryRun(newConf))
+		return
+	}
+
 	a.Conf = newConf
 
 	// since reloading the configuration can cause the shutdown of the API,
@@ -362,6 +592,12 @@ func (a *API) onConfigPathDefaultsPatch(ctx *gin.Context) {
 		return
 	}
 
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -375,6 +611,11 @@ func (a *API) onConfigPathDefaultsPatch(ctx *gin.Context) {
 		return
 	}
 
+	if dryRun {
+		ctx.JSON(http.StatusOK, a.configDryRun(newConf))
+		return
+	}
+
 	a.Conf = newConf
 	a.Parent.APIConfigSet(newConf)
 
@@ -439,6 +680,12 @@ func (a *API) onConfigPathsAdd(ctx *gin.Context) { //nolint:dupl
 		return
 	}
 
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -456,6 +703,11 @@ func (a *API) onConfigPathsAdd(ctx *gin.Context) { //nolint:dupl
 		return
 	}
 
+	if dryRun {
+		ctx.JSON(http.StatusOK, a.configDryRun(newConf))
+		return
+	}
+
 	a.Conf = newConf
 	a.Parent.APIConfigSet(newConf)
 
@@ -476,6 +728,12 @@ func (a *API) onConfigPathsPatch(ctx *gin.Context) { //nolint:dupl
 		return
 	}
 
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -497,6 +755,11 @@ func (a *API) onConfigPathsPatch(ctx *gin.Context) { //nolint:dupl
 		return
 	}
 
+	if dryRun {
+		ctx.JSON(http.StatusOK, a.configDryRun(newConf))
+		return
+	}
+
 	a.Conf = newConf
 	a.Parent.APIConfigSet(newConf)
 
@@ -517,6 +780,12 @@ func (a *API) onConfigPathsReplace(ctx *gin.Context) { //nolint:dupl
 		return
 	}
 
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
@@ -538,6 +807,11 @@ func (a *API) onConfigPathsReplace(ctx *gin.Context) { //nolint:dupl
 		return
 	}
 
+	if dryRun {
+		ctx.JSON(http.StatusOK, a.configDryRun(newConf))
+		return
+	}
+
 	a.Conf = newConf
 	a.Parent.APIConfigSet(newConf)
 
@@ -551,12 +825,18 @@ func (a *API) onConfigPathsDelete(ctx *gin.Context) {
 		return
 	}
 
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
 	newConf := a.Conf.Clone()
 
-	err := newConf.RemovePath(confName)
+	err = newConf.RemovePath(confName)
 	if err != nil {
 		if errors.Is(err, conf.ErrPathNotFound) {
 			a.writeError(ctx, http.StatusNotFound, err)
@@ -572,6 +852,11 @@ func (a *API) onConfigPathsDelete(ctx *gin.Context) {
 		return
 	}
 
+	if dryRun {
+		ctx.JSON(http.StatusOK, a.configDryRun(newConf))
+		return
+	}
+
 	a.Conf = newConf
 	a.Parent.APIConfigSet(newConf)
 
@@ -596,6 +881,51 @@ func (a *API) onPathsList(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, data)
 }
 
+func (a *API) onPathsMute(ctx *gin.Context) {
+	pathName, ok := paramName(ctx)
+	if !ok {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid name"))
+		return
+	}
+
+	var req defs.APIPathMuteReq
+	err := json.NewDecoder(ctx.Request.Body).Decode(&req)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := a.PathManager.APIPathsMute(pathName, &req)
+	if err != nil {
+		if errors.Is(err, conf.ErrPathNotFound) {
+			a.writeError(ctx, http.StatusNotFound, err)
+		} else {
+			a.writeError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, data)
+}
+
+func (a *API) onPathsHealth(ctx *gin.Context) {
+	data, err := a.PathManager.APIPathsHealth()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	data.ItemCount = len(data.Items)
+	pageCount, err := paginate(&data.Items, ctx.Query("itemsPerPage"), ctx.Query("page"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	data.PageCount = pageCount
+
+	ctx.JSON(http.StatusOK, data)
+}
+
 func (a *API) onPathsGet(ctx *gin.Context) {
 	pathName, ok := paramName(ctx)
 	if !ok {
@@ -616,6 +946,26 @@ func (a *API) onPathsGet(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, data)
 }
 
+func (a *API) onPathsDebug(ctx *gin.Context) {
+	pathName, ok := paramName(ctx)
+	if !ok {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid name"))
+		return
+	}
+
+	data, err := a.PathManager.APIPathsDebug(pathName)
+	if err != nil {
+		if errors.Is(err, conf.ErrPathNotFound) {
+			a.writeError(ctx, http.StatusNotFound, err)
+		} else {
+			a.writeError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, data)
+}
+
 func (a *API) onRTSPConnsList(ctx *gin.Context) {
 	data, err := a.RTSPServer.APIConnsList()
 	if err != nil {
@@ -699,7 +1049,22 @@ func (a *API) onRTSPSessionsKick(ctx *gin.Context) {
 		return
 	}
 
-	err = a.RTSPServer.APISessionsKick(uuid)
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var res *defs.APIDryRun
+	if dryRun {
+		var data *defs.APIRTSPSession
+		data, err = a.RTSPServer.APISessionsGet(uuid)
+		if err == nil {
+			res = kickDryRun(data.ID, data.Path)
+		}
+	} else {
+		err = a.RTSPServer.APISessionsKick(uuid)
+	}
 	if err != nil {
 		if errors.Is(err, rtsp.ErrSessionNotFound) {
 			a.writeError(ctx, http.StatusNotFound, err)
@@ -709,6 +1074,11 @@ func (a *API) onRTSPSessionsKick(ctx *gin.Context) {
 		return
 	}
 
+	if res != nil {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	ctx.Status(http.StatusOK)
 }
 
@@ -795,7 +1165,22 @@ func (a *API) onRTSPSSessionsKick(ctx *gin.Context) {
 		return
 	}
 
-	err = a.RTSPSServer.APISessionsKick(uuid)
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var res *defs.APIDryRun
+	if dryRun {
+		var data *defs.APIRTSPSession
+		data, err = a.RTSPSServer.APISessionsGet(uuid)
+		if err == nil {
+			res = kickDryRun(data.ID, data.Path)
+		}
+	} else {
+		err = a.RTSPSServer.APISessionsKick(uuid)
+	}
 	if err != nil {
 		if errors.Is(err, rtsp.ErrSessionNotFound) {
 			a.writeError(ctx, http.StatusNotFound, err)
@@ -805,6 +1190,11 @@ func (a *API) onRTSPSSessionsKick(ctx *gin.Context) {
 		return
 	}
 
+	if res != nil {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	ctx.Status(http.StatusOK)
 }
 
@@ -853,7 +1243,22 @@ func (a *API) onRTMPConnsKick(ctx *gin.Context) {
 		return
 	}
 
-	err = a.RTMPServer.APIConnsKick(uuid)
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var res *defs.APIDryRun
+	if dryRun {
+		var data *defs.APIRTMPConn
+		data, err = a.RTMPServer.APIConnsGet(uuid)
+		if err == nil {
+			res = kickDryRun(data.ID, data.Path)
+		}
+	} else {
+		err = a.RTMPServer.APIConnsKick(uuid)
+	}
 	if err != nil {
 		if errors.Is(err, rtmp.ErrConnNotFound) {
 			a.writeError(ctx, http.StatusNotFound, err)
@@ -863,6 +1268,11 @@ func (a *API) onRTMPConnsKick(ctx *gin.Context) {
 		return
 	}
 
+	if res != nil {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	ctx.Status(http.StatusOK)
 }
 
@@ -911,7 +1321,22 @@ func (a *API) onRTMPSConnsKick(ctx *gin.Context) {
 		return
 	}
 
-	err = a.RTMPSServer.APIConnsKick(uuid)
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var res *defs.APIDryRun
+	if dryRun {
+		var data *defs.APIRTMPConn
+		data, err = a.RTMPSServer.APIConnsGet(uuid)
+		if err == nil {
+			res = kickDryRun(data.ID, data.Path)
+		}
+	} else {
+		err = a.RTMPSServer.APIConnsKick(uuid)
+	}
 	if err != nil {
 		if errors.Is(err, rtmp.ErrConnNotFound) {
 			a.writeError(ctx, http.StatusNotFound, err)
@@ -921,6 +1346,11 @@ func (a *API) onRTMPSConnsKick(ctx *gin.Context) {
 		return
 	}
 
+	if res != nil {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	ctx.Status(http.StatusOK)
 }
 
@@ -962,26 +1392,89 @@ func (a *API) onHLSMuxersGet(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, data)
 }
 
-func (a *API) onWebRTCSessionsList(ctx *gin.Context) {
-	data, err := a.WebRTCServer.APISessionsList()
+func (a *API) onHLSSessionsKick(ctx *gin.Context) {
+	uuid, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
-		a.writeError(ctx, http.StatusInternalServerError, err)
+		a.writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	data.ItemCount = len(data.Items)
-	pageCount, err := paginate(&data.Items, ctx.Query("itemsPerPage"), ctx.Query("page"))
+	dryRun, err := parseDryRun(ctx)
 	if err != nil {
 		a.writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	data.PageCount = pageCount
-
-	ctx.JSON(http.StatusOK, data)
-}
 
-func (a *API) onWebRTCSessionsGet(ctx *gin.Context) {
-	uuid, err := uuid.Parse(ctx.Param("id"))
+	var res *defs.APIDryRun
+	if dryRun {
+		res, err = a.hlsSessionsKickDryRun(uuid)
+	} else {
+		err = a.HLSServer.APISessionsKick(uuid)
+	}
+	if err != nil {
+		if errors.Is(err, hls.ErrSessionNotFound) {
+			a.writeError(ctx, http.StatusNotFound, err)
+		} else if errors.Is(err, hls.ErrSessionTokensDisabled) {
+			a.writeError(ctx, http.StatusBadRequest, err)
+		} else {
+			a.writeError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if res != nil {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (a *API) hlsSessionsKickDryRun(id uuid.UUID) (*defs.APIDryRun, error) {
+	a.mutex.RLock()
+	sessionTokens := a.Conf.HLSSessionTokens
+	a.mutex.RUnlock()
+
+	if !sessionTokens {
+		return nil, hls.ErrSessionTokensDisabled
+	}
+
+	data, err := a.HLSServer.APIMuxersList()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, muxer := range data.Items {
+		for _, session := range muxer.Sessions {
+			if session.ID == id && session.Closed == nil {
+				return kickDryRun(id, muxer.Path), nil
+			}
+		}
+	}
+
+	return nil, hls.ErrSessionNotFound
+}
+
+func (a *API) onWebRTCSessionsList(ctx *gin.Context) {
+	data, err := a.WebRTCServer.APISessionsList()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	data.ItemCount = len(data.Items)
+	pageCount, err := paginate(&data.Items, ctx.Query("itemsPerPage"), ctx.Query("page"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	data.PageCount = pageCount
+
+	ctx.JSON(http.StatusOK, data)
+}
+
+func (a *API) onWebRTCSessionsGet(ctx *gin.Context) {
+	uuid, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
 		a.writeError(ctx, http.StatusBadRequest, err)
 		return
@@ -1007,7 +1500,22 @@ func (a *API) onWebRTCSessionsKick(ctx *gin.Context) {
 		return
 	}
 
-	err = a.WebRTCServer.APISessionsKick(uuid)
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var res *defs.APIDryRun
+	if dryRun {
+		var data *defs.APIWebRTCSession
+		data, err = a.WebRTCServer.APISessionsGet(uuid)
+		if err == nil {
+			res = kickDryRun(data.ID, data.Path)
+		}
+	} else {
+		err = a.WebRTCServer.APISessionsKick(uuid)
+	}
 	if err != nil {
 		if errors.Is(err, webrtc.ErrSessionNotFound) {
 			a.writeError(ctx, http.StatusNotFound, err)
@@ -1017,6 +1525,11 @@ func (a *API) onWebRTCSessionsKick(ctx *gin.Context) {
 		return
 	}
 
+	if res != nil {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	ctx.Status(http.StatusOK)
 }
 
@@ -1065,7 +1578,22 @@ func (a *API) onSRTConnsKick(ctx *gin.Context) {
 		return
 	}
 
-	err = a.SRTServer.APIConnsKick(uuid)
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var res *defs.APIDryRun
+	if dryRun {
+		var data *defs.APISRTConn
+		data, err = a.SRTServer.APIConnsGet(uuid)
+		if err == nil {
+			res = kickDryRun(data.ID, data.Path)
+		}
+	} else {
+		err = a.SRTServer.APIConnsKick(uuid)
+	}
 	if err != nil {
 		if errors.Is(err, srt.ErrConnNotFound) {
 			a.writeError(ctx, http.StatusNotFound, err)
@@ -1075,6 +1603,117 @@ func (a *API) onSRTConnsKick(ctx *gin.Context) {
 		return
 	}
 
+	if res != nil {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (a *API) onRTPPushSessionsCreate(ctx *gin.Context) {
+	pathName, ok := paramName(ctx)
+	if !ok {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid name"))
+		return
+	}
+
+	offer, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var localIP string
+	if addr, ok2 := ctx.Request.Context().Value(http.LocalAddrContextKey).(net.Addr); ok2 {
+		localIP, _, _ = net.SplitHostPort(addr.String())
+	}
+
+	data, answer, err := a.RTPPushServer.APISessionsCreate(pathName, offer, localIP)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	ctx.Header("Location", "/v3/rtppushsessions/get/"+data.ID.String())
+	ctx.Data(http.StatusCreated, "application/sdp", answer)
+}
+
+func (a *API) onRTPPushSessionsList(ctx *gin.Context) {
+	data, err := a.RTPPushServer.APISessionsList()
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	data.ItemCount = len(data.Items)
+	pageCount, err := paginate(&data.Items, ctx.Query("itemsPerPage"), ctx.Query("page"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	data.PageCount = pageCount
+
+	ctx.JSON(http.StatusOK, data)
+}
+
+func (a *API) onRTPPushSessionsGet(ctx *gin.Context) {
+	uuid, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := a.RTPPushServer.APISessionsGet(uuid)
+	if err != nil {
+		if errors.Is(err, rtppush.ErrSessionNotFound) {
+			a.writeError(ctx, http.StatusNotFound, err)
+		} else {
+			a.writeError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, data)
+}
+
+func (a *API) onRTPPushSessionsKick(ctx *gin.Context) {
+	uuid, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	dryRun, err := parseDryRun(ctx)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var res *defs.APIDryRun
+	if dryRun {
+		var data *defs.APIRTPPushSession
+		data, err = a.RTPPushServer.APISessionsGet(uuid)
+		if err == nil {
+			res = kickDryRun(data.ID, data.Path)
+		}
+	} else {
+		err = a.RTPPushServer.APISessionsKick(uuid)
+	}
+	if err != nil {
+		if errors.Is(err, rtppush.ErrSessionNotFound) {
+			a.writeError(ctx, http.StatusNotFound, err)
+		} else {
+			a.writeError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if res != nil {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	ctx.Status(http.StatusOK)
 }
 
@@ -1125,6 +1764,55 @@ func (a *API) onRecordingsGet(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, recordingsOfPath(pathConf, pathName))
 }
 
+func (a *API) onRecordingsFind(ctx *gin.Context) {
+	start, err := time.Parse(time.RFC3339, ctx.Query("start"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid 'start' parameter: %w", err))
+		return
+	}
+
+	end, err := time.Parse(time.RFC3339, ctx.Query("end"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid 'end' parameter: %w", err))
+		return
+	}
+
+	if !end.After(start) {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("'end' must be after 'start'"))
+		return
+	}
+
+	a.mutex.RLock()
+	c := a.Conf
+	a.mutex.RUnlock()
+
+	data := defs.APIRecordingMatchList{
+		Items: []*defs.APIRecordingMatch{},
+	}
+
+	for _, pathName := range recordstore.FindAllPathsWithSegments(c.Paths) {
+		pathConf, _, err2 := conf.FindPathConf(c.Paths, pathName)
+		if err2 != nil {
+			continue
+		}
+
+		item := recordingMatchesOfPath(pathConf, pathName, start, end)
+		if item != nil {
+			data.Items = append(data.Items, item)
+		}
+	}
+
+	data.ItemCount = len(data.Items)
+	pageCount, err := paginate(&data.Items, ctx.Query("itemsPerPage"), ctx.Query("page"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	data.PageCount = pageCount
+
+	ctx.JSON(http.StatusOK, data)
+}
+
 func (a *API) onRecordingDeleteSegment(ctx *gin.Context) {
 	pathName := ctx.Query("path")
 
@@ -1162,9 +1850,278 @@ func (a *API) onRecordingDeleteSegment(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+func importSegment(fpath string, r io.Reader) error {
+	f, err := os.Create(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	err = playback.SegmentFMP4Validate(f)
+	if err != nil {
+		return fmt.Errorf("invalid segment: %w", err)
+	}
+
+	return nil
+}
+
+func (a *API) onRecordingImportSegment(ctx *gin.Context) {
+	pathName := ctx.Query("path")
+
+	start, err := time.Parse(time.RFC3339, ctx.Query("start"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid 'start' parameter: %w", err))
+		return
+	}
+
+	a.mutex.RLock()
+	c := a.Conf
+	a.mutex.RUnlock()
+
+	pathConf, _, err := conf.FindPathConf(c.Paths, pathName)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// the playback server only supports fMP4 segments
+	if pathConf.RecordFormat != conf.RecordFormatFMP4 {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("segments can only be imported when record format is fmp4"))
+		return
+	}
+
+	pathFormat := recordstore.PathAddExtension(
+		strings.ReplaceAll(pathConf.RecordPath, "%path", pathName),
+		pathConf.RecordFormat,
+	)
+
+	segmentPath := recordstore.Path{
+		Start: start,
+	}.Encode(pathFormat)
+
+	_, err = os.Stat(segmentPath)
+	if err == nil {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("segment already exists"))
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(segmentPath), 0o755)
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// write into a temporary file in order not to expose
+	// incomplete segments to the playback server.
+	tmpPath := segmentPath + ".tmp"
+
+	err = importSegment(tmpPath, ctx.Request.Body)
+	if err != nil {
+		os.Remove(tmpPath)
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	err = os.Rename(tmpPath, segmentPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (a *API) onExternalCmdsList(ctx *gin.Context) {
+	data := &defs.APIExternalCmdList{
+		Items: []*defs.APIExternalCmd{},
+	}
+
+	if a.ExternalCmdPool != nil {
+		for _, s := range a.ExternalCmdPool.List() {
+			data.Items = append(data.Items, &defs.APIExternalCmd{
+				ID:        s.ID,
+				Created:   s.Created,
+				Command:   s.Command,
+				Running:   s.Running,
+				Restarts:  s.Restarts,
+				LastError: s.LastError,
+			})
+		}
+	}
+
+	data.ItemCount = len(data.Items)
+	pageCount, err := paginate(&data.Items, ctx.Query("itemsPerPage"), ctx.Query("page"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	data.PageCount = pageCount
+
+	ctx.JSON(http.StatusOK, data)
+}
+
 // ReloadConf is called by core.
 func (a *API) ReloadConf(conf *conf.Conf) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 	a.Conf = conf
 }
+
+// ptzOfPath returns the PTZ service of the ONVIF device of a path.
+func (a *API) ptzOfPath(ctx *gin.Context) (*onvif.PTZ, bool) {
+	pathName, ok := paramName(ctx)
+	if !ok {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid name"))
+		return nil, false
+	}
+
+	a.mutex.RLock()
+	c := a.Conf
+	a.mutex.RUnlock()
+
+	pathConf, _, err := conf.FindPathConf(c.Paths, pathName)
+	if err != nil {
+		a.writeError(ctx, http.StatusNotFound, err)
+		return nil, false
+	}
+
+	if pathConf.ONVIFDeviceURL == "" {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("path '%s' has no ONVIF device", pathName))
+		return nil, false
+	}
+
+	client, err := onvif.NewClientFromURL(pathConf.ONVIFDeviceURL, &http.Client{Timeout: ptzRequestTimeout})
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	ptz, err := client.PTZ(ctx.Request.Context())
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return ptz, true
+}
+
+func (a *API) onPTZPresets(ctx *gin.Context) {
+	ptz, ok := a.ptzOfPath(ctx)
+	if !ok {
+		return
+	}
+
+	presets, err := ptz.GetPresets(ctx.Request.Context())
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	data := defs.APIPTZPresetList{
+		Items: make([]*defs.APIPTZPreset, len(presets)),
+	}
+
+	for i, pr := range presets {
+		data.Items[i] = &defs.APIPTZPreset{
+			Token: pr.Token,
+			Name:  pr.Name,
+		}
+	}
+
+	data.ItemCount = len(data.Items)
+	pageCount, err := paginate(&data.Items, ctx.Query("itemsPerPage"), ctx.Query("page"))
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	data.PageCount = pageCount
+
+	ctx.JSON(http.StatusOK, data)
+}
+
+func (a *API) onPTZContinuousMove(ctx *gin.Context) {
+	var req defs.APIPTZContinuousMoveReq
+	err := json.NewDecoder(ctx.Request.Body).Decode(&req)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	for _, v := range []float64{req.Pan, req.Tilt, req.Zoom} {
+		if v < -1 || v > 1 {
+			a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("velocities must be between -1 and 1"))
+			return
+		}
+	}
+
+	if req.Timeout < 0 {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("timeout must be greater than or equal to zero"))
+		return
+	}
+
+	ptz, ok := a.ptzOfPath(ctx)
+	if !ok {
+		return
+	}
+
+	err = ptz.ContinuousMove(ctx.Request.Context(), req.Pan, req.Tilt, req.Zoom, time.Duration(req.Timeout))
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (a *API) onPTZGotoPreset(ctx *gin.Context) {
+	var req defs.APIPTZGotoPresetReq
+	err := json.NewDecoder(ctx.Request.Body).Decode(&req)
+	if err != nil {
+		a.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.Preset == "" {
+		a.writeError(ctx, http.StatusBadRequest, fmt.Errorf("preset is required"))
+		return
+	}
+
+	ptz, ok := a.ptzOfPath(ctx)
+	if !ok {
+		return
+	}
+
+	err = ptz.GotoPreset(ctx.Request.Context(), req.Preset)
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (a *API) onPTZStop(ctx *gin.Context) {
+	ptz, ok := a.ptzOfPath(ctx)
+	if !ok {
+		return
+	}
+
+	err := ptz.Stop(ctx.Request.Context())
+	if err != nil {
+		a.writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
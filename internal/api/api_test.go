@@ -11,10 +11,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bluenviron/mediacommon/pkg/codecs/mpeg4audio"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4/seekablebuffer"
+	"github.com/stretchr/testify/require"
+
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/test"
-	"github.com/stretchr/testify/require"
 )
 
 type testParent struct{}
@@ -403,6 +407,51 @@ func TestConfigPathsAddUnknownField(t *testing.T) { //nolint:dupl
 	checkError(t, "json: unknown field \"test\"", res.Body)
 }
 
+func TestConfigPathsAddInvalidField(t *testing.T) {
+	cnf := tempConf(t, "api: yes\n")
+
+	api := API{
+		Address:     "localhost:9997",
+		ReadTimeout: conf.Duration(10 * time.Second),
+		Conf:        cnf,
+		AuthManager: test.NilAuthManager,
+		Parent:      &testParent{},
+	}
+	err := api.Initialize()
+	require.NoError(t, err)
+	defer api.Close()
+
+	b := map[string]interface{}{
+		"recordVideo": false,
+		"recordAudio": false,
+	}
+
+	byts, err := json.Marshal(b)
+	require.NoError(t, err)
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	req, err := http.NewRequest(http.MethodPost,
+		"http://localhost:9997/v3/config/paths/add/mypath", bytes.NewReader(byts))
+	require.NoError(t, err)
+
+	res, err := hc.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	var resErr map[string]interface{}
+	err = json.NewDecoder(res.Body).Decode(&resErr)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"error": "at least one between 'recordVideo' and 'recordAudio' must be enabled",
+		"field": "paths.mypath.recordVideo",
+	}, resErr)
+}
+
 func TestConfigPathsPatch(t *testing.T) { //nolint:dupl
 	cnf := tempConf(t, "api: yes\n")
 
@@ -552,6 +601,65 @@ func TestConfigPathsDelete(t *testing.T) {
 	checkError(t, "path configuration not found", res.Body)
 }
 
+func TestConfigPathsDryRun(t *testing.T) {
+	cnf := tempConf(t, "api: yes\n"+
+		"paths:\n"+
+		"  existing:\n"+
+		"    source: rtsp://127.0.0.1:9999/mypath\n"+
+		"    sourceOnDemand: yes\n")
+
+	api := API{
+		Address:     "localhost:9997",
+		ReadTimeout: conf.Duration(10 * time.Second),
+		Conf:        cnf,
+		AuthManager: test.NilAuthManager,
+		Parent:      &testParent{},
+	}
+	err := api.Initialize()
+	require.NoError(t, err)
+	defer api.Close()
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	var out map[string]interface{}
+	httpRequest(t, hc, http.MethodPost, "http://localhost:9997/v3/config/paths/add/my/path?dryRun=true",
+		map[string]interface{}{
+			"source":         "rtsp://127.0.0.1:9999/mypath",
+			"sourceOnDemand": true,
+		}, &out)
+	require.Equal(t, map[string]interface{}{
+		"kickedSessions":   []interface{}{},
+		"changedGlobal":    false,
+		"changedPathConfs": []interface{}{"my/path"},
+		"affectedPaths":    []interface{}{},
+	}, out)
+
+	httpRequest(t, hc, http.MethodDelete, "http://localhost:9997/v3/config/paths/delete/existing?dryRun=true", nil, &out)
+	require.Equal(t, []interface{}{"existing"}, out["changedPathConfs"])
+
+	httpRequest(t, hc, http.MethodPatch, "http://localhost:9997/v3/config/global/patch?dryRun=true",
+		map[string]interface{}{
+			"readTimeout": "7s",
+		}, &out)
+	require.Equal(t, true, out["changedGlobal"])
+	require.Equal(t, []interface{}{}, out["changedPathConfs"])
+
+	res, err := hc.Get("http://localhost:9997/v3/config/paths/get/my/path")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res2, err := hc.Get("http://localhost:9997/v3/config/paths/get/existing")
+	require.NoError(t, err)
+	defer res2.Body.Close()
+	require.Equal(t, http.StatusOK, res2.StatusCode)
+
+	httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/config/global/get", nil, &out)
+	require.Equal(t, "10s", out["readTimeout"])
+}
+
 func TestRecordingsList(t *testing.T) {
 	dir, err := os.MkdirTemp("", "mediamtx-playback")
 	require.NoError(t, err)
@@ -671,6 +779,130 @@ func TestRecordingsGet(t *testing.T) {
 	}, out)
 }
 
+func writeSegment(t *testing.T, fpath string, codecs ...fmp4.Codec) int {
+	init := fmp4.Init{}
+	part := fmp4.Part{SequenceNumber: 1}
+
+	for i, codec := range codecs {
+		init.Tracks = append(init.Tracks, &fmp4.InitTrack{
+			ID:        i + 1,
+			TimeScale: 90000,
+			Codec:     codec,
+		})
+		part.Tracks = append(part.Tracks, &fmp4.PartTrack{
+			ID: i + 1,
+			Samples: []*fmp4.PartSample{{
+				Duration: 90000,
+				Payload:  []byte{1, 2},
+			}},
+		})
+	}
+
+	var buf seekablebuffer.Buffer
+	err := init.Marshal(&buf)
+	require.NoError(t, err)
+
+	err = part.Marshal(&buf)
+	require.NoError(t, err)
+
+	err = os.WriteFile(fpath, buf.Bytes(), 0o644)
+	require.NoError(t, err)
+
+	return len(buf.Bytes())
+}
+
+func TestRecordingsFind(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-playback")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cnf := tempConf(t, "pathDefaults:\n"+
+		"  recordPath: "+filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f")+"\n"+
+		"paths:\n"+
+		"  all_others:\n")
+
+	api := API{
+		Address:     "localhost:9997",
+		ReadTimeout: conf.Duration(10 * time.Second),
+		Conf:        cnf,
+		AuthManager: test.NilAuthManager,
+		Parent:      &testParent{},
+	}
+	err = api.Initialize()
+	require.NoError(t, err)
+	defer api.Close()
+
+	err = os.Mkdir(filepath.Join(dir, "mypath1"), 0o755)
+	require.NoError(t, err)
+
+	err = os.Mkdir(filepath.Join(dir, "mypath2"), 0o755)
+	require.NoError(t, err)
+
+	err = os.Mkdir(filepath.Join(dir, "mypath3"), 0o755)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "mypath1", "2008-11-07_11-22-00-500000.mp4"), []byte(""), 0o644)
+	require.NoError(t, err)
+
+	size1 := writeSegment(t, filepath.Join(dir, "mypath1", "2009-11-07_11-22-00-900000.mp4"),
+		&fmp4.CodecH264{
+			SPS: test.FormatH264.SPS,
+			PPS: test.FormatH264.PPS,
+		},
+		&fmp4.CodecMPEG4Audio{
+			Config: mpeg4audio.Config{
+				Type:         mpeg4audio.ObjectTypeAACLC,
+				SampleRate:   48000,
+				ChannelCount: 2,
+			},
+		})
+
+	size2 := writeSegment(t, filepath.Join(dir, "mypath2", "2009-11-07_11-22-00-900000.mp4"),
+		&fmp4.CodecOpus{
+			ChannelCount: 2,
+		})
+
+	err = os.WriteFile(filepath.Join(dir, "mypath3", "2010-11-07_11-22-00-900000.mp4"), []byte(""), 0o644)
+	require.NoError(t, err)
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	v := url.Values{}
+	v.Set("start", time.Date(2009, 11, 0o7, 11, 22, 1, 0, time.Local).Format(time.RFC3339))
+	v.Set("end", time.Date(2009, 11, 0o7, 11, 22, 10, 0, time.Local).Format(time.RFC3339))
+
+	var out interface{}
+	httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/recordings/find?"+v.Encode(), nil, &out)
+	require.Equal(t, map[string]interface{}{
+		"itemCount": float64(2),
+		"pageCount": float64(1),
+		"items": []interface{}{
+			map[string]interface{}{
+				"name": "mypath1",
+				"segments": []interface{}{
+					map[string]interface{}{
+						"start":  time.Date(2009, 11, 0o7, 11, 22, 0, 900000000, time.Local).Format(time.RFC3339Nano),
+						"size":   float64(size1),
+						"codecs": []interface{}{"H264", "MPEG-4 Audio"},
+					},
+				},
+			},
+			map[string]interface{}{
+				"name": "mypath2",
+				"segments": []interface{}{
+					map[string]interface{}{
+						"start":  time.Date(2009, 11, 0o7, 11, 22, 0, 900000000, time.Local).Format(time.RFC3339Nano),
+						"size":   float64(size2),
+						"codecs": []interface{}{"Opus"},
+					},
+				},
+			},
+		},
+	}, out)
+}
+
 func TestRecordingsDeleteSegment(t *testing.T) {
 	dir, err := os.MkdirTemp("", "mediamtx-playback")
 	require.NoError(t, err)
@@ -718,3 +950,96 @@ func TestRecordingsDeleteSegment(t *testing.T) {
 	defer res.Body.Close()
 	require.Equal(t, http.StatusOK, res.StatusCode)
 }
+
+func TestRecordingsImportSegment(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-playback")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cnf := tempConf(t, "pathDefaults:\n"+
+		"  recordPath: "+filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f")+"\n"+
+		"paths:\n"+
+		"  all_others:\n")
+
+	api := API{
+		Address:     "localhost:9997",
+		ReadTimeout: conf.Duration(10 * time.Second),
+		Conf:        cnf,
+		AuthManager: test.NilAuthManager,
+		Parent:      &testParent{},
+	}
+	err = api.Initialize()
+	require.NoError(t, err)
+	defer api.Close()
+
+	init := fmp4.Init{
+		Tracks: []*fmp4.InitTrack{{
+			ID:        1,
+			TimeScale: 90000,
+			Codec: &fmp4.CodecH264{
+				SPS: test.FormatH264.SPS,
+				PPS: test.FormatH264.PPS,
+			},
+		}},
+	}
+
+	var buf seekablebuffer.Buffer
+	err = init.Marshal(&buf)
+	require.NoError(t, err)
+
+	part := fmp4.Part{
+		SequenceNumber: 1,
+		Tracks: []*fmp4.PartTrack{{
+			ID: 1,
+			Samples: []*fmp4.PartSample{{
+				Duration: 90000,
+				Payload:  []byte{1, 2},
+			}},
+		}},
+	}
+	err = part.Marshal(&buf)
+	require.NoError(t, err)
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	for _, ca := range []string{"invalid", "valid"} {
+		t.Run(ca, func(t *testing.T) {
+			u, err := url.Parse("http://localhost:9997/v3/recordings/importsegment")
+			require.NoError(t, err)
+
+			v := url.Values{}
+			v.Set("path", "mypath1")
+			v.Set("start", time.Date(2008, 11, 0o7, 11, 22, 0, 900000000, time.Local).Format(time.RFC3339Nano))
+			u.RawQuery = v.Encode()
+
+			var body []byte
+			if ca == "valid" {
+				body = buf.Bytes()
+			} else {
+				body = []byte("invalid")
+			}
+
+			res, err := hc.Post(u.String(), "video/mp4", bytes.NewReader(body))
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			fpath := filepath.Join(dir, "mypath1", "2008-11-07_11-22-00-900000.mp4")
+
+			if ca == "valid" {
+				require.Equal(t, http.StatusOK, res.StatusCode)
+
+				var byts []byte
+				byts, err = os.ReadFile(fpath)
+				require.NoError(t, err)
+				require.Equal(t, buf.Bytes(), byts)
+			} else {
+				require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+				_, err = os.Stat(fpath)
+				require.Error(t, err)
+			}
+		})
+	}
+}
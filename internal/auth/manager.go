@@ -107,15 +107,36 @@ type Manager struct {
 	HTTPExclude     []conf.AuthInternalUserPermission
 	JWTJWKS         string
 	JWTClaimKey     string
+	TokenSecret     string
+	Script          string
 	ReadTimeout     time.Duration
 	RTSPAuthMethods []auth.ValidateMethod
 
 	mutex          sync.RWMutex
+	script         *script
 	jwtHTTPClient  *http.Client
 	jwtLastRefresh time.Time
 	jwtKeyFunc     keyfunc.Keyfunc
 }
 
+// Initialize initializes Manager.
+// It loads the authentication script, if any.
+func (m *Manager) Initialize() error {
+	if m.Script != "" {
+		m.script = &script{
+			fpath:   m.Script,
+			timeout: m.ReadTimeout,
+		}
+		err := m.script.initialize()
+		if err != nil {
+			m.script = nil
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ReloadInternalUsers reloads InternalUsers.
 func (m *Manager) ReloadInternalUsers(u []conf.AuthInternalUser) {
 	m.mutex.Lock()
@@ -124,9 +145,47 @@ func (m *Manager) ReloadInternalUsers(u []conf.AuthInternalUser) {
 }
 
 // Authenticate authenticates a request.
+// If the authentication script routes the request to another path, req.Path is updated.
 func (m *Manager) Authenticate(req *Request) error {
+	var newPath string
+
+	if m.script != nil {
+		var err error
+		newPath, err = m.script.evaluate(req)
+		if err != nil {
+			return &Error{
+				Message:        err.Error(),
+				AskCredentials: (req.User == "" && req.Pass == ""),
+			}
+		}
+	}
+
+	err := m.authenticateInner(req)
+	if err != nil {
+		return err
+	}
+
+	if newPath != "" {
+		req.Path = newPath
+	}
+
+	return nil
+}
+
+func (m *Manager) authenticateInner(req *Request) error {
 	var err error
 
+	// signed tokens are validated locally, regardless of the authentication method.
+	if m.TokenSecret != "" {
+		if token := tokenFromQuery(req.Query); token != "" {
+			err = verifyToken(m.TokenSecret, token, req)
+			if err != nil {
+				return &Error{Message: err.Error()}
+			}
+			return nil
+		}
+	}
+
 	switch m.Method {
 	case conf.AuthMethodInternal:
 		err = m.authenticateInternal(req)
@@ -8,6 +8,9 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -431,3 +434,245 @@ func TestAuthJWT(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthSignedToken(t *testing.T) {
+	for _, ca := range []string{
+		"ok",
+		"wrong path",
+		"wrong action",
+		"expired",
+		"wrong secret",
+	} {
+		t.Run(ca, func(t *testing.T) {
+			m := Manager{
+				Method:          conf.AuthMethodInternal,
+				InternalUsers:   []conf.AuthInternalUser{},
+				TokenSecret:     "0123456789abcdef",
+				RTSPAuthMethods: nil,
+			}
+
+			claims := TokenClaims{
+				Path:       "mypath",
+				Action:     conf.AuthActionPublish,
+				Expiration: time.Now().Add(time.Hour).Unix(),
+			}
+
+			switch ca {
+			case "wrong path":
+				claims.Path = "otherpath"
+
+			case "wrong action":
+				claims.Action = conf.AuthActionRead
+
+			case "expired":
+				claims.Expiration = time.Now().Add(-time.Hour).Unix()
+			}
+
+			secret := m.TokenSecret
+			if ca == "wrong secret" {
+				secret = "fedcba9876543210"
+			}
+
+			token, err := SignToken(secret, claims)
+			require.NoError(t, err)
+
+			err = m.Authenticate(&Request{
+				IP:     net.ParseIP("127.0.0.1"),
+				Action: conf.AuthActionPublish,
+				Path:   "mypath",
+				Query:  "token=" + token,
+			})
+
+			if ca == "ok" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthScript(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-auth-script")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "auth.lua")
+
+	err = os.WriteFile(fpath, []byte(
+		"function authorize(req)\n"+
+			"  if req.ip == \"127.0.0.2\" then\n"+
+			"    return false, \"blocked IP\"\n"+
+			"  end\n"+
+			"  if req.action == \"read\" and req.path == \"camera\" then\n"+
+			"    return true, \"camera_lowres\"\n"+
+			"  end\n"+
+			"  return true\n"+
+			"end\n"), 0o644)
+	require.NoError(t, err)
+
+	m := Manager{
+		Method: conf.AuthMethodInternal,
+		InternalUsers: []conf.AuthInternalUser{
+			{
+				User: "any",
+				Pass: "",
+				Permissions: []conf.AuthInternalUserPermission{
+					{
+						Action: conf.AuthActionPublish,
+					},
+					{
+						Action: conf.AuthActionRead,
+					},
+				},
+			},
+		},
+		Script:      fpath,
+		ReadTimeout: 10 * time.Second,
+	}
+	err = m.Initialize()
+	require.NoError(t, err)
+
+	req := &Request{
+		IP:     net.ParseIP("127.0.0.2"),
+		Action: conf.AuthActionPublish,
+		Path:   "camera",
+	}
+	err = m.Authenticate(req)
+	require.EqualError(t, err, "authentication failed: denied by script: blocked IP")
+
+	req = &Request{
+		IP:     net.ParseIP("127.0.0.1"),
+		Action: conf.AuthActionPublish,
+		Path:   "camera",
+	}
+	err = m.Authenticate(req)
+	require.NoError(t, err)
+	require.Equal(t, "camera", req.Path)
+
+	req = &Request{
+		IP:     net.ParseIP("127.0.0.1"),
+		Action: conf.AuthActionRead,
+		Path:   "camera",
+	}
+	err = m.Authenticate(req)
+	require.NoError(t, err)
+	require.Equal(t, "camera_lowres", req.Path)
+
+	req = &Request{
+		IP:     net.ParseIP("127.0.0.1"),
+		Action: conf.AuthActionPlayback,
+		Path:   "camera",
+	}
+	err = m.Authenticate(req)
+	require.Error(t, err)
+}
+
+func TestAuthScriptLoadError(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-auth-script")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, ca := range []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			"missing",
+			"",
+			"unable to load script: open FPATH: no such file or directory",
+		},
+		{
+			"syntax error",
+			"function authorize(req)\n",
+			"unable to load script: FPATH at EOF:   syntax error\n",
+		},
+		{
+			"missing function",
+			"x = 1\n",
+			"script does not define the 'authorize' function",
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			fpath := filepath.Join(dir, "auth.lua")
+			os.Remove(fpath)
+
+			if ca.name != "missing" {
+				err = os.WriteFile(fpath, []byte(ca.content), 0o644)
+				require.NoError(t, err)
+			}
+
+			m := Manager{
+				Method:      conf.AuthMethodInternal,
+				Script:      fpath,
+				ReadTimeout: 10 * time.Second,
+			}
+			err = m.Initialize()
+			require.EqualError(t, err, strings.ReplaceAll(ca.err, "FPATH", fpath))
+		})
+	}
+}
+
+func TestAuthScriptParallel(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-auth-script")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "auth.lua")
+
+	err = os.WriteFile(fpath, []byte(
+		"function authorize(req)\n"+
+			"  if req.path == \"slow\" then\n"+
+			"    while true do end\n"+
+			"  end\n"+
+			"  return true\n"+
+			"end\n"), 0o644)
+	require.NoError(t, err)
+
+	m := Manager{
+		Method: conf.AuthMethodInternal,
+		InternalUsers: []conf.AuthInternalUser{{
+			User:        "any",
+			Permissions: []conf.AuthInternalUserPermission{{Action: conf.AuthActionRead}},
+		}},
+		Script:      fpath,
+		ReadTimeout: 1 * time.Second,
+	}
+	err = m.Initialize()
+	require.NoError(t, err)
+
+	slowDone := make(chan error)
+
+	go func() {
+		slowDone <- m.Authenticate(&Request{
+			IP:     net.ParseIP("127.0.0.1"),
+			Action: conf.AuthActionRead,
+			Path:   "slow",
+		})
+	}()
+
+	// other requests are not blocked by the slow one
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	err = m.Authenticate(&Request{
+		IP:     net.ParseIP("127.0.0.1"),
+		Action: conf.AuthActionRead,
+		Path:   "fast",
+	})
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+
+	err = <-slowDone
+	require.Error(t, err)
+
+	// states are usable after a timeout
+	for i := 0; i < scriptPoolSize*2; i++ {
+		err = m.Authenticate(&Request{
+			IP:     net.ParseIP("127.0.0.1"),
+			Action: conf.AuthActionRead,
+			Path:   "fast",
+		})
+		require.NoError(t, err)
+	}
+}
@@ -31,6 +31,8 @@ const (
 	ProtocolRTSP   Protocol = "rtsp"
 	ProtocolRTMP   Protocol = "rtmp"
 	ProtocolHLS    Protocol = "hls"
+	ProtocolDASH   Protocol = "dash"
+	ProtocolMJPEG  Protocol = "mjpeg"
 	ProtocolWebRTC Protocol = "webrtc"
 	ProtocolSRT    Protocol = "srt"
 )
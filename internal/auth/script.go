@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+)
+
+const (
+	scriptFunction = "authorize"
+	scriptPoolSize = 4
+)
+
+// script is a Lua script that can allow, deny or route requests.
+// The script is loaded into a pool of independent Lua states,
+// in order to evaluate several requests in parallel.
+type script struct {
+	fpath   string
+	timeout time.Duration
+
+	proto *lua.FunctionProto
+	pool  chan *lua.LState
+}
+
+func (s *script) initialize() error {
+	f, err := os.Open(s.fpath)
+	if err != nil {
+		return fmt.Errorf("unable to load script: %w", err)
+	}
+	defer f.Close()
+
+	chunk, err := parse.Parse(f, s.fpath)
+	if err != nil {
+		return fmt.Errorf("unable to load script: %w", err)
+	}
+
+	s.proto, err = lua.Compile(chunk, s.fpath)
+	if err != nil {
+		return fmt.Errorf("unable to load script: %w", err)
+	}
+
+	s.pool = make(chan *lua.LState, scriptPoolSize)
+
+	for i := 0; i < scriptPoolSize; i++ {
+		var state *lua.LState
+		state, err = s.newState()
+		if err != nil {
+			close(s.pool)
+			for prev := range s.pool {
+				prev.Close()
+			}
+			return err
+		}
+
+		s.pool <- state
+	}
+
+	return nil
+}
+
+func (s *script) newState() (*lua.LState, error) {
+	state := lua.NewState()
+
+	state.Push(state.NewFunctionFromProto(s.proto))
+	err := state.PCall(0, lua.MultRet, nil)
+	if err != nil {
+		state.Close()
+		return nil, fmt.Errorf("unable to load script: %w", err)
+	}
+
+	if state.GetGlobal(scriptFunction).Type() != lua.LTFunction {
+		state.Close()
+		return nil, fmt.Errorf("script does not define the '%s' function", scriptFunction)
+	}
+
+	return state, nil
+}
+
+// evaluate calls the script with the request.
+// It returns an error if the request is denied,
+// otherwise the path the request must be routed to, or an empty string.
+func (s *script) evaluate(req *Request) (string, error) {
+	state := <-s.pool
+	defer func() {
+		// discard values left on the stack by a failed call
+		state.SetTop(0)
+		s.pool <- state
+	}()
+
+	// prevent a script from blocking the server
+	if s.timeout != 0 {
+		ctx, ctxCancel := context.WithTimeout(context.Background(), s.timeout)
+		defer ctxCancel()
+
+		state.SetContext(ctx)
+		defer state.RemoveContext()
+	}
+
+	tbl := state.NewTable()
+	tbl.RawSetString("user", lua.LString(req.User))
+	tbl.RawSetString("pass", lua.LString(req.Pass))
+	tbl.RawSetString("ip", lua.LString(req.IP.String()))
+	tbl.RawSetString("action", lua.LString(req.Action))
+	tbl.RawSetString("path", lua.LString(req.Path))
+	tbl.RawSetString("protocol", lua.LString(req.Protocol))
+	tbl.RawSetString("query", lua.LString(req.Query))
+	if req.ID != nil {
+		tbl.RawSetString("id", lua.LString(req.ID.String()))
+	}
+
+	err := state.CallByParam(lua.P{
+		Fn:      state.GetGlobal(scriptFunction),
+		NRet:    2,
+		Protect: true,
+	}, tbl)
+	if err != nil {
+		return "", fmt.Errorf("script failed: %w", err)
+	}
+
+	allowed := lua.LVAsBool(state.Get(-2))
+	msg := state.Get(-1)
+
+	if !allowed {
+		if str, ok := msg.(lua.LString); ok && str != "" {
+			return "", fmt.Errorf("denied by script: %s", string(str))
+		}
+		return "", fmt.Errorf("denied by script")
+	}
+
+	if str, ok := msg.(lua.LString); ok && str != "" {
+		if req.Action != conf.AuthActionPublish && req.Action != conf.AuthActionRead {
+			return "", fmt.Errorf("script returned a path for action '%s'", req.Action)
+		}
+		return string(str), nil
+	}
+
+	return "", nil
+}
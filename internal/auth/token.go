@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+)
+
+// TokenClaims are the claims of a signed token.
+type TokenClaims struct {
+	Path       string          `json:"path"`
+	Action     conf.AuthAction `json:"action"`
+	Expiration int64           `json:"exp"`
+}
+
+func tokenSignature(secret string, payload string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// SignToken generates a token that allows to perform a single action on a single path,
+// that can be validated by any server that shares the same secret.
+func SignToken(secret string, claims TokenClaims) (string, error) {
+	byts, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(byts)
+	return payload + "." + tokenSignature(secret, payload), nil
+}
+
+func verifyToken(secret string, token string, req *Request) error {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return fmt.Errorf("invalid token")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(tokenSignature(secret, payload))) {
+		return fmt.Errorf("invalid token signature")
+	}
+
+	byts, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return fmt.Errorf("invalid token")
+	}
+
+	var claims TokenClaims
+	err = json.Unmarshal(byts, &claims)
+	if err != nil {
+		return fmt.Errorf("invalid token")
+	}
+
+	if time.Now().Unix() >= claims.Expiration {
+		return fmt.Errorf("token is expired")
+	}
+
+	if claims.Action != req.Action || claims.Path != req.Path {
+		return fmt.Errorf("token doesn't allow to perform action")
+	}
+
+	return nil
+}
+
+func tokenFromQuery(rawQuery string) string {
+	v, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	return v.Get("token")
+}
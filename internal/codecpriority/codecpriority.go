@@ -0,0 +1,35 @@
+// Package codecpriority contains utilities to select tracks by codec priority.
+package codecpriority
+
+import (
+	"strings"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+)
+
+// Select returns the format of the stream whose codec comes first in the priority list,
+// among the ones that satisfy accept().
+// It returns nil if none of the listed codecs is available.
+func Select(
+	desc *description.Session,
+	priority []string,
+	accept func(format.Format) bool,
+) format.Format {
+	for _, codec := range priority {
+		for _, media := range desc.Medias {
+			for _, forma := range media.Formats {
+				if strings.EqualFold(forma.Codec(), codec) && accept(forma) {
+					return forma
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Allowed returns whether a format can be used, given the format returned by Select().
+func Allowed(selected format.Format, forma format.Format) bool {
+	return selected == nil || selected == forma
+}
@@ -0,0 +1,36 @@
+package codecpriority
+
+import (
+	"testing"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/test"
+)
+
+func TestSelect(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{
+		test.UniqueMediaH264(),
+		{
+			Type:    description.MediaTypeVideo,
+			Formats: []format.Format{test.FormatH265},
+		},
+	}}
+
+	acceptAll := func(format.Format) bool { return true }
+
+	require.Nil(t, Select(desc, nil, acceptAll))
+	require.Equal(t, format.Format(test.FormatH264), Select(desc, []string{"h264", "H265"}, acceptAll))
+	require.Equal(t, format.Format(test.FormatH265), Select(desc, []string{"AV1", "H265", "H264"}, acceptAll))
+	require.Equal(t, format.Format(test.FormatH264), Select(desc, []string{"H265", "H264"},
+		func(forma format.Format) bool {
+			_, ok := forma.(*format.H265)
+			return !ok
+		}))
+
+	require.True(t, Allowed(nil, test.FormatH265))
+	require.True(t, Allowed(test.FormatH265, test.FormatH265))
+	require.False(t, Allowed(test.FormatH265, test.FormatH264))
+}
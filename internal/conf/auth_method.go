@@ -61,3 +61,8 @@ func (d *AuthMethod) UnmarshalJSON(b []byte) error {
 func (d *AuthMethod) UnmarshalEnv(_ string, v string) error {
 	return d.UnmarshalJSON([]byte(`"` + v + `"`))
 }
+
+// enumValues implements schemaEnum.
+func (AuthMethod) enumValues() []string {
+	return []string{"internal", "http", "jwt"}
+}
@@ -0,0 +1,30 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// codecs whose priority can be set, since outputs
+// are able to choose between them.
+var prioritizableCodecs = []string{
+	"AV1", "VP9", "VP8", "H265", "H264",
+	"Opus", "G722", "G711", "LPCM",
+}
+
+func checkCodecPriority(codecs []string) error {
+	for _, codec := range codecs {
+		found := false
+		for _, c := range prioritizableCodecs {
+			if strings.EqualFold(codec, c) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("unsupported codec '%s', supported codecs are %v", codec, prioritizableCodecs)
+		}
+	}
+	return nil
+}
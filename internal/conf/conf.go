@@ -9,6 +9,7 @@ import (
 	"net"
 	"os"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -26,6 +27,12 @@ import (
 // ErrPathNotFound is returned when a path is not found.
 var ErrPathNotFound = errors.New("path not found")
 
+const (
+	lowMemoryWriteQueueSize    = 128
+	lowMemorySegmentCount      = 3
+	lowMemoryHLSSegmentMaxSize = 10 * 1024 * 1024
+)
+
 func sortedKeys(paths map[string]*OptionalPath) []string {
 	ret := make([]string, len(paths))
 	i := 0
@@ -158,26 +165,35 @@ var defaultAuthInternalUsers = AuthInternalUsers{
 // WARNING: Avoid using slices directly due to https://github.com/golang/go/issues/21092
 type Conf struct {
 	// General
-	LogLevel            LogLevel        `json:"logLevel"`
-	LogDestinations     LogDestinations `json:"logDestinations"`
-	LogFile             string          `json:"logFile"`
-	ReadTimeout         Duration        `json:"readTimeout"`
-	WriteTimeout        Duration        `json:"writeTimeout"`
-	ReadBufferCount     *int            `json:"readBufferCount,omitempty"` // deprecated
-	WriteQueueSize      int             `json:"writeQueueSize"`
-	UDPMaxPayloadSize   int             `json:"udpMaxPayloadSize"`
-	RunOnConnect        string          `json:"runOnConnect"`
-	RunOnConnectRestart bool            `json:"runOnConnectRestart"`
-	RunOnDisconnect     string          `json:"runOnDisconnect"`
+	LogLevel             LogLevel         `json:"logLevel"`
+	LogDestinations      LogDestinations  `json:"logDestinations"`
+	LogFile              string           `json:"logFile"`
+	LogCommandOutput     bool             `json:"logCommandOutput"`
+	ReadTimeout          Duration         `json:"readTimeout"`
+	WriteTimeout         Duration         `json:"writeTimeout"`
+	ReadBufferCount      *int             `json:"readBufferCount,omitempty" deprecated:"true"`
+	WriteQueueSize       int              `json:"writeQueueSize"`
+	ReaderIdleTimeout    Duration         `json:"readerIdleTimeout"`
+	UDPMaxPayloadSize    int              `json:"udpMaxPayloadSize"`
+	LowMemory            bool             `json:"lowMemory"`
+	PathRewriteRules     PathRewriteRules `json:"pathRewriteRules"`
+	RunOnConnect         string           `json:"runOnConnect"`
+	RunOnConnectRestart  bool             `json:"runOnConnectRestart"`
+	RunOnDisconnect      string           `json:"runOnDisconnect"`
+	CrashReport          bool             `json:"crashReport"`
+	CrashReportDirectory string           `json:"crashReportDirectory"`
+	CrashReportURL       string           `json:"crashReportURL"`
 
 	// Authentication
 	AuthMethod                AuthMethod                  `json:"authMethod"`
 	AuthInternalUsers         AuthInternalUsers           `json:"authInternalUsers"`
 	AuthHTTPAddress           string                      `json:"authHTTPAddress"`
-	ExternalAuthenticationURL *string                     `json:"externalAuthenticationURL,omitempty"` // deprecated
+	ExternalAuthenticationURL *string                     `json:"externalAuthenticationURL,omitempty" deprecated:"true"`
 	AuthHTTPExclude           AuthInternalUserPermissions `json:"authHTTPExclude"`
 	AuthJWTJWKS               string                      `json:"authJWTJWKS"`
 	AuthJWTClaimKey           string                      `json:"authJWTClaimKey"`
+	AuthTokenSecret           string                      `json:"authTokenSecret"`
+	AuthScript                string                      `json:"authScript"`
 
 	// Control API
 	API               bool       `json:"api"`
@@ -196,6 +212,7 @@ type Conf struct {
 	MetricsServerCert     string     `json:"metricsServerCert"`
 	MetricsAllowOrigin    string     `json:"metricsAllowOrigin"`
 	MetricsTrustedProxies IPNetworks `json:"metricsTrustedProxies"`
+	MetricsLegacyNames    bool       `json:"metricsLegacyNames"`
 
 	// PPROF
 	PPROF               bool       `json:"pprof"`
@@ -214,13 +231,15 @@ type Conf struct {
 	PlaybackServerCert     string     `json:"playbackServerCert"`
 	PlaybackAllowOrigin    string     `json:"playbackAllowOrigin"`
 	PlaybackTrustedProxies IPNetworks `json:"playbackTrustedProxies"`
+	PlaybackMaxRequests    int        `json:"playbackMaxRequests"`
+	PlaybackRate           float64    `json:"playbackRate"`
 
 	// RTSP server
 	RTSP              bool             `json:"rtsp"`
-	RTSPDisable       *bool            `json:"rtspDisable,omitempty"` // deprecated
-	Protocols         *RTSPTransports  `json:"protocols,omitempty"`   // deprecated
+	RTSPDisable       *bool            `json:"rtspDisable,omitempty" deprecated:"true"`
+	Protocols         *RTSPTransports  `json:"protocols,omitempty" deprecated:"true"`
 	RTSPTransports    RTSPTransports   `json:"rtspTransports"`
-	Encryption        *Encryption      `json:"encryption,omitempty"` // deprecated
+	Encryption        *Encryption      `json:"encryption,omitempty" deprecated:"true"`
 	RTSPEncryption    Encryption       `json:"rtspEncryption"`
 	RTSPAddress       string           `json:"rtspAddress"`
 	RTSPSAddress      string           `json:"rtspsAddress"`
@@ -229,16 +248,16 @@ type Conf struct {
 	MulticastIPRange  string           `json:"multicastIPRange"`
 	MulticastRTPPort  int              `json:"multicastRTPPort"`
 	MulticastRTCPPort int              `json:"multicastRTCPPort"`
-	ServerKey         *string          `json:"serverKey,omitempty"`
-	ServerCert        *string          `json:"serverCert,omitempty"`
+	ServerKey         *string          `json:"serverKey,omitempty" deprecated:"true"`
+	ServerCert        *string          `json:"serverCert,omitempty" deprecated:"true"`
 	RTSPServerKey     string           `json:"rtspServerKey"`
 	RTSPServerCert    string           `json:"rtspServerCert"`
-	AuthMethods       *RTSPAuthMethods `json:"authMethods,omitempty"` // deprecated
+	AuthMethods       *RTSPAuthMethods `json:"authMethods,omitempty" deprecated:"true"`
 	RTSPAuthMethods   RTSPAuthMethods  `json:"rtspAuthMethods"`
 
 	// RTMP server
 	RTMP           bool       `json:"rtmp"`
-	RTMPDisable    *bool      `json:"rtmpDisable,omitempty"` // deprecated
+	RTMPDisable    *bool      `json:"rtmpDisable,omitempty" deprecated:"true"`
 	RTMPAddress    string     `json:"rtmpAddress"`
 	RTMPEncryption Encryption `json:"rtmpEncryption"`
 	RTMPSAddress   string     `json:"rtmpsAddress"`
@@ -247,7 +266,7 @@ type Conf struct {
 
 	// HLS server
 	HLS                bool       `json:"hls"`
-	HLSDisable         *bool      `json:"hlsDisable,omitempty"` // deprecated
+	HLSDisable         *bool      `json:"hlsDisable,omitempty" deprecated:"true"`
 	HLSAddress         string     `json:"hlsAddress"`
 	HLSEncryption      bool       `json:"hlsEncryption"`
 	HLSServerKey       string     `json:"hlsServerKey"`
@@ -262,10 +281,32 @@ type Conf struct {
 	HLSSegmentMaxSize  StringSize `json:"hlsSegmentMaxSize"`
 	HLSDirectory       string     `json:"hlsDirectory"`
 	HLSMuxerCloseAfter Duration   `json:"hlsMuxerCloseAfter"`
+	HLSSessionTokens   bool       `json:"hlsSessionTokens"`
+
+	// DASH server
+	DASH                bool       `json:"dash"`
+	DASHAddress         string     `json:"dashAddress"`
+	DASHEncryption      bool       `json:"dashEncryption"`
+	DASHServerKey       string     `json:"dashServerKey"`
+	DASHServerCert      string     `json:"dashServerCert"`
+	DASHAllowOrigin     string     `json:"dashAllowOrigin"`
+	DASHTrustedProxies  IPNetworks `json:"dashTrustedProxies"`
+	DASHSegmentCount    int        `json:"dashSegmentCount"`
+	DASHSegmentDuration Duration   `json:"dashSegmentDuration"`
+	DASHMuxerCloseAfter Duration   `json:"dashMuxerCloseAfter"`
+
+	// MJPEG server
+	MJPEG               bool       `json:"mjpeg"`
+	MJPEGAddress        string     `json:"mjpegAddress"`
+	MJPEGEncryption     bool       `json:"mjpegEncryption"`
+	MJPEGServerKey      string     `json:"mjpegServerKey"`
+	MJPEGServerCert     string     `json:"mjpegServerCert"`
+	MJPEGAllowOrigin    string     `json:"mjpegAllowOrigin"`
+	MJPEGTrustedProxies IPNetworks `json:"mjpegTrustedProxies"`
 
 	// WebRTC server
 	WebRTC                      bool             `json:"webrtc"`
-	WebRTCDisable               *bool            `json:"webrtcDisable,omitempty"` // deprecated
+	WebRTCDisable               *bool            `json:"webrtcDisable,omitempty" deprecated:"true"`
 	WebRTCAddress               string           `json:"webrtcAddress"`
 	WebRTCEncryption            bool             `json:"webrtcEncryption"`
 	WebRTCServerKey             string           `json:"webrtcServerKey"`
@@ -280,22 +321,39 @@ type Conf struct {
 	WebRTCICEServers2           WebRTCICEServers `json:"webrtcICEServers2"`
 	WebRTCHandshakeTimeout      Duration         `json:"webrtcHandshakeTimeout"`
 	WebRTCTrackGatherTimeout    Duration         `json:"webrtcTrackGatherTimeout"`
-	WebRTCICEUDPMuxAddress      *string          `json:"webrtcICEUDPMuxAddress,omitempty"`  // deprecated
-	WebRTCICETCPMuxAddress      *string          `json:"webrtcICETCPMuxAddress,omitempty"`  // deprecated
-	WebRTCICEHostNAT1To1IPs     *[]string        `json:"webrtcICEHostNAT1To1IPs,omitempty"` // deprecated
-	WebRTCICEServers            *[]string        `json:"webrtcICEServers,omitempty"`        // deprecated
+	WebRTCICEUDPMuxAddress      *string          `json:"webrtcICEUDPMuxAddress,omitempty" deprecated:"true"`
+	WebRTCICETCPMuxAddress      *string          `json:"webrtcICETCPMuxAddress,omitempty" deprecated:"true"`
+	WebRTCICEHostNAT1To1IPs     *[]string        `json:"webrtcICEHostNAT1To1IPs,omitempty" deprecated:"true"`
+	WebRTCICEServers            *[]string        `json:"webrtcICEServers,omitempty" deprecated:"true"`
 
 	// SRT server
-	SRT        bool   `json:"srt"`
-	SRTAddress string `json:"srtAddress"`
+	SRT           bool     `json:"srt"`
+	SRTAddress    string   `json:"srtAddress"`
+	SRTLatency    Duration `json:"srtLatency"`
+	SRTOverheadBW int      `json:"srtOverheadBW"`
+
+	// RTP push
+	RTPPush        bool   `json:"rtpPush"`
+	RTPPushAddress string `json:"rtpPushAddress"`
+
+	// ONVIF discovery
+	ONVIFDiscovery         bool     `json:"onvifDiscovery"`
+	ONVIFDiscoveryInterval Duration `json:"onvifDiscoveryInterval"`
+	ONVIFDiscoveryUser     string   `json:"onvifDiscoveryUser"`
+	ONVIFDiscoveryPass     string   `json:"onvifDiscoveryPass"`
+	ONVIFDiscoveryPathName string   `json:"onvifDiscoveryPathName"`
+
+	// Dynamic DNS
+	DDNSUpdateURL      string   `json:"ddnsUpdateURL"`
+	DDNSUpdateInterval Duration `json:"ddnsUpdateInterval"`
 
 	// Record (deprecated)
-	Record                *bool         `json:"record,omitempty"`                // deprecated
-	RecordPath            *string       `json:"recordPath,omitempty"`            // deprecated
-	RecordFormat          *RecordFormat `json:"recordFormat,omitempty"`          // deprecated
-	RecordPartDuration    *Duration     `json:"recordPartDuration,omitempty"`    // deprecated
-	RecordSegmentDuration *Duration     `json:"recordSegmentDuration,omitempty"` // deprecated
-	RecordDeleteAfter     *Duration     `json:"recordDeleteAfter,omitempty"`     // deprecated
+	Record                *bool         `json:"record,omitempty" deprecated:"true"`
+	RecordPath            *string       `json:"recordPath,omitempty" deprecated:"true"`
+	RecordFormat          *RecordFormat `json:"recordFormat,omitempty" deprecated:"true"`
+	RecordPartDuration    *Duration     `json:"recordPartDuration,omitempty" deprecated:"true"`
+	RecordSegmentDuration *Duration     `json:"recordSegmentDuration,omitempty" deprecated:"true"`
+	RecordDeleteAfter     *Duration     `json:"recordDeleteAfter,omitempty" deprecated:"true"`
 
 	// Path defaults
 	PathDefaults Path `json:"pathDefaults"`
@@ -314,6 +372,8 @@ func (conf *Conf) setDefaults() {
 	conf.WriteTimeout = 10 * Duration(time.Second)
 	conf.WriteQueueSize = 512
 	conf.UDPMaxPayloadSize = 1472
+	conf.PathRewriteRules = []PathRewriteRule{}
+	conf.CrashReportDirectory = "crashes"
 
 	// Authentication
 	conf.AuthInternalUsers = defaultAuthInternalUsers
@@ -392,6 +452,21 @@ func (conf *Conf) setDefaults() {
 	conf.HLSSegmentMaxSize = 50 * 1024 * 1024
 	conf.HLSMuxerCloseAfter = 60 * Duration(time.Second)
 
+	// DASH
+	conf.DASHAddress = ":8887"
+	conf.DASHServerKey = "server.key"
+	conf.DASHServerCert = "server.crt"
+	conf.DASHAllowOrigin = "*"
+	conf.DASHSegmentCount = 7
+	conf.DASHSegmentDuration = 1 * Duration(time.Second)
+	conf.DASHMuxerCloseAfter = 60 * Duration(time.Second)
+
+	// MJPEG
+	conf.MJPEGAddress = ":8886"
+	conf.MJPEGServerKey = "server.key"
+	conf.MJPEGServerCert = "server.crt"
+	conf.MJPEGAllowOrigin = "*"
+
 	// WebRTC server
 	conf.WebRTC = true
 	conf.WebRTCAddress = ":8889"
@@ -409,6 +484,15 @@ func (conf *Conf) setDefaults() {
 	// SRT server
 	conf.SRT = true
 	conf.SRTAddress = ":8890"
+	conf.SRTLatency = 120 * Duration(time.Millisecond)
+	conf.SRTOverheadBW = 25
+
+	// ONVIF discovery
+	conf.ONVIFDiscoveryInterval = 60 * Duration(time.Second)
+	conf.ONVIFDiscoveryPathName = "onvif_%host"
+
+	// Dynamic DNS
+	conf.DDNSUpdateInterval = 5 * Duration(time.Minute)
 
 	conf.PathDefaults.setDefaults()
 }
@@ -417,17 +501,31 @@ func (conf *Conf) setDefaults() {
 func Load(fpath string, defaultConfPaths []string, l logger.Writer) (*Conf, string, error) {
 	conf := &Conf{}
 
-	fpath, err := conf.loadFromFile(fpath, defaultConfPaths)
+	key, hasKey, err := confKey()
 	if err != nil {
 		return nil, "", err
 	}
 
-	err = env.Load("RTSP", conf) // legacy prefix
+	fpath, err = conf.loadFromFile(fpath, defaultConfPaths, key, hasKey)
 	if err != nil {
 		return nil, "", err
 	}
 
-	err = env.Load("MTX", conf)
+	envMap := env.Environ()
+
+	if hasKey {
+		err = decryptEnv(key, envMap)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	err = env.LoadMap(envMap, "RTSP", conf) // legacy prefix
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = env.LoadMap(envMap, "MTX", conf)
 	if err != nil {
 		return nil, "", err
 	}
@@ -440,7 +538,47 @@ func Load(fpath string, defaultConfPaths []string, l logger.Writer) (*Conf, stri
 	return conf, fpath, nil
 }
 
-func (conf *Conf) loadFromFile(fpath string, defaultConfPaths []string) (string, error) {
+// confKey returns the key used to decrypt the configuration.
+func confKey() (string, bool, error) {
+	if key, ok := os.LookupEnv("MTX_CONFKEY"); ok {
+		return key, true, nil
+	}
+
+	if fpath, ok := os.LookupEnv("MTX_CONFKEY_FILE"); ok {
+		byts, err := os.ReadFile(fpath)
+		if err != nil {
+			return "", false, fmt.Errorf("unable to read MTX_CONFKEY_FILE: %w", err)
+		}
+		return strings.TrimRight(string(byts), "\r\n"), true, nil
+	}
+
+	return "", false, nil
+}
+
+// decryptEnv decrypts values of environment variables that are prefixed with decrypt.ValuePrefix.
+func decryptEnv(key string, envMap map[string]string) error {
+	for k, v := range envMap {
+		if (!strings.HasPrefix(k, "MTX_") && !strings.HasPrefix(k, "RTSP_")) ||
+			!strings.HasPrefix(v, decrypt.ValuePrefix) {
+			continue
+		}
+
+		dec, err := decrypt.DecryptValue(key, v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		envMap[k] = dec
+	}
+
+	return nil
+}
+
+func (conf *Conf) loadFromFile(
+	fpath string,
+	defaultConfPaths []string,
+	key string,
+	hasKey bool,
+) (string, error) {
 	if fpath == "" {
 		fpath = firstThatExists(defaultConfPaths)
 
@@ -464,8 +602,12 @@ func (conf *Conf) loadFromFile(fpath string, defaultConfPaths []string) (string,
 		}
 	}
 
-	if key, ok := os.LookupEnv("MTX_CONFKEY"); ok {
-		byts, err = decrypt.Decrypt(key, byts)
+	if hasKey {
+		if decrypt.IsEncrypted(byts) {
+			byts, err = decrypt.Decrypt(key, byts)
+		} else {
+			byts, err = decrypt.DecryptValues(key, byts)
+		}
 		if err != nil {
 			return "", err
 		}
@@ -509,20 +651,40 @@ func (conf *Conf) Validate(l logger.Writer) error {
 	// General
 
 	if conf.ReadTimeout <= 0 {
-		return fmt.Errorf("'readTimeout' must be greater than zero")
+		return newValidationError("readTimeout", "'readTimeout' must be greater than zero")
 	}
 	if conf.WriteTimeout <= 0 {
-		return fmt.Errorf("'writeTimeout' must be greater than zero")
+		return newValidationError("writeTimeout", "'writeTimeout' must be greater than zero")
 	}
 	if conf.ReadBufferCount != nil {
 		l.Log(logger.Warn, "parameter 'readBufferCount' is deprecated and has been replaced with 'writeQueueSize'")
 		conf.WriteQueueSize = *conf.ReadBufferCount
 	}
 	if (conf.WriteQueueSize & (conf.WriteQueueSize - 1)) != 0 {
-		return fmt.Errorf("'writeQueueSize' must be a power of two")
+		return newValidationError("writeQueueSize", "'writeQueueSize' must be a power of two")
+	}
+	if conf.ReaderIdleTimeout < 0 {
+		return newValidationError("readerIdleTimeout", "'readerIdleTimeout' must be greater than or equal to zero")
 	}
 	if conf.UDPMaxPayloadSize > 1472 {
-		return fmt.Errorf("'udpMaxPayloadSize' must be less than 1472")
+		return newValidationError("udpMaxPayloadSize", "'udpMaxPayloadSize' must be less than 1472")
+	}
+	for _, rule := range conf.PathRewriteRules {
+		if rule.Match == "" {
+			return newValidationError("pathRewriteRules", "path rewrite rules must have a 'match' regular expression")
+		}
+		_, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return newValidationError("pathRewriteRules", "invalid path rewrite rule '%s': %v", rule.Match, err)
+		}
+	}
+	if conf.CrashReport && conf.CrashReportDirectory == "" {
+		return newValidationError("crashReportDirectory", "'crashReportDirectory' must not be empty")
+	}
+	if conf.CrashReportURL != "" &&
+		!strings.HasPrefix(conf.CrashReportURL, "http://") &&
+		!strings.HasPrefix(conf.CrashReportURL, "https://") {
+		return newValidationError("crashReportURL", "'crashReportURL' must be a HTTP URL")
 	}
 
 	// Authentication
@@ -536,12 +698,12 @@ func (conf *Conf) Validate(l logger.Writer) error {
 	if conf.AuthHTTPAddress != "" &&
 		!strings.HasPrefix(conf.AuthHTTPAddress, "http://") &&
 		!strings.HasPrefix(conf.AuthHTTPAddress, "https://") {
-		return fmt.Errorf("'externalAuthenticationURL' must be a HTTP URL")
+		return newValidationError("externalAuthenticationURL", "'externalAuthenticationURL' must be a HTTP URL")
 	}
 	if conf.AuthJWTJWKS != "" &&
 		!strings.HasPrefix(conf.AuthJWTJWKS, "http://") &&
 		!strings.HasPrefix(conf.AuthJWTJWKS, "https://") {
-		return fmt.Errorf("'authJWTJWKS' must be a HTTP URL")
+		return newValidationError("authJWTJWKS", "'authJWTJWKS' must be a HTTP URL")
 	}
 	deprecatedCredentialsMode := false
 	if anyPathHasDeprecatedCredentials(conf.PathDefaults, conf.OptionalPaths) {
@@ -550,7 +712,7 @@ func (conf *Conf) Validate(l logger.Writer) error {
 			"These have been replaced by 'authInternalUsers'")
 
 		if conf.AuthInternalUsers != nil && !reflect.DeepEqual(conf.AuthInternalUsers, defaultAuthInternalUsers) {
-			return fmt.Errorf("authInternalUsers and legacy credentials " +
+			return newValidationError("authInternalUsers", "authInternalUsers and legacy credentials "+
 				"(publishUser, publishPass, publishIPs, readUser, readPass, readIPs) cannot be used together")
 		}
 
@@ -584,18 +746,38 @@ func (conf *Conf) Validate(l logger.Writer) error {
 	switch conf.AuthMethod {
 	case AuthMethodHTTP:
 		if conf.AuthHTTPAddress == "" {
-			return fmt.Errorf("'authHTTPAddress' is empty")
+			return newValidationError("authHTTPAddress", "'authHTTPAddress' is empty")
 		}
 
 	case AuthMethodJWT:
 		if conf.AuthJWTJWKS == "" {
-			return fmt.Errorf("'authJWTJWKS' is empty")
+			return newValidationError("authJWTJWKS", "'authJWTJWKS' is empty")
 		}
 		if conf.AuthJWTClaimKey == "" {
-			return fmt.Errorf("'authJWTClaimKey' is empty")
+			return newValidationError("authJWTClaimKey", "'authJWTClaimKey' is empty")
 		}
 	}
 
+	if conf.AuthTokenSecret != "" && len(conf.AuthTokenSecret) < 16 {
+		return newValidationError("authTokenSecret", "'authTokenSecret' must be at least 16 characters long")
+	}
+
+	// Metrics
+
+	if conf.MetricsLegacyNames {
+		l.Log(logger.Warn, "parameter 'metricsLegacyNames' is deprecated, metric names and labels "+
+			"used by previous versions will be removed in a future release")
+	}
+
+	// Playback
+
+	if conf.PlaybackMaxRequests < 0 {
+		return newValidationError("playbackMaxRequests", "'playbackMaxRequests' must be greater than or equal to zero")
+	}
+	if conf.PlaybackRate < 0 {
+		return newValidationError("playbackRate", "'playbackRate' must be greater than or equal to zero")
+	}
+
 	// RTSP
 
 	if conf.RTSPDisable != nil {
@@ -612,10 +794,10 @@ func (conf *Conf) Validate(l logger.Writer) error {
 	}
 	if conf.RTSPEncryption == EncryptionStrict {
 		if _, ok := conf.RTSPTransports[gortsplib.TransportUDP]; ok {
-			return fmt.Errorf("strict encryption cannot be used with the UDP transport protocol")
+			return newValidationError("rtspTransports", "strict encryption cannot be used with the UDP transport protocol")
 		}
 		if _, ok := conf.RTSPTransports[gortsplib.TransportUDPMulticast]; ok {
-			return fmt.Errorf("strict encryption cannot be used with the UDP-multicast transport protocol")
+			return newValidationError("rtspTransports", "strict encryption cannot be used with the UDP-multicast transport protocol")
 		}
 	}
 	if conf.AuthMethods != nil {
@@ -624,11 +806,11 @@ func (conf *Conf) Validate(l logger.Writer) error {
 	}
 	if contains(conf.RTSPAuthMethods, auth.ValidateMethodDigestMD5) {
 		if conf.AuthMethod != AuthMethodInternal {
-			return fmt.Errorf("when RTSP digest is enabled, the only supported auth method is 'internal'")
+			return newValidationError("authMethod", "when RTSP digest is enabled, the only supported auth method is 'internal'")
 		}
 		for _, user := range conf.AuthInternalUsers {
 			if user.User.IsHashed() || user.Pass.IsHashed() {
-				return fmt.Errorf("when RTSP digest is enabled, hashed credentials cannot be used")
+				return newValidationError("authInternalUsers", "when RTSP digest is enabled, hashed credentials cannot be used")
 			}
 		}
 	}
@@ -655,6 +837,12 @@ func (conf *Conf) Validate(l logger.Writer) error {
 		conf.HLS = !*conf.HLSDisable
 	}
 
+	// DASH
+
+	if conf.DASHSegmentCount < 1 {
+		return newValidationError("dashSegmentCount", "'dashSegmentCount' must be greater than zero")
+	}
+
 	// WebRTC
 
 	if conf.WebRTCDisable != nil {
@@ -699,18 +887,52 @@ func (conf *Conf) Validate(l logger.Writer) error {
 		if !strings.HasPrefix(server.URL, "stun:") &&
 			!strings.HasPrefix(server.URL, "turn:") &&
 			!strings.HasPrefix(server.URL, "turns:") {
-			return fmt.Errorf("invalid ICE server: '%s'", server.URL)
+			return newValidationError("webrtcICEServers2", "invalid ICE server: '%s'", server.URL)
 		}
 	}
 	if conf.WebRTCLocalUDPAddress == "" &&
 		conf.WebRTCLocalTCPAddress == "" &&
 		len(conf.WebRTCICEServers2) == 0 {
-		return fmt.Errorf("at least one between 'webrtcLocalUDPAddress'," +
+		return newValidationError("webrtcLocalUDPAddress", "at least one between 'webrtcLocalUDPAddress',"+
 			" 'webrtcLocalTCPAddress' or 'webrtcICEServers2' must be filled")
 	}
 	if conf.WebRTCLocalUDPAddress != "" || conf.WebRTCLocalTCPAddress != "" {
 		if !conf.WebRTCIPsFromInterfaces && len(conf.WebRTCAdditionalHosts) == 0 {
-			return fmt.Errorf("at least one between 'webrtcIPsFromInterfaces' or 'webrtcAdditionalHosts' must be filled")
+			return newValidationError("webrtcIPsFromInterfaces", "at least one between 'webrtcIPsFromInterfaces' or 'webrtcAdditionalHosts' must be filled")
+		}
+	}
+
+	// SRT
+
+	if conf.SRTLatency < 0 {
+		return newValidationError("srtLatency", "'srtLatency' must be greater than or equal to zero")
+	}
+
+	if conf.SRTOverheadBW < 10 || conf.SRTOverheadBW > 100 {
+		return newValidationError("srtOverheadBW", "'srtOverheadBW' must be between 10 and 100")
+	}
+
+	// ONVIF discovery
+
+	if conf.ONVIFDiscoveryInterval <= 0 {
+		return newValidationError("onvifDiscoveryInterval", "'onvifDiscoveryInterval' must be greater than zero")
+	}
+	if !strings.Contains(conf.ONVIFDiscoveryPathName, "%host") {
+		return newValidationError("onvifDiscoveryPathName", "'onvifDiscoveryPathName' must contain '%%host'")
+	}
+	err := isValidPathName(strings.ReplaceAll(conf.ONVIFDiscoveryPathName, "%host", "0.0.0.0"))
+	if err != nil {
+		return newValidationError("onvifDiscoveryPathName", "invalid 'onvifDiscoveryPathName': %w", err)
+	}
+
+	// Dynamic DNS
+
+	if conf.DDNSUpdateURL != "" {
+		if !strings.HasPrefix(conf.DDNSUpdateURL, "http://") && !strings.HasPrefix(conf.DDNSUpdateURL, "https://") {
+			return newValidationError("ddnsUpdateURL", "'ddnsUpdateURL' must be a HTTP or HTTPS URL")
+		}
+		if conf.DDNSUpdateInterval <= 0 {
+			return newValidationError("ddnsUpdateInterval", "'ddnsUpdateInterval' must be greater than zero")
 		}
 	}
 
@@ -775,13 +997,59 @@ func (conf *Conf) Validate(l logger.Writer) error {
 	for _, name := range sortedKeys(conf.OptionalPaths) {
 		err := conf.Paths[name].validate(conf, name, deprecatedCredentialsMode, l)
 		if err != nil {
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				verr.Path = name
+			}
 			return err
 		}
 	}
 
+	aliases := make(map[string]string)
+	for _, name := range sortedKeys(conf.OptionalPaths) {
+		for _, alias := range conf.Paths[name].Aliases {
+			if other, ok := aliases[alias]; ok {
+				return fmt.Errorf("alias '%s' is used by paths '%s' and '%s'", alias, other, name)
+			}
+			aliases[alias] = name
+		}
+	}
+
+	if conf.LowMemory {
+		conf.applyLowMemoryProfile()
+	}
+
 	return nil
 }
 
+// applyLowMemoryProfile lowers buffer sizes and disables optional muxers
+// in order to reduce memory usage on constrained devices.
+// Resulting values are the effective ones and are exposed by the API.
+func (conf *Conf) applyLowMemoryProfile() {
+	if conf.WriteQueueSize > lowMemoryWriteQueueSize {
+		conf.WriteQueueSize = lowMemoryWriteQueueSize
+	}
+
+	conf.HLSAlwaysRemux = false
+	if conf.HLSVariant != HLSVariant(gohlslib.MuxerVariantLowLatency) &&
+		conf.HLSSegmentCount > lowMemorySegmentCount {
+		conf.HLSSegmentCount = lowMemorySegmentCount
+	}
+	if conf.HLSSegmentMaxSize > lowMemoryHLSSegmentMaxSize {
+		conf.HLSSegmentMaxSize = lowMemoryHLSSegmentMaxSize
+	}
+
+	conf.DASH = false
+	if conf.DASHSegmentCount > lowMemorySegmentCount {
+		conf.DASHSegmentCount = lowMemorySegmentCount
+	}
+
+	for _, pconf := range conf.Paths {
+		pconf.HLSAlwaysRemux = false
+		pconf.GOPCache = false
+	}
+}
+
 // UnmarshalJSON implements json.Unmarshaler.
 func (conf *Conf) UnmarshalJSON(b []byte) error {
 	conf.setDefaults()
@@ -4,17 +4,25 @@ package decrypt
 import (
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"golang.org/x/crypto/nacl/secretbox"
+	"gopkg.in/yaml.v2"
 )
 
-// Decrypt decrypts the configuration with the given key.
-func Decrypt(key string, byts []byte) ([]byte, error) {
-	enc, err := base64.StdEncoding.DecodeString(string(byts))
+// ValuePrefix is the prefix of encrypted values.
+const ValuePrefix = "encrypted:"
+
+func decryptBase64(key string, str string) ([]byte, error) {
+	enc, err := base64.StdEncoding.DecodeString(str)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(enc) < 24 {
+		return nil, fmt.Errorf("decryption error")
+	}
+
 	var secretKey [32]byte
 	copy(secretKey[:], key)
 
@@ -27,3 +35,81 @@ func Decrypt(key string, byts []byte) ([]byte, error) {
 
 	return decrypted, nil
 }
+
+// Decrypt decrypts the configuration with the given key.
+func Decrypt(key string, byts []byte) ([]byte, error) {
+	return decryptBase64(key, string(byts))
+}
+
+// IsEncrypted checks whether the configuration is entirely encrypted.
+func IsEncrypted(byts []byte) bool {
+	_, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(byts)))
+	return err == nil
+}
+
+func decryptValues(key string, i interface{}) (interface{}, error) {
+	switch x := i.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range x {
+			var err error
+			x[k], err = decryptValues(key, v)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return x, nil
+
+	case []interface{}:
+		for j, v := range x {
+			var err error
+			x[j], err = decryptValues(key, v)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return x, nil
+
+	case string:
+		if !strings.HasPrefix(x, ValuePrefix) {
+			return x, nil
+		}
+		return DecryptValue(key, x)
+	}
+
+	return i, nil
+}
+
+// DecryptValue decrypts a single value prefixed with ValuePrefix.
+func DecryptValue(key string, v string) (string, error) {
+	dec, err := decryptBase64(key, strings.TrimPrefix(v, ValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt value: %w", err)
+	}
+	return string(dec), nil
+}
+
+// DecryptValues decrypts values of the configuration that are prefixed with ValuePrefix.
+//
+// Every string value that starts with ValuePrefix is decrypted, therefore a plain
+// value that starts with ValuePrefix can't be used; it has to be encrypted.
+// Only string values can be encrypted, since the decrypted value is always a string.
+// The configuration is decoded and encoded again, therefore comments
+// and anchors are not preserved.
+func DecryptValues(key string, byts []byte) ([]byte, error) {
+	if !strings.Contains(string(byts), ValuePrefix) {
+		return byts, nil
+	}
+
+	var temp interface{}
+	err := yaml.Unmarshal(byts, &temp)
+	if err != nil {
+		return nil, err
+	}
+
+	temp, err = decryptValues(key, temp)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(temp)
+}
@@ -61,3 +61,8 @@ func (d *Encryption) UnmarshalJSON(b []byte) error {
 func (d *Encryption) UnmarshalEnv(_ string, v string) error {
 	return d.UnmarshalJSON([]byte(`"` + v + `"`))
 }
+
+// enumValues implements schemaEnum.
+func (Encryption) enumValues() []string {
+	return []string{"no", "optional", "strict"}
+}
@@ -14,6 +14,12 @@ type Unmarshaler interface {
 	UnmarshalEnv(prefix string, v string) error
 }
 
+// MapUnmarshaler can be implemented to override the unmarshaling process
+// of values that are loaded from several variables.
+type MapUnmarshaler interface {
+	UnmarshalEnvMap(env map[string]string, prefix string) error
+}
+
 func envHasAtLeastAKeyWithPrefix(env map[string]string, prefix string) bool {
 	for key := range env {
 		if strings.HasPrefix(key, prefix) {
@@ -30,6 +36,20 @@ func loadEnvInternal(env map[string]string, prefix string, prv reflect.Value) er
 
 	rt := prv.Type().Elem()
 
+	if i, ok := prv.Interface().(MapUnmarshaler); ok {
+		if envHasAtLeastAKeyWithPrefix(env, prefix) {
+			if prv.IsNil() {
+				prv.Set(reflect.New(rt))
+				i = prv.Interface().(MapUnmarshaler)
+			}
+			err := i.UnmarshalEnvMap(env, prefix)
+			if err != nil {
+				return fmt.Errorf("%s: %w", prefix, err)
+			}
+		}
+		return nil
+	}
+
 	if i, ok := prv.Interface().(Unmarshaler); ok {
 		if ev, ok := env[prefix]; ok {
 			if prv.IsNil() {
@@ -239,11 +259,8 @@ func loadEnvInternal(env map[string]string, prefix string, prv reflect.Value) er
 	return fmt.Errorf("unsupported type: %v", rt)
 }
 
-func loadWithEnv(env map[string]string, prefix string, v interface{}) error {
-	return loadEnvInternal(env, prefix, reflect.ValueOf(v).Elem())
-}
-
-func envToMap() map[string]string {
+// Environ returns the environment variables of the process.
+func Environ() map[string]string {
 	env := make(map[string]string)
 	for _, kv := range os.Environ() {
 		tmp := strings.SplitN(kv, "=", 2)
@@ -252,7 +269,12 @@ func envToMap() map[string]string {
 	return env
 }
 
+// LoadMap loads the configuration from the given environment variables.
+func LoadMap(env map[string]string, prefix string, v interface{}) error {
+	return loadEnvInternal(env, prefix, reflect.ValueOf(v).Elem())
+}
+
 // Load loads the configuration from the environment.
 func Load(prefix string, v interface{}) error {
-	return loadWithEnv(envToMap(), prefix, v)
+	return LoadMap(Environ(), prefix, v)
 }
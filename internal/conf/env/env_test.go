@@ -200,6 +200,6 @@ func FuzzLoad(f *testing.F) {
 		}
 
 		var s testStruct
-		loadWithEnv(env, "MYPREFIX", &s) //nolint:errcheck
+		LoadMap(env, "MYPREFIX", &s) //nolint:errcheck
 	})
 }
@@ -56,3 +56,8 @@ func (d *HLSVariant) UnmarshalJSON(b []byte) error {
 func (d *HLSVariant) UnmarshalEnv(_ string, v string) error {
 	return d.UnmarshalJSON([]byte(`"` + v + `"`))
 }
+
+// enumValues implements schemaEnum.
+func (HLSVariant) enumValues() []string {
+	return []string{"mpegts", "fmp4", "lowLatency"}
+}
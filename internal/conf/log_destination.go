@@ -86,3 +86,8 @@ func (d *LogDestinations) UnmarshalEnv(_ string, v string) error {
 	byts, _ := json.Marshal(strings.Split(v, ","))
 	return d.UnmarshalJSON(byts)
 }
+
+// enumValues implements schemaEnum.
+func (LogDestinations) enumValues() []string {
+	return []string{"stdout", "file", "syslog"}
+}
@@ -62,3 +62,8 @@ func (d *LogLevel) UnmarshalJSON(b []byte) error {
 func (d *LogLevel) UnmarshalEnv(_ string, v string) error {
 	return d.UnmarshalJSON([]byte(`"` + v + `"`))
 }
+
+// enumValues implements schemaEnum.
+func (LogLevel) enumValues() []string {
+	return []string{"error", "warn", "info", "debug"}
+}
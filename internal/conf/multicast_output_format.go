@@ -0,0 +1,61 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MulticastOutputFormat is the multicastOutputFormat parameter.
+type MulticastOutputFormat int
+
+// supported values.
+const (
+	MulticastOutputFormatMPEGTS MulticastOutputFormat = iota
+	MulticastOutputFormatRTP
+)
+
+// MarshalJSON implements json.Marshaler.
+func (d MulticastOutputFormat) MarshalJSON() ([]byte, error) {
+	var out string
+
+	switch d {
+	case MulticastOutputFormatRTP:
+		out = "rtp"
+
+	default:
+		out = "mpegts"
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *MulticastOutputFormat) UnmarshalJSON(b []byte) error {
+	var in string
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+
+	switch in {
+	case "mpegts":
+		*d = MulticastOutputFormatMPEGTS
+
+	case "rtp":
+		*d = MulticastOutputFormatRTP
+
+	default:
+		return fmt.Errorf("invalid multicast output format '%s'", in)
+	}
+
+	return nil
+}
+
+// UnmarshalEnv implements env.Unmarshaler.
+func (d *MulticastOutputFormat) UnmarshalEnv(_ string, v string) error {
+	return d.UnmarshalJSON([]byte(`"` + v + `"`))
+}
+
+// enumValues implements schemaEnum.
+func (MulticastOutputFormat) enumValues() []string {
+	return []string{"mpegts", "rtp"}
+}
@@ -56,12 +56,12 @@ func (p *OptionalPath) UnmarshalJSON(b []byte) error {
 	return d.Decode(p.Values)
 }
 
-// UnmarshalEnv implements env.Unmarshaler.
-func (p *OptionalPath) UnmarshalEnv(prefix string, _ string) error {
+// UnmarshalEnvMap implements env.MapUnmarshaler.
+func (p *OptionalPath) UnmarshalEnvMap(envMap map[string]string, prefix string) error {
 	if p.Values == nil {
 		p.Values = newOptionalPathValues()
 	}
-	return env.Load(prefix, p.Values)
+	return env.LoadMap(envMap, prefix, p.Values)
 }
 
 // MarshalJSON implements json.Marshaler.
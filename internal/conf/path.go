@@ -16,6 +16,8 @@ import (
 
 var rePathName = regexp.MustCompile(`^[0-9a-zA-Z_\-/\.~]+$`)
 
+var reQualityName = regexp.MustCompile(`^[0-9a-zA-Z_\-]+$`)
+
 func isValidPathName(name string) error {
 	if name == "" {
 		return fmt.Errorf("cannot be empty")
@@ -36,6 +38,24 @@ func isValidPathName(name string) error {
 	return nil
 }
 
+func isStaticSourceURL(u string) bool {
+	for _, prefix := range []string{
+		"rtsp://", "rtsps://",
+		"rtmp://", "rtmps://",
+		"http://", "https://",
+		"udp://",
+		"file://",
+		"srt://",
+		"whep://", "wheps://",
+		"recording://",
+	} {
+		if strings.HasPrefix(u, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func srtCheckPassphrase(passphrase string) error {
 	switch {
 	case len(passphrase) < 10 || len(passphrase) > 79:
@@ -88,48 +108,103 @@ type Path struct {
 	Name   string         `json:"name"` // filled by Check()
 
 	// General
-	Source                     string   `json:"source"`
-	SourceFingerprint          string   `json:"sourceFingerprint"`
-	SourceOnDemand             bool     `json:"sourceOnDemand"`
-	SourceOnDemandStartTimeout Duration `json:"sourceOnDemandStartTimeout"`
-	SourceOnDemandCloseAfter   Duration `json:"sourceOnDemandCloseAfter"`
-	MaxReaders                 int      `json:"maxReaders"`
-	SRTReadPassphrase          string   `json:"srtReadPassphrase"`
-	Fallback                   string   `json:"fallback"`
+	Source                     string         `json:"source"`
+	SourceFingerprint          string         `json:"sourceFingerprint"`
+	SourceClientCert           string         `json:"sourceClientCert"`
+	SourceClientKey            string         `json:"sourceClientKey"`
+	SourceProxy                string         `json:"sourceProxy"`
+	SourceOnDemand             bool           `json:"sourceOnDemand"`
+	SourceOnDemandStartTimeout Duration       `json:"sourceOnDemandStartTimeout"`
+	SourceOnDemandCloseAfter   Duration       `json:"sourceOnDemandCloseAfter"`
+	SourceStallTimeout         Duration       `json:"sourceStallTimeout"`
+	SourceFallbacks            []string       `json:"sourceFallbacks"`
+	SourceFallbackAfter        int            `json:"sourceFallbackAfter"`
+	SourceSchedule             ReadSchedule   `json:"sourceSchedule"`
+	QualitySources             QualitySources `json:"qualitySources"`
+	MaxReaders                 int            `json:"maxReaders"`
+	StreamDelay                Duration       `json:"streamDelay"`
+	NTPFromPTS                 bool           `json:"ntpFromPTS"`
+	GOPCache                   bool           `json:"gopCache"`
+	GOPCacheMaxSize            StringSize     `json:"gopCacheMaxSize"`
+	ReadProtocols              ReadProtocols  `json:"readProtocols"`
+	ReadSchedule               ReadSchedule   `json:"readSchedule"`
+	ReadScheduleTimezone       string         `json:"readScheduleTimezone"`
+	MaxPaths                   int            `json:"maxPaths"`
+	Aliases                    []string       `json:"aliases"`
+	MediaSubPaths              bool           `json:"mediaSubPaths"`
+	SRTReadPassphrase          string         `json:"srtReadPassphrase"`
+	Fallback                   string         `json:"fallback"`
+	OnDemandCacheDescription   bool           `json:"onDemandCacheDescription"`
+	RTSPSessionName            string         `json:"rtspSessionName"`
+	HLSAlwaysRemux             bool           `json:"hlsAlwaysRemux"`
+
+	// Codec priority
+	CodecPriority       []string `json:"codecPriority"`
+	HLSCodecPriority    []string `json:"hlsCodecPriority"`
+	WebRTCCodecPriority []string `json:"webrtcCodecPriority"`
+
+	// WebRTC
+	WebRTCICEServers WebRTCICEServers `json:"webrtcICEServers"`
 
 	// Record
 	Record                bool         `json:"record"`
-	Playback              *bool        `json:"playback,omitempty"` // deprecated
+	Playback              *bool        `json:"playback,omitempty" deprecated:"true"`
 	RecordPath            string       `json:"recordPath"`
 	RecordFormat          RecordFormat `json:"recordFormat"`
+	RecordVideo           bool         `json:"recordVideo"`
+	RecordAudio           bool         `json:"recordAudio"`
 	RecordPartDuration    Duration     `json:"recordPartDuration"`
 	RecordSegmentDuration Duration     `json:"recordSegmentDuration"`
 	RecordDeleteAfter     Duration     `json:"recordDeleteAfter"`
+	RecordConvertAfter    Duration     `json:"recordConvertAfter"`
+	RecordVerifyInterval  Duration     `json:"recordVerifyInterval"`
+	RecordSchedule        ReadSchedule `json:"recordSchedule"`
+
+	// Multicast output
+	MulticastOutput          string                `json:"multicastOutput"`
+	MulticastOutputFormat    MulticastOutputFormat `json:"multicastOutputFormat"`
+	MulticastOutputTTL       uint                  `json:"multicastOutputTTL"`
+	MulticastOutputInterface string                `json:"multicastOutputInterface"`
+	MulticastOutputSAP       bool                  `json:"multicastOutputSAP"`
+
+	// Forwarding
+	ForwardTo []string `json:"forwardTo"`
 
 	// Authentication (deprecated)
-	PublishUser *Credential `json:"publishUser,omitempty"` // deprecated
-	PublishPass *Credential `json:"publishPass,omitempty"` // deprecated
-	PublishIPs  *IPNetworks `json:"publishIPs,omitempty"`  // deprecated
-	ReadUser    *Credential `json:"readUser,omitempty"`    // deprecated
-	ReadPass    *Credential `json:"readPass,omitempty"`    // deprecated
-	ReadIPs     *IPNetworks `json:"readIPs,omitempty"`     // deprecated
+	PublishUser *Credential `json:"publishUser,omitempty" deprecated:"true"`
+	PublishPass *Credential `json:"publishPass,omitempty" deprecated:"true"`
+	PublishIPs  *IPNetworks `json:"publishIPs,omitempty" deprecated:"true"`
+	ReadUser    *Credential `json:"readUser,omitempty" deprecated:"true"`
+	ReadPass    *Credential `json:"readPass,omitempty" deprecated:"true"`
+	ReadIPs     *IPNetworks `json:"readIPs,omitempty" deprecated:"true"`
 
 	// Publisher source
-	OverridePublisher        bool   `json:"overridePublisher"`
-	DisablePublisherOverride *bool  `json:"disablePublisherOverride,omitempty"` // deprecated
-	SRTPublishPassphrase     string `json:"srtPublishPassphrase"`
+	OverridePublisher        bool     `json:"overridePublisher"`
+	DisablePublisherOverride *bool    `json:"disablePublisherOverride,omitempty" deprecated:"true"`
+	SRTPublishPassphrase     string   `json:"srtPublishPassphrase"`
+	BackupPublisher          bool     `json:"backupPublisher"`
+	BackupPublisherTimeout   Duration `json:"backupPublisherTimeout"`
 
 	// RTSP source
-	RTSPTransport       RTSPTransport  `json:"rtspTransport"`
-	RTSPAnyPort         bool           `json:"rtspAnyPort"`
-	SourceProtocol      *RTSPTransport `json:"sourceProtocol,omitempty"`      // deprecated
-	SourceAnyPortEnable *bool          `json:"sourceAnyPortEnable,omitempty"` // deprecated
-	RTSPRangeType       RTSPRangeType  `json:"rtspRangeType"`
-	RTSPRangeStart      string         `json:"rtspRangeStart"`
+	RTSPTransport        RTSPTransport        `json:"rtspTransport"`
+	RTSPAnyPort          bool                 `json:"rtspAnyPort"`
+	SourceProtocol       *RTSPTransport       `json:"sourceProtocol,omitempty" deprecated:"true"`
+	SourceAnyPortEnable  *bool                `json:"sourceAnyPortEnable,omitempty" deprecated:"true"`
+	RTSPRangeType        RTSPRangeType        `json:"rtspRangeType"`
+	RTSPRangeStart       string               `json:"rtspRangeStart"`
+	RTSPQuirks           []string             `json:"rtspQuirks"`
+	RTSPSetupErrorPolicy RTSPSetupErrorPolicy `json:"rtspSetupErrorPolicy"`
 
 	// Redirect source
 	SourceRedirect string `json:"sourceRedirect"`
 
+	// Bonded source
+	BondedLinks   []string `json:"bondedLinks"`
+	BondedLatency Duration `json:"bondedLatency"`
+
+	// ONVIF
+	ONVIFDeviceURL string `json:"onvifDeviceURL"`
+
 	// Raspberry Pi Camera source
 	RPICameraCamID             uint      `json:"rpiCameraCamID"`
 	RPICameraWidth             uint      `json:"rpiCameraWidth"`
@@ -168,21 +243,25 @@ type Path struct {
 	RPICameraLevel             string    `json:"rpiCameraLevel"`
 
 	// Hooks
-	RunOnInit                  string   `json:"runOnInit"`
-	RunOnInitRestart           bool     `json:"runOnInitRestart"`
-	RunOnDemand                string   `json:"runOnDemand"`
-	RunOnDemandRestart         bool     `json:"runOnDemandRestart"`
-	RunOnDemandStartTimeout    Duration `json:"runOnDemandStartTimeout"`
-	RunOnDemandCloseAfter      Duration `json:"runOnDemandCloseAfter"`
-	RunOnUnDemand              string   `json:"runOnUnDemand"`
-	RunOnReady                 string   `json:"runOnReady"`
-	RunOnReadyRestart          bool     `json:"runOnReadyRestart"`
-	RunOnNotReady              string   `json:"runOnNotReady"`
-	RunOnRead                  string   `json:"runOnRead"`
-	RunOnReadRestart           bool     `json:"runOnReadRestart"`
-	RunOnUnread                string   `json:"runOnUnread"`
-	RunOnRecordSegmentCreate   string   `json:"runOnRecordSegmentCreate"`
-	RunOnRecordSegmentComplete string   `json:"runOnRecordSegmentComplete"`
+	RunOnInit                   string   `json:"runOnInit"`
+	RunOnInitRestart            bool     `json:"runOnInitRestart"`
+	RunOnDemand                 string   `json:"runOnDemand"`
+	RunOnDemandRestart          bool     `json:"runOnDemandRestart"`
+	RunOnDemandRestartPause     Duration `json:"runOnDemandRestartPause"`
+	RunOnDemandRestartMaxPause  Duration `json:"runOnDemandRestartMaxPause"`
+	RunOnDemandStartTimeout     Duration `json:"runOnDemandStartTimeout"`
+	RunOnDemandCloseAfter       Duration `json:"runOnDemandCloseAfter"`
+	RunOnUnDemand               string   `json:"runOnUnDemand"`
+	RunOnReady                  string   `json:"runOnReady"`
+	RunOnReadyRestart           bool     `json:"runOnReadyRestart"`
+	RunOnNotReady               string   `json:"runOnNotReady"`
+	RunOnSourceStall            string   `json:"runOnSourceStall"`
+	RunOnRead                   string   `json:"runOnRead"`
+	RunOnReadRestart            bool     `json:"runOnReadRestart"`
+	RunOnUnread                 string   `json:"runOnUnread"`
+	RunOnRecordSegmentCreate    string   `json:"runOnRecordSegmentCreate"`
+	RunOnRecordSegmentComplete  string   `json:"runOnRecordSegmentComplete"`
+	RunOnRecordSegmentCorrupted string   `json:"runOnRecordSegmentCorrupted"`
 }
 
 func (pconf *Path) setDefaults() {
@@ -190,16 +269,58 @@ func (pconf *Path) setDefaults() {
 	pconf.Source = "publisher"
 	pconf.SourceOnDemandStartTimeout = 10 * Duration(time.Second)
 	pconf.SourceOnDemandCloseAfter = 10 * Duration(time.Second)
+	pconf.SourceFallbacks = []string{}
+	pconf.Aliases = []string{}
+	pconf.SourceFallbackAfter = 3
+	pconf.SourceSchedule = []ReadScheduleWindow{}
+	pconf.QualitySources = []QualitySource{}
+	pconf.ReadProtocols = ReadProtocols{
+		"rtsp":   {},
+		"rtmp":   {},
+		"hls":    {},
+		"dash":   {},
+		"mjpeg":  {},
+		"webrtc": {},
+		"srt":    {},
+	}
+	pconf.ReadSchedule = []ReadScheduleWindow{}
+	pconf.GOPCacheMaxSize = 10 * 1024 * 1024
+
+	// Codec priority
+	pconf.CodecPriority = []string{}
+	pconf.HLSCodecPriority = []string{}
+	pconf.WebRTCCodecPriority = []string{}
+
+	// WebRTC
+	pconf.WebRTCICEServers = []WebRTCICEServer{}
 
 	// Record
 	pconf.RecordPath = "./recordings/%path/%Y-%m-%d_%H-%M-%S-%f"
 	pconf.RecordFormat = RecordFormatFMP4
+	pconf.RecordVideo = true
+	pconf.RecordAudio = true
 	pconf.RecordPartDuration = Duration(1 * time.Second)
 	pconf.RecordSegmentDuration = 3600 * Duration(time.Second)
 	pconf.RecordDeleteAfter = 24 * 3600 * Duration(time.Second)
+	pconf.RecordSchedule = []ReadScheduleWindow{}
+
+	// Multicast output
+	pconf.MulticastOutputFormat = MulticastOutputFormatMPEGTS
+	pconf.MulticastOutputTTL = 1
+
+	// Forwarding
+	pconf.ForwardTo = []string{}
 
 	// Publisher source
 	pconf.OverridePublisher = true
+	pconf.BackupPublisherTimeout = 2 * Duration(time.Second)
+
+	// RTSP source
+	pconf.RTSPQuirks = []string{}
+
+	// Bonded source
+	pconf.BondedLinks = []string{}
+	pconf.BondedLatency = 200 * Duration(time.Millisecond)
 
 	// Raspberry Pi Camera source
 	pconf.RPICameraWidth = 1920
@@ -224,6 +345,8 @@ func (pconf *Path) setDefaults() {
 	pconf.RPICameraLevel = "4.1"
 
 	// Hooks
+	pconf.RunOnDemandRestartPause = 5 * Duration(time.Second)
+	pconf.RunOnDemandRestartMaxPause = 5 * Duration(time.Second)
 	pconf.RunOnDemandStartTimeout = 10 * Duration(time.Second)
 	pconf.RunOnDemandCloseAfter = 10 * Duration(time.Second)
 }
@@ -283,16 +406,50 @@ func (pconf *Path) validate(
 
 	if pconf.Source != "publisher" && pconf.Source != "redirect" &&
 		pconf.Regexp != nil && !pconf.SourceOnDemand {
-		return fmt.Errorf("a path with a regular expression (or path 'all') and a static source" +
+		return newValidationError("sourceOnDemand", "a path with a regular expression (or path 'all') and a static source"+
 			" must have 'sourceOnDemand' set to true")
 	}
 
 	if pconf.SRTPublishPassphrase != "" && pconf.Source != "publisher" {
-		return fmt.Errorf("'srtPublishPassphase' can only be used when source is 'publisher'")
+		return newValidationError("srtPublishPassphrase", "'srtPublishPassphase' can only be used when source is 'publisher'")
 	}
 
 	if pconf.SourceOnDemand && pconf.Source == "publisher" {
-		return fmt.Errorf("'sourceOnDemand' is useless when source is 'publisher'")
+		return newValidationError("sourceOnDemand", "'sourceOnDemand' is useless when source is 'publisher'")
+	}
+
+	// Read schedule
+
+	for i, w := range pconf.ReadSchedule {
+		err := w.validate()
+		if err != nil {
+			return newValidationError("readSchedule", "invalid read schedule window %d: %w", i+1, err)
+		}
+	}
+
+	if pconf.ReadScheduleTimezone != "" {
+		_, err := time.LoadLocation(pconf.ReadScheduleTimezone)
+		if err != nil {
+			return newValidationError("readScheduleTimezone", "invalid 'readScheduleTimezone': %w", err)
+		}
+	}
+
+	// Source schedule
+
+	if len(pconf.SourceSchedule) != 0 {
+		if !pconf.HasStaticSource() {
+			return newValidationError("sourceSchedule", "'sourceSchedule' can only be used with a static source")
+		}
+		if pconf.SourceOnDemand {
+			return newValidationError("sourceSchedule", "'sourceSchedule' and 'sourceOnDemand' cannot be used together")
+		}
+	}
+
+	for i, w := range pconf.SourceSchedule {
+		err := w.validate()
+		if err != nil {
+			return newValidationError("sourceSchedule", "invalid source schedule window %d: %w", i+1, err)
+		}
 	}
 
 	// source-dependent settings
@@ -308,15 +465,19 @@ func (pconf *Path) validate(
 		if pconf.SRTPublishPassphrase != "" {
 			err := srtCheckPassphrase(pconf.SRTPublishPassphrase)
 			if err != nil {
-				return fmt.Errorf("invalid 'srtPublishPassphrase': %w", err)
+				return newValidationError("srtPublishPassphrase", "invalid 'srtPublishPassphrase': %w", err)
 			}
 		}
 
+		if pconf.BackupPublisherTimeout <= 0 {
+			return newValidationError("backupPublisherTimeout", "'backupPublisherTimeout' must be greater than zero")
+		}
+
 	case strings.HasPrefix(pconf.Source, "rtsp://") ||
 		strings.HasPrefix(pconf.Source, "rtsps://"):
 		_, err := base.ParseURL(pconf.Source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return newValidationError("source", "'%s' is not a valid URL", pconf.Source)
 		}
 
 		if pconf.SourceProtocol != nil {
@@ -328,11 +489,17 @@ func (pconf *Path) validate(
 			pconf.RTSPAnyPort = *pconf.SourceAnyPortEnable
 		}
 
+		err = checkRTSPQuirks(pconf.RTSPQuirks)
+		if err != nil {
+			return newValidationError("rtspQuirks", "invalid 'rtspQuirks': %w", err)
+		}
+		applyRTSPQuirks(pconf)
+
 	case strings.HasPrefix(pconf.Source, "rtmp://") ||
 		strings.HasPrefix(pconf.Source, "rtmps://"):
 		u, err := gourl.Parse(pconf.Source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return newValidationError("source", "'%s' is not a valid URL", pconf.Source)
 		}
 
 		if u.User != nil {
@@ -340,7 +507,7 @@ func (pconf *Path) validate(
 			user := u.User.Username()
 			if user != "" && pass == "" ||
 				user == "" && pass != "" {
-				return fmt.Errorf("username and password must be both provided")
+				return newValidationError("source", "username and password must be both provided")
 			}
 		}
 
@@ -348,10 +515,10 @@ func (pconf *Path) validate(
 		strings.HasPrefix(pconf.Source, "https://"):
 		u, err := gourl.Parse(pconf.Source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return newValidationError("source", "'%s' is not a valid URL", pconf.Source)
 		}
 		if u.Scheme != "http" && u.Scheme != "https" {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return newValidationError("source", "'%s' is not a valid URL", pconf.Source)
 		}
 
 		if u.User != nil {
@@ -359,44 +526,93 @@ func (pconf *Path) validate(
 			user := u.User.Username()
 			if user != "" && pass == "" ||
 				user == "" && pass != "" {
-				return fmt.Errorf("username and password must be both provided")
+				return newValidationError("source", "username and password must be both provided")
 			}
 		}
 
 	case strings.HasPrefix(pconf.Source, "udp://"):
 		_, _, err := net.SplitHostPort(pconf.Source[len("udp://"):])
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid UDP URL", pconf.Source)
+			return newValidationError("source", "'%s' is not a valid UDP URL", pconf.Source)
+		}
+
+	case strings.HasPrefix(pconf.Source, "file://"):
+		if pconf.Source == "file://" {
+			return newValidationError("source", "'%s' doesn't contain the path of a SDP file", pconf.Source)
 		}
 
 	case strings.HasPrefix(pconf.Source, "srt://"):
 		_, err := gourl.Parse(pconf.Source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return newValidationError("source", "'%s' is not a valid URL", pconf.Source)
 		}
 
 	case strings.HasPrefix(pconf.Source, "whep://") ||
 		strings.HasPrefix(pconf.Source, "wheps://"):
 		_, err := gourl.Parse(pconf.Source)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid URL", pconf.Source)
+			return newValidationError("source", "'%s' is not a valid URL", pconf.Source)
+		}
+
+	case strings.HasPrefix(pconf.Source, "recording://"):
+		u, err := gourl.Parse(pconf.Source)
+		if err != nil {
+			return newValidationError("source", "'%s' is not a valid URL", pconf.Source)
+		}
+
+		if u.Host == "" {
+			return newValidationError("source", "'%s' doesn't contain the name of a path", pconf.Source)
+		}
+
+		_, err = time.Parse(time.RFC3339, u.Query().Get("start"))
+		if err != nil {
+			return newValidationError("source", "'%s' doesn't contain a valid start: %w", pconf.Source, err)
+		}
+
+		d, err := time.ParseDuration(u.Query().Get("duration"))
+		if err != nil || d <= 0 {
+			return newValidationError("source", "'%s' doesn't contain a valid duration", pconf.Source)
+		}
+
+		if pconf.RecordFormat != RecordFormatFMP4 {
+			return newValidationError("recordFormat", "recording sources can only read the fMP4 record format")
 		}
 
 	case pconf.Source == "redirect":
 		if pconf.SourceRedirect == "" {
-			return fmt.Errorf("source redirect must be filled")
+			return newValidationError("sourceRedirect", "source redirect must be filled")
 		}
 
 		_, err := base.ParseURL(pconf.SourceRedirect)
 		if err != nil {
-			return fmt.Errorf("'%s' is not a valid RTSP URL", pconf.SourceRedirect)
+			return newValidationError("sourceRedirect", "'%s' is not a valid RTSP URL", pconf.SourceRedirect)
+		}
+
+	case pconf.Source == "bonded":
+		if len(pconf.BondedLinks) == 0 {
+			return newValidationError("bondedLinks", "at least one link must be provided")
+		}
+
+		for _, link := range pconf.BondedLinks {
+			if !strings.HasPrefix(link, "udp://") {
+				return newValidationError("bondedLinks", "'%s' is not a valid UDP URL", link)
+			}
+
+			_, _, err := net.SplitHostPort(link[len("udp://"):])
+			if err != nil {
+				return newValidationError("bondedLinks", "'%s' is not a valid UDP URL", link)
+			}
+		}
+
+		if pconf.BondedLatency <= 0 {
+			return newValidationError("bondedLatency", "'bondedLatency' must be greater than zero")
 		}
 
 	case pconf.Source == "rpiCamera":
 		for otherName, otherPath := range conf.Paths {
 			if otherPath != pconf && otherPath != nil &&
 				otherPath.Source == "rpiCamera" && otherPath.RPICameraCamID == pconf.RPICameraCamID {
-				return fmt.Errorf("'rpiCamera' with same camera ID %d is used as source in two paths, '%s' and '%s'",
+				return newValidationError("source", "'rpiCamera' with same camera ID %d is used as source in two paths, '%s' and '%s'",
 					pconf.RPICameraCamID, name, otherName)
 			}
 		}
@@ -404,55 +620,61 @@ func (pconf *Path) validate(
 		switch pconf.RPICameraExposure {
 		case "normal", "short", "long", "custom":
 		default:
-			return fmt.Errorf("invalid 'rpiCameraExposure' value")
+			return newValidationError("rpiCameraExposure", "invalid 'rpiCameraExposure' value")
 		}
 		switch pconf.RPICameraAWB {
 		case "auto", "incandescent", "tungsten", "fluorescent", "indoor", "daylight", "cloudy", "custom":
 		default:
-			return fmt.Errorf("invalid 'rpiCameraAWB' value")
+			return newValidationError("rpiCameraAWB", "invalid 'rpiCameraAWB' value")
 		}
 		if len(pconf.RPICameraAWBGains) != 2 {
-			return fmt.Errorf("invalid 'rpiCameraAWBGains' value")
+			return newValidationError("rpiCameraAWBGains", "invalid 'rpiCameraAWBGains' value")
 		}
 		switch pconf.RPICameraDenoise {
 		case "off", "cdn_off", "cdn_fast", "cdn_hq":
 		default:
-			return fmt.Errorf("invalid 'rpiCameraDenoise' value")
+			return newValidationError("rpiCameraDenoise", "invalid 'rpiCameraDenoise' value")
 		}
 		switch pconf.RPICameraMetering {
 		case "centre", "spot", "matrix", "custom":
 		default:
-			return fmt.Errorf("invalid 'rpiCameraMetering' value")
+			return newValidationError("rpiCameraMetering", "invalid 'rpiCameraMetering' value")
 		}
 		switch pconf.RPICameraAfMode {
 		case "auto", "manual", "continuous":
 		default:
-			return fmt.Errorf("invalid 'rpiCameraAfMode' value")
+			return newValidationError("rpiCameraAfMode", "invalid 'rpiCameraAfMode' value")
 		}
 		switch pconf.RPICameraAfRange {
 		case "normal", "macro", "full":
 		default:
-			return fmt.Errorf("invalid 'rpiCameraAfRange' value")
+			return newValidationError("rpiCameraAfRange", "invalid 'rpiCameraAfRange' value")
 		}
 		switch pconf.RPICameraAfSpeed {
 		case "normal", "fast":
 		default:
-			return fmt.Errorf("invalid 'rpiCameraAfSpeed' value")
+			return newValidationError("rpiCameraAfSpeed", "invalid 'rpiCameraAfSpeed' value")
 		}
 		switch pconf.RPICameraCodec {
 		case "auto", "hardwareH264", "softwareH264":
 		default:
-			return fmt.Errorf("invalid 'rpiCameraCodec' value")
+			return newValidationError("rpiCameraCodec", "invalid 'rpiCameraCodec' value")
 		}
 
+	case strings.HasPrefix(pconf.Source, "dshow://") ||
+		strings.HasPrefix(pconf.Source, "avfoundation://") ||
+		strings.HasPrefix(pconf.Source, "screen://"):
+		return newValidationError("source", "capture devices are not supported natively; "+
+			"use 'runOnInit' with FFmpeg to publish them")
+
 	default:
-		return fmt.Errorf("invalid source: '%s'", pconf.Source)
+		return newValidationError("source", "invalid source: '%s'", pconf.Source)
 	}
 
 	if pconf.SRTReadPassphrase != "" {
 		err := srtCheckPassphrase(pconf.SRTReadPassphrase)
 		if err != nil {
-			return fmt.Errorf("invalid 'readRTPassphrase': %w", err)
+			return newValidationError("srtReadPassphrase", "invalid 'readRTPassphrase': %w", err)
 		}
 	}
 
@@ -460,22 +682,151 @@ func (pconf *Path) validate(
 		if strings.HasPrefix(pconf.Fallback, "/") {
 			err := isValidPathName(pconf.Fallback[1:])
 			if err != nil {
-				return fmt.Errorf("'%s': %w", pconf.Fallback, err)
+				return newValidationError("fallback", "'%s': %w", pconf.Fallback, err)
 			}
 		} else {
 			_, err := base.ParseURL(pconf.Fallback)
 			if err != nil {
-				return fmt.Errorf("'%s' is not a valid RTSP URL", pconf.Fallback)
+				return newValidationError("fallback", "'%s' is not a valid RTSP URL", pconf.Fallback)
+			}
+		}
+	}
+
+	if pconf.SourceStallTimeout < 0 {
+		return newValidationError("sourceStallTimeout", "'sourceStallTimeout' must be greater than or equal to zero")
+	}
+
+	if pconf.SourceProxy != "" {
+		if !strings.HasPrefix(pconf.Source, "rtsp://") && !strings.HasPrefix(pconf.Source, "rtsps://") &&
+			!strings.HasPrefix(pconf.Source, "rtmp://") && !strings.HasPrefix(pconf.Source, "rtmps://") {
+			return newValidationError("sourceProxy", "'sourceProxy' can only be used with RTSP and RTMP sources")
+		}
+
+		u, err := gourl.Parse(pconf.SourceProxy)
+		if err != nil {
+			return newValidationError("sourceProxy", "'%s' is not a valid URL", pconf.SourceProxy)
+		}
+
+		switch u.Scheme {
+		case "socks5", "socks5h", "http":
+		default:
+			return newValidationError("sourceProxy", "unsupported proxy scheme: '%s'", u.Scheme)
+		}
+	}
+
+	if (pconf.SourceClientCert != "") != (pconf.SourceClientKey != "") {
+		return newValidationError("sourceClientCert", "'sourceClientCert' and 'sourceClientKey' must be used together")
+	}
+
+	if pconf.StreamDelay < 0 {
+		return newValidationError("streamDelay", "'streamDelay' must be greater than or equal to zero")
+	}
+
+	if pconf.GOPCache && pconf.GOPCacheMaxSize == 0 {
+		return newValidationError("gopCacheMaxSize", "'gopCacheMaxSize' must be greater than zero")
+	}
+
+	if len(pconf.SourceFallbacks) != 0 {
+		if pconf.Source == "publisher" || pconf.Source == "redirect" {
+			return newValidationError("sourceFallbacks", "'sourceFallbacks' can only be used with a static source")
+		}
+
+		for _, fallback := range pconf.SourceFallbacks {
+			if !isStaticSourceURL(fallback) {
+				return newValidationError("sourceFallbacks", "'%s' is not a supported source URL", fallback)
 			}
 		}
 	}
 
+	qualityNames := make(map[string]struct{})
+	for _, qs := range pconf.QualitySources {
+		if !reQualityName.MatchString(qs.Name) {
+			return newValidationError("qualitySources",
+				"invalid quality name '%s': can contain only alphanumeric characters, underscore or minus", qs.Name)
+		}
+		if _, ok := qualityNames[qs.Name]; ok {
+			return newValidationError("qualitySources", "quality '%s' is defined twice", qs.Name)
+		}
+		qualityNames[qs.Name] = struct{}{}
+
+		if !isStaticSourceURL(qs.Source) {
+			return newValidationError("qualitySources", "'%s' is not a supported source URL", qs.Source)
+		}
+	}
+
+	if pconf.ONVIFDeviceURL != "" {
+		u, err := gourl.Parse(pconf.ONVIFDeviceURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return newValidationError("onvifDeviceURL", "'onvifDeviceURL' must be a HTTP or HTTPS URL")
+		}
+	}
+
+	if pconf.SourceFallbackAfter < 1 {
+		return newValidationError("sourceFallbackAfter", "'sourceFallbackAfter' must be greater than zero")
+	}
+
+	if pconf.MaxPaths < 0 {
+		return newValidationError("maxPaths", "'maxPaths' must be greater than or equal to zero")
+	}
+	if pconf.MaxPaths != 0 && pconf.Regexp == nil {
+		return newValidationError("maxPaths", "'maxPaths' can be used only with paths that use a regular expression")
+	}
+
+	if len(pconf.Aliases) != 0 && pconf.Regexp != nil {
+		return newValidationError("aliases", "'aliases' can't be used with paths that use a regular expression")
+	}
+
+	for _, alias := range pconf.Aliases {
+		err := isValidPathName(alias)
+		if err != nil {
+			return newValidationError("aliases", "invalid alias '%s': %w", alias, err)
+		}
+
+		if _, ok := conf.Paths[alias]; ok {
+			return newValidationError("aliases", "alias '%s' is the name of another path", alias)
+		}
+	}
+
+	// Codec priority
+
+	err := checkCodecPriority(pconf.CodecPriority)
+	if err != nil {
+		return newValidationError("codecPriority", "invalid 'codecPriority': %w", err)
+	}
+
+	err = checkCodecPriority(pconf.HLSCodecPriority)
+	if err != nil {
+		return newValidationError("hlsCodecPriority", "invalid 'hlsCodecPriority': %w", err)
+	}
+
+	err = checkCodecPriority(pconf.WebRTCCodecPriority)
+	if err != nil {
+		return newValidationError("webrtcCodecPriority", "invalid 'webrtcCodecPriority': %w", err)
+	}
+
+	// WebRTC
+
+	for _, server := range pconf.WebRTCICEServers {
+		if !strings.HasPrefix(server.URL, "stun:") &&
+			!strings.HasPrefix(server.URL, "turn:") &&
+			!strings.HasPrefix(server.URL, "turns:") {
+			return newValidationError("webrtcICEServers", "invalid ICE server: '%s'", server.URL)
+		}
+	}
+
 	// Record
 
 	if pconf.Playback != nil {
 		l.Log(logger.Warn, "parameter 'playback' is deprecated and has no effect")
 	}
 
+	for i, w := range pconf.RecordSchedule {
+		err := w.validate()
+		if err != nil {
+			return newValidationError("recordSchedule", "invalid record schedule window %d: %w", i+1, err)
+		}
+	}
+
 	if conf.Playback {
 		if !strings.Contains(pconf.RecordPath, "%Y") ||
 			!strings.Contains(pconf.RecordPath, "%m") ||
@@ -484,15 +835,56 @@ func (pconf *Path) validate(
 			!strings.Contains(pconf.RecordPath, "%M") ||
 			!strings.Contains(pconf.RecordPath, "%S") ||
 			!strings.Contains(pconf.RecordPath, "%f") {
-			return fmt.Errorf("record path '%s' is missing one of the mandatory elements"+
+			return newValidationError("recordPath", "record path '%s' is missing one of the mandatory elements"+
 				" for the playback server to work: %%Y %%m %%d %%H %%M %%S %%f",
 				pconf.RecordPath)
 		}
 	}
 
+	if pconf.RecordConvertAfter < 0 {
+		return newValidationError("recordConvertAfter", "'recordConvertAfter' must be greater than or equal to zero")
+	}
+
+	if pconf.RecordVerifyInterval < 0 {
+		return newValidationError("recordVerifyInterval", "'recordVerifyInterval' must be greater than or equal to zero")
+	}
+
+	if !pconf.RecordVideo && !pconf.RecordAudio {
+		return newValidationError("recordVideo", "at least one between 'recordVideo' and 'recordAudio' must be enabled")
+	}
+
 	// avoid overflowing DurationV0 of mvhd
 	if pconf.RecordSegmentDuration > Duration(24*time.Hour) {
-		return fmt.Errorf("maximum segment duration is 1 day")
+		return newValidationError("recordSegmentDuration", "maximum segment duration is 1 day")
+	}
+
+	// Multicast output
+
+	if pconf.MulticastOutput != "" {
+		host, _, err := net.SplitHostPort(pconf.MulticastOutput)
+		if err != nil {
+			return newValidationError("multicastOutput", "invalid 'multicastOutput': %w", err)
+		}
+
+		ip := net.ParseIP(host)
+		if ip == nil || ip.To4() == nil || !ip.IsMulticast() {
+			return newValidationError("multicastOutput", "'multicastOutput' must be an IPv4 multicast address")
+		}
+	}
+
+	if pconf.MulticastOutputTTL == 0 || pconf.MulticastOutputTTL > 255 {
+		return newValidationError("multicastOutputTTL", "'multicastOutputTTL' must be between 1 and 255")
+	}
+
+	// Forwarding
+
+	for _, dest := range pconf.ForwardTo {
+		if !strings.HasPrefix(dest, "rtmp://") &&
+			!strings.HasPrefix(dest, "rtmps://") &&
+			!strings.HasPrefix(dest, "rtsp://") &&
+			!strings.HasPrefix(dest, "rtsps://") {
+			return newValidationError("forwardTo", "'%s' is not a supported forward destination", dest)
+		}
 	}
 
 	// Authentication (deprecated)
@@ -566,11 +958,18 @@ func (pconf *Path) validate(
 	// Hooks
 
 	if pconf.RunOnInit != "" && pconf.Regexp != nil {
-		return fmt.Errorf("a path with a regular expression (or path 'all')" +
+		return newValidationError("runOnInit", "a path with a regular expression (or path 'all')"+
 			" does not support option 'runOnInit'; use another path")
 	}
 	if (pconf.RunOnDemand != "" || pconf.RunOnUnDemand != "") && pconf.Source != "publisher" {
-		return fmt.Errorf("'runOnDemand' and 'runOnUnDemand' can be used only when source is 'publisher'")
+		return newValidationError("runOnDemand", "'runOnDemand' and 'runOnUnDemand' can be used only when source is 'publisher'")
+	}
+	if pconf.RunOnDemandRestartPause <= 0 {
+		return newValidationError("runOnDemandRestartPause", "'runOnDemandRestartPause' must be greater than zero")
+	}
+	if pconf.RunOnDemandRestartMaxPause < pconf.RunOnDemandRestartPause {
+		return newValidationError("runOnDemandRestartMaxPause",
+			"'runOnDemandRestartMaxPause' must be greater than or equal to 'runOnDemandRestartPause'")
 	}
 
 	return nil
@@ -581,6 +980,53 @@ func (pconf *Path) Equal(other *Path) bool {
 	return reflect.DeepEqual(pconf, other)
 }
 
+// scheduleContains checks whether a schedule contains the given time.
+// An empty schedule contains any time.
+func (pconf Path) scheduleContains(schedule ReadSchedule, t time.Time) bool {
+	if len(schedule) == 0 {
+		return true
+	}
+
+	if pconf.ReadScheduleTimezone != "" {
+		loc, err := time.LoadLocation(pconf.ReadScheduleTimezone)
+		if err == nil {
+			t = t.In(loc)
+		}
+	} else {
+		t = t.Local()
+	}
+
+	for _, w := range schedule {
+		if w.contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadAllowed checks whether reading is allowed at the given time.
+func (pconf Path) ReadAllowed(t time.Time) bool {
+	return pconf.scheduleContains(pconf.ReadSchedule, t)
+}
+
+// SourceAllowed checks whether the static source can be pulled at the given time.
+func (pconf Path) SourceAllowed(t time.Time) bool {
+	return pconf.scheduleContains(pconf.SourceSchedule, t)
+}
+
+// RecordAllowed checks whether recording is allowed at the given time.
+func (pconf Path) RecordAllowed(t time.Time) bool {
+	return pconf.scheduleContains(pconf.RecordSchedule, t)
+}
+
+// HasSchedules checks whether the path has at least one schedule.
+func (pconf Path) HasSchedules() bool {
+	return len(pconf.ReadSchedule) != 0 ||
+		len(pconf.SourceSchedule) != 0 ||
+		len(pconf.RecordSchedule) != 0
+}
+
 // HasStaticSource checks whether the path has a static source.
 func (pconf Path) HasStaticSource() bool {
 	return pconf.Source != "publisher" && pconf.Source != "redirect"
@@ -0,0 +1,20 @@
+package conf
+
+import "encoding/json"
+
+// PathRewriteRule is a rule that rewrites the name of requested paths.
+type PathRewriteRule struct {
+	Match   string `json:"match"`
+	Replace string `json:"replace"`
+}
+
+// PathRewriteRules is a list of PathRewriteRule.
+type PathRewriteRules []PathRewriteRule
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *PathRewriteRules) UnmarshalJSON(b []byte) error {
+	// remove default value before loading new value
+	// https://github.com/golang/go/issues/21092
+	*r = nil
+	return json.Unmarshal(b, (*[]PathRewriteRule)(r))
+}
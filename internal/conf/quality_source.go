@@ -0,0 +1,21 @@
+package conf
+
+import "encoding/json"
+
+// QualitySource is a source that provides a specific quality of a path,
+// that can be requested by readers through the "quality" query parameter.
+type QualitySource struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// QualitySources is a list of QualitySource.
+type QualitySources []QualitySource
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *QualitySources) UnmarshalJSON(b []byte) error {
+	// remove default value before loading new value
+	// https://github.com/golang/go/issues/21092
+	*s = nil
+	return json.Unmarshal(b, (*[]QualitySource)(s))
+}
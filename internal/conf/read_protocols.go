@@ -0,0 +1,68 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// protocols that can be used to read a path.
+var readProtocols = []string{"rtsp", "rtmp", "hls", "dash", "mjpeg", "webrtc", "srt"}
+
+// ReadProtocols is the readProtocols parameter.
+type ReadProtocols map[string]struct{}
+
+// MarshalJSON implements json.Marshaler.
+func (d ReadProtocols) MarshalJSON() ([]byte, error) {
+	out := make([]string, len(d))
+	i := 0
+
+	for p := range d {
+		out[i] = p
+		i++
+	}
+
+	sort.Strings(out)
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *ReadProtocols) UnmarshalJSON(b []byte) error {
+	var in []string
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+
+	*d = make(ReadProtocols)
+
+	for _, proto := range in {
+		found := false
+		for _, p := range readProtocols {
+			if proto == p {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("invalid protocol: %s", proto)
+		}
+
+		(*d)[proto] = struct{}{}
+	}
+
+	return nil
+}
+
+// UnmarshalEnv implements env.Unmarshaler.
+func (d *ReadProtocols) UnmarshalEnv(_ string, v string) error {
+	byts, _ := json.Marshal(strings.Split(v, ","))
+	return d.UnmarshalJSON(byts)
+}
+
+// enumValues implements schemaEnum.
+func (ReadProtocols) enumValues() []string {
+	return readProtocols
+}
@@ -0,0 +1,97 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+var readScheduleDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func parseReadScheduleTime(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time '%s', it must be in the HH:MM format", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ReadScheduleWindow is a time window in which reading is allowed.
+type ReadScheduleWindow struct {
+	Days  []string `json:"days"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+func (w ReadScheduleWindow) validate() error {
+	for _, day := range w.Days {
+		if _, ok := readScheduleDays[day]; !ok {
+			return fmt.Errorf("invalid day '%s'", day)
+		}
+	}
+
+	start, err := parseReadScheduleTime(w.Start)
+	if err != nil {
+		return err
+	}
+
+	end, err := parseReadScheduleTime(w.End)
+	if err != nil {
+		return err
+	}
+
+	if start == end {
+		return fmt.Errorf("start and end must be different")
+	}
+
+	return nil
+}
+
+func (w ReadScheduleWindow) hasDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+
+	for _, d := range w.Days {
+		if readScheduleDays[d] == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contains checks whether the window contains the given time.
+// When end is before start, the window crosses midnight
+// and days refer to the day in which the window starts.
+func (w ReadScheduleWindow) contains(t time.Time) bool {
+	start, _ := parseReadScheduleTime(w.Start)
+	end, _ := parseReadScheduleTime(w.End)
+	cur := t.Hour()*60 + t.Minute()
+
+	if start < end {
+		return w.hasDay(t.Weekday()) && cur >= start && cur < end
+	}
+
+	return (w.hasDay(t.Weekday()) && cur >= start) ||
+		(w.hasDay((t.Weekday()+6)%7) && cur < end)
+}
+
+// ReadSchedule is a list of ReadScheduleWindow.
+type ReadSchedule []ReadScheduleWindow
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *ReadSchedule) UnmarshalJSON(b []byte) error {
+	// remove default value before loading new value
+	// https://github.com/golang/go/issues/21092
+	*s = nil
+	return json.Unmarshal(b, (*[]ReadScheduleWindow)(s))
+}
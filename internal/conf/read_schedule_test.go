@@ -0,0 +1,88 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathReadAllowed(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+
+	for _, ca := range []struct {
+		name    string
+		window  ReadScheduleWindow
+		t       time.Time
+		allowed bool
+	}{
+		{
+			"inside",
+			ReadScheduleWindow{Start: "08:00", End: "18:00"},
+			time.Date(2024, 5, 6, 8, 0, 0, 0, loc),
+			true,
+		},
+		{
+			"after end",
+			ReadScheduleWindow{Start: "08:00", End: "18:00"},
+			time.Date(2024, 5, 6, 18, 0, 0, 0, loc),
+			false,
+		},
+		{
+			"timezone",
+			ReadScheduleWindow{Start: "08:00", End: "18:00"},
+			time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC),
+			true,
+		},
+		{
+			"wrong day",
+			ReadScheduleWindow{Days: []string{"mon", "tue"}, Start: "08:00", End: "18:00"},
+			time.Date(2024, 5, 8, 10, 0, 0, 0, loc),
+			false,
+		},
+		{
+			"overnight start day",
+			ReadScheduleWindow{Days: []string{"fri"}, Start: "22:00", End: "06:00"},
+			time.Date(2024, 5, 10, 23, 0, 0, 0, loc),
+			true,
+		},
+		{
+			"overnight next day",
+			ReadScheduleWindow{Days: []string{"fri"}, Start: "22:00", End: "06:00"},
+			time.Date(2024, 5, 11, 5, 59, 0, 0, loc),
+			true,
+		},
+		{
+			"overnight wrong day",
+			ReadScheduleWindow{Days: []string{"fri"}, Start: "22:00", End: "06:00"},
+			time.Date(2024, 5, 10, 5, 0, 0, 0, loc),
+			false,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			pconf := Path{
+				ReadSchedule:         ReadSchedule{ca.window},
+				ReadScheduleTimezone: "Europe/Rome",
+			}
+			require.Equal(t, ca.allowed, pconf.ReadAllowed(ca.t))
+		})
+	}
+}
+
+func TestPathSourceAndRecordAllowed(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+
+	pconf := Path{
+		SourceSchedule:       ReadSchedule{{Start: "08:00", End: "18:00"}},
+		RecordSchedule:       ReadSchedule{{Days: []string{"mon"}, Start: "09:00", End: "12:00"}},
+		ReadScheduleTimezone: "Europe/Rome",
+	}
+
+	require.Equal(t, true, pconf.ReadAllowed(time.Date(2024, 5, 6, 7, 0, 0, 0, loc)))
+	require.Equal(t, false, pconf.SourceAllowed(time.Date(2024, 5, 6, 7, 0, 0, 0, loc)))
+	require.Equal(t, true, pconf.SourceAllowed(time.Date(2024, 5, 6, 10, 0, 0, 0, loc)))
+	require.Equal(t, true, pconf.RecordAllowed(time.Date(2024, 5, 6, 10, 0, 0, 0, loc)))
+	require.Equal(t, false, pconf.RecordAllowed(time.Date(2024, 5, 7, 10, 0, 0, 0, loc)))
+}
@@ -54,3 +54,8 @@ func (d *RecordFormat) UnmarshalJSON(b []byte) error {
 func (d *RecordFormat) UnmarshalEnv(_ string, v string) error {
 	return d.UnmarshalJSON([]byte(`"` + v + `"`))
 }
+
+// enumValues implements schemaEnum.
+func (RecordFormat) enumValues() []string {
+	return []string{"fmp4", "mpegts"}
+}
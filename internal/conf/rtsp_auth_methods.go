@@ -61,3 +61,8 @@ func (d *RTSPAuthMethods) UnmarshalEnv(_ string, v string) error {
 	byts, _ := json.Marshal(strings.Split(v, ","))
 	return d.UnmarshalJSON(byts)
 }
+
+// enumValues implements schemaEnum.
+func (RTSPAuthMethods) enumValues() []string {
+	return []string{"basic", "digest"}
+}
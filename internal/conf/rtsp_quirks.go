@@ -0,0 +1,48 @@
+package conf
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bluenviron/gortsplib/v4"
+)
+
+// rtspQuirks contains workarounds for RTSP sources that don't follow the specification,
+// grouped by name. Each profile bundles settings that are otherwise configured one by one.
+var rtspQuirks = map[string]func(pconf *Path){
+	// sources that send packets from ports different from the ones
+	// announced during SETUP, usually because they're behind a NAT.
+	"random-ports": func(pconf *Path) {
+		pconf.RTSPAnyPort = true
+	},
+
+	// sources that have a broken or missing UDP implementation.
+	"tcp-only": func(pconf *Path) {
+		v := gortsplib.TransportTCP
+		pconf.RTSPTransport = RTSPTransport{Transport: &v}
+	},
+}
+
+func rtspQuirkNames() []string {
+	ret := make([]string, 0, len(rtspQuirks))
+	for name := range rtspQuirks {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func checkRTSPQuirks(names []string) error {
+	for _, name := range names {
+		if _, ok := rtspQuirks[name]; !ok {
+			return fmt.Errorf("unknown quirk profile '%s', available profiles are %v", name, rtspQuirkNames())
+		}
+	}
+	return nil
+}
+
+func applyRTSPQuirks(pconf *Path) {
+	for _, name := range pconf.RTSPQuirks {
+		rtspQuirks[name](pconf)
+	}
+}
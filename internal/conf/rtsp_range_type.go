@@ -68,3 +68,8 @@ func (d *RTSPRangeType) UnmarshalJSON(b []byte) error {
 func (d *RTSPRangeType) UnmarshalEnv(_ string, v string) error {
 	return d.UnmarshalJSON([]byte(`"` + v + `"`))
 }
+
+// enumValues implements schemaEnum.
+func (RTSPRangeType) enumValues() []string {
+	return []string{"", "clock", "npt", "smpte"}
+}
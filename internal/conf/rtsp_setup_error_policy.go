@@ -0,0 +1,68 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RTSPSetupErrorPolicy is the policy applied when the SETUP of a track fails.
+type RTSPSetupErrorPolicy int
+
+// supported values.
+const (
+	RTSPSetupErrorPolicySkip RTSPSetupErrorPolicy = iota
+	RTSPSetupErrorPolicyFail
+	RTSPSetupErrorPolicyRetry
+)
+
+// MarshalJSON implements json.Marshaler.
+func (d RTSPSetupErrorPolicy) MarshalJSON() ([]byte, error) {
+	var out string
+
+	switch d {
+	case RTSPSetupErrorPolicyFail:
+		out = "fail"
+
+	case RTSPSetupErrorPolicyRetry:
+		out = "retry"
+
+	default:
+		out = "skip"
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *RTSPSetupErrorPolicy) UnmarshalJSON(b []byte) error {
+	var in string
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+
+	switch in {
+	case "skip":
+		*d = RTSPSetupErrorPolicySkip
+
+	case "fail":
+		*d = RTSPSetupErrorPolicyFail
+
+	case "retry":
+		*d = RTSPSetupErrorPolicyRetry
+
+	default:
+		return fmt.Errorf("invalid rtsp setup error policy: '%s'", in)
+	}
+
+	return nil
+}
+
+// UnmarshalEnv implements env.Unmarshaler.
+func (d *RTSPSetupErrorPolicy) UnmarshalEnv(_ string, v string) error {
+	return d.UnmarshalJSON([]byte(`"` + v + `"`))
+}
+
+// enumValues implements schemaEnum.
+func (RTSPSetupErrorPolicy) enumValues() []string {
+	return []string{"skip", "fail", "retry"}
+}
@@ -68,3 +68,8 @@ func (d *RTSPTransport) UnmarshalJSON(b []byte) error {
 func (d *RTSPTransport) UnmarshalEnv(_ string, v string) error {
 	return d.UnmarshalJSON([]byte(`"` + v + `"`))
 }
+
+// enumValues implements schemaEnum.
+func (RTSPTransport) enumValues() []string {
+	return []string{"automatic", "udp", "multicast", "tcp"}
+}
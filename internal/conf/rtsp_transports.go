@@ -73,3 +73,8 @@ func (d *RTSPTransports) UnmarshalEnv(_ string, v string) error {
 	byts, _ := json.Marshal(strings.Split(v, ","))
 	return d.UnmarshalJSON(byts)
 }
+
+// enumValues implements schemaEnum.
+func (RTSPTransports) enumValues() []string {
+	return []string{"udp", "multicast", "tcp"}
+}
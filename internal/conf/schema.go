@@ -0,0 +1,117 @@
+package conf
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// schemaEnum is implemented by parameters that can only assume a fixed set of values.
+type schemaEnum interface {
+	enumValues() []string
+}
+
+func schemaEnumValues(t reflect.Type) []string {
+	if e, ok := reflect.Zero(t).Interface().(schemaEnum); ok {
+		return e.enumValues()
+	}
+	return nil
+}
+
+// SchemaField describes a configuration parameter.
+type SchemaField struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Default interface{} `json:"default"`
+	Enum    []string    `json:"enum,omitempty"`
+}
+
+// Schema describes all configuration parameters.
+type Schema struct {
+	Global []*SchemaField `json:"global"`
+	Path   []*SchemaField `json:"path"`
+}
+
+func schemaType(t reflect.Type, def interface{}) string {
+	switch t {
+	case reflect.TypeOf(Duration(0)):
+		return "duration"
+
+	case reflect.TypeOf(StringSize(0)):
+		return "size"
+	}
+
+	switch def.(type) {
+	case bool:
+		return "boolean"
+
+	case float64:
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return "number"
+		default:
+			return "integer"
+		}
+
+	case string:
+		return "string"
+
+	case []interface{}:
+		return "array"
+
+	case map[string]interface{}:
+		return "object"
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map:
+		return "array"
+
+	case reflect.Struct:
+		return "object"
+
+	default:
+		return "string"
+	}
+}
+
+func schemaFields(t reflect.Type, defaults interface{}) []*SchemaField {
+	buf, _ := json.Marshal(defaults)
+	var values map[string]interface{}
+	json.Unmarshal(buf, &values) //nolint:errcheck
+
+	var fields []*SchemaField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		js := f.Tag.Get("json")
+
+		// skip internal and deprecated parameters
+		if js == "" || js == "-" || js == "name" || js == "paths" || js == "pathDefaults" ||
+			f.Tag.Get("deprecated") == "true" {
+			continue
+		}
+
+		js = strings.Split(js, ",")[0]
+
+		fields = append(fields, &SchemaField{
+			Name:    js,
+			Type:    schemaType(f.Type, values[js]),
+			Default: values[js],
+			Enum:    schemaEnumValues(f.Type),
+		})
+	}
+
+	return fields
+}
+
+// GenerateSchema generates the schema of the configuration.
+func GenerateSchema() *Schema {
+	var conf Conf
+	conf.setDefaults()
+
+	return &Schema{
+		Global: schemaFields(reflect.TypeOf(Conf{}), conf),
+		Path:   schemaFields(reflect.TypeOf(Path{}), conf.PathDefaults),
+	}
+}
@@ -0,0 +1,96 @@
+package conf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func findSchemaField(fields []*SchemaField, name string) *SchemaField {
+	for _, f := range fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func TestSchema(t *testing.T) {
+	s := GenerateSchema()
+
+	require.Equal(t, &SchemaField{
+		Name:    "readTimeout",
+		Type:    "duration",
+		Default: "10s",
+	}, findSchemaField(s.Global, "readTimeout"))
+
+	require.Equal(t, &SchemaField{
+		Name:    "logLevel",
+		Type:    "string",
+		Default: "info",
+		Enum:    []string{"error", "warn", "info", "debug"},
+	}, findSchemaField(s.Global, "logLevel"))
+
+	require.Equal(t, &SchemaField{
+		Name:    "writeQueueSize",
+		Type:    "integer",
+		Default: float64(512),
+	}, findSchemaField(s.Global, "writeQueueSize"))
+
+	require.Equal(t, &SchemaField{
+		Name:    "recordFormat",
+		Type:    "string",
+		Default: "fmp4",
+		Enum:    []string{"fmp4", "mpegts"},
+	}, findSchemaField(s.Path, "recordFormat"))
+
+	require.Equal(t, &SchemaField{
+		Name:    "multicastOutputFormat",
+		Type:    "string",
+		Default: "mpegts",
+		Enum:    []string{"mpegts", "rtp"},
+	}, findSchemaField(s.Path, "multicastOutputFormat"))
+
+	// deprecated parameters are not part of the schema
+	require.Nil(t, findSchemaField(s.Global, "readBufferCount"))
+	require.Nil(t, findSchemaField(s.Global, "serverKey"))
+	require.Nil(t, findSchemaField(s.Path, "sourceProtocol"))
+	require.Nil(t, findSchemaField(s.Path, "name"))
+}
+
+func TestSchemaEnumDefaults(t *testing.T) {
+	s := GenerateSchema()
+
+	for _, f := range append(s.Global, s.Path...) {
+		if f.Enum == nil {
+			continue
+		}
+
+		switch def := f.Default.(type) {
+		case string:
+			require.Contains(t, f.Enum, def, f.Name)
+
+		case []interface{}:
+			for _, v := range def {
+				require.Contains(t, f.Enum, v, f.Name)
+			}
+		}
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	tmpf, err := createTempFile([]byte("paths:\n" +
+		"  mypath:\n" +
+		"    recordVideo: no\n" +
+		"    recordAudio: no\n"))
+	require.NoError(t, err)
+	defer os.Remove(tmpf)
+
+	_, _, err = Load(tmpf, nil, nil)
+	require.Error(t, err)
+
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "paths.mypath.recordVideo", verr.FieldPath())
+}
@@ -0,0 +1,42 @@
+package conf
+
+import (
+	"fmt"
+)
+
+// ValidationError is a configuration validation error
+// that is associated with a specific parameter.
+type ValidationError struct {
+	// name of the path, if the parameter belongs to a path.
+	Path string
+
+	// name of the parameter.
+	Field string
+
+	err error
+}
+
+func newValidationError(field string, format string, args ...interface{}) error {
+	return &ValidationError{
+		Field: field,
+		err:   fmt.Errorf(format, args...),
+	}
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap implements the error interface.
+func (e *ValidationError) Unwrap() error {
+	return e.err
+}
+
+// FieldPath returns the full path of the parameter, in the same format used by the configuration file.
+func (e *ValidationError) FieldPath() string {
+	if e.Path != "" {
+		return "paths." + e.Path + "." + e.Field
+	}
+	return e.Field
+}
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"net"
@@ -124,6 +125,8 @@ func TestAPIPathsList(t *testing.T) {
 		})
 		require.NoError(t, err)
 
+		time.Sleep(500 * time.Millisecond)
+
 		var out pathList
 		httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/paths/list", nil, &out)
 		require.Equal(t, pathList{
@@ -342,6 +345,184 @@ func TestAPIPathsGet(t *testing.T) {
 	}
 }
 
+func TestAPIPathsDebug(t *testing.T) {
+	p, ok := newInstance("api: yes\n" +
+		"paths:\n" +
+		"  all_others:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	source := gortsplib.Client{}
+	err := source.StartRecording("rtsp://localhost:8554/mypath",
+		&description.Session{Medias: []*description.Media{test.UniqueMediaH264()}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	var out map[string]interface{}
+	httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/paths/debug/mypath", nil, &out)
+	require.Equal(t, map[string]interface{}{
+		"name":  "mypath",
+		"ready": true,
+		"tracks": []interface{}{
+			map[string]interface{}{
+				"codec":   "H264",
+				"width":   float64(1920),
+				"height":  float64(1080),
+				"profile": float64(66),
+				"level":   float64(40),
+				"parameterSets": []interface{}{
+					base64.StdEncoding.EncodeToString(test.FormatH264.SPS),
+					base64.StdEncoding.EncodeToString(test.FormatH264.PPS),
+				},
+				"units":            float64(0),
+				"keyFrames":        float64(0),
+				"lastKeyFrameTime": nil,
+				"lastKeyFrameSize": float64(0),
+				"lastGOPUnits":     float64(0),
+				"averageGOPUnits":  float64(0),
+				"timestampJumps":   float64(0),
+			},
+		},
+	}, out)
+
+	res, err := hc.Get("http://localhost:9997/v3/paths/debug/nonexisting")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+	checkError(t, "path not found", res.Body)
+}
+
+func TestAPIPathsHealth(t *testing.T) {
+	p, ok := newInstance("api: yes\n" +
+		"paths:\n" +
+		"  idlepath:\n" +
+		"  all_others:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	source := gortsplib.Client{}
+	err := source.StartRecording("rtsp://localhost:8554/mypath",
+		&description.Session{Medias: []*description.Media{test.UniqueMediaH264()}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	var out map[string]interface{}
+	httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/paths/health", nil, &out)
+	require.Equal(t, map[string]interface{}{
+		"pageCount": float64(1),
+		"itemCount": float64(2),
+		"items": []interface{}{
+			map[string]interface{}{
+				"name":          "idlepath",
+				"state":         "idle",
+				"lastPacketAgo": nil,
+				"readers":       float64(0),
+				"recording":     false,
+				"lastError":     nil,
+			},
+			map[string]interface{}{
+				"name":          "mypath",
+				"state":         "ready",
+				"lastPacketAgo": nil,
+				"readers":       float64(0),
+				"recording":     false,
+				"lastError":     nil,
+			},
+		},
+	}, out)
+}
+
+func TestAPIPathsMute(t *testing.T) {
+	p, ok := newInstance("api: yes\n" +
+		"paths:\n" +
+		"  mypath:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	var out map[string]interface{}
+	httpRequest(t, hc, http.MethodPost, "http://localhost:9997/v3/paths/mute/mypath",
+		map[string]interface{}{"audio": true}, &out)
+	require.Equal(t, map[string]interface{}{
+		"audio": true,
+		"video": false,
+	}, out)
+
+	httpRequest(t, hc, http.MethodPost, "http://localhost:9997/v3/paths/mute/mypath",
+		map[string]interface{}{"video": true}, &out)
+	require.Equal(t, map[string]interface{}{
+		"audio": true,
+		"video": true,
+	}, out)
+}
+
+func TestAPIPathsTracks(t *testing.T) {
+	p, ok := newInstance("api: yes\n" +
+		"paths:\n" +
+		"  all_others:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	source := gortsplib.Client{}
+	err := source.StartRecording("rtsp://localhost:8554/mypath",
+		&description.Session{Medias: []*description.Media{
+			test.UniqueMediaH264(),
+			test.UniqueMediaMPEG4Audio(),
+		}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	var out struct {
+		Tracks2 []map[string]interface{} `json:"tracks2"`
+	}
+	httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/paths/get/mypath", nil, &out)
+
+	require.Len(t, out.Tracks2, 2)
+
+	// fps depends on the VUI of the SPS, bitrate depends on timing
+	for _, track := range out.Tracks2 {
+		delete(track, "fps")
+		delete(track, "bitrate")
+	}
+
+	require.Equal(t, []map[string]interface{}{
+		{
+			"codec":        "H264",
+			"width":        float64(1920),
+			"height":       float64(1080),
+			"profile":      float64(66),
+			"level":        float64(40),
+			"sampleRate":   nil,
+			"channelCount": nil,
+		},
+		{
+			"codec":        "MPEG-4 Audio",
+			"width":        nil,
+			"height":       nil,
+			"profile":      nil,
+			"level":        nil,
+			"sampleRate":   float64(44100),
+			"channelCount": float64(2),
+		},
+	}, out.Tracks2)
+}
+
 func TestAPIProtocolListGet(t *testing.T) {
 	serverCertFpath, err := test.CreateTempFile(test.TLSCertPub)
 	require.NoError(t, err)
@@ -630,6 +811,7 @@ func TestAPIProtocolListGet(t *testing.T) {
 							"rtpPacketsLost":      float64(0),
 							"rtpPacketsInError":   float64(0),
 							"rtpPacketsJitter":    float64(0),
+							"rtpPacketsDropped":   float64(0),
 							"rtcpPacketsReceived": float64(0),
 							"rtcpPacketsSent":     float64(0),
 							"rtcpPacketsInError":  float64(0),
@@ -673,6 +855,7 @@ func TestAPIProtocolListGet(t *testing.T) {
 							"rtpPacketsLost":      float64(0),
 							"rtpPacketsInError":   float64(0),
 							"rtpPacketsJitter":    float64(0),
+							"rtpPacketsDropped":   float64(0),
 							"rtcpPacketsReceived": float64(0),
 							"rtcpPacketsSent":     float64(0),
 							"rtcpPacketsInError":  float64(0),
@@ -717,19 +900,39 @@ func TestAPIProtocolListGet(t *testing.T) {
 				}, out1)
 
 			case "hls":
+				item := out1.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})
+
+				// the playlist request performed above is the only session
+				sessions := item["sessions"].([]interface{})
+				require.Len(t, sessions, 1)
+				session := sessions[0].(map[string]interface{})
+
 				require.Equal(t, map[string]interface{}{
 					"itemCount": float64(1),
 					"pageCount": float64(1),
 					"items": []interface{}{
 						map[string]interface{}{
-							"bytesSent":   out1.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["bytesSent"],
-							"created":     out1.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["created"],
-							"lastRequest": out1.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["lastRequest"],
+							"bytesSent":   item["bytesSent"],
+							"created":     item["created"],
+							"lastRequest": item["lastRequest"],
 							"path":        "mypath",
+							"sessions": []interface{}{
+								map[string]interface{}{
+									"id":          session["id"],
+									"created":     session["created"],
+									"lastRequest": session["lastRequest"],
+									"remoteAddr":  session["remoteAddr"],
+									"userAgent":   "Go-http-client/1.1",
+									"bytesSent":   item["bytesSent"],
+									"closed":      nil,
+								},
+							},
 						},
 					},
 				}, out1)
 
+				require.Regexp(t, `^127\.0\.0\.1:[0-9]+$`, session["remoteAddr"])
+
 			case "webrtc":
 				require.Equal(t, map[string]interface{}{
 					"itemCount": float64(1),
@@ -747,6 +950,7 @@ func TestAPIProtocolListGet(t *testing.T) {
 							"remoteAddr":                out1.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["remoteAddr"],
 							"remoteCandidate":           out1.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["remoteCandidate"],
 							"state":                     "read",
+							"audioLevel":                nil,
 						},
 					},
 				}, out1)
@@ -4,11 +4,14 @@ package core
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"reflect"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,20 +23,36 @@ import (
 	"github.com/bluenviron/mediamtx/internal/auth"
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/confwatcher"
+	"github.com/bluenviron/mediamtx/internal/crashreport"
+	"github.com/bluenviron/mediamtx/internal/ddns"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/metrics"
+	"github.com/bluenviron/mediamtx/internal/onvif"
 	"github.com/bluenviron/mediamtx/internal/playback"
 	"github.com/bluenviron/mediamtx/internal/pprof"
 	"github.com/bluenviron/mediamtx/internal/recordcleaner"
+	"github.com/bluenviron/mediamtx/internal/recordconverter"
+	"github.com/bluenviron/mediamtx/internal/recordverifier"
 	"github.com/bluenviron/mediamtx/internal/rlimit"
+	"github.com/bluenviron/mediamtx/internal/servers/dash"
 	"github.com/bluenviron/mediamtx/internal/servers/hls"
+	"github.com/bluenviron/mediamtx/internal/servers/mjpeg"
 	"github.com/bluenviron/mediamtx/internal/servers/rtmp"
+	"github.com/bluenviron/mediamtx/internal/servers/rtppush"
 	"github.com/bluenviron/mediamtx/internal/servers/rtsp"
 	"github.com/bluenviron/mediamtx/internal/servers/srt"
 	"github.com/bluenviron/mediamtx/internal/servers/webrtc"
 )
 
+const (
+	// garbage collector target percentage used by the low memory profile.
+	lowMemoryGCPercent = 50
+
+	// number of log entries kept in memory and attached to crash reports.
+	crashReportLogEntries = 1000
+)
+
 //go:generate go run ./versiongetter
 
 //go:embed VERSION
@@ -49,6 +68,7 @@ var defaultConfPaths = []string{
 
 var cli struct {
 	Version  bool   `help:"print version"`
+	Schema   bool   `help:"print the configuration schema in JSON format"`
 	Confpath string `arg:"" default:""`
 }
 
@@ -59,11 +79,14 @@ type Core struct {
 	confPath        string
 	conf            *conf.Conf
 	logger          *logger.Logger
+	crashReporter   *crashreport.Reporter
 	externalCmdPool *externalcmd.Pool
 	authManager     *auth.Manager
 	metrics         *metrics.Metrics
 	pprof           *pprof.PPROF
 	recordCleaner   *recordcleaner.Cleaner
+	recordConverter *recordconverter.Converter
+	recordVerifier  *recordverifier.Verifier
 	playbackServer  *playback.Server
 	pathManager     *pathManager
 	rtspServer      *rtsp.Server
@@ -71,13 +94,20 @@ type Core struct {
 	rtmpServer      *rtmp.Server
 	rtmpsServer     *rtmp.Server
 	hlsServer       *hls.Server
+	dashServer      *dash.Server
+	mjpegServer     *mjpeg.Server
 	webRTCServer    *webrtc.Server
 	srtServer       *srt.Server
+	rtpPushServer   *rtppush.Server
 	api             *api.API
+	onvifDiscoverer *onvif.Discoverer
+	ddnsUpdater     *ddns.Updater
 	confWatcher     *confwatcher.ConfWatcher
+	gcPercent       int
 
 	// in
-	chAPIConfigSet chan *conf.Conf
+	chAPIConfigSet  chan *conf.Conf
+	chONVIFAddPaths chan map[string]onvif.DiscoveredCamera
 
 	// out
 	done chan struct{}
@@ -109,16 +139,23 @@ func New(args []string) (*Core, bool) {
 		os.Exit(0)
 	}
 
+	if cli.Schema {
+		buf, _ := json.MarshalIndent(conf.GenerateSchema(), "", "  ")
+		fmt.Println(string(buf))
+		os.Exit(0)
+	}
+
 	ctx, ctxCancel := context.WithCancel(context.Background())
 
 	p := &Core{
-		ctx:            ctx,
-		ctxCancel:      ctxCancel,
-		chAPIConfigSet: make(chan *conf.Conf),
-		done:           make(chan struct{}),
+		ctx:             ctx,
+		ctxCancel:       ctxCancel,
+		chAPIConfigSet:  make(chan *conf.Conf),
+		chONVIFAddPaths: make(chan map[string]onvif.DiscoveredCamera),
+		done:            make(chan struct{}),
 	}
 
-	tempLogger, _ := logger.New(logger.Warn, []logger.Destination{logger.DestinationStdout}, "")
+	tempLogger, _ := logger.New(logger.Warn, []logger.Destination{logger.DestinationStdout}, "", 0)
 
 	p.conf, p.confPath, err = conf.Load(cli.Confpath, defaultConfPaths, tempLogger)
 	if err != nil {
@@ -160,6 +197,7 @@ func (p *Core) Log(level logger.Level, format string, args ...interface{}) {
 
 func (p *Core) run() {
 	defer close(p.done)
+	defer p.handlePanic()
 
 	confChanged := func() chan struct{} {
 		if p.confWatcher != nil {
@@ -198,6 +236,20 @@ outer:
 				break outer
 			}
 
+		case paths := <-p.chONVIFAddPaths:
+			newConf := p.confWithONVIFPaths(paths)
+			if newConf == nil {
+				continue
+			}
+
+			p.Log(logger.Info, "reloading configuration (ONVIF discovery)")
+
+			err := p.reloadConf(newConf, false)
+			if err != nil {
+				p.Log(logger.Error, "%s", err)
+				break outer
+			}
+
 		case <-interrupt:
 			p.Log(logger.Info, "shutting down gracefully")
 			break outer
@@ -216,10 +268,16 @@ func (p *Core) createResources(initial bool) error {
 	var err error
 
 	if p.logger == nil {
+		ringSize := 0
+		if p.conf.CrashReport {
+			ringSize = crashReportLogEntries
+		}
+
 		p.logger, err = logger.New(
 			logger.Level(p.conf.LogLevel),
 			p.conf.LogDestinations,
 			p.conf.LogFile,
+			ringSize,
 		)
 		if err != nil {
 			return err
@@ -251,19 +309,60 @@ func (p *Core) createResources(initial bool) error {
 		gin.SetMode(gin.ReleaseMode)
 
 		p.externalCmdPool = externalcmd.NewPool()
+
+		// save the GC target percentage set by GOGC, in order to restore it
+		// when the low memory profile is disabled.
+		p.gcPercent = debug.SetGCPercent(100)
+		debug.SetGCPercent(p.gcPercent)
+	}
+
+	if p.conf.LowMemory {
+		debug.SetGCPercent(lowMemoryGCPercent)
+		p.Log(logger.Info, "low memory profile is enabled")
+	} else {
+		debug.SetGCPercent(p.gcPercent)
+	}
+
+	if p.conf.CrashReport &&
+		p.crashReporter == nil {
+		i := &crashreport.Reporter{
+			Directory: p.conf.CrashReportDirectory,
+			URL:       p.conf.CrashReportURL,
+			Version:   string(version),
+			Logs:      p.logger,
+			Parent:    p,
+		}
+		err = i.Initialize()
+		if err != nil {
+			return err
+		}
+		p.crashReporter = i
+	}
+
+	if p.conf.LogCommandOutput {
+		p.externalCmdPool.SetOutputLogger(p)
+	} else {
+		p.externalCmdPool.SetOutputLogger(nil)
 	}
 
 	if p.authManager == nil {
-		p.authManager = &auth.Manager{
+		i := &auth.Manager{
 			Method:          p.conf.AuthMethod,
 			InternalUsers:   p.conf.AuthInternalUsers,
 			HTTPAddress:     p.conf.AuthHTTPAddress,
 			HTTPExclude:     p.conf.AuthHTTPExclude,
 			JWTJWKS:         p.conf.AuthJWTJWKS,
 			JWTClaimKey:     p.conf.AuthJWTClaimKey,
+			TokenSecret:     p.conf.AuthTokenSecret,
+			Script:          p.conf.AuthScript,
 			ReadTimeout:     time.Duration(p.conf.ReadTimeout),
 			RTSPAuthMethods: p.conf.RTSPAuthMethods,
 		}
+		err = i.Initialize()
+		if err != nil {
+			return err
+		}
+		p.authManager = i
 	}
 
 	if p.conf.Metrics &&
@@ -276,6 +375,7 @@ func (p *Core) createResources(initial bool) error {
 			AllowOrigin:    p.conf.MetricsAllowOrigin,
 			TrustedProxies: p.conf.MetricsTrustedProxies,
 			ReadTimeout:    p.conf.ReadTimeout,
+			LegacyNames:    p.conf.MetricsLegacyNames,
 			AuthManager:    p.authManager,
 			Parent:         p,
 		}
@@ -314,6 +414,27 @@ func (p *Core) createResources(initial bool) error {
 		p.recordCleaner.Initialize()
 	}
 
+	if p.recordConverter == nil {
+		p.recordConverter = &recordconverter.Converter{
+			PathConfs: p.conf.Paths,
+			Parent:    p,
+		}
+		p.recordConverter.Initialize()
+	}
+
+	if p.recordVerifier == nil {
+		p.recordVerifier = &recordverifier.Verifier{
+			PathConfs:       p.conf.Paths,
+			ExternalCmdPool: p.externalCmdPool,
+			Parent:          p,
+		}
+		p.recordVerifier.Initialize()
+
+		if p.metrics != nil {
+			p.metrics.SetRecordVerifier(p.recordVerifier)
+		}
+	}
+
 	if p.conf.Playback &&
 		p.playbackServer == nil {
 		i := &playback.Server{
@@ -324,6 +445,8 @@ func (p *Core) createResources(initial bool) error {
 			AllowOrigin:    p.conf.PlaybackAllowOrigin,
 			TrustedProxies: p.conf.PlaybackTrustedProxies,
 			ReadTimeout:    p.conf.ReadTimeout,
+			MaxRequests:    p.conf.PlaybackMaxRequests,
+			Rate:           p.conf.PlaybackRate,
 			PathConfs:      p.conf.Paths,
 			AuthManager:    p.authManager,
 			Parent:         p,
@@ -343,7 +466,9 @@ func (p *Core) createResources(initial bool) error {
 			readTimeout:       p.conf.ReadTimeout,
 			writeTimeout:      p.conf.WriteTimeout,
 			writeQueueSize:    p.conf.WriteQueueSize,
+			readerIdleTimeout: p.conf.ReaderIdleTimeout,
 			udpMaxPayloadSize: p.conf.UDPMaxPayloadSize,
+			pathRewriteRules:  p.conf.PathRewriteRules,
 			pathConfs:         p.conf.Paths,
 			externalCmdPool:   p.externalCmdPool,
 			parent:            p,
@@ -515,7 +640,9 @@ func (p *Core) createResources(initial bool) error {
 			SegmentMaxSize:  p.conf.HLSSegmentMaxSize,
 			Directory:       p.conf.HLSDirectory,
 			ReadTimeout:     p.conf.ReadTimeout,
+			WriteTimeout:    p.conf.WriteTimeout,
 			MuxerCloseAfter: p.conf.HLSMuxerCloseAfter,
+			SessionTokens:   p.conf.HLSSessionTokens,
 			PathManager:     p.pathManager,
 			Parent:          p,
 		}
@@ -532,6 +659,51 @@ func (p *Core) createResources(initial bool) error {
 		}
 	}
 
+	if p.conf.DASH &&
+		p.dashServer == nil {
+		i := &dash.Server{
+			Address:         p.conf.DASHAddress,
+			Encryption:      p.conf.DASHEncryption,
+			ServerKey:       p.conf.DASHServerKey,
+			ServerCert:      p.conf.DASHServerCert,
+			AllowOrigin:     p.conf.DASHAllowOrigin,
+			TrustedProxies:  p.conf.DASHTrustedProxies,
+			SegmentCount:    p.conf.DASHSegmentCount,
+			SegmentDuration: p.conf.DASHSegmentDuration,
+			ReadTimeout:     p.conf.ReadTimeout,
+			WriteTimeout:    p.conf.WriteTimeout,
+			MuxerCloseAfter: p.conf.DASHMuxerCloseAfter,
+			PathManager:     p.pathManager,
+			Parent:          p,
+		}
+		err = i.Initialize()
+		if err != nil {
+			return err
+		}
+		p.dashServer = i
+	}
+
+	if p.conf.MJPEG &&
+		p.mjpegServer == nil {
+		i := &mjpeg.Server{
+			Address:        p.conf.MJPEGAddress,
+			Encryption:     p.conf.MJPEGEncryption,
+			ServerKey:      p.conf.MJPEGServerKey,
+			ServerCert:     p.conf.MJPEGServerCert,
+			AllowOrigin:    p.conf.MJPEGAllowOrigin,
+			TrustedProxies: p.conf.MJPEGTrustedProxies,
+			ReadTimeout:    p.conf.ReadTimeout,
+			WriteTimeout:   p.conf.WriteTimeout,
+			PathManager:    p.pathManager,
+			Parent:         p,
+		}
+		err = i.Initialize()
+		if err != nil {
+			return err
+		}
+		p.mjpegServer = i
+	}
+
 	if p.conf.WebRTC &&
 		p.webRTCServer == nil {
 		i := &webrtc.Server{
@@ -569,6 +741,8 @@ func (p *Core) createResources(initial bool) error {
 		p.srtServer == nil {
 		i := &srt.Server{
 			Address:             p.conf.SRTAddress,
+			Latency:             p.conf.SRTLatency,
+			OverheadBW:          int64(p.conf.SRTOverheadBW),
 			RTSPAddress:         p.conf.RTSPAddress,
 			ReadTimeout:         p.conf.ReadTimeout,
 			WriteTimeout:        p.conf.WriteTimeout,
@@ -591,27 +765,44 @@ func (p *Core) createResources(initial bool) error {
 		}
 	}
 
+	if p.conf.RTPPush &&
+		p.rtpPushServer == nil {
+		i := &rtppush.Server{
+			Address:     p.conf.RTPPushAddress,
+			ReadTimeout: p.conf.ReadTimeout,
+			PathManager: p.pathManager,
+			Parent:      p,
+		}
+		err = i.Initialize()
+		if err != nil {
+			return err
+		}
+		p.rtpPushServer = i
+	}
+
 	if p.conf.API &&
 		p.api == nil {
 		i := &api.API{
-			Address:        p.conf.APIAddress,
-			Encryption:     p.conf.APIEncryption,
-			ServerKey:      p.conf.APIServerKey,
-			ServerCert:     p.conf.APIServerCert,
-			AllowOrigin:    p.conf.APIAllowOrigin,
-			TrustedProxies: p.conf.APITrustedProxies,
-			ReadTimeout:    p.conf.ReadTimeout,
-			Conf:           p.conf,
-			AuthManager:    p.authManager,
-			PathManager:    p.pathManager,
-			RTSPServer:     p.rtspServer,
-			RTSPSServer:    p.rtspsServer,
-			RTMPServer:     p.rtmpServer,
-			RTMPSServer:    p.rtmpsServer,
-			HLSServer:      p.hlsServer,
-			WebRTCServer:   p.webRTCServer,
-			SRTServer:      p.srtServer,
-			Parent:         p,
+			Address:         p.conf.APIAddress,
+			Encryption:      p.conf.APIEncryption,
+			ServerKey:       p.conf.APIServerKey,
+			ServerCert:      p.conf.APIServerCert,
+			AllowOrigin:     p.conf.APIAllowOrigin,
+			TrustedProxies:  p.conf.APITrustedProxies,
+			ReadTimeout:     p.conf.ReadTimeout,
+			Conf:            p.conf,
+			AuthManager:     p.authManager,
+			PathManager:     p.pathManager,
+			RTSPServer:      p.rtspServer,
+			RTSPSServer:     p.rtspsServer,
+			RTMPServer:      p.rtmpServer,
+			RTMPSServer:     p.rtmpsServer,
+			HLSServer:       p.hlsServer,
+			WebRTCServer:    p.webRTCServer,
+			SRTServer:       p.srtServer,
+			RTPPushServer:   p.rtpPushServer,
+			ExternalCmdPool: p.externalCmdPool,
+			Parent:          p,
 		}
 		err = i.Initialize()
 		if err != nil {
@@ -620,6 +811,28 @@ func (p *Core) createResources(initial bool) error {
 		p.api = i
 	}
 
+	if p.conf.ONVIFDiscovery &&
+		p.onvifDiscoverer == nil {
+		p.onvifDiscoverer = &onvif.Discoverer{
+			Interval: p.conf.ONVIFDiscoveryInterval,
+			User:     p.conf.ONVIFDiscoveryUser,
+			Pass:     p.conf.ONVIFDiscoveryPass,
+			PathName: p.conf.ONVIFDiscoveryPathName,
+			Parent:   p,
+		}
+		p.onvifDiscoverer.Initialize()
+	}
+
+	if p.conf.DDNSUpdateURL != "" &&
+		p.ddnsUpdater == nil {
+		p.ddnsUpdater = &ddns.Updater{
+			URL:      p.conf.DDNSUpdateURL,
+			Interval: p.conf.DDNSUpdateInterval,
+			Parent:   p,
+		}
+		p.ddnsUpdater.Initialize()
+	}
+
 	if initial && p.confPath != "" {
 		p.confWatcher, err = confwatcher.New(p.confPath)
 		if err != nil {
@@ -634,7 +847,14 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 	closeLogger := newConf == nil ||
 		newConf.LogLevel != p.conf.LogLevel ||
 		!reflect.DeepEqual(newConf.LogDestinations, p.conf.LogDestinations) ||
-		newConf.LogFile != p.conf.LogFile
+		newConf.LogFile != p.conf.LogFile ||
+		newConf.CrashReport != p.conf.CrashReport
+
+	closeCrashReporter := newConf == nil ||
+		newConf.CrashReport != p.conf.CrashReport ||
+		newConf.CrashReportDirectory != p.conf.CrashReportDirectory ||
+		newConf.CrashReportURL != p.conf.CrashReportURL ||
+		closeLogger
 
 	closeAuthManager := newConf == nil ||
 		newConf.AuthMethod != p.conf.AuthMethod ||
@@ -642,6 +862,8 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		!reflect.DeepEqual(newConf.AuthHTTPExclude, p.conf.AuthHTTPExclude) ||
 		newConf.AuthJWTJWKS != p.conf.AuthJWTJWKS ||
 		newConf.AuthJWTClaimKey != p.conf.AuthJWTClaimKey ||
+		newConf.AuthTokenSecret != p.conf.AuthTokenSecret ||
+		newConf.AuthScript != p.conf.AuthScript ||
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		!reflect.DeepEqual(newConf.RTSPAuthMethods, p.conf.RTSPAuthMethods)
 	if !closeAuthManager && !reflect.DeepEqual(newConf.AuthInternalUsers, p.conf.AuthInternalUsers) {
@@ -656,6 +878,7 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.MetricsServerCert != p.conf.MetricsServerCert ||
 		newConf.MetricsAllowOrigin != p.conf.MetricsAllowOrigin ||
 		!reflect.DeepEqual(newConf.MetricsTrustedProxies, p.conf.MetricsTrustedProxies) ||
+		newConf.MetricsLegacyNames != p.conf.MetricsLegacyNames ||
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		closeAuthManager ||
 		closeLogger
@@ -678,6 +901,19 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		p.recordCleaner.ReloadPathConfs(newConf.Paths)
 	}
 
+	closeRecordConverter := newConf == nil ||
+		closeLogger
+	if !closeRecordConverter && !reflect.DeepEqual(newConf.Paths, p.conf.Paths) {
+		p.recordConverter.ReloadPathConfs(newConf.Paths)
+	}
+
+	closeRecordVerifier := newConf == nil ||
+		closeMetrics ||
+		closeLogger
+	if !closeRecordVerifier && !reflect.DeepEqual(newConf.Paths, p.conf.Paths) {
+		p.recordVerifier.ReloadPathConfs(newConf.Paths)
+	}
+
 	closePlaybackServer := newConf == nil ||
 		newConf.Playback != p.conf.Playback ||
 		newConf.PlaybackAddress != p.conf.PlaybackAddress ||
@@ -687,6 +923,8 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.PlaybackAllowOrigin != p.conf.PlaybackAllowOrigin ||
 		!reflect.DeepEqual(newConf.PlaybackTrustedProxies, p.conf.PlaybackTrustedProxies) ||
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
+		newConf.PlaybackMaxRequests != p.conf.PlaybackMaxRequests ||
+		newConf.PlaybackRate != p.conf.PlaybackRate ||
 		closeAuthManager ||
 		closeLogger
 	if !closePlaybackServer && p.playbackServer != nil && !reflect.DeepEqual(newConf.Paths, p.conf.Paths) {
@@ -700,7 +938,9 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		newConf.WriteTimeout != p.conf.WriteTimeout ||
 		newConf.WriteQueueSize != p.conf.WriteQueueSize ||
+		newConf.ReaderIdleTimeout != p.conf.ReaderIdleTimeout ||
 		newConf.UDPMaxPayloadSize != p.conf.UDPMaxPayloadSize ||
+		!reflect.DeepEqual(newConf.PathRewriteRules, p.conf.PathRewriteRules) ||
 		closeMetrics ||
 		closeAuthManager ||
 		closeLogger
@@ -795,11 +1035,42 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		newConf.HLSSegmentMaxSize != p.conf.HLSSegmentMaxSize ||
 		newConf.HLSDirectory != p.conf.HLSDirectory ||
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
+		newConf.WriteTimeout != p.conf.WriteTimeout ||
 		newConf.HLSMuxerCloseAfter != p.conf.HLSMuxerCloseAfter ||
+		newConf.HLSSessionTokens != p.conf.HLSSessionTokens ||
 		closePathManager ||
 		closeMetrics ||
 		closeLogger
 
+	closeDASHServer := newConf == nil ||
+		newConf.DASH != p.conf.DASH ||
+		newConf.DASHAddress != p.conf.DASHAddress ||
+		newConf.DASHEncryption != p.conf.DASHEncryption ||
+		newConf.DASHServerKey != p.conf.DASHServerKey ||
+		newConf.DASHServerCert != p.conf.DASHServerCert ||
+		newConf.DASHAllowOrigin != p.conf.DASHAllowOrigin ||
+		!reflect.DeepEqual(newConf.DASHTrustedProxies, p.conf.DASHTrustedProxies) ||
+		newConf.DASHSegmentCount != p.conf.DASHSegmentCount ||
+		newConf.DASHSegmentDuration != p.conf.DASHSegmentDuration ||
+		newConf.ReadTimeout != p.conf.ReadTimeout ||
+		newConf.WriteTimeout != p.conf.WriteTimeout ||
+		newConf.DASHMuxerCloseAfter != p.conf.DASHMuxerCloseAfter ||
+		closePathManager ||
+		closeLogger
+
+	closeMJPEGServer := newConf == nil ||
+		newConf.MJPEG != p.conf.MJPEG ||
+		newConf.MJPEGAddress != p.conf.MJPEGAddress ||
+		newConf.MJPEGEncryption != p.conf.MJPEGEncryption ||
+		newConf.MJPEGServerKey != p.conf.MJPEGServerKey ||
+		newConf.MJPEGServerCert != p.conf.MJPEGServerCert ||
+		newConf.MJPEGAllowOrigin != p.conf.MJPEGAllowOrigin ||
+		!reflect.DeepEqual(newConf.MJPEGTrustedProxies, p.conf.MJPEGTrustedProxies) ||
+		newConf.ReadTimeout != p.conf.ReadTimeout ||
+		newConf.WriteTimeout != p.conf.WriteTimeout ||
+		closePathManager ||
+		closeLogger
+
 	closeWebRTCServer := newConf == nil ||
 		newConf.WebRTC != p.conf.WebRTC ||
 		newConf.WebRTCAddress != p.conf.WebRTCAddress ||
@@ -824,6 +1095,8 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 	closeSRTServer := newConf == nil ||
 		newConf.SRT != p.conf.SRT ||
 		newConf.SRTAddress != p.conf.SRTAddress ||
+		newConf.SRTLatency != p.conf.SRTLatency ||
+		newConf.SRTOverheadBW != p.conf.SRTOverheadBW ||
 		newConf.RTSPAddress != p.conf.RTSPAddress ||
 		newConf.ReadTimeout != p.conf.ReadTimeout ||
 		newConf.WriteTimeout != p.conf.WriteTimeout ||
@@ -834,6 +1107,13 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		closePathManager ||
 		closeLogger
 
+	closeRTPPushServer := newConf == nil ||
+		newConf.RTPPush != p.conf.RTPPush ||
+		newConf.RTPPushAddress != p.conf.RTPPushAddress ||
+		newConf.ReadTimeout != p.conf.ReadTimeout ||
+		closePathManager ||
+		closeLogger
+
 	closeAPI := newConf == nil ||
 		newConf.API != p.conf.API ||
 		newConf.APIAddress != p.conf.APIAddress ||
@@ -851,6 +1131,20 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		closeHLSServer ||
 		closeWebRTCServer ||
 		closeSRTServer ||
+		closeRTPPushServer ||
+		closeLogger
+
+	closeONVIFDiscoverer := newConf == nil ||
+		newConf.ONVIFDiscovery != p.conf.ONVIFDiscovery ||
+		newConf.ONVIFDiscoveryInterval != p.conf.ONVIFDiscoveryInterval ||
+		newConf.ONVIFDiscoveryUser != p.conf.ONVIFDiscoveryUser ||
+		newConf.ONVIFDiscoveryPass != p.conf.ONVIFDiscoveryPass ||
+		newConf.ONVIFDiscoveryPathName != p.conf.ONVIFDiscoveryPathName ||
+		closeLogger
+
+	closeDDNSUpdater := newConf == nil ||
+		newConf.DDNSUpdateURL != p.conf.DDNSUpdateURL ||
+		newConf.DDNSUpdateInterval != p.conf.DDNSUpdateInterval ||
 		closeLogger
 
 	if newConf == nil && p.confWatcher != nil {
@@ -858,6 +1152,16 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		p.confWatcher = nil
 	}
 
+	if closeDDNSUpdater && p.ddnsUpdater != nil {
+		p.ddnsUpdater.Close()
+		p.ddnsUpdater = nil
+	}
+
+	if closeONVIFDiscoverer && p.onvifDiscoverer != nil {
+		p.onvifDiscoverer.Close()
+		p.onvifDiscoverer = nil
+	}
+
 	if p.api != nil {
 		if closeAPI {
 			p.api.Close()
@@ -867,6 +1171,11 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		}
 	}
 
+	if closeRTPPushServer && p.rtpPushServer != nil {
+		p.rtpPushServer.Close()
+		p.rtpPushServer = nil
+	}
+
 	if closeSRTServer && p.srtServer != nil {
 		if p.metrics != nil {
 			p.metrics.SetSRTServer(nil)
@@ -885,6 +1194,16 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		p.webRTCServer = nil
 	}
 
+	if closeMJPEGServer && p.mjpegServer != nil {
+		p.mjpegServer.Close()
+		p.mjpegServer = nil
+	}
+
+	if closeDASHServer && p.dashServer != nil {
+		p.dashServer.Close()
+		p.dashServer = nil
+	}
+
 	if closeHLSServer && p.hlsServer != nil {
 		if p.metrics != nil {
 			p.metrics.SetHLSServer(nil)
@@ -946,6 +1265,20 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		p.playbackServer = nil
 	}
 
+	if closeRecordVerifier && p.recordVerifier != nil {
+		if p.metrics != nil {
+			p.metrics.SetRecordVerifier(nil)
+		}
+
+		p.recordVerifier.Close()
+		p.recordVerifier = nil
+	}
+
+	if closeRecordConverter && p.recordConverter != nil {
+		p.recordConverter.Close()
+		p.recordConverter = nil
+	}
+
 	if closeRecorderCleaner && p.recordCleaner != nil {
 		p.recordCleaner.Close()
 		p.recordCleaner = nil
@@ -970,18 +1303,96 @@ func (p *Core) closeResources(newConf *conf.Conf, calledByAPI bool) {
 		p.externalCmdPool.Close()
 	}
 
+	if closeCrashReporter && p.crashReporter != nil {
+		p.crashReporter.Close()
+		p.crashReporter = nil
+	}
+
 	if closeLogger && p.logger != nil {
 		p.logger.Close()
 		p.logger = nil
 	}
 }
 
+// handlePanic writes a crash report when the main loop panics.
+func (p *Core) handlePanic() {
+	err := recover()
+	if err == nil {
+		return
+	}
+
+	if p.crashReporter == nil {
+		panic(err)
+	}
+
+	p.crashReporter.Report(err)
+	os.Exit(1)
+}
+
 func (p *Core) reloadConf(newConf *conf.Conf, calledByAPI bool) error {
 	p.closeResources(newConf, calledByAPI)
 	p.conf = newConf
 	return p.createResources(false)
 }
 
+// confWithONVIFPaths returns a copy of the configuration that contains a path
+// for each camera discovered with ONVIF, or nil when all cameras already have one.
+func (p *Core) confWithONVIFPaths(paths map[string]onvif.DiscoveredCamera) *conf.Conf {
+	newConf := p.conf.Clone()
+	var added []string
+
+outer:
+	for name, camera := range paths {
+		if _, ok := newConf.OptionalPaths[name]; ok {
+			continue
+		}
+
+		for _, pathConf := range p.conf.Paths {
+			if pathConf.Source == camera.Source {
+				continue outer
+			}
+		}
+
+		enc, _ := json.Marshal(map[string]string{
+			"source":         camera.Source,
+			"onvifDeviceURL": camera.DeviceURL,
+		})
+
+		var optional conf.OptionalPath
+		err := json.Unmarshal(enc, &optional)
+		if err != nil {
+			panic(err)
+		}
+
+		newConf.AddPath(name, &optional) //nolint:errcheck
+		added = append(added, name)
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	err := newConf.Validate(nil)
+	if err != nil {
+		p.Log(logger.Warn, "[ONVIF] unable to add discovered paths: %v", err)
+		return nil
+	}
+
+	sort.Strings(added)
+	p.Log(logger.Info, "[ONVIF] adding paths: %s", strings.Join(added, ", "))
+
+	return newConf
+}
+
+// ONVIFAddPaths is called by onvif.Discoverer.
+func (p *Core) ONVIFAddPaths(ctx context.Context, paths map[string]onvif.DiscoveredCamera) {
+	select {
+	case p.chONVIFAddPaths <- paths:
+	case <-ctx.Done():
+	case <-p.ctx.Done():
+	}
+}
+
 // APIConfigSet is called by api.
 func (p *Core) APIConfigSet(conf *conf.Conf) {
 	select {
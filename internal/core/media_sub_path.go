@@ -0,0 +1,207 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+const mediaSubPathSourcePrefix = "mediaSubPath://"
+
+var mediaSubPathTypes = map[string]description.MediaType{
+	"audio": description.MediaTypeAudio,
+	"video": description.MediaTypeVideo,
+}
+
+// mediaSubPathConf derives the configuration of a sub-path that provides
+// the medias of a given type of the parent path.
+func mediaSubPathConf(pathConf *conf.Path, parentName string, typ string) *conf.Path {
+	subConf := pathConf.Clone()
+	subConf.Source = mediaSubPathSourcePrefix + typ + "/" + parentName
+	subConf.SourceOnDemand = true
+	subConf.SourceFallbacks = []string{}
+	subConf.SourceSchedule = []conf.ReadScheduleWindow{}
+	subConf.QualitySources = []conf.QualitySource{}
+	subConf.BackupPublisher = false
+	subConf.Aliases = []string{}
+	subConf.MediaSubPaths = false
+	subConf.Record = false
+	subConf.ForwardTo = []string{}
+	subConf.MulticastOutput = ""
+	subConf.RunOnInit = ""
+	subConf.RunOnDemand = ""
+	subConf.RunOnReady = ""
+	subConf.RunOnNotReady = ""
+	return subConf
+}
+
+// findPathConfOrMediaSubPath finds the configuration of a path.
+// If the name is in the format "parent/audio" or "parent/video"
+// and the parent path has mediaSubPaths enabled, a derived configuration is returned.
+func findPathConfOrMediaSubPath(
+	pathConfs map[string]*conf.Path,
+	name string,
+) (*conf.Path, []string, error) {
+	if i := strings.LastIndex(name, "/"); i > 0 {
+		parentName, typ := name[:i], name[i+1:]
+
+		if _, ok := mediaSubPathTypes[typ]; ok {
+			parentConf, parentMatches, err := conf.FindPathConf(pathConfs, parentName)
+			if err == nil && parentConf.MediaSubPaths {
+				return mediaSubPathConf(parentConf, parentName, typ), parentMatches, nil
+			}
+		}
+	}
+
+	return conf.FindPathConf(pathConfs, name)
+}
+
+type mediaSubPathSourcePathManager interface {
+	AddReader(req defs.PathAddReaderReq) (defs.Path, *stream.Stream, error)
+}
+
+// mediaSubPathSource is a static source that reads the parent path
+// and provides the medias of a given type only.
+type mediaSubPathSource struct {
+	pathManager mediaSubPathSourcePathManager
+	parent      defs.StaticSourceParent
+
+	mutex   sync.Mutex
+	chClose chan struct{}
+}
+
+// Log implements logger.Writer.
+func (s *mediaSubPathSource) Log(level logger.Level, format string, args ...interface{}) {
+	s.parent.Log(level, "[media sub-path source] "+format, args...)
+}
+
+// Close implements defs.Reader.
+// It is called by the parent path when it is closing.
+func (s *mediaSubPathSource) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	select {
+	case <-s.chClose:
+	default:
+		close(s.chClose)
+	}
+}
+
+// Run implements defs.StaticSource.
+func (s *mediaSubPathSource) Run(params defs.StaticSourceRunParams) error {
+	tmp := strings.TrimPrefix(params.ResolvedSource, mediaSubPathSourcePrefix)
+	i := strings.Index(tmp, "/")
+	typ, parentName := tmp[:i], tmp[i+1:]
+	mediaType := mediaSubPathTypes[typ]
+
+	chClose := make(chan struct{})
+	s.mutex.Lock()
+	s.chClose = chClose
+	s.mutex.Unlock()
+
+	path, parentStrm, err := s.pathManager.AddReader(defs.PathAddReaderReq{
+		Author: s,
+		AccessRequest: defs.PathAccessRequest{
+			Name:     parentName,
+			SkipAuth: true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	defer path.RemoveReader(defs.PathRemoveReaderReq{Author: s})
+
+	var medias []*description.Media
+	for _, medi := range parentStrm.Desc().Medias {
+		if medi.Type == mediaType {
+			medias = append(medias, medi)
+		}
+	}
+
+	if len(medias) == 0 {
+		return fmt.Errorf("path '%s' does not contain any %s media", parentName, typ)
+	}
+
+	res := s.parent.SetReady(defs.PathSourceStaticSetReadyReq{
+		Desc:               &description.Session{Medias: medias},
+		GenerateRTPPackets: false,
+	})
+	if res.Err != nil {
+		return res.Err
+	}
+
+	defer s.parent.SetNotReady(defs.PathSourceStaticSetNotReadyReq{})
+
+	for _, medi := range medias {
+		for _, forma := range medi.Formats {
+			cmedi := medi
+			cforma := forma
+
+			// units are shared with the other readers of the parent path,
+			// therefore they are routed as RTP packets and decoded again
+			// by the stream of the sub-path.
+			parentStrm.AddReader(s, cmedi, cforma, func(u unit.Unit) error {
+				s.writeUnit(res.Stream, cmedi, cforma, u)
+				return nil
+			})
+		}
+	}
+
+	parentStrm.StartReader(s)
+	defer parentStrm.RemoveReader(s)
+
+	for {
+		select {
+		case err := <-parentStrm.ReaderError(s):
+			return err
+
+		case <-params.ReloadConf:
+
+		case <-chClose:
+			return fmt.Errorf("parent path has been closed")
+
+		case <-params.Context.Done():
+			return fmt.Errorf("terminated")
+		}
+	}
+}
+
+func (*mediaSubPathSource) writeUnit(
+	strm *stream.Stream,
+	medi *description.Media,
+	forma format.Format,
+	u unit.Unit,
+) {
+	for _, pkt := range u.GetRTPPackets() {
+		// packets may be modified by the stream; copy them.
+		cpkt := *pkt
+		strm.WriteRTPPacket(medi, forma, &cpkt, u.GetNTP(), u.GetPTS())
+	}
+}
+
+// APISourceDescribe implements defs.StaticSource.
+func (*mediaSubPathSource) APISourceDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "mediaSubPathSource",
+		ID:   "",
+	}
+}
+
+// APIReaderDescribe implements defs.Reader.
+func (*mediaSubPathSource) APIReaderDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "mediaSubPath",
+		ID:   "",
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -42,6 +43,26 @@ func httpPullFile(t *testing.T, hc *http.Client, u string) []byte {
 	return byts
 }
 
+func metricsTypes(bo []byte) string {
+	out := ""
+	for _, line := range strings.Split(string(bo), "\n") {
+		if strings.HasPrefix(line, "# TYPE ") {
+			out += line + "\n"
+		}
+	}
+	return out
+}
+
+func metricsSamples(bo []byte) string {
+	out := ""
+	for _, line := range strings.Split(string(bo), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			out += line + "\n"
+		}
+	}
+	return out
+}
+
 func TestMetrics(t *testing.T) {
 	serverCertFpath, err := test.CreateTempFile(test.TLSCertPub)
 	require.NoError(t, err)
@@ -74,99 +95,57 @@ func TestMetrics(t *testing.T) {
 	t.Run("initial", func(t *testing.T) {
 		bo := httpPullFile(t, hc, "http://localhost:9998/metrics")
 
-		require.Equal(t, `paths 0
-hls_muxers 0
-hls_muxers_bytes_sent 0
-rtsp_conns 0
-rtsp_conns_bytes_received 0
-rtsp_conns_bytes_sent 0
-rtsp_sessions 0
-rtsp_sessions_bytes_received 0
-rtsp_sessions_bytes_sent 0
-rtsp_sessions_rtp_packets_received 0
-rtsp_sessions_rtp_packets_sent 0
-rtsp_sessions_rtp_packets_lost 0
-rtsp_sessions_rtp_packets_in_error 0
-rtsp_sessions_rtp_packets_jitter 0
-rtsp_sessions_rtcp_packets_received 0
-rtsp_sessions_rtcp_packets_sent 0
-rtsp_sessions_rtcp_packets_in_error 0
-rtsps_conns 0
-rtsps_conns_bytes_received 0
-rtsps_conns_bytes_sent 0
-rtsps_sessions 0
-rtsps_sessions_bytes_received 0
-rtsps_sessions_bytes_sent 0
-rtsps_sessions_rtp_packets_received 0
-rtsps_sessions_rtp_packets_sent 0
-rtsps_sessions_rtp_packets_lost 0
-rtsps_sessions_rtp_packets_in_error 0
-rtsps_sessions_rtp_packets_jitter 0
-rtsps_sessions_rtcp_packets_received 0
-rtsps_sessions_rtcp_packets_sent 0
-rtsps_sessions_rtcp_packets_in_error 0
-rtmp_conns 0
-rtmp_conns_bytes_received 0
-rtmp_conns_bytes_sent 0
-rtmps_conns 0
-rtmps_conns_bytes_received 0
-rtmps_conns_bytes_sent 0
-srt_conns 0
-srt_conns_packets_sent 0
-srt_conns_packets_received 0
-srt_conns_packets_sent_unique 0
-srt_conns_packets_received_unique 0
-srt_conns_packets_send_loss 0
-srt_conns_packets_received_loss 0
-srt_conns_packets_retrans 0
-srt_conns_packets_received_retrans 0
-srt_conns_packets_sent_ack 0
-srt_conns_packets_received_ack 0
-srt_conns_packets_sent_nak 0
-srt_conns_packets_received_nak 0
-srt_conns_packets_sent_km 0
-srt_conns_packets_received_km 0
-srt_conns_us_snd_duration 0
-srt_conns_packets_send_drop 0
-srt_conns_packets_received_drop 0
-srt_conns_packets_received_undecrypt 0
-srt_conns_bytes_sent 0
-srt_conns_bytes_received 0
-srt_conns_bytes_sent_unique 0
-srt_conns_bytes_received_unique 0
-srt_conns_bytes_received_loss 0
-srt_conns_bytes_retrans 0
-srt_conns_bytes_received_retrans 0
-srt_conns_bytes_send_drop 0
-srt_conns_bytes_received_drop 0
-srt_conns_bytes_received_undecrypt 0
-srt_conns_us_packets_send_period 0
-srt_conns_packets_flow_window 0
-srt_conns_packets_flight_size 0
-srt_conns_ms_rtt 0
-srt_conns_mbps_send_rate 0
-srt_conns_mbps_receive_rate 0
-srt_conns_mbps_link_capacity 0
-srt_conns_bytes_avail_send_buf 0
-srt_conns_bytes_avail_receive_buf 0
-srt_conns_mbps_max_bw 0
-srt_conns_bytes_mss 0
-srt_conns_packets_send_buf 0
-srt_conns_bytes_send_buf 0
-srt_conns_ms_send_buf 0
-srt_conns_ms_send_tsb_pd_delay 0
-srt_conns_packets_receive_buf 0
-srt_conns_bytes_receive_buf 0
-srt_conns_ms_receive_buf 0
-srt_conns_ms_receive_tsb_pd_delay 0
-srt_conns_packets_reorder_tolerance 0
-srt_conns_packets_received_avg_belated_time 0
-srt_conns_packets_send_loss_rate 0
-srt_conns_packets_received_loss_rate 0
-webrtc_sessions 0
-webrtc_sessions_bytes_received 0
-webrtc_sessions_bytes_sent 0
-`, string(bo))
+		require.Equal(t, ""+
+			"# TYPE paths gauge\n"+
+			"# TYPE paths_readers gauge\n"+
+			"# TYPE paths_bytes_total counter\n"+
+			"# TYPE hls_muxers gauge\n"+
+			"# TYPE hls_muxers_sessions gauge\n"+
+			"# TYPE hls_muxers_bytes_total counter\n"+
+			"# TYPE sessions gauge\n"+
+			"# TYPE sessions_bytes_total counter\n"+
+			"# TYPE rtsp_conns gauge\n"+
+			"# TYPE rtsp_conns_bytes_total counter\n"+
+			"# TYPE rtsp_sessions_rtp_packets_total counter\n"+
+			"# TYPE rtsp_sessions_rtp_packets_lost_total counter\n"+
+			"# TYPE rtsp_sessions_rtp_packets_in_error_total counter\n"+
+			"# TYPE rtsp_sessions_rtp_packets_jitter gauge\n"+
+			"# TYPE rtsp_sessions_rtcp_packets_total counter\n"+
+			"# TYPE rtsp_sessions_rtcp_packets_in_error_total counter\n"+
+			"# TYPE srt_conns_packets_total counter\n"+
+			"# TYPE srt_conns_packets_unique_total counter\n"+
+			"# TYPE srt_conns_packets_loss_total counter\n"+
+			"# TYPE srt_conns_packets_retrans_total counter\n"+
+			"# TYPE srt_conns_packets_ack_total counter\n"+
+			"# TYPE srt_conns_packets_nak_total counter\n"+
+			"# TYPE srt_conns_packets_km_total counter\n"+
+			"# TYPE srt_conns_packets_drop_total counter\n"+
+			"# TYPE srt_conns_packets_received_undecrypt_total counter\n"+
+			"# TYPE srt_conns_us_snd_duration_total counter\n"+
+			"# TYPE srt_conns_bytes_total counter\n"+
+			"# TYPE srt_conns_bytes_unique_total counter\n"+
+			"# TYPE srt_conns_bytes_received_loss_total counter\n"+
+			"# TYPE srt_conns_bytes_retrans_total counter\n"+
+			"# TYPE srt_conns_bytes_drop_total counter\n"+
+			"# TYPE srt_conns_bytes_received_undecrypt_total counter\n"+
+			"# TYPE srt_conns_us_packets_send_period gauge\n"+
+			"# TYPE srt_conns_packets_flow_window gauge\n"+
+			"# TYPE srt_conns_packets_flight_size gauge\n"+
+			"# TYPE srt_conns_ms_rtt gauge\n"+
+			"# TYPE srt_conns_mbps_rate gauge\n"+
+			"# TYPE srt_conns_mbps_link_capacity gauge\n"+
+			"# TYPE srt_conns_bytes_avail_buf gauge\n"+
+			"# TYPE srt_conns_mbps_max_bw gauge\n"+
+			"# TYPE srt_conns_bytes_mss gauge\n"+
+			"# TYPE srt_conns_packets_buf gauge\n"+
+			"# TYPE srt_conns_bytes_buf gauge\n"+
+			"# TYPE srt_conns_ms_buf gauge\n"+
+			"# TYPE srt_conns_ms_tsb_pd_delay gauge\n"+
+			"# TYPE srt_conns_packets_reorder_tolerance gauge\n"+
+			"# TYPE srt_conns_packets_received_avg_belated_time gauge\n"+
+			"# TYPE srt_conns_packets_loss_rate gauge\n"+
+			"# TYPE record_corrupted_segments gauge\n", metricsTypes(bo))
+		require.Equal(t, "", metricsSamples(bo))
 	})
 
 	t.Run("with data", func(t *testing.T) {
@@ -318,127 +297,158 @@ webrtc_sessions_bytes_sent 0
 		bo := httpPullFile(t, hc, "http://localhost:9998/metrics")
 
 		require.Regexp(t,
-			`^paths\{name=".*?",state="ready"\} 1`+"\n"+
-				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths\{name=".*?",state="ready"\} 1`+"\n"+
-				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths\{name=".*?",state="ready"\} 1`+"\n"+
-				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths\{name=".*?",state="ready"\} 1`+"\n"+
-				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths\{name=".*?",state="ready"\} 1`+"\n"+
-				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths\{name=".*?",state="ready"\} 1`+"\n"+
-				`paths_bytes_received\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`paths_bytes_sent\{name=".*?",state="ready"\} [0-9]+`+"\n"+
-				`hls_muxers\{name=".*?"\} 1`+"\n"+
-				`hls_muxers_bytes_sent\{name=".*?"\} 0`+"\n"+
-				`hls_muxers\{name=".*?"\} 1`+"\n"+
-				`hls_muxers_bytes_sent\{name=".*?"\} 0`+"\n"+
-				`hls_muxers\{name=".*?"\} 1`+"\n"+
-				`hls_muxers_bytes_sent\{name=".*?"\} 0`+"\n"+
-				`hls_muxers\{name=".*?"\} 1`+"\n"+
-				`hls_muxers_bytes_sent\{name=".*?"\} 0`+"\n"+
-				`hls_muxers\{name=".*?"\} 1`+"\n"+
-				`hls_muxers_bytes_sent\{name=".*?"\} 0`+"\n"+
-				`hls_muxers\{name=".*?"\} 1`+"\n"+
-				`hls_muxers_bytes_sent\{name=".*?"\} 0`+"\n"+
-				`rtsp_conns\{id=".*?"\} 1`+"\n"+
-				`rtsp_conns_bytes_received\{id=".*?"\} [0-9]+`+"\n"+
-				`rtsp_conns_bytes_sent\{id=".*?"\} [0-9]+`+"\n"+
-				`rtsp_sessions\{id=".*?",state="publish"\} 1`+"\n"+
-				`rtsp_sessions_bytes_received\{id=".*?",state="publish"\} 0`+"\n"+
-				`rtsp_sessions_bytes_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsp_sessions_rtp_packets_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsp_sessions_rtp_packets_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsp_sessions_rtp_packets_lost\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsp_sessions_rtp_packets_in_error\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsp_sessions_rtp_packets_jitter\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsp_sessions_rtcp_packets_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsp_sessions_rtcp_packets_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsp_sessions_rtcp_packets_in_error\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_conns\{id=".*?"\} 1`+"\n"+
-				`rtsps_conns_bytes_received\{id=".*?"\} [0-9]+`+"\n"+
-				`rtsps_conns_bytes_sent\{id=".*?"\} [0-9]+`+"\n"+
-				`rtsps_sessions\{id=".*?",state="publish"\} 1`+"\n"+
-				`rtsps_sessions_bytes_received\{id=".*?",state="publish"\} 0`+"\n"+
-				`rtsps_sessions_bytes_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_sessions_rtp_packets_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_sessions_rtp_packets_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_sessions_rtp_packets_lost\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_sessions_rtp_packets_in_error\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_sessions_rtp_packets_jitter\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_sessions_rtcp_packets_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_sessions_rtcp_packets_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtsps_sessions_rtcp_packets_in_error\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtmp_conns\{id=".*?",state="publish"\} 1`+"\n"+
-				`rtmp_conns_bytes_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtmp_conns_bytes_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtmps_conns\{id=".*?",state="publish"\} 1`+"\n"+
-				`rtmps_conns_bytes_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`rtmps_conns_bytes_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns\{id=".*?",state="publish"\} 1`+"\n"+
-				`srt_conns_packets_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_sent_unique\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_unique\{id=".*?",state="publish"\} 1`+"\n"+
-				`srt_conns_packets_send_loss\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_loss\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_retrans\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_retrans\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_sent_ack\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_ack\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_sent_nak\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_nak\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_sent_km\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_km\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_us_snd_duration\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_send_drop\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_drop\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_undecrypt\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_sent\{id=".*?",state="publish"\} 0`+"\n"+
-				`srt_conns_bytes_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_sent_unique\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_received_unique\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_received_loss\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_retrans\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_received_retrans\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_send_drop\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_received_drop\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_received_undecrypt\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_us_packets_send_period\{id=".*?",state="publish"\} \d+\.\d+`+"\n"+
-				`srt_conns_packets_flow_window\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_flight_size\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_ms_rtt\{id=".*?",state="publish"\} \d+\.\d+`+"\n"+
-				`srt_conns_mbps_send_rate\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_mbps_receive_rate\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_mbps_link_capacity\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_avail_send_buf\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_avail_receive_buf\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_mbps_max_bw\{id=".*?",state="publish"\} -1`+"\n"+
-				`srt_conns_bytes_mss\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_send_buf\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_send_buf\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_ms_send_buf\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_ms_send_tsb_pd_delay\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_receive_buf\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_bytes_receive_buf\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_ms_receive_buf\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_ms_receive_tsb_pd_delay\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_reorder_tolerance\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_avg_belated_time\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_send_loss_rate\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`srt_conns_packets_received_loss_rate\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`webrtc_sessions\{id=".*?",state="publish"\} 1`+"\n"+
-				`webrtc_sessions_bytes_received\{id=".*?",state="publish"\} [0-9]+`+"\n"+
-				`webrtc_sessions_bytes_sent\{id=".*?",state="publish"\} [0-9]+`+"\n"+
+			`^paths\{path=".*?",state="ready"\} 1`+"\n"+
+				`paths\{path=".*?",state="ready"\} 1`+"\n"+
+				`paths\{path=".*?",state="ready"\} 1`+"\n"+
+				`paths\{path=".*?",state="ready"\} 1`+"\n"+
+				`paths\{path=".*?",state="ready"\} 1`+"\n"+
+				`paths\{path=".*?",state="ready"\} 1`+"\n"+
+				`paths_readers\{path=".*?"\} [0-9]+`+"\n"+
+				`paths_readers\{path=".*?"\} [0-9]+`+"\n"+
+				`paths_readers\{path=".*?"\} [0-9]+`+"\n"+
+				`paths_readers\{path=".*?"\} [0-9]+`+"\n"+
+				`paths_readers\{path=".*?"\} [0-9]+`+"\n"+
+				`paths_readers\{path=".*?"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="received"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="sent"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="received"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="sent"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="received"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="sent"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="received"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="sent"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="received"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="sent"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="received"\} [0-9]+`+"\n"+
+				`paths_bytes_total\{path=".*?",direction="sent"\} [0-9]+`+"\n"+
+				`hls_muxers\{path=".*?"\} 1`+"\n"+
+				`hls_muxers\{path=".*?"\} 1`+"\n"+
+				`hls_muxers\{path=".*?"\} 1`+"\n"+
+				`hls_muxers\{path=".*?"\} 1`+"\n"+
+				`hls_muxers\{path=".*?"\} 1`+"\n"+
+				`hls_muxers\{path=".*?"\} 1`+"\n"+
+				`hls_muxers_sessions\{path=".*?"\} 0`+"\n"+
+				`hls_muxers_sessions\{path=".*?"\} 0`+"\n"+
+				`hls_muxers_sessions\{path=".*?"\} 0`+"\n"+
+				`hls_muxers_sessions\{path=".*?"\} 0`+"\n"+
+				`hls_muxers_sessions\{path=".*?"\} 0`+"\n"+
+				`hls_muxers_sessions\{path=".*?"\} 0`+"\n"+
+				`hls_muxers_bytes_total\{path=".*?",direction="sent"\} 0`+"\n"+
+				`hls_muxers_bytes_total\{path=".*?",direction="sent"\} 0`+"\n"+
+				`hls_muxers_bytes_total\{path=".*?",direction="sent"\} 0`+"\n"+
+				`hls_muxers_bytes_total\{path=".*?",direction="sent"\} 0`+"\n"+
+				`hls_muxers_bytes_total\{path=".*?",direction="sent"\} 0`+"\n"+
+				`hls_muxers_bytes_total\{path=".*?",direction="sent"\} 0`+"\n"+
+				`sessions\{protocol="rtsp",id=".*?",path="rtsp_path",state="publish"\} 1`+"\n"+
+				`sessions\{protocol="rtsps",id=".*?",path="rtsps_path",state="publish"\} 1`+"\n"+
+				`sessions\{protocol="rtmp",id=".*?",path="rtmp_path",state="publish"\} 1`+"\n"+
+				`sessions\{protocol="rtmps",id=".*?",path="rtmps_path",state="publish"\} 1`+"\n"+
+				`sessions\{protocol="srt",id=".*?",path="srt_path",state="publish"\} 1`+"\n"+
+				`sessions\{protocol="webrtc",id=".*?",path="webrtc_path",state="publish"\} 1`+"\n"+
+				`sessions_bytes_total\{protocol="rtsp",id=".*?",path="rtsp_path",direction="received"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="rtsp",id=".*?",path="rtsp_path",direction="sent"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="rtsps",id=".*?",path="rtsps_path",direction="received"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="rtsps",id=".*?",path="rtsps_path",direction="sent"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="rtmp",id=".*?",path="rtmp_path",direction="received"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="rtmp",id=".*?",path="rtmp_path",direction="sent"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="rtmps",id=".*?",path="rtmps_path",direction="received"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="rtmps",id=".*?",path="rtmps_path",direction="sent"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="srt",id=".*?",path="srt_path",direction="received"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="srt",id=".*?",path="srt_path",direction="sent"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="webrtc",id=".*?",path="webrtc_path",direction="received"\} [0-9]+`+"\n"+
+				`sessions_bytes_total\{protocol="webrtc",id=".*?",path="webrtc_path",direction="sent"\} [0-9]+`+"\n"+
+				`rtsp_conns\{protocol="rtsp",id=".*?"\} 1`+"\n"+
+				`rtsp_conns\{protocol="rtsps",id=".*?"\} 1`+"\n"+
+				`rtsp_conns_bytes_total\{protocol="rtsp",id=".*?",direction="received"\} [0-9]+`+"\n"+
+				`rtsp_conns_bytes_total\{protocol="rtsp",id=".*?",direction="sent"\} [0-9]+`+"\n"+
+				`rtsp_conns_bytes_total\{protocol="rtsps",id=".*?",direction="received"\} [0-9]+`+"\n"+
+				`rtsp_conns_bytes_total\{protocol="rtsps",id=".*?",direction="sent"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_total\{protocol="rtsp",id=".*?",path="rtsp_path",direction="received"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_total\{protocol="rtsp",id=".*?",path="rtsp_path",direction="sent"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_total\{protocol="rtsps",id=".*?",path="rtsps_path",direction="received"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_total\{protocol="rtsps",id=".*?",path="rtsps_path",direction="sent"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_lost_total\{protocol="rtsp",id=".*?",path="rtsp_path"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_lost_total\{protocol="rtsps",id=".*?",path="rtsps_path"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_in_error_total\{protocol="rtsp",id=".*?",path="rtsp_path"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_in_error_total\{protocol="rtsps",id=".*?",path="rtsps_path"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_jitter\{protocol="rtsp",id=".*?",path="rtsp_path"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtp_packets_jitter\{protocol="rtsps",id=".*?",path="rtsps_path"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtcp_packets_total\{protocol="rtsp",id=".*?",path="rtsp_path",direction="received"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtcp_packets_total\{protocol="rtsp",id=".*?",path="rtsp_path",direction="sent"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtcp_packets_total\{protocol="rtsps",id=".*?",path="rtsps_path",direction="received"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtcp_packets_total\{protocol="rtsps",id=".*?",path="rtsps_path",direction="sent"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtcp_packets_in_error_total\{protocol="rtsp",id=".*?",path="rtsp_path"\} [0-9]+`+"\n"+
+				`rtsp_sessions_rtcp_packets_in_error_total\{protocol="rtsps",id=".*?",path="rtsps_path"\} [0-9]+`+"\n"+
+				`srt_conns_packets_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_unique_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_unique_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_loss_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_loss_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_retrans_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_retrans_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_ack_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_ack_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_nak_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_nak_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_km_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_km_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_drop_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_drop_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_received_undecrypt_total\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_us_snd_duration_total\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_unique_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_unique_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_received_loss_total\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_retrans_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_retrans_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_drop_total\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_drop_total\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_received_undecrypt_total\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_us_packets_send_period\{id=".*?",path="srt_path"\} \d+\.\d+`+"\n"+
+				`srt_conns_packets_flow_window\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_flight_size\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_ms_rtt\{id=".*?",path="srt_path"\} \d+\.\d+`+"\n"+
+				`srt_conns_mbps_rate\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_mbps_rate\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_mbps_link_capacity\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_avail_buf\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_avail_buf\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_mbps_max_bw\{id=".*?",path="srt_path"\} -1`+"\n"+
+				`srt_conns_bytes_mss\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_buf\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_buf\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_buf\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_bytes_buf\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_ms_buf\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_ms_buf\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_ms_tsb_pd_delay\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_ms_tsb_pd_delay\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_reorder_tolerance\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_received_avg_belated_time\{id=".*?",path="srt_path"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_loss_rate\{id=".*?",path="srt_path",direction="received"\} [0-9.]+`+"\n"+
+				`srt_conns_packets_loss_rate\{id=".*?",path="srt_path",direction="sent"\} [0-9.]+`+"\n"+
 				"$",
-			string(bo))
+			metricsSamples(bo))
+
+		req, err := http.NewRequest(http.MethodGet, "http://localhost:9998/metrics", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0,text/plain;version=0.0.4;q=0.5")
+
+		res, err := hc.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, "application/openmetrics-text; version=1.0.0; charset=utf-8", res.Header.Get("Content-Type"))
+
+		bo, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		require.Regexp(t, `(?m)^# TYPE paths_bytes counter$`, string(bo))
+		require.Regexp(t, `(?m)^paths_bytes_total\{path="rtsp_path",direction="received"\} [0-9]+$`, string(bo))
+		require.True(t, strings.HasSuffix(string(bo), "\n# EOF\n"))
 
 		close(terminate)
 		wg.Wait()
@@ -457,6 +467,61 @@ webrtc_sessions_bytes_sent 0
 
 		bo := httpPullFile(t, hc, "http://localhost:9998/metrics")
 
-		require.Equal(t, "paths 0\n", string(bo))
+		require.Equal(t, "# TYPE paths gauge\n"+
+			"# TYPE paths_readers gauge\n"+
+			"# TYPE paths_bytes_total counter\n"+
+			"# TYPE sessions gauge\n"+
+			"# TYPE sessions_bytes_total counter\n"+
+			"# TYPE record_corrupted_segments gauge\n", metricsTypes(bo))
+		require.Equal(t, "", metricsSamples(bo))
 	})
 }
+
+func TestMetricsLegacyNames(t *testing.T) {
+	p, ok := newInstance("metrics: yes\n" +
+		"metricsLegacyNames: yes\n" +
+		"rtmp: no\n" +
+		"hls: no\n" +
+		"webrtc: no\n" +
+		"srt: no\n" +
+		"paths:\n" +
+		"  all_others:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	source := gortsplib.Client{}
+	err := source.StartRecording("rtsp://localhost:8554/rtsp_path",
+		&description.Session{Medias: []*description.Media{test.UniqueMediaH264()}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	time.Sleep(500 * time.Millisecond)
+
+	bo := httpPullFile(t, hc, "http://localhost:9998/metrics")
+
+	require.Equal(t, "", metricsTypes(bo))
+	require.Regexp(t,
+		`^paths\{name="rtsp_path",state="ready"\} 1`+"\n"+
+			`paths_bytes_received\{name="rtsp_path",state="ready"\} 0`+"\n"+
+			`paths_bytes_sent\{name="rtsp_path",state="ready"\} 0`+"\n"+
+			`rtsp_conns\{id="[0-9a-f\-]+"\} 1`+"\n"+
+			`rtsp_conns_bytes_received\{id="[0-9a-f\-]+"\} [0-9]+`+"\n"+
+			`rtsp_conns_bytes_sent\{id="[0-9a-f\-]+"\} [0-9]+`+"\n"+
+			`rtsp_sessions\{id="[0-9a-f\-]+",state="publish"\} 1`+"\n"+
+			`rtsp_sessions_bytes_received\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`rtsp_sessions_bytes_sent\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`rtsp_sessions_rtp_packets_received\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`rtsp_sessions_rtp_packets_sent\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`rtsp_sessions_rtp_packets_lost\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`rtsp_sessions_rtp_packets_in_error\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`rtsp_sessions_rtp_packets_jitter\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`rtsp_sessions_rtcp_packets_received\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`rtsp_sessions_rtcp_packets_sent\{id="[0-9a-f\-]+",state="publish"\} [0-9]+`+"\n"+
+			`rtsp_sessions_rtcp_packets_in_error\{id="[0-9a-f\-]+",state="publish"\} 0`+"\n"+
+			`record_corrupted_segments 0`+"\n$",
+		string(bo))
+}
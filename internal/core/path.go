@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,8 +16,10 @@ import (
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/forwarder"
 	"github.com/bluenviron/mediamtx/internal/hooks"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/multicaster"
 	"github.com/bluenviron/mediamtx/internal/recorder"
 	"github.com/bluenviron/mediamtx/internal/stream"
 )
@@ -27,10 +30,15 @@ func emptyTimer() *time.Timer {
 	return t
 }
 
+// time given to readers to receive the end of stream before being closed.
+const pathEndOfStreamCloseDelay = 1 * time.Second
+
 type pathParent interface {
 	logger.Writer
+	AddReader(req defs.PathAddReaderReq) (defs.Path, *stream.Stream, error)
 	pathReady(*path)
 	pathNotReady(*path)
+	setCachedDescription(string, *description.Session)
 	closePath(*path)
 }
 
@@ -63,6 +71,31 @@ type pathAPIPathsGetReq struct {
 	res  chan pathAPIPathsGetRes
 }
 
+type pathAPIPathsDebugRes struct {
+	data *defs.APIPathDebug
+}
+
+type pathAPIPathsDebugReq struct {
+	res chan pathAPIPathsDebugRes
+}
+
+type pathAPIPathsMuteRes struct {
+	data *defs.APIPathMute
+}
+
+type pathAPIPathsMuteReq struct {
+	data *defs.APIPathMuteReq
+	res  chan pathAPIPathsMuteRes
+}
+
+type pathAPIPathsHealthRes struct {
+	data *defs.APIPathHealth
+}
+
+type pathAPIPathsHealthReq struct {
+	res chan pathAPIPathsHealthRes
+}
+
 type path struct {
 	parentCtx         context.Context
 	logLevel          conf.LogLevel
@@ -70,6 +103,7 @@ type path struct {
 	readTimeout       conf.Duration
 	writeTimeout      conf.Duration
 	writeQueueSize    int
+	readerIdleTimeout conf.Duration
 	udpMaxPayloadSize int
 	conf              *conf.Path
 	name              string
@@ -84,10 +118,14 @@ type path struct {
 	source                         defs.Source
 	publisherQuery                 string
 	stream                         *stream.Stream
+	cachedDesc                     *description.Session
 	recorder                       *recorder.Recorder
+	multicaster                    *multicaster.Multicaster
+	forwarders                     []*forwarder.Forwarder
 	readyTime                      time.Time
 	onUnDemandHook                 func(string)
-	onNotReadyHook                 func()
+	onNotReadyHook                 func(bool)
+	endOfStream                    bool
 	readers                        map[defs.Reader]struct{}
 	describeRequestsOnHold         []defs.PathDescribeReq
 	readerAddRequestsOnHold        []defs.PathAddReaderReq
@@ -97,6 +135,16 @@ type path struct {
 	onDemandPublisherState         pathOnDemandState
 	onDemandPublisherReadyTimer    *time.Timer
 	onDemandPublisherCloseTimer    *time.Timer
+	onDemandPublisherRun           int
+	readScheduleTimer              *time.Timer
+	backupPublisher                defs.Publisher
+	backupPublisherQuery           string
+	backupStream                   *stream.Stream
+	backupCheckTimer               *time.Timer
+	primaryBytesReceived           uint64
+	forwarder                      *publisherForwarder
+	audioMuted                     bool
+	videoMuted                     bool
 
 	// in
 	chReloadConf              chan *conf.Path
@@ -110,6 +158,10 @@ type path struct {
 	chAddReader               chan defs.PathAddReaderReq
 	chRemoveReader            chan defs.PathRemoveReaderReq
 	chAPIPathsGet             chan pathAPIPathsGetReq
+	chAPIPathsDebug           chan pathAPIPathsDebugReq
+	chAPIPathsHealth          chan pathAPIPathsHealthReq
+	chAPIPathsMute            chan pathAPIPathsMuteReq
+	chOnDemandPublisherExit   chan int
 
 	// out
 	done chan struct{}
@@ -125,6 +177,8 @@ func (pa *path) initialize() {
 	pa.onDemandStaticSourceCloseTimer = emptyTimer()
 	pa.onDemandPublisherReadyTimer = emptyTimer()
 	pa.onDemandPublisherCloseTimer = emptyTimer()
+	pa.readScheduleTimer = emptyTimer()
+	pa.backupCheckTimer = emptyTimer()
 	pa.chReloadConf = make(chan *conf.Path)
 	pa.chStaticSourceSetReady = make(chan defs.PathSourceStaticSetReadyReq)
 	pa.chStaticSourceSetNotReady = make(chan defs.PathSourceStaticSetNotReadyReq)
@@ -136,6 +190,10 @@ func (pa *path) initialize() {
 	pa.chAddReader = make(chan defs.PathAddReaderReq)
 	pa.chRemoveReader = make(chan defs.PathRemoveReaderReq)
 	pa.chAPIPathsGet = make(chan pathAPIPathsGetReq)
+	pa.chAPIPathsDebug = make(chan pathAPIPathsDebugReq)
+	pa.chAPIPathsHealth = make(chan pathAPIPathsHealthReq)
+	pa.chAPIPathsMute = make(chan pathAPIPathsMuteReq)
+	pa.chOnDemandPublisherExit = make(chan int)
 	pa.done = make(chan struct{})
 
 	pa.Log(logger.Debug, "created")
@@ -169,18 +227,24 @@ func (pa *path) run() {
 		pa.source = &sourceRedirect{}
 	} else if pa.conf.HasStaticSource() {
 		pa.source = &staticSourceHandler{
-			conf:           pa.conf,
-			logLevel:       pa.logLevel,
-			readTimeout:    pa.readTimeout,
-			writeTimeout:   pa.writeTimeout,
-			writeQueueSize: pa.writeQueueSize,
-			matches:        pa.matches,
-			parent:         pa,
+			conf:            pa.conf,
+			logLevel:        pa.logLevel,
+			readTimeout:     pa.readTimeout,
+			writeTimeout:    pa.writeTimeout,
+			writeQueueSize:  pa.writeQueueSize,
+			matches:         pa.matches,
+			externalCmdPool: pa.externalCmdPool,
+			pathManager:     pa.parent,
+			parent:          pa,
 		}
 		pa.source.(*staticSourceHandler).initialize()
 
 		if !pa.conf.SourceOnDemand {
-			pa.source.(*staticSourceHandler).start(false, "")
+			if pa.conf.SourceAllowed(time.Now()) {
+				pa.source.(*staticSourceHandler).start(false, "")
+			} else {
+				pa.Log(logger.Info, "source schedule window is closed, source will be started when it opens")
+			}
 		}
 	}
 
@@ -191,6 +255,8 @@ func (pa *path) run() {
 		ExternalCmdEnv:  pa.ExternalCmdEnv(),
 	})
 
+	pa.readScheduleScheduleCheck()
+
 	err := pa.runInner()
 
 	// call before destroying context
@@ -202,6 +268,8 @@ func (pa *path) run() {
 	pa.onDemandStaticSourceCloseTimer.Stop()
 	pa.onDemandPublisherReadyTimer.Stop()
 	pa.onDemandPublisherCloseTimer.Stop()
+	pa.readScheduleTimer.Stop()
+	pa.backupCheckTimer.Stop()
 
 	onUnInitHook()
 
@@ -214,12 +282,17 @@ func (pa *path) run() {
 	}
 
 	if pa.stream != nil {
-		pa.setNotReady()
+		pa.setNotReady(false)
+	}
+
+	if pa.backupPublisher != nil {
+		pa.removeBackupPublisher()
 	}
 
 	if pa.source != nil {
 		if source, ok := pa.source.(*staticSourceHandler); ok {
-			if !pa.conf.SourceOnDemand || pa.onDemandStaticSourceState != pathOnDemandStateInitial {
+			if source.running &&
+				(!pa.conf.SourceOnDemand || pa.onDemandStaticSourceState != pathOnDemandStateInitial) {
 				source.close("path is closing")
 			}
 		} else if source, ok := pa.source.(defs.Publisher); ok {
@@ -261,6 +334,19 @@ func (pa *path) runInner() error {
 		case <-pa.onDemandPublisherCloseTimer.C:
 			pa.doOnDemandPublisherCloseTimer()
 
+		case <-pa.readScheduleTimer.C:
+			pa.doReadScheduleTimer()
+
+		case <-pa.backupCheckTimer.C:
+			pa.doBackupCheckTimer()
+
+		case run := <-pa.chOnDemandPublisherExit:
+			pa.doOnDemandPublisherExit(run)
+
+			if pa.shouldClose() {
+				return fmt.Errorf("not in use")
+			}
+
 		case newConf := <-pa.chReloadConf:
 			pa.doReloadConf(newConf)
 
@@ -314,6 +400,15 @@ func (pa *path) runInner() error {
 		case req := <-pa.chAPIPathsGet:
 			pa.doAPIPathsGet(req)
 
+		case req := <-pa.chAPIPathsDebug:
+			pa.doAPIPathsDebug(req)
+
+		case req := <-pa.chAPIPathsHealth:
+			pa.doAPIPathsHealth(req)
+
+		case req := <-pa.chAPIPathsMute:
+			pa.doAPIPathsMute(req)
+
 		case <-pa.ctx.Done():
 			return fmt.Errorf("terminated")
 		}
@@ -335,7 +430,7 @@ func (pa *path) doOnDemandStaticSourceReadyTimer() {
 }
 
 func (pa *path) doOnDemandStaticSourceCloseTimer() {
-	pa.setNotReady()
+	pa.setNotReady(false)
 	pa.onDemandStaticSourceStop("not needed by anyone")
 }
 
@@ -357,7 +452,101 @@ func (pa *path) doOnDemandPublisherCloseTimer() {
 	pa.onDemandPublisherStop("not needed by anyone")
 }
 
+func (pa *path) doReadScheduleTimer() {
+	now := time.Now()
+
+	if !pa.conf.ReadAllowed(now) && len(pa.readers) != 0 {
+		pa.Log(logger.Info, "read schedule window is closed, closing readers")
+
+		for r := range pa.readers {
+			pa.executeRemoveReader(r)
+			r.Close()
+		}
+	}
+
+	pa.applySourceSchedule(now)
+	pa.applyRecordSchedule(now)
+
+	pa.readScheduleScheduleCheck()
+}
+
+// applySourceSchedule starts or stops the static source
+// depending on the source schedule.
+func (pa *path) applySourceSchedule(now time.Time) {
+	if !pa.conf.HasStaticSource() || pa.conf.SourceOnDemand {
+		return
+	}
+
+	source := pa.source.(*staticSourceHandler)
+	allowed := pa.conf.SourceAllowed(now)
+
+	switch {
+	case allowed && !source.running:
+		pa.Log(logger.Info, "source schedule window is open, starting source")
+		source.start(false, "")
+
+	case !allowed && source.running:
+		if pa.stream != nil {
+			pa.setNotReady(false)
+		}
+		source.stop("source schedule window is closed")
+	}
+}
+
+// applyRecordSchedule starts or stops the recorder
+// depending on the record schedule.
+func (pa *path) applyRecordSchedule(now time.Time) {
+	if !pa.conf.Record || len(pa.conf.RecordSchedule) == 0 {
+		return
+	}
+
+	allowed := pa.conf.RecordAllowed(now)
+
+	switch {
+	case allowed && pa.stream != nil && pa.recorder == nil:
+		pa.Log(logger.Info, "record schedule window is open, starting recording")
+		pa.startRecording()
+
+	case !allowed && pa.recorder != nil:
+		pa.Log(logger.Info, "record schedule window is closed, stopping recording")
+		pa.recorder.Close()
+		pa.recorder = nil
+	}
+}
+
+func (pa *path) doOnDemandPublisherExit(run int) {
+	// exit of a previous command, or command is going to be restarted
+	if run != pa.onDemandPublisherRun ||
+		pa.onDemandPublisherState != pathOnDemandStateWaitingReady ||
+		pa.conf.RunOnDemandRestart {
+		return
+	}
+
+	for _, req := range pa.describeRequestsOnHold {
+		req.Res <- defs.PathDescribeRes{Err: fmt.Errorf("runOnDemand command of path '%s' exited before publishing", pa.name)}
+	}
+	pa.describeRequestsOnHold = nil
+
+	for _, req := range pa.readerAddRequestsOnHold {
+		req.Res <- defs.PathAddReaderRes{Err: fmt.Errorf("runOnDemand command of path '%s' exited before publishing", pa.name)}
+	}
+	pa.readerAddRequestsOnHold = nil
+
+	pa.onDemandPublisherReadyTimer.Stop()
+	pa.onDemandPublisherReadyTimer = emptyTimer()
+
+	pa.onDemandPublisherStop("command exited")
+}
+
 func (pa *path) doReloadConf(newConf *conf.Path) {
+	multicastChanged := newConf.MulticastOutput != pa.conf.MulticastOutput ||
+		newConf.MulticastOutputFormat != pa.conf.MulticastOutputFormat ||
+		newConf.MulticastOutputTTL != pa.conf.MulticastOutputTTL ||
+		newConf.MulticastOutputInterface != pa.conf.MulticastOutputInterface ||
+		newConf.MulticastOutputSAP != pa.conf.MulticastOutputSAP
+
+	forwardToChanged := !slices.Equal(newConf.ForwardTo, pa.conf.ForwardTo)
+
 	pa.confMutex.Lock()
 	pa.conf = newConf
 	pa.confMutex.Unlock()
@@ -366,7 +555,7 @@ func (pa *path) doReloadConf(newConf *conf.Path) {
 		pa.source.(*staticSourceHandler).reloadConf(newConf)
 	}
 
-	if pa.conf.Record {
+	if pa.conf.Record && pa.conf.RecordAllowed(time.Now()) {
 		if pa.stream != nil && pa.recorder == nil {
 			pa.startRecording()
 		}
@@ -374,6 +563,31 @@ func (pa *path) doReloadConf(newConf *conf.Path) {
 		pa.recorder.Close()
 		pa.recorder = nil
 	}
+
+	pa.applySourceSchedule(time.Now())
+
+	pa.readScheduleTimer.Stop()
+	pa.readScheduleTimer = emptyTimer()
+	pa.readScheduleScheduleCheck()
+
+	if multicastChanged {
+		if pa.multicaster != nil {
+			pa.multicaster.Close()
+			pa.multicaster = nil
+		}
+
+		if pa.stream != nil && pa.conf.MulticastOutput != "" {
+			pa.startMulticasting()
+		}
+	}
+
+	if forwardToChanged {
+		pa.stopForwarding()
+
+		if pa.stream != nil {
+			pa.startForwarding()
+		}
+	}
 }
 
 func (pa *path) doSourceStaticSetReady(req defs.PathSourceStaticSetReadyReq) {
@@ -395,7 +609,7 @@ func (pa *path) doSourceStaticSetReady(req defs.PathSourceStaticSetReadyReq) {
 }
 
 func (pa *path) doSourceStaticSetNotReady(req defs.PathSourceStaticSetNotReadyReq) {
-	pa.setNotReady()
+	pa.setNotReady(req.Ended)
 
 	// send response before calling onDemandStaticSourceStop()
 	// in order to avoid a deadlock due to staticSourceHandler.stop()
@@ -406,6 +620,19 @@ func (pa *path) doSourceStaticSetNotReady(req defs.PathSourceStaticSetNotReadyRe
 	}
 }
 
+// describeFromCache answers a describe request with the cached description, if available.
+// The source is not started, since it is started by the reader that follows the request.
+func (pa *path) describeFromCache(req defs.PathDescribeReq) bool {
+	if !pa.conf.OnDemandCacheDescription || pa.cachedDesc == nil {
+		return false
+	}
+
+	req.Res <- defs.PathDescribeRes{
+		Desc: pa.cachedDesc,
+	}
+	return true
+}
+
 func (pa *path) doDescribe(req defs.PathDescribeReq) {
 	if _, ok := pa.source.(*sourceRedirect); ok {
 		req.Res <- defs.PathDescribeRes{
@@ -422,6 +649,9 @@ func (pa *path) doDescribe(req defs.PathDescribeReq) {
 	}
 
 	if pa.conf.HasOnDemandStaticSource() {
+		if pa.describeFromCache(req) {
+			return
+		}
 		if pa.onDemandStaticSourceState == pathOnDemandStateInitial {
 			pa.onDemandStaticSourceStart(req.AccessRequest.Query)
 		}
@@ -430,6 +660,9 @@ func (pa *path) doDescribe(req defs.PathDescribeReq) {
 	}
 
 	if pa.conf.HasOnDemandPublisher() {
+		if pa.describeFromCache(req) {
+			return
+		}
 		if pa.onDemandPublisherState == pathOnDemandStateInitial {
 			pa.onDemandPublisherStart(req.AccessRequest.Query)
 		}
@@ -457,9 +690,39 @@ func (pa *path) doDescribe(req defs.PathDescribeReq) {
 	req.Res <- defs.PathDescribeRes{Err: defs.PathNoOnePublishingError{PathName: pa.name}}
 }
 
+func (pa *path) doBackupCheckTimer() {
+	if pa.backupStream == nil {
+		return
+	}
+
+	if pa.stream != nil {
+		received := pa.stream.BytesReceived()
+
+		if received == pa.primaryBytesReceived {
+			pa.Log(logger.Warn, "primary publisher didn't send any data for %v, switching to backup publisher",
+				time.Duration(pa.conf.BackupPublisherTimeout))
+			pa.source.(defs.Publisher).Close()
+			pa.switchToBackupPublisher(false)
+			return
+		}
+
+		pa.primaryBytesReceived = received
+	}
+
+	pa.backupCheckTimer = time.NewTimer(time.Duration(pa.conf.BackupPublisherTimeout))
+}
+
 func (pa *path) doRemovePublisher(req defs.PathRemovePublisherReq) {
-	if pa.source == req.Author {
-		pa.executeRemovePublisher()
+	switch {
+	case pa.source == req.Author:
+		if pa.backupPublisher != nil {
+			pa.switchToBackupPublisher(req.Ended)
+		} else {
+			pa.executeRemovePublisher(req.Ended)
+		}
+
+	case pa.backupPublisher == req.Author:
+		pa.removeBackupPublisher()
 	}
 	close(req.Res)
 }
@@ -473,6 +736,13 @@ func (pa *path) doAddPublisher(req defs.PathAddPublisherReq) {
 	}
 
 	if pa.source != nil {
+		if pa.conf.BackupPublisher && pa.backupPublisher == nil {
+			pa.backupPublisher = req.Author
+			pa.backupPublisherQuery = req.AccessRequest.Query
+			req.Res <- defs.PathAddPublisherRes{Path: pa}
+			return
+		}
+
 		if !pa.conf.OverridePublisher {
 			req.Res <- defs.PathAddPublisherRes{Err: fmt.Errorf("someone is already publishing to path '%s'", pa.name)}
 			return
@@ -480,7 +750,7 @@ func (pa *path) doAddPublisher(req defs.PathAddPublisherReq) {
 
 		pa.Log(logger.Info, "closing existing publisher")
 		pa.source.(defs.Publisher).Close()
-		pa.executeRemovePublisher()
+		pa.executeRemovePublisher(false)
 	}
 
 	pa.source = req.Author
@@ -490,15 +760,43 @@ func (pa *path) doAddPublisher(req defs.PathAddPublisherReq) {
 }
 
 func (pa *path) doStartPublisher(req defs.PathStartPublisherReq) {
+	if pa.backupPublisher != nil && pa.backupPublisher == req.Author {
+		pa.startBackupPublisher(req)
+		return
+	}
+
 	if pa.source != req.Author {
 		req.Res <- defs.PathStartPublisherRes{Err: fmt.Errorf("publisher is not assigned to this path anymore")}
 		return
 	}
 
-	err := pa.setReady(req.Desc, req.GenerateRTPPackets)
-	if err != nil {
-		req.Res <- defs.PathStartPublisherRes{Err: err}
-		return
+	// the publisher replaced the primary publisher before starting:
+	// readers are still attached to the stream of the path.
+	if pa.stream != nil && !descsAreCompatible(pa.stream.Desc(), req.Desc) {
+		pa.setNotReady(false)
+	}
+
+	if pa.stream == nil {
+		// a backup publisher may write units without RTP packets into the stream
+		err := pa.setReady(req.Desc, req.GenerateRTPPackets || pa.conf.BackupPublisher)
+		if err != nil {
+			req.Res <- defs.PathStartPublisherRes{Err: err}
+			return
+		}
+	}
+
+	strm := pa.stream
+
+	// when a backup publisher is allowed, the stream of the publisher is forwarded into the one of the path,
+	// in order to switch publishers without disconnecting readers.
+	if pa.conf.BackupPublisher {
+		var err error
+		strm, err = pa.startForwardedPublisher(req)
+		if err != nil {
+			pa.setNotReady(false)
+			req.Res <- defs.PathStartPublisherRes{Err: err}
+			return
+		}
 	}
 
 	req.Author.Log(logger.Info, "is publishing to path '%s', %s",
@@ -513,12 +811,19 @@ func (pa *path) doStartPublisher(req defs.PathStartPublisherReq) {
 
 	pa.consumeOnHoldRequests()
 
-	req.Res <- defs.PathStartPublisherRes{Stream: pa.stream}
+	req.Res <- defs.PathStartPublisherRes{Stream: strm}
 }
 
 func (pa *path) doStopPublisher(req defs.PathStopPublisherReq) {
-	if req.Author == pa.source && pa.stream != nil {
-		pa.setNotReady()
+	switch {
+	case req.Author == pa.source && pa.stream != nil:
+		pa.setNotReady(false)
+
+	case pa.backupPublisher != nil && req.Author == pa.backupPublisher && pa.backupStream != nil:
+		pa.backupCheckTimer.Stop()
+		pa.backupCheckTimer = emptyTimer()
+		pa.backupStream.Close()
+		pa.backupStream = nil
 	}
 	close(req.Res)
 }
@@ -567,6 +872,126 @@ func (pa *path) doRemoveReader(req defs.PathRemoveReaderReq) {
 	}
 }
 
+func (pa *path) doAPIPathsDebug(req pathAPIPathsDebugReq) {
+	req.res <- pathAPIPathsDebugRes{
+		data: &defs.APIPathDebug{
+			Name:  pa.name,
+			Ready: pa.stream != nil,
+			Tracks: func() []defs.APIPathDebugTrack {
+				if pa.stream == nil {
+					return []defs.APIPathDebugTrack{}
+				}
+				return apiPathDebugTracks(pa.stream)
+			}(),
+		},
+	}
+}
+
+func (pa *path) doAPIPathsMute(req pathAPIPathsMuteReq) {
+	if req.data.Audio != nil {
+		pa.audioMuted = *req.data.Audio
+		pa.Log(logger.Info, "audio %s", mutedStr(pa.audioMuted))
+	}
+
+	if req.data.Video != nil {
+		pa.videoMuted = *req.data.Video
+		pa.Log(logger.Info, "video %s", mutedStr(pa.videoMuted))
+	}
+
+	if pa.stream != nil {
+		pa.applyMuted()
+	}
+
+	req.res <- pathAPIPathsMuteRes{
+		data: &defs.APIPathMute{
+			Audio: pa.audioMuted,
+			Video: pa.videoMuted,
+		},
+	}
+}
+
+func mutedStr(muted bool) string {
+	if muted {
+		return "muted"
+	}
+	return "unmuted"
+}
+
+func (pa *path) applyMuted() {
+	pa.stream.SetMuted(description.MediaTypeAudio, pa.audioMuted)
+	pa.stream.SetMuted(description.MediaTypeVideo, pa.videoMuted)
+}
+
+func (pa *path) doAPIPathsHealth(req pathAPIPathsHealthReq) {
+	req.res <- pathAPIPathsHealthRes{
+		data: &defs.APIPathHealth{
+			Name: pa.name,
+			State: func() string {
+				switch {
+				case pa.stream != nil:
+					return "ready"
+				case pa.source != nil:
+					return "notReady"
+				default:
+					return "idle"
+				}
+			}(),
+			LastPacketAgo: func() *float64 {
+				if pa.stream == nil {
+					return nil
+				}
+				t := pa.stream.LastReceived()
+				if t.IsZero() {
+					return nil
+				}
+				v := time.Since(t).Seconds()
+				return &v
+			}(),
+			Readers: len(pa.readers),
+			Recording: func() bool {
+				if pa.recorder == nil {
+					return false
+				}
+				return pa.recorder.Status().Writing
+			}(),
+			LastError: func() *string {
+				if pa.recorder != nil {
+					if err := pa.recorder.Status().LastError; err != nil {
+						v := err.Error()
+						return &v
+					}
+				}
+				if source, ok := pa.source.(*staticSourceHandler); ok {
+					if err := source.lastError(); err != nil {
+						v := err.Error()
+						return &v
+					}
+				}
+				return nil
+			}(),
+		},
+	}
+}
+
+func apiPathRecorder(status recorder.Status) *defs.APIPathRecorder {
+	ret := &defs.APIPathRecorder{
+		Writing:      status.Writing,
+		SegmentBytes: status.SegmentBytes,
+	}
+
+	if status.Writing {
+		v := status.SegmentStart
+		ret.SegmentStart = &v
+	}
+
+	if status.LastError != nil {
+		v := status.LastError.Error()
+		ret.LastError = &v
+	}
+
+	return ret
+}
+
 func (pa *path) doAPIPathsGet(req pathAPIPathsGetReq) {
 	req.res <- pathAPIPathsGetRes{
 		data: &defs.APIPath{
@@ -587,12 +1012,19 @@ func (pa *path) doAPIPathsGet(req pathAPIPathsGetReq) {
 				v := pa.readyTime
 				return &v
 			}(),
+			EndOfStream: pa.endOfStream,
 			Tracks: func() []string {
 				if pa.stream == nil {
 					return []string{}
 				}
 				return defs.MediasToCodecs(pa.stream.Desc().Medias)
 			}(),
+			Tracks2: func() []defs.APIPathTrack {
+				if pa.stream == nil {
+					return []defs.APIPathTrack{}
+				}
+				return apiPathTracks(pa.stream)
+			}(),
 			BytesReceived: func() uint64 {
 				if pa.stream == nil {
 					return 0
@@ -605,6 +1037,18 @@ func (pa *path) doAPIPathsGet(req pathAPIPathsGetReq) {
 				}
 				return pa.stream.BytesSent()
 			}(),
+			GOPCacheSize: func() uint64 {
+				if pa.stream == nil {
+					return 0
+				}
+				return pa.stream.GOPCacheSize()
+			}(),
+			Recorder: func() *defs.APIPathRecorder {
+				if pa.recorder == nil {
+					return nil
+				}
+				return apiPathRecorder(pa.recorder.Status())
+			}(),
 			Readers: func() []defs.APIPathSourceOrReader {
 				ret := []defs.APIPathSourceOrReader{}
 				for r := range pa.readers {
@@ -647,6 +1091,17 @@ func (pa *path) shouldClose() bool {
 		len(pa.readerAddRequestsOnHold) == 0
 }
 
+// readScheduleScheduleCheck schedules a check of the read, source and record schedules
+// at the beginning of next minute, since windows have minute granularity.
+func (pa *path) readScheduleScheduleCheck() {
+	if !pa.conf.HasSchedules() {
+		return
+	}
+
+	now := time.Now()
+	pa.readScheduleTimer = time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+}
+
 func (pa *path) onDemandStaticSourceStart(query string) {
 	pa.source.(*staticSourceHandler).start(true, query)
 
@@ -675,12 +1130,21 @@ func (pa *path) onDemandStaticSourceStop(reason string) {
 }
 
 func (pa *path) onDemandPublisherStart(query string) {
+	pa.onDemandPublisherRun++
+	run := pa.onDemandPublisherRun
+
 	pa.onUnDemandHook = hooks.OnDemand(hooks.OnDemandParams{
 		Logger:          pa,
 		ExternalCmdPool: pa.externalCmdPool,
 		Conf:            pa.conf,
 		ExternalCmdEnv:  pa.ExternalCmdEnv(),
 		Query:           query,
+		OnExit: func(_ error) {
+			select {
+			case pa.chOnDemandPublisherExit <- run:
+			case <-pa.ctx.Done():
+			}
+		},
 	})
 
 	pa.onDemandPublisherReadyTimer.Stop()
@@ -709,9 +1173,18 @@ func (pa *path) onDemandPublisherStop(reason string) {
 }
 
 func (pa *path) setReady(desc *description.Session, allocateEncoder bool) error {
+	if pa.conf.RTSPSessionName != "" {
+		// medias are shared with the source and must not be copied.
+		descCopy := *desc
+		descCopy.Title = pa.conf.RTSPSessionName
+		desc = &descCopy
+	}
+
 	var err error
 	pa.stream, err = stream.New(
 		pa.writeQueueSize,
+		time.Duration(pa.readerIdleTimeout),
+		time.Duration(pa.conf.StreamDelay),
 		pa.udpMaxPayloadSize,
 		desc,
 		allocateEncoder,
@@ -721,11 +1194,40 @@ func (pa *path) setReady(desc *description.Session, allocateEncoder bool) error
 		return err
 	}
 
-	if pa.conf.Record {
+	if pa.cachedDesc != nil {
+		added, removed := codecsDiff(defs.MediasToCodecs(pa.cachedDesc.Medias), defs.MediasToCodecs(desc.Medias))
+		if len(added) != 0 || len(removed) != 0 {
+			pa.Log(logger.Warn, "tracks have changed since the previous source (added: %s, removed: %s)",
+				codecsList(added), codecsList(removed))
+		}
+	}
+
+	pa.cachedDesc = desc
+
+	if pa.conf.OnDemandCacheDescription {
+		pa.parent.setCachedDescription(pa.name, desc)
+	}
+
+	pa.stream.SetNTPFromPTS(pa.conf.NTPFromPTS)
+
+	if pa.conf.GOPCache {
+		pa.stream.SetGOPCache(uint64(pa.conf.GOPCacheMaxSize))
+	}
+
+	pa.applyMuted()
+
+	if pa.conf.Record && pa.conf.RecordAllowed(time.Now()) {
 		pa.startRecording()
 	}
 
+	if pa.conf.MulticastOutput != "" {
+		pa.startMulticasting()
+	}
+
+	pa.startForwarding()
+
 	pa.readyTime = time.Now()
+	pa.endOfStream = false
 
 	pa.onNotReadyHook = hooks.OnReady(hooks.OnReadyParams{
 		Logger:          pa,
@@ -741,6 +1243,42 @@ func (pa *path) setReady(desc *description.Session, allocateEncoder bool) error
 	return nil
 }
 
+// codecsDiff returns codecs that are present in cur only and codecs that are present in prev only.
+func codecsDiff(prev []string, cur []string) ([]string, []string) {
+	count := make(map[string]int)
+	for _, c := range prev {
+		count[c]--
+	}
+	for _, c := range cur {
+		count[c]++
+	}
+
+	var added []string
+	var removed []string
+
+	for _, c := range cur {
+		if count[c] > 0 {
+			added = append(added, c)
+			count[c]--
+		}
+	}
+	for _, c := range prev {
+		if count[c] < 0 {
+			removed = append(removed, c)
+			count[c]++
+		}
+	}
+
+	return added, removed
+}
+
+func codecsList(codecs []string) string {
+	if len(codecs) == 0 {
+		return "none"
+	}
+	return strings.Join(codecs, ", ")
+}
+
 func (pa *path) consumeOnHoldRequests() {
 	for _, req := range pa.describeRequestsOnHold {
 		req.Res <- defs.PathDescribeRes{
@@ -755,21 +1293,62 @@ func (pa *path) consumeOnHoldRequests() {
 	pa.readerAddRequestsOnHold = nil
 }
 
-func (pa *path) setNotReady() {
+// setNotReady makes the path not ready.
+// When ended is true, the source has ended the stream cleanly
+// and readers are notified before being closed.
+func (pa *path) setNotReady(ended bool) {
 	pa.parent.pathNotReady(pa)
 
+	if pa.forwarder != nil {
+		pa.forwarder.close()
+		pa.forwarder = nil
+	}
+
+	pa.endOfStream = ended
+
+	if ended {
+		pa.Log(logger.Info, "stream has ended")
+		pa.stream.WriteEndOfStream()
+	}
+
+	readers := make([]defs.Reader, 0, len(pa.readers))
 	for r := range pa.readers {
 		pa.executeRemoveReader(r)
-		r.Close()
+		readers = append(readers, r)
 	}
 
-	pa.onNotReadyHook()
+	pa.onNotReadyHook(ended)
 
 	if pa.recorder != nil {
 		pa.recorder.Close()
 		pa.recorder = nil
 	}
 
+	if pa.multicaster != nil {
+		pa.multicaster.Close()
+		pa.multicaster = nil
+	}
+
+	pa.stopForwarding()
+
+	if ended {
+		// closing readers discards queued packets, therefore readers and the stream
+		// are closed later, in order to deliver the RTCP BYE.
+		strm := pa.stream
+		time.AfterFunc(pathEndOfStreamCloseDelay, func() {
+			for _, r := range readers {
+				r.Close()
+			}
+			strm.Close()
+		})
+		pa.stream = nil
+		return
+	}
+
+	for _, r := range readers {
+		r.Close()
+	}
+
 	if pa.stream != nil {
 		pa.stream.Close()
 		pa.stream = nil
@@ -782,6 +1361,8 @@ func (pa *path) startRecording() {
 		Format:          pa.conf.RecordFormat,
 		PartDuration:    time.Duration(pa.conf.RecordPartDuration),
 		SegmentDuration: time.Duration(pa.conf.RecordSegmentDuration),
+		SkipVideo:       !pa.conf.RecordVideo,
+		SkipAudio:       !pa.conf.RecordAudio,
 		PathName:        pa.name,
 		Stream:          pa.stream,
 		OnSegmentCreate: func(segmentPath string) {
@@ -818,18 +1399,185 @@ func (pa *path) startRecording() {
 	pa.recorder.Initialize()
 }
 
+func (pa *path) startMulticasting() {
+	m := &multicaster.Multicaster{
+		Address:   pa.conf.MulticastOutput,
+		Format:    pa.conf.MulticastOutputFormat,
+		TTL:       pa.conf.MulticastOutputTTL,
+		Interface: pa.conf.MulticastOutputInterface,
+		SAP:       pa.conf.MulticastOutputSAP,
+		PathName:  pa.name,
+		Stream:    pa.stream,
+		Parent:    pa,
+	}
+	err := m.Initialize()
+	if err != nil {
+		pa.Log(logger.Error, "unable to start multicast output: %v", err)
+		return
+	}
+	pa.multicaster = m
+}
+
+func (pa *path) startForwarding() {
+	for _, dest := range pa.conf.ForwardTo {
+		f := &forwarder.Forwarder{
+			URL:          dest,
+			ReadTimeout:  pa.readTimeout,
+			WriteTimeout: pa.writeTimeout,
+			Stream:       pa.stream,
+			Parent:       pa,
+		}
+		f.Initialize()
+		pa.forwarders = append(pa.forwarders, f)
+	}
+}
+
+func (pa *path) stopForwarding() {
+	for _, f := range pa.forwarders {
+		f.Close()
+	}
+	pa.forwarders = nil
+}
+
 func (pa *path) executeRemoveReader(r defs.Reader) {
 	delete(pa.readers, r)
 }
 
-func (pa *path) executeRemovePublisher() {
+func (pa *path) executeRemovePublisher(ended bool) {
 	if pa.stream != nil {
-		pa.setNotReady()
+		pa.setNotReady(ended)
 	}
 
 	pa.source = nil
 }
 
+// newPublisherStream creates a stream that is not attached to the path,
+// whose units are forwarded into the stream of the path when needed.
+func (pa *path) newPublisherStream(req defs.PathStartPublisherReq) (*stream.Stream, error) {
+	return stream.New(
+		pa.writeQueueSize,
+		time.Duration(pa.readerIdleTimeout),
+		0,
+		pa.udpMaxPayloadSize,
+		req.Desc,
+		req.GenerateRTPPackets,
+		logger.NewLimitedLogger(req.Author),
+	)
+}
+
+func (pa *path) startForwardedPublisher(req defs.PathStartPublisherReq) (*stream.Stream, error) {
+	strm, err := pa.newPublisherStream(req)
+	if err != nil {
+		return nil, err
+	}
+
+	pa.forwarder = &publisherForwarder{
+		src:    strm,
+		dest:   pa.stream,
+		parent: pa,
+	}
+	pa.forwarder.initialize()
+
+	return strm, nil
+}
+
+func (pa *path) startBackupPublisher(req defs.PathStartPublisherReq) {
+	if pa.stream != nil && !descsAreCompatible(pa.stream.Desc(), req.Desc) {
+		req.Res <- defs.PathStartPublisherRes{
+			Err: fmt.Errorf("tracks of the backup publisher don't match the ones of the primary publisher"),
+		}
+		return
+	}
+
+	strm, err := pa.newPublisherStream(req)
+	if err != nil {
+		req.Res <- defs.PathStartPublisherRes{Err: err}
+		return
+	}
+
+	pa.backupStream = strm
+
+	req.Author.Log(logger.Info, "is publishing to path '%s' as backup, %s",
+		pa.name,
+		defs.MediasInfo(req.Desc.Medias))
+
+	pa.primaryBytesReceived = 0
+	pa.backupCheckTimer.Stop()
+	pa.backupCheckTimer = time.NewTimer(time.Duration(pa.conf.BackupPublisherTimeout))
+
+	req.Res <- defs.PathStartPublisherRes{Stream: strm}
+}
+
+func (pa *path) removeBackupPublisher() {
+	pa.backupCheckTimer.Stop()
+	pa.backupCheckTimer = emptyTimer()
+
+	if pa.backupStream != nil {
+		pa.backupStream.Close()
+		pa.backupStream = nil
+	}
+
+	pa.backupPublisher.Close()
+	pa.backupPublisher = nil
+}
+
+// switchToBackupPublisher replaces the primary publisher with the backup one.
+// If the backup publisher is already publishing, its stream is forwarded into the stream of the path,
+// otherwise it becomes the primary publisher and readers wait for it to publish.
+func (pa *path) switchToBackupPublisher(ended bool) {
+	backup := pa.backupPublisher
+	backupStream := pa.backupStream
+
+	pa.backupCheckTimer.Stop()
+	pa.backupCheckTimer = emptyTimer()
+	pa.backupPublisher = nil
+	pa.backupStream = nil
+
+	if pa.forwarder != nil {
+		pa.forwarder.close()
+		pa.forwarder = nil
+	}
+
+	if backupStream != nil && pa.stream != nil && !descsAreCompatible(pa.stream.Desc(), backupStream.Desc()) {
+		pa.setNotReady(ended)
+	}
+
+	if backupStream == nil {
+		pa.source = backup
+		pa.publisherQuery = pa.backupPublisherQuery
+
+		if pa.stream != nil {
+			pa.Log(logger.Info, "switched to backup publisher, waiting for it to publish")
+		}
+		return
+	}
+
+	if pa.stream == nil {
+		err := pa.setReady(backupStream.Desc(), true)
+		if err != nil {
+			pa.Log(logger.Error, "unable to switch to backup publisher: %v", err)
+			backupStream.Close()
+			backup.Close()
+			pa.source = nil
+			return
+		}
+
+		pa.consumeOnHoldRequests()
+	}
+
+	pa.source = backup
+	pa.publisherQuery = pa.backupPublisherQuery
+
+	pa.forwarder = &publisherForwarder{
+		src:    backupStream,
+		dest:   pa.stream,
+		parent: pa,
+	}
+	pa.forwarder.initialize()
+
+	pa.Log(logger.Info, "switched to backup publisher")
+}
+
 func (pa *path) addReaderPost(req defs.PathAddReaderReq) {
 	if _, ok := pa.readers[req.Author]; ok {
 		req.Res <- defs.PathAddReaderRes{
@@ -996,3 +1744,52 @@ func (pa *path) APIPathsGet(req pathAPIPathsGetReq) (*defs.APIPath, error) {
 		return nil, fmt.Errorf("terminated")
 	}
 }
+
+// APIPathsMute is called by api.
+func (pa *path) APIPathsMute(data *defs.APIPathMuteReq) (*defs.APIPathMute, error) {
+	req := pathAPIPathsMuteReq{
+		data: data,
+		res:  make(chan pathAPIPathsMuteRes),
+	}
+
+	select {
+	case pa.chAPIPathsMute <- req:
+		res := <-req.res
+		return res.data, nil
+
+	case <-pa.ctx.Done():
+		return nil, fmt.Errorf("terminated")
+	}
+}
+
+// APIPathsHealth is called by api.
+func (pa *path) APIPathsHealth() (*defs.APIPathHealth, error) {
+	req := pathAPIPathsHealthReq{
+		res: make(chan pathAPIPathsHealthRes),
+	}
+
+	select {
+	case pa.chAPIPathsHealth <- req:
+		res := <-req.res
+		return res.data, nil
+
+	case <-pa.ctx.Done():
+		return nil, fmt.Errorf("terminated")
+	}
+}
+
+// APIPathsDebug is called by api.
+func (pa *path) APIPathsDebug() (*defs.APIPathDebug, error) {
+	req := pathAPIPathsDebugReq{
+		res: make(chan pathAPIPathsDebugRes),
+	}
+
+	select {
+	case pa.chAPIPathsDebug <- req:
+		res := <-req.res
+		return res.data, nil
+
+	case <-pa.ctx.Done():
+		return nil, fmt.Errorf("terminated")
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"encoding/base64"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/stream"
+)
+
+func encodeParameterSets(params ...[]byte) []string {
+	ret := []string{}
+	for _, p := range params {
+		if p != nil {
+			ret = append(ret, base64.StdEncoding.EncodeToString(p))
+		}
+	}
+	return ret
+}
+
+// fillParameterSets fills fields that are decoded from parameter sets.
+func fillParameterSets(track *defs.APIPathDebugTrack, forma format.Format) {
+	switch forma := forma.(type) {
+	case *format.H264:
+		sps, pps := forma.SafeParams()
+		track.ParameterSets = encodeParameterSets(sps, pps)
+
+	case *format.H265:
+		vps, sps, pps := forma.SafeParams()
+		track.ParameterSets = encodeParameterSets(vps, sps, pps)
+	}
+
+	props := decodeTrackProps(forma)
+	track.Width = props.width
+	track.Height = props.height
+	track.Profile = props.profile
+	track.Level = props.level
+}
+
+func apiPathDebugTracks(strm *stream.Stream) []defs.APIPathDebugTrack {
+	ret := []defs.APIPathDebugTrack{}
+
+	for _, medi := range strm.Desc().Medias {
+		for _, forma := range medi.Formats {
+			stats := strm.FormatStats(medi, forma)
+
+			track := defs.APIPathDebugTrack{
+				Codec:            forma.Codec(),
+				ParameterSets:    []string{},
+				Units:            stats.Units,
+				KeyFrames:        stats.KeyFrames,
+				LastKeyFrameTime: stats.LastKeyFrameTime,
+				LastKeyFrameSize: stats.LastKeyFrameSize,
+				LastGOPUnits:     stats.LastGOPUnits,
+				AverageGOPUnits:  stats.AverageGOPUnits,
+				TimestampJumps:   stats.TimestampJumps,
+			}
+
+			fillParameterSets(&track, forma)
+
+			ret = append(ret, track)
+		}
+	}
+
+	return ret
+}
@@ -2,9 +2,15 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
 	"sort"
 	"sync"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 
 	"github.com/bluenviron/mediamtx/internal/auth"
 	"github.com/bluenviron/mediamtx/internal/conf"
@@ -14,11 +20,91 @@ import (
 	"github.com/bluenviron/mediamtx/internal/stream"
 )
 
+var errPathConfChanged = errors.New("path configuration has changed, retry")
+
+// qualityPathConf derives the configuration of a path that provides a quality
+// from the configuration of the original path.
+func qualityPathConf(pathConf *conf.Path, source string) *conf.Path {
+	qualityConf := pathConf.Clone()
+	qualityConf.Source = source
+	qualityConf.SourceOnDemand = true
+	qualityConf.SourceFallbacks = []string{}
+	qualityConf.SourceSchedule = []conf.ReadScheduleWindow{}
+	qualityConf.QualitySources = []conf.QualitySource{}
+	qualityConf.BackupPublisher = false
+	return qualityConf
+}
+
+// findQualityPathConf returns the name and the configuration of the path
+// that provides the quality requested through the "quality" query parameter.
+// Qualities are searched in the qualitySources of the original path,
+// then in the path named after the original one plus "_" and the quality.
+func findQualityPathConf(
+	pathConfs map[string]*conf.Path,
+	name string,
+	query string,
+) (string, *conf.Path, []string, error) {
+	pathConf, pathMatches, err := findPathConfOrMediaSubPath(pathConfs, name)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	v, _ := url.ParseQuery(query)
+	quality := v.Get("quality")
+	if quality == "" {
+		return name, pathConf, pathMatches, nil
+	}
+
+	qualityName := name + "_" + quality
+
+	for _, qs := range pathConf.QualitySources {
+		if qs.Name == quality {
+			return qualityName, qualityPathConf(pathConf, qs.Source), pathMatches, nil
+		}
+	}
+
+	pathConf, pathMatches, err = conf.FindPathConf(pathConfs, qualityName)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("quality '%s' of path '%s' is not available", quality, name)
+	}
+
+	return qualityName, pathConf, pathMatches, nil
+}
+
+// pathAliases maps aliases to the names of the paths they belong to.
+func pathAliases(pathConfs map[string]*conf.Path) map[string]string {
+	ret := make(map[string]string)
+	for name, pathConf := range pathConfs {
+		for _, alias := range pathConf.Aliases {
+			ret[alias] = name
+		}
+	}
+	return ret
+}
+
 func pathConfCanBeUpdated(oldPathConf *conf.Path, newPathConf *conf.Path) bool {
 	clone := oldPathConf.Clone()
 
+	clone.ReadProtocols = newPathConf.ReadProtocols
+	clone.ReadSchedule = newPathConf.ReadSchedule
+	clone.ReadScheduleTimezone = newPathConf.ReadScheduleTimezone
+	clone.SourceSchedule = newPathConf.SourceSchedule
+	clone.RecordSchedule = newPathConf.RecordSchedule
+
 	clone.Record = newPathConf.Record
 
+	clone.MulticastOutput = newPathConf.MulticastOutput
+	clone.MulticastOutputFormat = newPathConf.MulticastOutputFormat
+	clone.MulticastOutputTTL = newPathConf.MulticastOutputTTL
+	clone.MulticastOutputInterface = newPathConf.MulticastOutputInterface
+	clone.MulticastOutputSAP = newPathConf.MulticastOutputSAP
+
+	clone.ForwardTo = newPathConf.ForwardTo
+
+	clone.WebRTCICEServers = newPathConf.WebRTCICEServers
+
+	clone.ONVIFDeviceURL = newPathConf.ONVIFDeviceURL
+
 	clone.RPICameraBrightness = newPathConf.RPICameraBrightness
 	clone.RPICameraContrast = newPathConf.RPICameraContrast
 	clone.RPICameraSaturation = newPathConf.RPICameraSaturation
@@ -39,6 +125,22 @@ func pathConfCanBeUpdated(oldPathConf *conf.Path, newPathConf *conf.Path) bool {
 	return newPathConf.Equal(clone)
 }
 
+func checkReadAllowed(pathConf *conf.Path, req *defs.PathAccessRequest) error {
+	if req.Publish || req.Proto == "" {
+		return nil
+	}
+
+	if _, ok := pathConf.ReadProtocols[string(req.Proto)]; !ok {
+		return fmt.Errorf("reading from path '%s' with %s is disabled", req.Name, req.Proto)
+	}
+
+	if !pathConf.ReadAllowed(time.Now()) {
+		return fmt.Errorf("reading from path '%s' is not allowed at this time", req.Name)
+	}
+
+	return nil
+}
+
 type pathManagerHLSServer interface {
 	PathReady(defs.Path)
 	PathNotReady(defs.Path)
@@ -55,17 +157,24 @@ type pathManager struct {
 	readTimeout       conf.Duration
 	writeTimeout      conf.Duration
 	writeQueueSize    int
+	readerIdleTimeout conf.Duration
 	udpMaxPayloadSize int
+	pathRewriteRules  conf.PathRewriteRules
 	pathConfs         map[string]*conf.Path
 	externalCmdPool   *externalcmd.Pool
 	parent            pathManagerParent
 
-	ctx         context.Context
-	ctxCancel   func()
-	wg          sync.WaitGroup
-	hlsManager  pathManagerHLSServer
-	paths       map[string]*path
-	pathsByConf map[string]map[*path]struct{}
+	ctx                context.Context
+	ctxCancel          func()
+	wg                 sync.WaitGroup
+	pathConfsMutex     sync.RWMutex
+	pathAliases        map[string]string
+	hlsManager         pathManagerHLSServer
+	paths              map[string]*path
+	pathsByConf        map[string]map[*path]struct{}
+	pathRewriteRegexps []*regexp.Regexp
+	cachedDescsMutex   sync.Mutex
+	cachedDescs        map[string]*description.Session
 
 	// in
 	chReloadConf   chan map[string]*conf.Path
@@ -73,7 +182,6 @@ type pathManager struct {
 	chClosePath    chan *path
 	chPathReady    chan *path
 	chPathNotReady chan *path
-	chFindPathConf chan defs.PathFindPathConfReq
 	chDescribe     chan defs.PathDescribeReq
 	chAddReader    chan defs.PathAddReaderReq
 	chAddPublisher chan defs.PathAddPublisherReq
@@ -88,18 +196,25 @@ func (pm *pathManager) initialize() {
 	pm.ctxCancel = ctxCancel
 	pm.paths = make(map[string]*path)
 	pm.pathsByConf = make(map[string]map[*path]struct{})
+	pm.cachedDescs = make(map[string]*description.Session)
 	pm.chReloadConf = make(chan map[string]*conf.Path)
 	pm.chSetHLSServer = make(chan pathManagerHLSServer)
 	pm.chClosePath = make(chan *path)
 	pm.chPathReady = make(chan *path)
 	pm.chPathNotReady = make(chan *path)
-	pm.chFindPathConf = make(chan defs.PathFindPathConfReq)
 	pm.chDescribe = make(chan defs.PathDescribeReq)
 	pm.chAddReader = make(chan defs.PathAddReaderReq)
 	pm.chAddPublisher = make(chan defs.PathAddPublisherReq)
 	pm.chAPIPathsList = make(chan pathAPIPathsListReq)
 	pm.chAPIPathsGet = make(chan pathAPIPathsGetReq)
 
+	pm.pathAliases = pathAliases(pm.pathConfs)
+
+	// rules have already been validated
+	for _, rule := range pm.pathRewriteRules {
+		pm.pathRewriteRegexps = append(pm.pathRewriteRegexps, regexp.MustCompile(rule.Match))
+	}
+
 	for _, pathConf := range pm.pathConfs {
 		if pathConf.Regexp == nil {
 			pm.createPath(pathConf, pathConf.Name, nil)
@@ -144,9 +259,6 @@ outer:
 		case pa := <-pm.chPathNotReady:
 			pm.doPathNotReady(pa)
 
-		case req := <-pm.chFindPathConf:
-			pm.doFindPathConf(req)
-
 		case req := <-pm.chDescribe:
 			pm.doDescribe(req)
 
@@ -177,7 +289,14 @@ func (pm *pathManager) doReloadConf(newPaths map[string]*conf.Path) {
 			if !newPath.Equal(pathConf) {
 				if pathConfCanBeUpdated(pathConf, newPath) { // paths associated with the configuration can be updated
 					for pa := range pm.pathsByConf[confName] {
-						go pa.reloadConf(newPath)
+						// paths that provide a quality use a derived configuration and must be recreated
+						if pa.conf != pathConf {
+							pm.removePath(pa)
+							pa.close()
+							pa.wait()
+						} else {
+							go pa.reloadConf(newPath)
+						}
 					}
 				} else { // paths associated with the configuration must be recreated
 					for pa := range pm.pathsByConf[confName] {
@@ -197,7 +316,12 @@ func (pm *pathManager) doReloadConf(newPaths map[string]*conf.Path) {
 		}
 	}
 
+	pm.pruneCachedDescriptions(pm.pathConfs, newPaths)
+
+	pm.pathConfsMutex.Lock()
 	pm.pathConfs = newPaths
+	pm.pathAliases = pathAliases(newPaths)
+	pm.pathConfsMutex.Unlock()
 
 	// add new paths
 	for pathConfName, pathConf := range pm.pathConfs {
@@ -207,6 +331,21 @@ func (pm *pathManager) doReloadConf(newPaths map[string]*conf.Path) {
 	}
 }
 
+// pruneCachedDescriptions removes cached descriptions of paths whose configuration
+// has been changed or deleted, since their source may have changed too.
+func (pm *pathManager) pruneCachedDescriptions(oldPaths map[string]*conf.Path, newPaths map[string]*conf.Path) {
+	pm.cachedDescsMutex.Lock()
+	defer pm.cachedDescsMutex.Unlock()
+
+	for name := range pm.cachedDescs {
+		oldPathConf, _, err1 := conf.FindPathConf(oldPaths, name)
+		newPathConf, _, err2 := conf.FindPathConf(newPaths, name)
+		if err1 != nil || err2 != nil || !newPathConf.Equal(oldPathConf) {
+			delete(pm.cachedDescs, name)
+		}
+	}
+}
+
 func (pm *pathManager) doSetHLSServer(m pathManagerHLSServer) {
 	pm.hlsManager = m
 }
@@ -230,87 +369,136 @@ func (pm *pathManager) doPathNotReady(pa *path) {
 	}
 }
 
-func (pm *pathManager) doFindPathConf(req defs.PathFindPathConfReq) {
-	pathConf, _, err := conf.FindPathConf(pm.pathConfs, req.AccessRequest.Name)
-	if err != nil {
-		req.Res <- defs.PathFindPathConfRes{Err: err}
-		return
+// rewritePathName applies the first matching path rewrite rule to a path name.
+func (pm *pathManager) rewritePathName(name string) string {
+	for i, re := range pm.pathRewriteRegexps {
+		m := re.FindStringSubmatchIndex(name)
+		if m != nil {
+			return string(re.ExpandString(nil, pm.pathRewriteRules[i].Replace, name, m))
+		}
 	}
+	return name
+}
 
-	err = pm.authManager.Authenticate(req.AccessRequest.ToAuthRequest())
-	if err != nil {
-		req.Res <- defs.PathFindPathConfRes{Err: err}
-		return
+// resolveAlias returns the name of the path an alias belongs to.
+// It can be called by any goroutine.
+func (pm *pathManager) resolveAlias(name string) string {
+	pm.pathConfsMutex.RLock()
+	defer pm.pathConfsMutex.RUnlock()
+	if v, ok := pm.pathAliases[name]; ok {
+		return v
 	}
+	return name
+}
 
-	req.Res <- defs.PathFindPathConfRes{Conf: pathConf}
+// findPathConf finds the configuration of a path.
+// It can be called by any goroutine.
+func (pm *pathManager) findPathConf(name string) (*conf.Path, []string, error) {
+	pm.pathConfsMutex.RLock()
+	defer pm.pathConfsMutex.RUnlock()
+	return findPathConfOrMediaSubPath(pm.pathConfs, name)
 }
 
-func (pm *pathManager) doDescribe(req defs.PathDescribeReq) {
-	pathConf, pathMatches, err := conf.FindPathConf(pm.pathConfs, req.AccessRequest.Name)
+// resolveRequest rewrites the path name, finds the path configuration and authenticates the request.
+// If the authentication script routes the request to another path,
+// the request is updated and the configuration of the new path is returned.
+// This is performed by the caller goroutine instead of the event loop,
+// since authentication may involve HTTP requests or scripts and would
+// otherwise serialize all requests of all paths.
+func (pm *pathManager) resolveRequest(accessReq *defs.PathAccessRequest) (*conf.Path, error) {
+	accessReq.Name = pm.resolveAlias(pm.rewritePathName(accessReq.Name))
+
+	pathConf, _, err := pm.findPathConf(accessReq.Name)
 	if err != nil {
-		req.Res <- defs.PathDescribeRes{Err: err}
-		return
+		return nil, err
 	}
 
-	err = pm.authManager.Authenticate(req.AccessRequest.ToAuthRequest())
+	if accessReq.SkipAuth {
+		accessReq.PathConf = pathConf
+		return pathConf, nil
+	}
+
+	authReq := accessReq.ToAuthRequest()
+
+	err = pm.authManager.Authenticate(authReq)
 	if err != nil {
-		req.Res <- defs.PathDescribeRes{Err: err}
-		return
+		return nil, err
 	}
 
-	// create path if it doesn't exist
-	if _, ok := pm.paths[req.AccessRequest.Name]; !ok {
-		pm.createPath(pathConf, req.AccessRequest.Name, pathMatches)
+	if authReq.Path != accessReq.Name {
+		accessReq.Name = authReq.Path
+		pathConf, _, err = pm.findPathConf(accessReq.Name)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	req.Res <- defs.PathDescribeRes{Path: pm.paths[req.AccessRequest.Name]}
+	accessReq.PathConf = pathConf
+	return pathConf, nil
 }
 
-func (pm *pathManager) doAddReader(req defs.PathAddReaderReq) {
-	pathConf, pathMatches, err := conf.FindPathConf(pm.pathConfs, req.AccessRequest.Name)
+// getOrCreatePath returns the path with the given name, creating it if it doesn't exist.
+// The configuration is searched again since it may have been reloaded
+// after the request has been resolved. If it has changed, the request is rejected,
+// since it has been authenticated and checked against the previous configuration.
+// If the query contains a quality, the path that provides the quality is returned.
+func (pm *pathManager) getOrCreatePath(accessReq *defs.PathAccessRequest, query string) (*path, error) {
+	pathConf, _, err := findPathConfOrMediaSubPath(pm.pathConfs, accessReq.Name)
 	if err != nil {
-		req.Res <- defs.PathAddReaderRes{Err: err}
-		return
+		return nil, err
 	}
 
-	if !req.AccessRequest.SkipAuth {
-		err = pm.authManager.Authenticate(req.AccessRequest.ToAuthRequest())
-		if err != nil {
-			req.Res <- defs.PathAddReaderRes{Err: err}
-			return
-		}
+	if accessReq.PathConf != nil && pathConf != accessReq.PathConf && !pathConf.Equal(accessReq.PathConf) {
+		return nil, errPathConfChanged
 	}
 
-	// create path if it doesn't exist
-	if _, ok := pm.paths[req.AccessRequest.Name]; !ok {
-		pm.createPath(pathConf, req.AccessRequest.Name, pathMatches)
+	name, pathConf, pathMatches, err := findQualityPathConf(pm.pathConfs, accessReq.Name, query)
+	if err != nil {
+		return nil, err
 	}
 
-	req.Res <- defs.PathAddReaderRes{Path: pm.paths[req.AccessRequest.Name]}
+	if pa, ok := pm.paths[name]; ok {
+		return pa, nil
+	}
+
+	err = pm.checkMaxPaths(pathConf)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.createPath(pathConf, name, pathMatches)
+
+	return pm.paths[name], nil
 }
 
-func (pm *pathManager) doAddPublisher(req defs.PathAddPublisherReq) {
-	pathConf, pathMatches, err := conf.FindPathConf(pm.pathConfs, req.AccessRequest.Name)
+func (pm *pathManager) doDescribe(req defs.PathDescribeReq) {
+	pa, err := pm.getOrCreatePath(&req.AccessRequest, req.AccessRequest.Query)
 	if err != nil {
-		req.Res <- defs.PathAddPublisherRes{Err: err}
+		req.Res <- defs.PathDescribeRes{Err: err}
 		return
 	}
 
-	if !req.AccessRequest.SkipAuth {
-		err = pm.authManager.Authenticate(req.AccessRequest.ToAuthRequest())
-		if err != nil {
-			req.Res <- defs.PathAddPublisherRes{Err: err}
-			return
-		}
+	req.Res <- defs.PathDescribeRes{Path: pa}
+}
+
+func (pm *pathManager) doAddReader(req defs.PathAddReaderReq) {
+	pa, err := pm.getOrCreatePath(&req.AccessRequest, req.AccessRequest.Query)
+	if err != nil {
+		req.Res <- defs.PathAddReaderRes{Err: err}
+		return
 	}
 
-	// create path if it doesn't exist
-	if _, ok := pm.paths[req.AccessRequest.Name]; !ok {
-		pm.createPath(pathConf, req.AccessRequest.Name, pathMatches)
+	req.Res <- defs.PathAddReaderRes{Path: pa}
+}
+
+func (pm *pathManager) doAddPublisher(req defs.PathAddPublisherReq) {
+	pa, err := pm.getOrCreatePath(&req.AccessRequest, "")
+	if err != nil {
+		req.Res <- defs.PathAddPublisherRes{Err: err}
+		return
 	}
 
-	req.Res <- defs.PathAddPublisherRes{Path: pm.paths[req.AccessRequest.Name]}
+	req.Res <- defs.PathAddPublisherRes{Path: pa}
 }
 
 func (pm *pathManager) doAPIPathsList(req pathAPIPathsListReq) {
@@ -333,6 +521,13 @@ func (pm *pathManager) doAPIPathsGet(req pathAPIPathsGetReq) {
 	req.res <- pathAPIPathsGetRes{path: path}
 }
 
+func (pm *pathManager) checkMaxPaths(pathConf *conf.Path) error {
+	if pathConf.MaxPaths != 0 && len(pm.pathsByConf[pathConf.Name]) >= pathConf.MaxPaths {
+		return fmt.Errorf("maximum number of paths of configuration '%s' has been reached", pathConf.Name)
+	}
+	return nil
+}
+
 func (pm *pathManager) createPath(
 	pathConf *conf.Path,
 	name string,
@@ -345,10 +540,12 @@ func (pm *pathManager) createPath(
 		readTimeout:       pm.readTimeout,
 		writeTimeout:      pm.writeTimeout,
 		writeQueueSize:    pm.writeQueueSize,
+		readerIdleTimeout: pm.readerIdleTimeout,
 		udpMaxPayloadSize: pm.udpMaxPayloadSize,
 		conf:              pathConf,
 		name:              name,
 		matches:           matches,
+		cachedDesc:        pm.cachedDescription(name),
 		wg:                &pm.wg,
 		externalCmdPool:   pm.externalCmdPool,
 		parent:            pm,
@@ -397,6 +594,22 @@ func (pm *pathManager) pathNotReady(pa *path) {
 	}
 }
 
+// cachedDescription returns the description cached by a previous instance of a path.
+func (pm *pathManager) cachedDescription(name string) *description.Session {
+	pm.cachedDescsMutex.Lock()
+	defer pm.cachedDescsMutex.Unlock()
+	return pm.cachedDescs[name]
+}
+
+// setCachedDescription is called by path.
+// Descriptions are stored in the path manager since paths
+// that are created from a regular expression are destroyed when not in use.
+func (pm *pathManager) setCachedDescription(name string, desc *description.Session) {
+	pm.cachedDescsMutex.Lock()
+	defer pm.cachedDescsMutex.Unlock()
+	pm.cachedDescs[name] = desc
+}
+
 // closePath is called by path.
 func (pm *pathManager) closePath(pa *path) {
 	select {
@@ -406,21 +619,33 @@ func (pm *pathManager) closePath(pa *path) {
 	}
 }
 
-// GetConfForPath is called by a reader or publisher.
+// FindPathConf is called by a reader or publisher.
 func (pm *pathManager) FindPathConf(req defs.PathFindPathConfReq) (*conf.Path, error) {
-	req.Res = make(chan defs.PathFindPathConfRes)
-	select {
-	case pm.chFindPathConf <- req:
-		res := <-req.Res
-		return res.Conf, res.Err
+	pathConf, err := pm.resolveRequest(&req.AccessRequest)
+	if err != nil {
+		return nil, err
+	}
 
-	case <-pm.ctx.Done():
-		return nil, fmt.Errorf("terminated")
+	err = checkReadAllowed(pathConf, &req.AccessRequest)
+	if err != nil {
+		return nil, err
 	}
+
+	return pathConf, nil
 }
 
 // Describe is called by a reader or publisher.
 func (pm *pathManager) Describe(req defs.PathDescribeReq) defs.PathDescribeRes {
+	pathConf, err := pm.resolveRequest(&req.AccessRequest)
+	if err != nil {
+		return defs.PathDescribeRes{Err: err}
+	}
+
+	err = checkReadAllowed(pathConf, &req.AccessRequest)
+	if err != nil {
+		return defs.PathDescribeRes{Err: err}
+	}
+
 	req.Res = make(chan defs.PathDescribeRes)
 	select {
 	case pm.chDescribe <- req:
@@ -444,6 +669,11 @@ func (pm *pathManager) Describe(req defs.PathDescribeReq) defs.PathDescribeRes {
 
 // AddPublisher is called by a publisher.
 func (pm *pathManager) AddPublisher(req defs.PathAddPublisherReq) (defs.Path, error) {
+	_, err := pm.resolveRequest(&req.AccessRequest)
+	if err != nil {
+		return nil, err
+	}
+
 	req.Res = make(chan defs.PathAddPublisherRes)
 	select {
 	case pm.chAddPublisher <- req:
@@ -461,6 +691,16 @@ func (pm *pathManager) AddPublisher(req defs.PathAddPublisherReq) (defs.Path, er
 
 // AddReader is called by a reader.
 func (pm *pathManager) AddReader(req defs.PathAddReaderReq) (defs.Path, *stream.Stream, error) {
+	pathConf, err := pm.resolveRequest(&req.AccessRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = checkReadAllowed(pathConf, &req.AccessRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	req.Res = make(chan defs.PathAddReaderRes)
 	select {
 	case pm.chAddReader <- req:
@@ -516,6 +756,59 @@ func (pm *pathManager) APIPathsList() (*defs.APIPathList, error) {
 	}
 }
 
+// APIPathsMute is called by api.
+func (pm *pathManager) APIPathsMute(name string, data *defs.APIPathMuteReq) (*defs.APIPathMute, error) {
+	req := pathAPIPathsGetReq{
+		name: name,
+		res:  make(chan pathAPIPathsGetRes),
+	}
+
+	select {
+	case pm.chAPIPathsGet <- req:
+		res := <-req.res
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		return res.path.APIPathsMute(data)
+
+	case <-pm.ctx.Done():
+		return nil, fmt.Errorf("terminated")
+	}
+}
+
+// APIPathsHealth is called by api.
+func (pm *pathManager) APIPathsHealth() (*defs.APIPathHealthList, error) {
+	req := pathAPIPathsListReq{
+		res: make(chan pathAPIPathsListRes),
+	}
+
+	select {
+	case pm.chAPIPathsList <- req:
+		res := <-req.res
+
+		data := &defs.APIPathHealthList{
+			Items: []*defs.APIPathHealth{},
+		}
+
+		for _, pa := range res.paths {
+			item, err := pa.APIPathsHealth()
+			if err == nil {
+				data.Items = append(data.Items, item)
+			}
+		}
+
+		sort.Slice(data.Items, func(i, j int) bool {
+			return data.Items[i].Name < data.Items[j].Name
+		})
+
+		return data, nil
+
+	case <-pm.ctx.Done():
+		return nil, fmt.Errorf("terminated")
+	}
+}
+
 // APIPathsGet is called by api.
 func (pm *pathManager) APIPathsGet(name string) (*defs.APIPath, error) {
 	req := pathAPIPathsGetReq{
@@ -537,3 +830,24 @@ func (pm *pathManager) APIPathsGet(name string) (*defs.APIPath, error) {
 		return nil, fmt.Errorf("terminated")
 	}
 }
+
+// APIPathsDebug is called by api.
+func (pm *pathManager) APIPathsDebug(name string) (*defs.APIPathDebug, error) {
+	req := pathAPIPathsGetReq{
+		name: name,
+		res:  make(chan pathAPIPathsGetRes),
+	}
+
+	select {
+	case pm.chAPIPathsGet <- req:
+		res := <-req.res
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		return res.path.APIPathsDebug()
+
+	case <-pm.ctx.Done():
+		return nil, fmt.Errorf("terminated")
+	}
+}
@@ -3,11 +3,18 @@ package core
 import (
 	"bufio"
 	"net"
+	"os"
 	"testing"
 
+	"github.com/bluenviron/gortsplib/v4"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/test"
 )
 
 func TestPathAutoDeletion(t *testing.T) {
@@ -82,3 +89,263 @@ func TestPathAutoDeletion(t *testing.T) {
 		})
 	}
 }
+
+func TestPathManagerRewriteRules(t *testing.T) {
+	p, ok := newInstance("pathRewriteRules:\n" +
+		"- match: ^vendor/live/(.+)$\n" +
+		"  replace: $1\n" +
+		"paths:\n" +
+		"  mystream:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	source := gortsplib.Client{}
+
+	err := source.StartRecording(
+		"rtsp://localhost:8554/vendor/live/mystream",
+		&description.Session{Medias: []*description.Media{test.UniqueMediaH264()}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	data, err := p.pathManager.APIPathsList()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(data.Items))
+	require.Equal(t, "mystream", data.Items[0].Name)
+	require.Equal(t, true, data.Items[0].Ready)
+
+	reader := gortsplib.Client{}
+
+	u, err := base.ParseURL("rtsp://localhost:8554/vendor/live/mystream")
+	require.NoError(t, err)
+
+	err = reader.Start(u.Scheme, u.Host)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	desc, _, err := reader.Describe(u)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(desc.Medias))
+}
+
+func TestPathManagerAliases(t *testing.T) {
+	p, ok := newInstance("paths:\n" +
+		"  mystream:\n" +
+		"    aliases: [frontdoor, entrance]\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	source := gortsplib.Client{}
+
+	err := source.StartRecording(
+		"rtsp://localhost:8554/frontdoor",
+		&description.Session{Medias: []*description.Media{test.UniqueMediaH264()}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	data, err := p.pathManager.APIPathsList()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(data.Items))
+	require.Equal(t, "mystream", data.Items[0].Name)
+	require.Equal(t, true, data.Items[0].Ready)
+
+	for _, name := range []string{"mystream", "entrance"} {
+		reader := gortsplib.Client{}
+
+		u, err := base.ParseURL("rtsp://localhost:8554/" + name)
+		require.NoError(t, err)
+
+		err = reader.Start(u.Scheme, u.Host)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		desc, _, err := reader.Describe(u)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(desc.Medias))
+	}
+}
+
+func TestPathManagerReadProtocols(t *testing.T) {
+	p, ok := newInstance("paths:\n" +
+		"  mystream:\n" +
+		"    readProtocols: [hls]\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	source := gortsplib.Client{}
+
+	err := source.StartRecording(
+		"rtsp://localhost:8554/mystream",
+		&description.Session{Medias: []*description.Media{test.UniqueMediaH264()}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	reader := gortsplib.Client{}
+
+	u, err := base.ParseURL("rtsp://localhost:8554/mystream")
+	require.NoError(t, err)
+
+	err = reader.Start(u.Scheme, u.Host)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	_, _, err = reader.Describe(u)
+	require.Error(t, err)
+}
+
+func TestPathManagerQuality(t *testing.T) {
+	p, ok := newInstance("paths:\n" +
+		"  mystream:\n" +
+		"    qualitySources:\n" +
+		"    - name: low\n" +
+		"      source: rtsp://localhost:8554/substream\n" +
+		"  mystream_high:\n" +
+		"  substream:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	for _, name := range []string{"mystream_high", "substream"} {
+		source := gortsplib.Client{}
+
+		err := source.StartRecording(
+			"rtsp://localhost:8554/"+name,
+			&description.Session{Medias: []*description.Media{test.UniqueMediaH264()}})
+		require.NoError(t, err)
+		defer source.Close()
+	}
+
+	for _, ca := range []string{"convention", "config"} {
+		t.Run(ca, func(t *testing.T) {
+			var quality string
+			if ca == "convention" {
+				quality = "high"
+			} else {
+				quality = "low"
+			}
+
+			reader := gortsplib.Client{}
+
+			u, err := base.ParseURL("rtsp://localhost:8554/mystream?quality=" + quality)
+			require.NoError(t, err)
+
+			err = reader.Start(u.Scheme, u.Host)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			desc, _, err := reader.Describe(u)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(desc.Medias))
+
+			_, err = p.pathManager.APIPathsGet("mystream_" + quality)
+			require.NoError(t, err)
+		})
+	}
+
+	reader := gortsplib.Client{}
+
+	u, err := base.ParseURL("rtsp://localhost:8554/mystream?quality=medium")
+	require.NoError(t, err)
+
+	err = reader.Start(u.Scheme, u.Host)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	_, _, err = reader.Describe(u)
+	require.Error(t, err)
+}
+
+func TestPathManagerMediaSubPaths(t *testing.T) {
+	p, ok := newInstance("paths:\n" +
+		"  mystream:\n" +
+		"    mediaSubPaths: yes\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	source := gortsplib.Client{}
+
+	err := source.StartRecording(
+		"rtsp://localhost:8554/mystream",
+		&description.Session{Medias: []*description.Media{
+			test.UniqueMediaH264(),
+			test.UniqueMediaMPEG4Audio(),
+		}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	for _, ca := range []string{"audio", "video"} {
+		t.Run(ca, func(t *testing.T) {
+			reader := gortsplib.Client{}
+
+			u, err := base.ParseURL("rtsp://localhost:8554/mystream/" + ca)
+			require.NoError(t, err)
+
+			err = reader.Start(u.Scheme, u.Host)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			desc, _, err := reader.Describe(u)
+			require.NoError(t, err)
+			require.Equal(t, 1, len(desc.Medias))
+
+			if ca == "audio" {
+				require.Equal(t, description.MediaTypeAudio, desc.Medias[0].Type)
+			} else {
+				require.Equal(t, description.MediaTypeVideo, desc.Medias[0].Type)
+			}
+		})
+	}
+
+	publisher := gortsplib.Client{}
+
+	err = publisher.StartRecording(
+		"rtsp://localhost:8554/mystream/audio",
+		&description.Session{Medias: []*description.Media{test.UniqueMediaMPEG4Audio()}})
+	require.Error(t, err)
+}
+
+func TestPathManagerConfChangedAfterAuth(t *testing.T) {
+	p, ok := newInstance("paths:\n" +
+		"  mypath:\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	pm := p.pathManager
+
+	for _, ca := range []string{"unchanged", "changed"} {
+		t.Run(ca, func(t *testing.T) {
+			accessReq := defs.PathAccessRequest{
+				Name:     "mypath",
+				SkipAuth: true,
+			}
+			_, err := pm.resolveRequest(&accessReq)
+			require.NoError(t, err)
+
+			cnt := "paths:\n" +
+				"  mypath:\n"
+			if ca == "changed" {
+				cnt += "    maxReaders: 1\n"
+			}
+
+			tmpf, err := test.CreateTempFile([]byte(cnt))
+			require.NoError(t, err)
+			defer os.Remove(tmpf)
+
+			newConf, _, err := conf.Load(tmpf, nil, nil)
+			require.NoError(t, err)
+
+			pm.ReloadPathConfs(newConf.Paths)
+
+			req := defs.PathDescribeReq{
+				AccessRequest: accessReq,
+				Res:           make(chan defs.PathDescribeRes),
+			}
+			pm.chDescribe <- req
+			res := <-req.Res
+
+			if ca == "unchanged" {
+				require.NoError(t, res.Err)
+			} else {
+				require.Equal(t, errPathConfChanged, res.Err)
+			}
+		})
+	}
+}
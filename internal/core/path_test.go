@@ -2,6 +2,7 @@ package core
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
@@ -20,6 +21,7 @@ import (
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
 	srt "github.com/datarhei/gosrt"
+	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 
@@ -149,6 +151,110 @@ func TestPathRunOnDemand(t *testing.T) {
 	}
 }
 
+func TestPathRunOnDemandExit(t *testing.T) {
+	for _, code := range []string{"0", "1"} {
+		t.Run("code "+code, func(t *testing.T) {
+			p1, ok := newInstance("rtmp: no\n" +
+				"hls: no\n" +
+				"webrtc: no\n" +
+				"paths:\n" +
+				"  ondemand:\n" +
+				"    runOnDemand: sh -c \"exit " + code + "\"\n" +
+				"    runOnDemandStartTimeout: 20s\n")
+			require.Equal(t, true, ok)
+			defer p1.Close()
+
+			reader := gortsplib.Client{}
+
+			u, err := base.ParseURL("rtsp://localhost:8554/ondemand")
+			require.NoError(t, err)
+
+			err = reader.Start(u.Scheme, u.Host)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			start := time.Now()
+			_, _, err = reader.Describe(u)
+			require.EqualError(t, err, "bad status code: 400 (Bad Request)")
+			require.Less(t, time.Since(start), 10*time.Second)
+		})
+	}
+}
+
+func TestPathOnDemandCacheDescription(t *testing.T) {
+	onDemand := filepath.Join(os.TempDir(), "on_demand")
+	onUnDemand := filepath.Join(os.TempDir(), "on_undemand")
+	defer os.Remove(onDemand)
+	defer os.Remove(onUnDemand)
+
+	p1, ok := newInstance(fmt.Sprintf("rtmp: no\n"+
+		"hls: no\n"+
+		"webrtc: no\n"+
+		"paths:\n"+
+		"  '~^(on)demand$':\n"+
+		"    runOnDemand: sh -c \"ON_DEMAND=%s go run ./test_on_demand/main.go\"\n"+
+		"    runOnDemandCloseAfter: 1s\n"+
+		"    runOnUnDemand: touch %s\n"+
+		"    onDemandCacheDescription: yes\n", onDemand, onUnDemand))
+	require.Equal(t, true, ok)
+	defer p1.Close()
+
+	waitUnDemand := func() {
+		for {
+			_, err := os.Stat(onUnDemand)
+			if err == nil {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		os.Remove(onUnDemand)
+	}
+
+	describe := func() *description.Session {
+		reader := gortsplib.Client{}
+
+		u, err := base.ParseURL("rtsp://localhost:8554/ondemand?param=value")
+		require.NoError(t, err)
+
+		err = reader.Start(u.Scheme, u.Host)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		desc, _, err := reader.Describe(u)
+		require.NoError(t, err)
+
+		return desc
+	}
+
+	// first request: description is obtained from the source.
+	desc1 := describe()
+	waitUnDemand()
+
+	for {
+		_, err := os.Stat(onDemand)
+		if err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	os.Remove(onDemand)
+
+	// the path is destroyed since it is created from a regular expression and it is not in use.
+	// second request: description is obtained from cache, without starting the source.
+	desc2 := describe()
+
+	require.Equal(t, len(desc1.Medias), len(desc2.Medias))
+	require.Equal(t, desc1.Medias[0].Formats[0].Codec(), desc2.Medias[0].Formats[0].Codec())
+
+	time.Sleep(2 * time.Second)
+
+	_, err := os.Stat(onDemand)
+	require.True(t, os.IsNotExist(err))
+
+	_, err = os.Stat(onUnDemand)
+	require.True(t, os.IsNotExist(err))
+}
+
 func TestPathRunOnConnect(t *testing.T) {
 	serverCertFpath, err := test.CreateTempFile(test.TLSCertPub)
 	require.NoError(t, err)
@@ -658,6 +764,61 @@ func TestPathMaxReaders(t *testing.T) {
 	}
 }
 
+func TestPathMaxPaths(t *testing.T) {
+	p, ok := newInstance("paths:\n" +
+		"  all_others:\n" +
+		"    maxPaths: 1\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	for i := 0; i < 2; i++ {
+		source := gortsplib.Client{}
+
+		err := source.StartRecording(
+			fmt.Sprintf("rtsp://localhost:8554/mystream%d", i),
+			&description.Session{Medias: []*description.Media{
+				test.UniqueMediaH264(),
+			}})
+		if i == 0 {
+			require.NoError(t, err)
+			defer source.Close()
+		} else {
+			require.Error(t, err)
+		}
+	}
+}
+
+func TestPathRTSPSessionName(t *testing.T) {
+	p, ok := newInstance("paths:\n" +
+		"  all_others:\n" +
+		"    rtspSessionName: My Camera\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	source := gortsplib.Client{}
+
+	err := source.StartRecording(
+		"rtsp://localhost:8554/mystream",
+		&description.Session{Medias: []*description.Media{
+			test.UniqueMediaH264(),
+		}})
+	require.NoError(t, err)
+	defer source.Close()
+
+	reader := gortsplib.Client{}
+
+	u, err := base.ParseURL("rtsp://127.0.0.1:8554/mystream")
+	require.NoError(t, err)
+
+	err = reader.Start(u.Scheme, u.Host)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	desc, _, err := reader.Describe(u)
+	require.NoError(t, err)
+	require.Equal(t, "My Camera", desc.Title)
+}
+
 func TestPathRecord(t *testing.T) {
 	dir, err := os.MkdirTemp("", "rtsp-path-record")
 	require.NoError(t, err)
@@ -852,6 +1013,299 @@ func TestPathResolveSource(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPathSourceStall(t *testing.T) {
+	onStall := filepath.Join(os.TempDir(), "on_source_stall")
+	defer os.Remove(onStall)
+
+	var stream *gortsplib.ServerStream
+
+	s := gortsplib.Server{
+		Handler: &testServer{
+			onDescribe: func(_ *gortsplib.ServerHandlerOnDescribeCtx,
+			) (*base.Response, *gortsplib.ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *gortsplib.ServerHandlerOnSetupCtx) (*base.Response, *gortsplib.ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(_ *gortsplib.ServerHandlerOnPlayCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress: "127.0.0.1:8555",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	// the stream is never written, simulating a frozen camera
+	stream = gortsplib.NewServerStream(&s, &description.Session{Medias: []*description.Media{test.MediaH264}})
+	defer stream.Close()
+
+	p, ok := newInstance(fmt.Sprintf("paths:\n"+
+		"  test:\n"+
+		"    source: rtsp://127.0.0.1:8555/a\n"+
+		"    sourceStallTimeout: 1s\n"+
+		"    runOnSourceStall: sh -c 'echo \"$MTX_PATH $MTX_SOURCE_TYPE\" > %s'\n",
+		onStall))
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	var byts []byte
+	for i := 0; i < 40; i++ {
+		byts, err = os.ReadFile(onStall)
+		if err == nil && len(byts) != 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	require.Equal(t, "test rtspSource\n", string(byts))
+}
+
+func TestPathSourceFallbacks(t *testing.T) {
+	newSourceServer := func(address string) *gortsplib.Server {
+		var stream *gortsplib.ServerStream
+
+		s := &gortsplib.Server{
+			Handler: &testServer{
+				onDescribe: func(_ *gortsplib.ServerHandlerOnDescribeCtx,
+				) (*base.Response, *gortsplib.ServerStream, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, stream, nil
+				},
+				onSetup: func(_ *gortsplib.ServerHandlerOnSetupCtx) (*base.Response, *gortsplib.ServerStream, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, stream, nil
+				},
+				onPlay: func(_ *gortsplib.ServerHandlerOnPlayCtx) (*base.Response, error) {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, nil
+				},
+			},
+			RTSPAddress: address,
+		}
+
+		err := s.Start()
+		require.NoError(t, err)
+
+		stream = gortsplib.NewServerStream(s, &description.Session{Medias: []*description.Media{test.MediaH264}})
+
+		// write frames continuously, that are identified by the last byte of the address
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; ; i++ {
+				err2 := stream.WritePacketRTP(stream.Description().Medias[0], &rtp.Packet{
+					Header: rtp.Header{
+						Version:        2,
+						Marker:         true,
+						PayloadType:    96,
+						SequenceNumber: uint16(i),
+						Timestamp:      uint32(i) * 3000,
+						SSRC:           563423,
+					},
+					Payload: []byte{5, address[len(address)-1]},
+				})
+				if err2 != nil {
+					return
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+		}()
+
+		t.Cleanup(func() {
+			stream.Close()
+			s.Close()
+			<-done
+		})
+
+		return s
+	}
+
+	// reads the path until a frame is received and returns the frame.
+	readFrame := func() []byte {
+		deadline := time.Now().Add(30 * time.Second)
+
+		for time.Now().Before(deadline) {
+			payload := func() []byte {
+				c := gortsplib.Client{}
+
+				u, err := base.ParseURL("rtsp://localhost:8554/test")
+				require.NoError(t, err)
+
+				err = c.Start(u.Scheme, u.Host)
+				require.NoError(t, err)
+				defer c.Close()
+
+				desc, _, err := c.Describe(u)
+				if err != nil {
+					return nil
+				}
+
+				err = c.SetupAll(desc.BaseURL, desc.Medias)
+				require.NoError(t, err)
+
+				recv := make(chan []byte, 1)
+
+				c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+					select {
+					case recv <- pkt.Payload:
+					default:
+					}
+				})
+
+				_, err = c.Play(nil)
+				require.NoError(t, err)
+
+				select {
+				case payload := <-recv:
+					return payload
+				case <-time.After(2 * time.Second):
+					return nil
+				}
+			}()
+			if payload != nil {
+				return payload
+			}
+
+			time.Sleep(500 * time.Millisecond)
+		}
+
+		t.Fatal("no frames received")
+		return nil
+	}
+
+	newSourceServer("127.0.0.1:8556")
+
+	p, ok := newInstance("rtmp: no\n" +
+		"hls: no\n" +
+		"webrtc: no\n" +
+		"paths:\n" +
+		"  test:\n" +
+		"    source: rtsp://127.0.0.1:8555/primary\n" +
+		"    sourceFallbacks: [rtsp://127.0.0.1:8556/fallback]\n" +
+		"    sourceFallbackAfter: 2\n")
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	// the primary source fails twice, then the fallback source is used.
+	require.Equal(t, []byte{5, '6'}, readFrame())
+
+	// the primary source becomes available, the probe succeeds and the primary source is restored.
+	newSourceServer("127.0.0.1:8555")
+
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		payload := readFrame()
+		if payload[1] == '5' {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "primary source was not restored")
+	}
+}
+
+func TestPathEndOfStream(t *testing.T) {
+	onNotReady := filepath.Join(os.TempDir(), "on_not_ready")
+	defer os.Remove(onNotReady)
+
+	p, ok := newInstance(fmt.Sprintf("api: yes\n"+
+		"rtmp: no\n"+
+		"hls: no\n"+
+		"webrtc: no\n"+
+		"paths:\n"+
+		"  test:\n"+
+		"    runOnNotReady: sh -c 'echo \"$MTX_END_OF_STREAM\" > %s'\n",
+		onNotReady))
+	require.Equal(t, true, ok)
+	defer p.Close()
+
+	pub := &testPublisher{medi: test.UniqueMediaH264(), payload: []byte{5, 1, 1, 1}}
+
+	err := pub.StartRecording("rtsp://localhost:8554/test",
+		&description.Session{Medias: []*description.Media{pub.medi}})
+	require.NoError(t, err)
+	defer pub.Close()
+
+	recv := make(chan *rtp.Packet, 100)
+	byeRecv := make(chan struct{})
+
+	c := gortsplib.Client{}
+
+	u, err := base.ParseURL("rtsp://localhost:8554/test")
+	require.NoError(t, err)
+
+	err = c.Start(u.Scheme, u.Host)
+	require.NoError(t, err)
+	defer c.Close()
+
+	desc, _, err := c.Describe(u)
+	require.NoError(t, err)
+
+	err = c.SetupAll(desc.BaseURL, desc.Medias)
+	require.NoError(t, err)
+
+	c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+		select {
+		case recv <- pkt:
+		default:
+		}
+	})
+
+	c.OnPacketRTCP(desc.Medias[0], func(pkt rtcp.Packet) {
+		if _, ok2 := pkt.(*rtcp.Goodbye); ok2 {
+			close(byeRecv)
+		}
+	})
+
+	_, err = c.Play(nil)
+	require.NoError(t, err)
+
+	writeUntilReceived(t, pub, recv)
+
+	// the publisher sends a TEARDOWN request
+	pub.Close()
+
+	select {
+	case <-byeRecv:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RTCP BYE not received")
+	}
+
+	var byts []byte
+	for i := 0; i < 30; i++ {
+		byts, err = os.ReadFile(onNotReady)
+		if err == nil && len(byts) != 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	require.Equal(t, "1\n", string(byts))
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	var out struct {
+		Ready       bool `json:"ready"`
+		EndOfStream bool `json:"endOfStream"`
+	}
+	httpRequest(t, hc, http.MethodGet, "http://localhost:9997/v3/paths/get/test", nil, &out)
+	require.Equal(t, false, out.Ready)
+	require.Equal(t, true, out.EndOfStream)
+}
+
 func TestPathOverridePublisher(t *testing.T) {
 	for _, ca := range []string{
 		"enabled",
@@ -954,3 +1408,187 @@ func TestPathOverridePublisher(t *testing.T) {
 		})
 	}
 }
+
+type testPublisher struct {
+	gortsplib.Client
+	medi    *description.Media
+	payload []byte
+	seq     uint16
+	ts      uint32
+}
+
+func (p *testPublisher) writeFrame() {
+	p.seq++
+	p.ts += 3000
+
+	p.WritePacketRTP(p.medi, &rtp.Packet{ //nolint:errcheck
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    96,
+			SequenceNumber: p.seq,
+			Timestamp:      p.ts,
+			SSRC:           978651231,
+			Marker:         true,
+		},
+		Payload: p.payload,
+	})
+}
+
+// writeUntilReceived writes frames with a publisher until the reader receives one of them.
+func writeUntilReceived(t *testing.T, pub *testPublisher, recv chan *rtp.Packet) *rtp.Packet {
+	deadline := time.Now().Add(10 * time.Second)
+
+	for time.Now().Before(deadline) {
+		pub.writeFrame()
+
+		timeout := time.After(100 * time.Millisecond)
+
+	outer:
+		for {
+			select {
+			case pkt := <-recv:
+				// the path stream may prepend SPS and PPS to the IDR
+				if bytes.Contains(pkt.Payload, pub.payload) {
+					return pkt
+				}
+
+			case <-timeout:
+				break outer
+			}
+		}
+	}
+
+	t.Fatal("reader did not receive frames of the publisher")
+	return nil
+}
+
+func TestPathBackupPublisher(t *testing.T) {
+	for _, ca := range []string{
+		"primary disconnect",
+		"primary stall",
+		"backup not started",
+		"incompatible tracks",
+		"ex-backup disconnect",
+	} {
+		t.Run(ca, func(t *testing.T) {
+			p, ok := newInstance("rtmp: no\n" +
+				"paths:\n" +
+				"  all_others:\n" +
+				"    backupPublisher: yes\n" +
+				"    backupPublisherTimeout: 1s\n")
+			require.Equal(t, true, ok)
+			defer p.Close()
+
+			pub1 := &testPublisher{medi: test.UniqueMediaH264(), payload: []byte{5, 1, 1, 1}}
+
+			err := pub1.StartRecording("rtsp://localhost:8554/teststream",
+				&description.Session{Medias: []*description.Media{pub1.medi}})
+			require.NoError(t, err)
+			defer pub1.Close()
+
+			pub2 := &testPublisher{medi: test.UniqueMediaH264(), payload: []byte{5, 2, 2, 2}}
+			defer pub2.Close()
+
+			switch ca {
+			case "backup not started":
+				u, err2 := base.ParseURL("rtsp://localhost:8554/teststream")
+				require.NoError(t, err2)
+
+				err = pub2.Start(u.Scheme, u.Host)
+				require.NoError(t, err)
+
+				_, err = pub2.Announce(u, &description.Session{Medias: []*description.Media{pub2.medi}})
+				require.NoError(t, err)
+
+				_, err = pub2.Setup(u, pub2.medi, 0, 0)
+				require.NoError(t, err)
+
+			case "incompatible tracks":
+				err = pub2.StartRecording("rtsp://localhost:8554/teststream",
+					&description.Session{Medias: []*description.Media{pub2.medi, test.UniqueMediaMPEG4Audio()}})
+				require.Error(t, err)
+
+			default:
+				err = pub2.StartRecording("rtsp://localhost:8554/teststream",
+					&description.Session{Medias: []*description.Media{pub2.medi}})
+				require.NoError(t, err)
+			}
+
+			recv := make(chan *rtp.Packet, 100)
+
+			c := gortsplib.Client{}
+
+			u, err := base.ParseURL("rtsp://localhost:8554/teststream")
+			require.NoError(t, err)
+
+			err = c.Start(u.Scheme, u.Host)
+			require.NoError(t, err)
+			defer c.Close()
+
+			desc, _, err := c.Describe(u)
+			require.NoError(t, err)
+
+			err = c.SetupAll(desc.BaseURL, desc.Medias)
+			require.NoError(t, err)
+
+			c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+				select {
+				case recv <- pkt:
+				default:
+				}
+			})
+
+			_, err = c.Play(nil)
+			require.NoError(t, err)
+
+			before := writeUntilReceived(t, pub1, recv)
+
+			var after *rtp.Packet
+
+			switch ca {
+			case "primary disconnect":
+				pub1.Close()
+				after = writeUntilReceived(t, pub2, recv)
+
+			case "primary stall":
+				// pub1 stops writing, the backup publisher takes its place
+				after = writeUntilReceived(t, pub2, recv)
+
+				err = pub1.Wait()
+				require.Error(t, err)
+
+			case "backup not started":
+				pub1.Close()
+				time.Sleep(500 * time.Millisecond)
+
+				_, err = pub2.Record()
+				require.NoError(t, err)
+
+				after = writeUntilReceived(t, pub2, recv)
+
+			case "incompatible tracks":
+				after = writeUntilReceived(t, pub1, recv)
+
+			case "ex-backup disconnect":
+				pub1.Close()
+				writeUntilReceived(t, pub2, recv)
+
+				pub3 := &testPublisher{medi: test.UniqueMediaH264(), payload: []byte{5, 3, 3, 3}}
+
+				err = pub3.StartRecording("rtsp://localhost:8554/teststream",
+					&description.Session{Medias: []*description.Media{pub3.medi}})
+				require.NoError(t, err)
+				defer pub3.Close()
+
+				pub2.Close()
+				after = writeUntilReceived(t, pub3, recv)
+			}
+
+			require.NotNil(t, before)
+			require.NotNil(t, after)
+
+			// timestamps keep increasing after the switch
+			require.Greater(t, int32(after.Timestamp-before.Timestamp), int32(0))
+		})
+	}
+}
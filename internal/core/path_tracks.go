@@ -0,0 +1,111 @@
+package core
+
+import (
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
+
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/stream"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// trackProps contains properties of a track that are decoded from its format.
+type trackProps struct {
+	width        *int
+	height       *int
+	fps          *float64
+	profile      *int
+	level        *int
+	sampleRate   *int
+	channelCount *int
+}
+
+func decodeTrackProps(forma format.Format) trackProps {
+	var props trackProps
+
+	switch forma := forma.(type) {
+	case *format.H264:
+		sps, _ := forma.SafeParams()
+		if sps != nil {
+			var s h264.SPS
+			err := s.Unmarshal(sps)
+			if err == nil {
+				props.width = intPtr(s.Width())
+				props.height = intPtr(s.Height())
+				props.profile = intPtr(int(s.ProfileIdc))
+				props.level = intPtr(int(s.LevelIdc))
+				if fps := s.FPS(); fps != 0 {
+					props.fps = &fps
+				}
+			}
+		}
+
+	case *format.H265:
+		_, sps, _ := forma.SafeParams()
+		if sps != nil {
+			var s h265.SPS
+			err := s.Unmarshal(sps)
+			if err == nil {
+				props.width = intPtr(s.Width())
+				props.height = intPtr(s.Height())
+				props.profile = intPtr(int(s.ProfileTierLevel.GeneralProfileIdc))
+				props.level = intPtr(int(s.ProfileTierLevel.GeneralLevelIdc))
+				if fps := s.FPS(); fps != 0 {
+					props.fps = &fps
+				}
+			}
+		}
+
+	case *format.MPEG4Audio:
+		if forma.Config != nil {
+			props.sampleRate = intPtr(forma.Config.SampleRate)
+			props.channelCount = intPtr(forma.Config.ChannelCount)
+		}
+
+	case *format.Opus:
+		props.sampleRate = intPtr(48000)
+		props.channelCount = intPtr(forma.ChannelCount)
+
+	case *format.G711:
+		props.sampleRate = intPtr(forma.SampleRate)
+		props.channelCount = intPtr(forma.ChannelCount)
+
+	case *format.G722:
+		props.sampleRate = intPtr(16000)
+		props.channelCount = intPtr(1)
+
+	case *format.LPCM:
+		props.sampleRate = intPtr(forma.SampleRate)
+		props.channelCount = intPtr(forma.ChannelCount)
+	}
+
+	return props
+}
+
+func apiPathTracks(strm *stream.Stream) []defs.APIPathTrack {
+	ret := []defs.APIPathTrack{}
+
+	for _, medi := range strm.Desc().Medias {
+		for _, forma := range medi.Formats {
+			props := decodeTrackProps(forma)
+
+			ret = append(ret, defs.APIPathTrack{
+				Codec:        forma.Codec(),
+				Width:        props.width,
+				Height:       props.height,
+				FPS:          props.fps,
+				Profile:      props.profile,
+				Level:        props.level,
+				SampleRate:   props.sampleRate,
+				ChannelCount: props.channelCount,
+				Bitrate:      strm.FormatStats(medi, forma).Bitrate,
+			})
+		}
+	}
+
+	return ret
+}
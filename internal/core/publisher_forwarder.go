@@ -0,0 +1,168 @@
+package core
+
+import (
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
+	"github.com/pion/rtp"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+// minimum distance between the last unit of the previous publisher
+// and the first unit of the backup publisher.
+const minForwardGap = 40 * time.Millisecond
+
+type forwardedUnit interface {
+	SetPTS(int64)
+	SetRTPPackets([]*rtp.Packet)
+}
+
+// descsAreCompatible checks whether two sessions have the same tracks,
+// in the same order.
+func descsAreCompatible(desc1 *description.Session, desc2 *description.Session) bool {
+	if len(desc1.Medias) != len(desc2.Medias) {
+		return false
+	}
+
+	for i, medi := range desc1.Medias {
+		if len(medi.Formats) != len(desc2.Medias[i].Formats) {
+			return false
+		}
+
+		for j, forma := range medi.Formats {
+			if forma.Codec() != desc2.Medias[i].Formats[j].Codec() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func isVideoFormat(forma format.Format) bool {
+	switch forma.(type) {
+	case *format.H264, *format.H265:
+		return true
+	}
+	return false
+}
+
+func isKeyFrame(u unit.Unit) bool {
+	switch tu := u.(type) {
+	case *unit.H264:
+		return tu.AU != nil && h264.IDRPresent(tu.AU)
+
+	case *unit.H265:
+		return tu.AU != nil && h265.IsRandomAccess(tu.AU)
+	}
+	return false
+}
+
+func durationToTimestamp(d time.Duration, clockRate int) int64 {
+	return int64(d.Seconds() * float64(clockRate))
+}
+
+func timestampToDuration(t int64, clockRate int) time.Duration {
+	return time.Duration(float64(t) / float64(clockRate) * float64(time.Second))
+}
+
+// publisherForwarder writes the stream of a publisher into the stream of the path,
+// in order to replace publishers without disconnecting readers.
+// Forwarding starts with a key frame, and timestamps are shifted in order to
+// continue the ones of the previous publisher.
+type publisherForwarder struct {
+	src    *stream.Stream
+	dest   *stream.Stream
+	parent logger.Writer
+
+	started bool
+	offset  time.Duration
+}
+
+func (f *publisherForwarder) initialize() {
+	hasVideo := false
+	for _, medi := range f.src.Desc().Medias {
+		for _, forma := range medi.Formats {
+			if isVideoFormat(forma) {
+				hasVideo = true
+			}
+		}
+	}
+
+	for i, srcMedia := range f.src.Desc().Medias {
+		dstMedia := f.dest.Desc().Medias[i]
+
+		for j, srcFormat := range srcMedia.Formats {
+			dstFormat := dstMedia.Formats[j]
+
+			f.src.AddReader(f, srcMedia, srcFormat, func(u unit.Unit) error {
+				if !f.started {
+					if hasVideo && !isKeyFrame(u) {
+						return nil
+					}
+					f.start(dstMedia, dstFormat, u)
+				}
+
+				f.forward(dstMedia, dstFormat, u)
+				return nil
+			})
+		}
+	}
+
+	f.src.StartReader(f)
+}
+
+func (f *publisherForwarder) close() {
+	f.src.RemoveReader(f)
+	f.src.Close()
+}
+
+// Log implements logger.Writer.
+func (f *publisherForwarder) Log(level logger.Level, format string, args ...interface{}) {
+	f.parent.Log(level, "[forwarder] "+format, args...)
+}
+
+func (f *publisherForwarder) start(dstMedia *description.Media, dstFormat format.Format, u unit.Unit) {
+	f.started = true
+
+	stats := f.dest.FormatStats(dstMedia, dstFormat)
+	if stats.Units != 0 {
+		clockRate := dstFormat.ClockRate()
+
+		// the first forwarded unit must come after the last unit written by the previous publisher,
+		// otherwise DTS extractors of readers would fail.
+		gap := time.Since(f.dest.LastReceived())
+		if gap < minForwardGap {
+			gap = minForwardGap
+		}
+
+		f.offset = timestampToDuration(stats.LastPTS, clockRate) + gap - timestampToDuration(u.GetPTS(), clockRate)
+
+		f.Log(logger.Info, "backup publisher is now active")
+	}
+}
+
+func (f *publisherForwarder) forward(dstMedia *description.Media, dstFormat format.Format, u unit.Unit) {
+	pts := u.GetPTS() + durationToTimestamp(f.offset, dstFormat.ClockRate())
+
+	// generic units can only be routed as RTP packets
+	if tu, ok := u.(*unit.Generic); ok {
+		for _, pkt := range tu.RTPPackets {
+			f.dest.WriteRTPPacket(dstMedia, dstFormat, pkt, tu.NTP, pts)
+		}
+		return
+	}
+
+	// RTP packets are generated again by the destination stream
+	fu := u.(forwardedUnit)
+	fu.SetPTS(pts)
+	fu.SetRTPPackets(nil)
+
+	f.dest.WriteUnit(dstMedia, dstFormat, u)
+}
@@ -2,25 +2,37 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	bondedsource "github.com/bluenviron/mediamtx/internal/staticsources/bonded"
 	hlssource "github.com/bluenviron/mediamtx/internal/staticsources/hls"
+	recordingsource "github.com/bluenviron/mediamtx/internal/staticsources/recording"
 	rpicamerasource "github.com/bluenviron/mediamtx/internal/staticsources/rpicamera"
 	rtmpsource "github.com/bluenviron/mediamtx/internal/staticsources/rtmp"
+	rtpsource "github.com/bluenviron/mediamtx/internal/staticsources/rtp"
 	rtspsource "github.com/bluenviron/mediamtx/internal/staticsources/rtsp"
 	srtsource "github.com/bluenviron/mediamtx/internal/staticsources/srt"
 	udpsource "github.com/bluenviron/mediamtx/internal/staticsources/udp"
 	webrtcsource "github.com/bluenviron/mediamtx/internal/staticsources/webrtc"
+	"github.com/bluenviron/mediamtx/internal/stream"
 )
 
+var errStaticSourceProbeSucceeded = errors.New("primary source is available")
+
 const (
 	staticSourceHandlerRetryPause = 5 * time.Second
+
+	// period of the check performed to detect stalled sources.
+	staticSourceHandlerStallCheckPeriod = 1 * time.Second
 )
 
 func resolveSource(s string, matches []string, query string) string {
@@ -37,30 +49,70 @@ func resolveSource(s string, matches []string, query string) string {
 
 type staticSourceHandlerParent interface {
 	logger.Writer
+	ExternalCmdEnv() externalcmd.Environment
 	staticSourceHandlerSetReady(context.Context, defs.PathSourceStaticSetReadyReq)
 	staticSourceHandlerSetNotReady(context.Context, defs.PathSourceStaticSetNotReadyReq)
 }
 
+// staticSourceProbe is the parent of a static source that is started
+// to check whether the primary source is available again.
+type staticSourceProbe struct {
+	parent *staticSourceHandler
+
+	mutex sync.Mutex
+	ready bool
+}
+
+// Log implements logger.Writer.
+func (p *staticSourceProbe) Log(level logger.Level, format string, args ...interface{}) {
+	p.parent.Log(level, "[primary source check] "+format, args...)
+}
+
+// SetReady implements defs.StaticSourceParent.
+func (p *staticSourceProbe) SetReady(_ defs.PathSourceStaticSetReadyReq) defs.PathSourceStaticSetReadyRes {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.ready = true
+	return defs.PathSourceStaticSetReadyRes{Err: errStaticSourceProbeSucceeded}
+}
+
+// SetNotReady implements defs.StaticSourceParent.
+func (*staticSourceProbe) SetNotReady(_ defs.PathSourceStaticSetNotReadyReq) {
+}
+
+func (p *staticSourceProbe) succeeded() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.ready
+}
+
 // staticSourceHandler is a static source handler.
 type staticSourceHandler struct {
-	conf           *conf.Path
-	logLevel       conf.LogLevel
-	readTimeout    conf.Duration
-	writeTimeout   conf.Duration
-	writeQueueSize int
-	matches        []string
-	parent         staticSourceHandlerParent
-
-	ctx       context.Context
-	ctxCancel func()
-	instance  defs.StaticSource
-	running   bool
-	query     string
+	conf            *conf.Path
+	logLevel        conf.LogLevel
+	readTimeout     conf.Duration
+	writeTimeout    conf.Duration
+	writeQueueSize  int
+	matches         []string
+	externalCmdPool *externalcmd.Pool
+	pathManager     mediaSubPathSourcePathManager
+	parent          staticSourceHandlerParent
+
+	ctx          context.Context
+	ctxCancel    func()
+	instances    []defs.StaticSource
+	cur          int
+	curMutex     sync.RWMutex
+	lastErr      error
+	lastErrMutex sync.Mutex
+	running      bool
+	query        string
 
 	// in
 	chReloadConf          chan *conf.Path
 	chInstanceSetReady    chan defs.PathSourceStaticSetReadyReq
 	chInstanceSetNotReady chan defs.PathSourceStaticSetNotReadyReq
+	chInstanceStream      chan *stream.Stream
 
 	// out
 	done chan struct{}
@@ -70,55 +122,86 @@ func (s *staticSourceHandler) initialize() {
 	s.chReloadConf = make(chan *conf.Path)
 	s.chInstanceSetReady = make(chan defs.PathSourceStaticSetReadyReq)
 	s.chInstanceSetNotReady = make(chan defs.PathSourceStaticSetNotReadyReq)
+	s.chInstanceStream = make(chan *stream.Stream)
+
+	s.instances = make([]defs.StaticSource, len(s.sources()))
+	for i, source := range s.sources() {
+		s.instances[i] = s.newInstance(source, s)
+	}
+}
 
+func (s *staticSourceHandler) newInstance(source string, parent defs.StaticSourceParent) defs.StaticSource {
 	switch {
-	case strings.HasPrefix(s.conf.Source, "rtsp://") ||
-		strings.HasPrefix(s.conf.Source, "rtsps://"):
-		s.instance = &rtspsource.Source{
+	case strings.HasPrefix(source, "rtsp://") ||
+		strings.HasPrefix(source, "rtsps://"):
+		return &rtspsource.Source{
 			ReadTimeout:    s.readTimeout,
 			WriteTimeout:   s.writeTimeout,
 			WriteQueueSize: s.writeQueueSize,
-			Parent:         s,
+			Parent:         parent,
 		}
 
-	case strings.HasPrefix(s.conf.Source, "rtmp://") ||
-		strings.HasPrefix(s.conf.Source, "rtmps://"):
-		s.instance = &rtmpsource.Source{
+	case strings.HasPrefix(source, "rtmp://") ||
+		strings.HasPrefix(source, "rtmps://"):
+		return &rtmpsource.Source{
 			ReadTimeout:  s.readTimeout,
 			WriteTimeout: s.writeTimeout,
-			Parent:       s,
+			Parent:       parent,
+		}
+
+	case strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "https://"):
+		return &hlssource.Source{
+			ReadTimeout: s.readTimeout,
+			Parent:      parent,
 		}
 
-	case strings.HasPrefix(s.conf.Source, "http://") ||
-		strings.HasPrefix(s.conf.Source, "https://"):
-		s.instance = &hlssource.Source{
+	case strings.HasPrefix(source, "udp://"):
+		return &udpsource.Source{
 			ReadTimeout: s.readTimeout,
-			Parent:      s,
+			Parent:      parent,
 		}
 
-	case strings.HasPrefix(s.conf.Source, "udp://"):
-		s.instance = &udpsource.Source{
+	case strings.HasPrefix(source, "file://"):
+		return &rtpsource.Source{
 			ReadTimeout: s.readTimeout,
-			Parent:      s,
+			Parent:      parent,
 		}
 
-	case strings.HasPrefix(s.conf.Source, "srt://"):
-		s.instance = &srtsource.Source{
+	case strings.HasPrefix(source, "srt://"):
+		return &srtsource.Source{
 			ReadTimeout: s.readTimeout,
-			Parent:      s,
+			Parent:      parent,
+		}
+
+	case strings.HasPrefix(source, "whep://") ||
+		strings.HasPrefix(source, "wheps://"):
+		return &webrtcsource.Source{
+			ReadTimeout: s.readTimeout,
+			Parent:      parent,
+		}
+
+	case strings.HasPrefix(source, "recording://"):
+		return &recordingsource.Source{
+			Parent: parent,
+		}
+
+	case strings.HasPrefix(source, mediaSubPathSourcePrefix):
+		return &mediaSubPathSource{
+			pathManager: s.pathManager,
+			parent:      parent,
 		}
 
-	case strings.HasPrefix(s.conf.Source, "whep://") ||
-		strings.HasPrefix(s.conf.Source, "wheps://"):
-		s.instance = &webrtcsource.Source{
+	case source == "bonded":
+		return &bondedsource.Source{
 			ReadTimeout: s.readTimeout,
-			Parent:      s,
+			Parent:      parent,
 		}
 
-	case s.conf.Source == "rpiCamera":
-		s.instance = &rpicamerasource.Source{
+	case source == "rpiCamera":
+		return &rpicamerasource.Source{
 			LogLevel: s.logLevel,
-			Parent:   s,
+			Parent:   parent,
 		}
 
 	default:
@@ -126,6 +209,36 @@ func (s *staticSourceHandler) initialize() {
 	}
 }
 
+// sources returns the primary source followed by fallback sources.
+func (s *staticSourceHandler) sources() []string {
+	return append([]string{s.conf.Source}, s.conf.SourceFallbacks...)
+}
+
+func (s *staticSourceHandler) currentInstance() defs.StaticSource {
+	s.curMutex.RLock()
+	defer s.curMutex.RUnlock()
+	return s.instances[s.cur]
+}
+
+func (s *staticSourceHandler) setCurrent(cur int) {
+	s.curMutex.Lock()
+	defer s.curMutex.Unlock()
+	s.cur = cur
+}
+
+// lastError returns the last error of the source.
+func (s *staticSourceHandler) lastError() error {
+	s.lastErrMutex.Lock()
+	defer s.lastErrMutex.Unlock()
+	return s.lastErr
+}
+
+func (s *staticSourceHandler) setLastError(err error) {
+	s.lastErrMutex.Lock()
+	defer s.lastErrMutex.Unlock()
+	s.lastErr = err
+}
+
 func (s *staticSourceHandler) close(reason string) {
 	s.stop(reason)
 }
@@ -140,7 +253,7 @@ func (s *staticSourceHandler) start(onDemand bool, query string) {
 	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
 	s.done = make(chan struct{})
 
-	s.instance.Log(logger.Info, "started%s",
+	s.currentInstance().Log(logger.Info, "started%s",
 		func() string {
 			if onDemand {
 				return " on demand"
@@ -158,7 +271,7 @@ func (s *staticSourceHandler) stop(reason string) {
 
 	s.running = false
 
-	s.instance.Log(logger.Info, "stopped: %s", reason)
+	s.currentInstance().Log(logger.Info, "stopped: %s", reason)
 
 	s.ctxCancel()
 
@@ -174,17 +287,21 @@ func (s *staticSourceHandler) Log(level logger.Level, format string, args ...int
 func (s *staticSourceHandler) run() {
 	defer close(s.done)
 
+	// always start from the primary source
+	s.setCurrent(0)
+
 	var runCtx context.Context
 	var runCtxCancel func()
 	runErr := make(chan error)
 	runReloadConf := make(chan *conf.Path)
 
 	recreate := func() {
-		resolvedSource := resolveSource(s.conf.Source, s.matches, s.query)
+		instance := s.currentInstance()
+		resolvedSource := resolveSource(s.sources()[s.cur], s.matches, s.query)
 
 		runCtx, runCtxCancel = context.WithCancel(context.Background())
 		go func() {
-			runErr <- s.instance.Run(defs.StaticSourceRunParams{
+			runErr <- instance.Run(defs.StaticSourceRunParams{
 				Context:        runCtx,
 				ResolvedSource: resolvedSource,
 				Conf:           s.conf,
@@ -198,18 +315,101 @@ func (s *staticSourceHandler) run() {
 	recreating := false
 	recreateTimer := emptyTimer()
 
+	// stall detection
+	var stallStream *stream.Stream
+	var stallTicker *time.Ticker
+	var stallCheck <-chan time.Time
+	var lastBytesReceived uint64
+	var lastBytesReceivedTime time.Time
+	var stallErr error
+
+	stopStallCheck := func() {
+		if stallTicker != nil {
+			stallTicker.Stop()
+			stallTicker = nil
+			stallCheck = nil
+			stallStream = nil
+		}
+	}
+	defer stopStallCheck()
+
+	// fallback sources
+	failures := 0
+	switchingToPrimary := false
+	probing := false
+	probeTimer := emptyTimer()
+	probeCtxCancel := func() {}
+	probeResult := make(chan bool)
+
 	for {
 		select {
 		case err := <-runErr:
 			runCtxCancel()
-			s.instance.Log(logger.Error, err.Error())
+			stopStallCheck()
+
+			if switchingToPrimary {
+				switchingToPrimary = false
+				failures = 0
+				s.setCurrent(0)
+				s.Log(logger.Info, "primary source is available, switching back to it")
+				recreate()
+				continue
+			}
+
+			if stallErr != nil {
+				err = stallErr
+				stallErr = nil
+			}
+			s.currentInstance().Log(logger.Error, err.Error())
+			s.setLastError(err)
+
+			failures++
+			if len(s.conf.SourceFallbacks) != 0 && failures >= s.conf.SourceFallbackAfter {
+				failures = 0
+				next := s.cur + 1
+				if next >= len(s.instances) {
+					next = 1
+				}
+				s.setCurrent(next)
+				s.Log(logger.Warn, "switching to fallback source %d", next)
+
+				if !probing {
+					probeTimer = time.NewTimer(staticSourceHandlerRetryPause)
+				}
+			}
+
 			recreating = true
 			recreateTimer = time.NewTimer(staticSourceHandlerRetryPause)
 
 		case req := <-s.chInstanceSetReady:
 			s.parent.staticSourceHandlerSetReady(s.ctx, req)
 
+		case strm := <-s.chInstanceStream:
+			failures = 0
+
+			if s.conf.SourceStallTimeout > 0 {
+				stopStallCheck()
+				stallStream = strm
+				lastBytesReceived = strm.BytesReceived()
+				lastBytesReceivedTime = time.Now()
+				stallTicker = time.NewTicker(staticSourceHandlerStallCheckPeriod)
+				stallCheck = stallTicker.C
+			}
+
+		case <-stallCheck:
+			if v := stallStream.BytesReceived(); v != lastBytesReceived {
+				lastBytesReceived = v
+				lastBytesReceivedTime = time.Now()
+			} else if time.Since(lastBytesReceivedTime) >= time.Duration(s.conf.SourceStallTimeout) {
+				stopStallCheck()
+				stallErr = fmt.Errorf("source stalled: no data received for %v, restarting",
+					time.Duration(s.conf.SourceStallTimeout))
+				s.onStall()
+				runCtxCancel()
+			}
+
 		case req := <-s.chInstanceSetNotReady:
+			stopStallCheck()
 			s.parent.staticSourceHandlerSetNotReady(s.ctx, req)
 
 		case newConf := <-s.chReloadConf:
@@ -229,20 +429,96 @@ func (s *staticSourceHandler) run() {
 			recreate()
 			recreating = false
 
+		case <-probeTimer.C:
+			var probeCtx context.Context
+			probeCtx, probeCtxCancel = context.WithCancel(context.Background())
+			probing = true
+			go s.runProbe(probeCtx, s.conf, s.query, probeResult)
+
+		case ok := <-probeResult:
+			probeCtxCancel()
+			probing = false
+
+			switch {
+			case s.cur == 0: // primary has already been restored
+
+			case ok:
+				if recreating {
+					recreateTimer.Stop()
+					recreating = false
+					failures = 0
+					s.setCurrent(0)
+					s.Log(logger.Info, "primary source is available, switching back to it")
+					recreate()
+				} else {
+					switchingToPrimary = true
+					runCtxCancel()
+				}
+
+			default:
+				probeTimer = time.NewTimer(staticSourceHandlerRetryPause)
+			}
+
 		case <-s.ctx.Done():
 			if !recreating {
 				runCtxCancel()
 				<-runErr
 			}
+
+			probeTimer.Stop()
+			probeCtxCancel()
+			if probing {
+				<-probeResult
+			}
 			return
 		}
 	}
 }
 
+// runProbe checks whether the primary source is available,
+// by starting a dedicated instance that is stopped as soon as it becomes ready.
+func (s *staticSourceHandler) runProbe(
+	ctx context.Context,
+	pathConf *conf.Path,
+	query string,
+	result chan<- bool,
+) {
+	probe := &staticSourceProbe{parent: s}
+	instance := s.newInstance(pathConf.Source, probe)
+
+	instance.Run(defs.StaticSourceRunParams{ //nolint:errcheck
+		Context:        ctx,
+		ResolvedSource: resolveSource(pathConf.Source, s.matches, query),
+		Conf:           pathConf,
+		ReloadConf:     make(chan *conf.Path),
+	})
+
+	result <- probe.succeeded()
+}
+
+func (s *staticSourceHandler) onStall() {
+	if s.conf.RunOnSourceStall != "" {
+		env := s.parent.ExternalCmdEnv()
+		desc := s.currentInstance().APISourceDescribe()
+		env["MTX_SOURCE_TYPE"] = desc.Type
+		env["MTX_SOURCE_ID"] = desc.ID
+
+		s.currentInstance().Log(logger.Info, "runOnSourceStall command launched")
+		externalcmd.NewCmd(
+			s.externalCmdPool,
+			s.conf.RunOnSourceStall,
+			false,
+			env,
+			nil)
+	}
+}
+
 func (s *staticSourceHandler) reloadConf(newConf *conf.Path) {
 	ctx := s.ctx
 
+	// when the source is not running, the configuration can be replaced directly
 	if !s.running {
+		s.conf = newConf
 		return
 	}
 
@@ -256,7 +532,7 @@ func (s *staticSourceHandler) reloadConf(newConf *conf.Path) {
 
 // APISourceDescribe instanceements source.
 func (s *staticSourceHandler) APISourceDescribe() defs.APIPathSourceOrReader {
-	return s.instance.APISourceDescribe()
+	return s.currentInstance().APISourceDescribe()
 }
 
 // setReady is called by a staticSource.
@@ -267,7 +543,12 @@ func (s *staticSourceHandler) SetReady(req defs.PathSourceStaticSetReadyReq) def
 		res := <-req.Res
 
 		if res.Err == nil {
-			s.instance.Log(logger.Info, "ready: %s", defs.MediasInfo(req.Desc.Medias))
+			s.currentInstance().Log(logger.Info, "ready: %s", defs.MediasInfo(req.Desc.Medias))
+
+			select {
+			case s.chInstanceStream <- res.Stream:
+			case <-s.ctx.Done():
+			}
 		}
 
 		return res
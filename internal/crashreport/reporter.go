@@ -0,0 +1,231 @@
+// Package crashreport contains the crash reporter.
+package crashreport
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+const (
+	// name of the file that receives the output of crashes that are not recovered.
+	runtimeCrashFileName = "runtime_crash.log"
+
+	// name of the file that receives recent log entries,
+	// which are attached to reports of crashes that are not recovered.
+	recentLogsFileName = "recent_logs.log"
+
+	// period of the copy of recent log entries into recentLogsFileName.
+	recentLogsDumpPeriod = 1 * time.Second
+
+	uploadTimeout = 10 * time.Second
+)
+
+var timeNow = time.Now
+
+type recentEntriesProvider interface {
+	RecentEntries() []byte
+}
+
+// Reporter writes crash reports to disk and optionally uploads them.
+type Reporter struct {
+	Directory string
+	URL       string
+	Version   string
+	Logs      recentEntriesProvider
+	Parent    logger.Writer
+
+	runtimeCrashFile *os.File
+	terminate        chan struct{}
+	done             chan struct{}
+}
+
+// Initialize initializes a Reporter.
+func (r *Reporter) Initialize() error {
+	err := os.MkdirAll(r.Directory, 0o755)
+	if err != nil {
+		return err
+	}
+
+	r.collectRuntimeCrash()
+
+	// crashes that are not recovered (for instance, the ones happening
+	// in secondary goroutines) are written by the runtime into a dedicated file,
+	// that is collected on next startup.
+	r.runtimeCrashFile, err = os.OpenFile(filepath.Join(r.Directory, runtimeCrashFileName),
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	err = debug.SetCrashOutput(r.runtimeCrashFile, debug.CrashOptions{})
+	if err != nil {
+		r.runtimeCrashFile.Close()
+		return err
+	}
+
+	debug.SetTraceback("all")
+
+	r.terminate = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go r.runRecentLogsDumper()
+
+	r.Log(logger.Info, "enabled, reports are written into %s", r.Directory)
+
+	return nil
+}
+
+// Close closes the Reporter.
+func (r *Reporter) Close() {
+	close(r.terminate)
+	<-r.done
+
+	debug.SetCrashOutput(nil, debug.CrashOptions{}) //nolint:errcheck
+	r.runtimeCrashFile.Close()
+	os.Remove(r.runtimeCrashFile.Name())
+	os.Remove(filepath.Join(r.Directory, recentLogsFileName))
+}
+
+// Log implements logger.Writer.
+func (r *Reporter) Log(level logger.Level, format string, args ...interface{}) {
+	r.Parent.Log(level, "[crash reporter] "+format, args...)
+}
+
+// Report writes a crash report that contains the panic value,
+// the stack traces of all goroutines and recent log entries,
+// then uploads it if an URL is provided.
+// It is meant to be called after a recover().
+func (r *Reporter) Report(panicValue interface{}) {
+	buf := make([]byte, 1<<20)
+	n := runtime.Stack(buf, true)
+
+	var report bytes.Buffer
+	fmt.Fprintf(&report, "MediaMTX %s\n", r.Version)
+	fmt.Fprintf(&report, "%s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&report, "panic: %v\n\n", panicValue)
+	report.Write(buf[:n])
+
+	if r.Logs != nil {
+		if logs := r.Logs.RecentEntries(); len(logs) != 0 {
+			report.WriteString("\nrecent log entries:\n\n")
+			report.Write(logs)
+		}
+	}
+
+	r.writeAndUpload(report.Bytes(), timeNow())
+}
+
+// runRecentLogsDumper periodically copies recent log entries to disk,
+// since crashes that are not recovered terminate the process
+// without giving the chance of reading them from memory.
+func (r *Reporter) runRecentLogsDumper() {
+	defer close(r.done)
+
+	if r.Logs == nil {
+		return
+	}
+
+	fpath := filepath.Join(r.Directory, recentLogsFileName)
+	var prev []byte
+
+	t := time.NewTicker(recentLogsDumpPeriod)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			logs := r.Logs.RecentEntries()
+			if bytes.Equal(logs, prev) {
+				continue
+			}
+
+			// write into a temporary file, in order not to leave a truncated file
+			// if the process crashes in the meanwhile.
+			err := os.WriteFile(fpath+".tmp", logs, 0o644)
+			if err == nil {
+				err = os.Rename(fpath+".tmp", fpath)
+			}
+			if err != nil {
+				r.Log(logger.Warn, "unable to write recent log entries: %v", err)
+				continue
+			}
+
+			prev = logs
+
+		case <-r.terminate:
+			return
+		}
+	}
+}
+
+func (r *Reporter) collectRuntimeCrash() {
+	fpath := filepath.Join(r.Directory, runtimeCrashFileName)
+
+	fi, err := os.Stat(fpath)
+	if err != nil || fi.Size() == 0 {
+		return
+	}
+
+	byts, err := os.ReadFile(fpath)
+	if err != nil {
+		return
+	}
+
+	r.Log(logger.Warn, "the previous run crashed")
+
+	var report bytes.Buffer
+	fmt.Fprintf(&report, "MediaMTX %s\n", r.Version)
+	fmt.Fprintf(&report, "%s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	report.Write(byts)
+
+	if logs, err := os.ReadFile(filepath.Join(r.Directory, recentLogsFileName)); err == nil && len(logs) != 0 {
+		fmt.Fprintf(&report, "\nrecent log entries (written up to %v before the crash):\n\n", recentLogsDumpPeriod)
+		report.Write(logs)
+	}
+
+	r.writeAndUpload(report.Bytes(), fi.ModTime())
+}
+
+func (r *Reporter) writeAndUpload(report []byte, t time.Time) {
+	fpath := filepath.Join(r.Directory, "crash_"+t.Format("2006-01-02_15-04-05")+".log")
+
+	err := os.WriteFile(fpath, report, 0o644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write crash report: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "crash report written into %s\n", fpath)
+	}
+
+	if r.URL != "" {
+		err = r.upload(report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to upload crash report: %v\n", err)
+		}
+	}
+}
+
+func (r *Reporter) upload(report []byte) error {
+	hc := &http.Client{
+		Timeout: uploadTimeout,
+	}
+
+	res, err := hc.Post(r.URL, "text/plain", bytes.NewReader(report))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("bad status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
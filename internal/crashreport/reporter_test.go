@@ -0,0 +1,144 @@
+package crashreport
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+type dummyLogs struct{}
+
+func (dummyLogs) RecentEntries() []byte {
+	return []byte("2009/05/20 22:15:25 DEB my debug entry\n")
+}
+
+func TestReporter(t *testing.T) {
+	timeNow = func() time.Time {
+		return time.Date(2009, 5, 20, 22, 15, 25, 0, time.Local)
+	}
+	defer func() { timeNow = time.Now }()
+
+	dir, err := os.MkdirTemp("", "mediamtx-crashreport")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	uploaded := make(chan []byte, 1)
+
+	httpServ := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			byts, err2 := io.ReadAll(r.Body)
+			require.NoError(t, err2)
+			uploaded <- byts
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go httpServ.Serve(ln)
+	defer httpServ.Shutdown(context.Background())
+
+	r := &Reporter{
+		Directory: dir,
+		URL:       "http://" + ln.Addr().String() + "/report",
+		Version:   "v1.2.3",
+		Logs:      dummyLogs{},
+		Parent:    test.NilLogger,
+	}
+	err = r.Initialize()
+	require.NoError(t, err)
+	defer r.Close()
+
+	r.Report("my panic")
+
+	byts, err := os.ReadFile(filepath.Join(dir, "crash_2009-05-20_22-15-25.log"))
+	require.NoError(t, err)
+
+	require.Contains(t, string(byts), "MediaMTX v1.2.3\n")
+	require.Contains(t, string(byts), "panic: my panic\n")
+	require.Contains(t, string(byts), "TestReporter")
+	require.Contains(t, string(byts), "recent log entries:\n\n2009/05/20 22:15:25 DEB my debug entry\n")
+
+	require.Equal(t, byts, <-uploaded)
+}
+
+func TestReporterRuntimeCrash(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-crashreport")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, runtimeCrashFileName)
+
+	err = os.WriteFile(fpath, []byte("panic: previous panic\n"), 0o644)
+	require.NoError(t, err)
+
+	modTime := time.Date(2010, 1, 2, 3, 4, 5, 0, time.Local)
+	err = os.Chtimes(fpath, modTime, modTime)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, recentLogsFileName),
+		[]byte("2009/05/20 22:15:25 DEB previous debug entry\n"), 0o644)
+	require.NoError(t, err)
+
+	r := &Reporter{
+		Directory: dir,
+		Version:   "v1.2.3",
+		Parent:    test.NilLogger,
+	}
+	err = r.Initialize()
+	require.NoError(t, err)
+
+	byts, err := os.ReadFile(filepath.Join(dir, "crash_2010-01-02_03-04-05.log"))
+	require.NoError(t, err)
+	require.Contains(t, string(byts), "panic: previous panic\n")
+	require.Contains(t, string(byts), "recent log entries (written up to 1s before the crash):\n\n"+
+		"2009/05/20 22:15:25 DEB previous debug entry\n")
+
+	r.Close()
+
+	_, err = os.Stat(fpath)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestReporterRecentLogsDump(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-crashreport")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r := &Reporter{
+		Directory: dir,
+		Version:   "v1.2.3",
+		Logs:      dummyLogs{},
+		Parent:    test.NilLogger,
+	}
+	err = r.Initialize()
+	require.NoError(t, err)
+
+	fpath := filepath.Join(dir, recentLogsFileName)
+
+	var byts []byte
+	for i := 0; i < 30; i++ {
+		byts, err = os.ReadFile(fpath)
+		if err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	require.Equal(t, "2009/05/20 22:15:25 DEB my debug entry\n", string(byts))
+
+	r.Close()
+
+	_, err = os.Stat(fpath)
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,110 @@
+// Package ddns contains a dynamic DNS updater.
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	maxBodySize    = 1024
+)
+
+// Updater periodically calls the update URL of a dynamic DNS provider.
+// Most providers detect the public IP from the source address of the request,
+// therefore the IP doesn't need to be inserted into the URL.
+type Updater struct {
+	URL      string
+	Interval conf.Duration
+	Parent   logger.Writer
+
+	ctx       context.Context
+	ctxCancel func()
+	client    *http.Client
+
+	done chan struct{}
+}
+
+// Initialize initializes an Updater.
+func (u *Updater) Initialize() {
+	u.ctx, u.ctxCancel = context.WithCancel(context.Background())
+	u.client = &http.Client{Timeout: requestTimeout}
+	u.done = make(chan struct{})
+
+	u.Log(logger.Info, "updater started")
+
+	go u.run()
+}
+
+// Close closes the Updater.
+func (u *Updater) Close() {
+	u.Log(logger.Info, "updater is shutting down")
+	u.ctxCancel()
+	<-u.done
+}
+
+// Log implements logger.Writer.
+func (u *Updater) Log(level logger.Level, format string, args ...interface{}) {
+	u.Parent.Log(level, "[DDNS] "+format, args...)
+}
+
+func (u *Updater) run() {
+	defer close(u.done)
+
+	u.update()
+
+	for {
+		select {
+		case <-time.After(time.Duration(u.Interval)):
+			u.update()
+
+		case <-u.ctx.Done():
+			return
+		}
+	}
+}
+
+func (u *Updater) update() {
+	err := u.doUpdate()
+	if err != nil {
+		u.Log(logger.Warn, "update failed: %v", err)
+		return
+	}
+
+	u.Log(logger.Debug, "update succeeded")
+}
+
+func (u *Updater) doUpdate() error {
+	req, err := http.NewRequestWithContext(u.ctx, http.MethodGet, u.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := u.client.Do(req)
+	if err != nil {
+		// the URL may contain credentials, do not print it
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return uerr.Err
+		}
+		return err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("bad status code: %d, body: %s", res.StatusCode, body)
+	}
+
+	return nil
+}
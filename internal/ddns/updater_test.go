@@ -0,0 +1,36 @@
+package ddns
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdater(t *testing.T) {
+	updated := make(chan struct{}, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/update", r.URL.Path)
+		require.Equal(t, "mydomain", r.URL.Query().Get("domains"))
+		w.Write([]byte("OK")) //nolint:errcheck
+		updated <- struct{}{}
+	}))
+	defer srv.Close()
+
+	u := &Updater{
+		URL:      srv.URL + "/update?domains=mydomain&token=mytoken",
+		Interval: conf.Duration(100 * time.Millisecond),
+		Parent:   test.NilLogger,
+	}
+	u.Initialize()
+	defer u.Close()
+
+	// first update is performed immediately, the second one after Interval
+	<-updated
+	<-updated
+}
@@ -11,6 +11,28 @@ import (
 // APIError is a generic error.
 type APIError struct {
 	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+// APIDryRun is the result of a request performed with dryRun=true.
+type APIDryRun struct {
+	KickedSessions   []uuid.UUID `json:"kickedSessions"`
+	ChangedGlobal    bool        `json:"changedGlobal"`
+	ChangedPathConfs []string    `json:"changedPathConfs"`
+	AffectedPaths    []string    `json:"affectedPaths"`
+}
+
+// APIAuthTokenSignReq is a request to sign a token.
+type APIAuthTokenSignReq struct {
+	Path     string          `json:"path"`
+	Action   conf.AuthAction `json:"action"`
+	Duration conf.Duration   `json:"duration"`
+}
+
+// APIAuthToken is a signed token.
+type APIAuthToken struct {
+	Token      string    `json:"token"`
+	Expiration time.Time `json:"expiration"`
 }
 
 // APIPathConfList is a list of path configurations.
@@ -22,8 +44,15 @@ type APIPathConfList struct {
 
 // APIPathSourceOrReader is a source or a reader.
 type APIPathSourceOrReader struct {
-	Type string `json:"type"`
-	ID   string `json:"id"`
+	Type        string                    `json:"type"`
+	ID          string                    `json:"id"`
+	TrackErrors []APIPathSourceTrackError `json:"trackErrors,omitempty"`
+}
+
+// APIPathSourceTrackError is an error that occurred while setting up a track of a source.
+type APIPathSourceTrackError struct {
+	Track string `json:"track"`
+	Error string `json:"error"`
 }
 
 // APIPath is a path.
@@ -33,12 +62,37 @@ type APIPath struct {
 	Source        *APIPathSourceOrReader  `json:"source"`
 	Ready         bool                    `json:"ready"`
 	ReadyTime     *time.Time              `json:"readyTime"`
+	EndOfStream   bool                    `json:"endOfStream"`
 	Tracks        []string                `json:"tracks"`
+	Tracks2       []APIPathTrack          `json:"tracks2"`
 	BytesReceived uint64                  `json:"bytesReceived"`
 	BytesSent     uint64                  `json:"bytesSent"`
+	GOPCacheSize  uint64                  `json:"gopCacheSize"`
+	Recorder      *APIPathRecorder        `json:"recorder"`
 	Readers       []APIPathSourceOrReader `json:"readers"`
 }
 
+// APIPathRecorder contains the state of the recorder of a path.
+type APIPathRecorder struct {
+	Writing      bool       `json:"writing"`
+	SegmentStart *time.Time `json:"segmentStart"`
+	SegmentBytes uint64     `json:"segmentBytes"`
+	LastError    *string    `json:"lastError"`
+}
+
+// APIPathTrack contains properties of a track.
+type APIPathTrack struct {
+	Codec        string   `json:"codec"`
+	Width        *int     `json:"width"`
+	Height       *int     `json:"height"`
+	FPS          *float64 `json:"fps"`
+	Profile      *int     `json:"profile"`
+	Level        *int     `json:"level"`
+	SampleRate   *int     `json:"sampleRate"`
+	ChannelCount *int     `json:"channelCount"`
+	Bitrate      uint64   `json:"bitrate"`
+}
+
 // APIPathList is a list of paths.
 type APIPathList struct {
 	ItemCount int        `json:"itemCount"`
@@ -46,12 +100,105 @@ type APIPathList struct {
 	Items     []*APIPath `json:"items"`
 }
 
+// APIPathHealth is a compact summary of the state of a path.
+type APIPathHealth struct {
+	Name          string   `json:"name"`
+	State         string   `json:"state"`
+	LastPacketAgo *float64 `json:"lastPacketAgo"`
+	Readers       int      `json:"readers"`
+	Recording     bool     `json:"recording"`
+	LastError     *string  `json:"lastError"`
+}
+
+// APIPathHealthList is a list of path health summaries.
+type APIPathHealthList struct {
+	ItemCount int              `json:"itemCount"`
+	PageCount int              `json:"pageCount"`
+	Items     []*APIPathHealth `json:"items"`
+}
+
+// APIPathMuteReq is a request to mute or unmute the medias of a path.
+// Nil fields are left unchanged.
+type APIPathMuteReq struct {
+	Audio *bool `json:"audio"`
+	Video *bool `json:"video"`
+}
+
+// APIPathMute contains the mute state of a path.
+type APIPathMute struct {
+	Audio bool `json:"audio"`
+	Video bool `json:"video"`
+}
+
+// APIPTZContinuousMoveReq is a request to move a PTZ camera.
+// Velocities range from -1 to 1.
+type APIPTZContinuousMoveReq struct {
+	Pan     float64       `json:"pan"`
+	Tilt    float64       `json:"tilt"`
+	Zoom    float64       `json:"zoom"`
+	Timeout conf.Duration `json:"timeout"`
+}
+
+// APIPTZGotoPresetReq is a request to move a PTZ camera to a preset.
+type APIPTZGotoPresetReq struct {
+	Preset string `json:"preset"`
+}
+
+// APIPTZPreset is a preset of a PTZ camera.
+type APIPTZPreset struct {
+	Token string `json:"token"`
+	Name  string `json:"name"`
+}
+
+// APIPTZPresetList is a list of presets of a PTZ camera.
+type APIPTZPresetList struct {
+	ItemCount int             `json:"itemCount"`
+	PageCount int             `json:"pageCount"`
+	Items     []*APIPTZPreset `json:"items"`
+}
+
+// APIPathDebugTrack contains diagnostics of a track.
+type APIPathDebugTrack struct {
+	Codec            string     `json:"codec"`
+	Width            *int       `json:"width"`
+	Height           *int       `json:"height"`
+	Profile          *int       `json:"profile"`
+	Level            *int       `json:"level"`
+	ParameterSets    []string   `json:"parameterSets"`
+	Units            uint64     `json:"units"`
+	KeyFrames        uint64     `json:"keyFrames"`
+	LastKeyFrameTime *time.Time `json:"lastKeyFrameTime"`
+	LastKeyFrameSize uint64     `json:"lastKeyFrameSize"`
+	LastGOPUnits     uint64     `json:"lastGOPUnits"`
+	AverageGOPUnits  float64    `json:"averageGOPUnits"`
+	TimestampJumps   uint64     `json:"timestampJumps"`
+}
+
+// APIPathDebug contains diagnostics of a path.
+type APIPathDebug struct {
+	Name   string              `json:"name"`
+	Ready  bool                `json:"ready"`
+	Tracks []APIPathDebugTrack `json:"tracks"`
+}
+
+// APIHLSSession is an HLS viewer session.
+type APIHLSSession struct {
+	ID          uuid.UUID  `json:"id"`
+	Created     time.Time  `json:"created"`
+	LastRequest time.Time  `json:"lastRequest"`
+	RemoteAddr  string     `json:"remoteAddr"`
+	UserAgent   string     `json:"userAgent"`
+	BytesSent   uint64     `json:"bytesSent"`
+	Closed      *time.Time `json:"closed"`
+}
+
 // APIHLSMuxer is an HLS muxer.
 type APIHLSMuxer struct {
-	Path        string    `json:"path"`
-	Created     time.Time `json:"created"`
-	LastRequest time.Time `json:"lastRequest"`
-	BytesSent   uint64    `json:"bytesSent"`
+	Path        string           `json:"path"`
+	Created     time.Time        `json:"created"`
+	LastRequest time.Time        `json:"lastRequest"`
+	BytesSent   uint64           `json:"bytesSent"`
+	Sessions    []*APIHLSSession `json:"sessions"`
 }
 
 // APIHLSMuxerList is a list of HLS muxers.
@@ -133,6 +280,7 @@ type APIRTSPSession struct {
 	RTPPacketsLost      uint64              `json:"rtpPacketsLost"`
 	RTPPacketsInError   uint64              `json:"rtpPacketsInError"`
 	RTPPacketsJitter    float64             `json:"rtpPacketsJitter"`
+	RTPPacketsDropped   uint64              `json:"rtpPacketsDropped"`
 	RTCPPacketsReceived uint64              `json:"rtcpPacketsReceived"`
 	RTCPPacketsSent     uint64              `json:"rtcpPacketsSent"`
 	RTCPPacketsInError  uint64              `json:"rtcpPacketsInError"`
@@ -293,6 +441,22 @@ type APISRTConnList struct {
 	Items     []*APISRTConn `json:"items"`
 }
 
+// APIRTPPushSession is a RTP push session.
+type APIRTPPushSession struct {
+	ID            uuid.UUID `json:"id"`
+	Created       time.Time `json:"created"`
+	Path          string    `json:"path"`
+	Ports         []int     `json:"ports"`
+	BytesReceived uint64    `json:"bytesReceived"`
+}
+
+// APIRTPPushSessionList is a list of RTP push sessions.
+type APIRTPPushSessionList struct {
+	ItemCount int                  `json:"itemCount"`
+	PageCount int                  `json:"pageCount"`
+	Items     []*APIRTPPushSession `json:"items"`
+}
+
 // APIWebRTCSessionState is the state of a WebRTC connection.
 type APIWebRTCSessionState string
 
@@ -315,6 +479,7 @@ type APIWebRTCSession struct {
 	Query                     string                `json:"query"`
 	BytesReceived             uint64                `json:"bytesReceived"`
 	BytesSent                 uint64                `json:"bytesSent"`
+	AudioLevel                *int                  `json:"audioLevel"`
 }
 
 // APIWebRTCSessionList is a list of WebRTC sessions.
@@ -341,3 +506,40 @@ type APIRecordingList struct {
 	PageCount int             `json:"pageCount"`
 	Items     []*APIRecording `json:"items"`
 }
+
+// APIRecordingMatchSegment is a recording segment that overlaps a time window.
+type APIRecordingMatchSegment struct {
+	Start  time.Time `json:"start"`
+	Size   uint64    `json:"size"`
+	Codecs []string  `json:"codecs"`
+}
+
+// APIRecordingMatch contains the recording segments of a path that overlap a time window.
+type APIRecordingMatch struct {
+	Name     string                      `json:"name"`
+	Segments []*APIRecordingMatchSegment `json:"segments"`
+}
+
+// APIRecordingMatchList is a list of recording matches.
+type APIRecordingMatchList struct {
+	ItemCount int                  `json:"itemCount"`
+	PageCount int                  `json:"pageCount"`
+	Items     []*APIRecordingMatch `json:"items"`
+}
+
+// APIExternalCmd is an external command.
+type APIExternalCmd struct {
+	ID        uuid.UUID `json:"id"`
+	Created   time.Time `json:"created"`
+	Command   string    `json:"command"`
+	Running   bool      `json:"running"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"lastError"`
+}
+
+// APIExternalCmdList is a list of external commands.
+type APIExternalCmdList struct {
+	ItemCount int               `json:"itemCount"`
+	PageCount int               `json:"pageCount"`
+	Items     []*APIExternalCmd `json:"items"`
+}
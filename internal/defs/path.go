@@ -47,6 +47,7 @@ type PathFindPathConfReq struct {
 type PathDescribeRes struct {
 	Path     Path
 	Stream   *stream.Stream
+	Desc     *description.Session // cached description, filled when Stream is not available yet
 	Redirect string
 	Err      error
 }
@@ -73,6 +74,7 @@ type PathAddPublisherReq struct {
 // PathRemovePublisherReq contains arguments of RemovePublisher().
 type PathRemovePublisherReq struct {
 	Author Publisher
+	Ended  bool // the publisher ended the stream cleanly
 	Res    chan struct{}
 }
 
@@ -131,5 +133,6 @@ type PathSourceStaticSetReadyReq struct {
 
 // PathSourceStaticSetNotReadyReq contains arguments of SetNotReady().
 type PathSourceStaticSetNotReadyReq struct {
-	Res chan struct{}
+	Ended bool // the source ended the stream cleanly
+	Res   chan struct{}
 }
@@ -41,6 +41,9 @@ type PathAccessRequest struct {
 	// RTSP only
 	RTSPRequest *base.Request
 	RTSPNonce   string
+
+	// filled by the path manager
+	PathConf *conf.Path
 }
 
 // ToAuthRequest converts a path access request into an authentication request.
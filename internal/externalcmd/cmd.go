@@ -4,8 +4,14 @@ package externalcmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/kballard/go-shellquote"
 )
 
 const (
@@ -20,14 +26,44 @@ type OnExitFunc func(error)
 // Environment is a Cmd environment.
 type Environment map[string]string
 
+// RestartPolicy describes whether and how a command is restarted after it exits.
+type RestartPolicy struct {
+	// restart the command when it exits.
+	Enabled bool
+
+	// pause before the first restart.
+	Pause time.Duration
+
+	// maximum pause. The pause is doubled every time the command exits
+	// before MaxPause has passed, until it reaches MaxPause.
+	MaxPause time.Duration
+}
+
+// Status is the status of a command.
+type Status struct {
+	ID        uuid.UUID
+	Created   time.Time
+	Command   string
+	Running   bool
+	Restarts  int
+	LastError string
+}
+
 // Cmd is an external command.
 type Cmd struct {
 	pool    *Pool
 	cmdstr  string
-	restart bool
+	restart RestartPolicy
 	env     Environment
 	onExit  func(error)
 
+	id        uuid.UUID
+	created   time.Time
+	mutex     sync.Mutex
+	running   bool
+	restarts  int
+	lastError error
+
 	// in
 	terminate chan struct{}
 }
@@ -39,6 +75,26 @@ func NewCmd(
 	restart bool,
 	env Environment,
 	onExit OnExitFunc,
+) *Cmd {
+	return NewCmdWithRestartPolicy(
+		pool,
+		cmdstr,
+		RestartPolicy{
+			Enabled:  restart,
+			Pause:    restartPause,
+			MaxPause: restartPause,
+		},
+		env,
+		onExit)
+}
+
+// NewCmdWithRestartPolicy allocates a Cmd with a custom restart policy.
+func NewCmdWithRestartPolicy(
+	pool *Pool,
+	cmdstr string,
+	restart RestartPolicy,
+	env Environment,
+	onExit OnExitFunc,
 ) *Cmd {
 	// replace variables in both Linux and Windows, in order to allow using the
 	// same commands on both of them.
@@ -59,10 +115,13 @@ func NewCmd(
 		restart:   restart,
 		env:       env,
 		onExit:    onExit,
+		id:        uuid.New(),
+		created:   time.Now(),
 		terminate: make(chan struct{}),
 	}
 
 	pool.wg.Add(1)
+	pool.add(e)
 
 	go e.run()
 
@@ -74,37 +133,108 @@ func (e *Cmd) Close() {
 	close(e.terminate)
 }
 
+func (e *Cmd) status() *Status {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	s := &Status{
+		ID:       e.id,
+		Created:  e.created,
+		Command:  e.cmdstr,
+		Running:  e.running,
+		Restarts: e.restarts,
+	}
+
+	if e.lastError != nil {
+		s.LastError = e.lastError.Error()
+	}
+
+	return s
+}
+
+// outputs returns the writers of standard output and standard error.
+// Returned function must be called when the command exits.
+func (e *Cmd) outputs() (io.Writer, io.Writer, func()) {
+	l := e.pool.getOutputLogger()
+	if l == nil {
+		return os.Stdout, os.Stderr, func() {}
+	}
+
+	prefix := "cmd"
+	if parts, err := shellquote.Split(e.cmdstr); err == nil && len(parts) != 0 {
+		prefix = "cmd " + filepath.Base(parts[0])
+	}
+
+	w := &outputLogger{
+		prefix: prefix,
+		parent: l,
+	}
+	return w, w, w.flush
+}
+
+func (e *Cmd) runOnce(env []string) error {
+	e.mutex.Lock()
+	e.running = true
+	e.mutex.Unlock()
+
+	err := e.runOSSpecific(env)
+
+	e.mutex.Lock()
+	e.running = false
+	if err != nil && !errors.Is(err, errTerminated) {
+		e.lastError = err
+	}
+	e.mutex.Unlock()
+
+	return err
+}
+
 func (e *Cmd) run() {
 	defer e.pool.wg.Done()
+	defer e.pool.remove(e)
 
 	env := append([]string(nil), os.Environ()...)
 	for key, val := range e.env {
 		env = append(env, key+"="+val)
 	}
 
+	pause := e.restart.Pause
+
 	for {
-		err := e.runOSSpecific(env)
+		start := time.Now()
+
+		err := e.runOnce(env)
 		if errors.Is(err, errTerminated) {
 			return
 		}
 
-		if !e.restart {
-			if err != nil {
-				e.onExit(err)
-			}
+		if err == nil {
+			err = fmt.Errorf("command exited with code 0")
+		}
+		e.onExit(err)
+
+		if !e.restart.Enabled {
 			return
 		}
 
-		if err != nil {
-			e.onExit(err)
-		} else {
-			e.onExit(fmt.Errorf("command exited with code 0"))
+		// reset the pause if the command ran for a long time
+		if time.Since(start) >= e.restart.MaxPause {
+			pause = e.restart.Pause
 		}
 
 		select {
-		case <-time.After(restartPause):
+		case <-time.After(pause):
 		case <-e.terminate:
 			return
 		}
+
+		pause *= 2
+		if pause > e.restart.MaxPause {
+			pause = e.restart.MaxPause
+		}
+
+		e.mutex.Lock()
+		e.restarts++
+		e.mutex.Unlock()
 	}
 }
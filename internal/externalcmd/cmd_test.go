@@ -0,0 +1,45 @@
+package externalcmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdOnExit(t *testing.T) {
+	for _, ca := range []struct {
+		name string
+		cmd  string
+		err  string
+	}{
+		{
+			"code 0",
+			`sh -c "exit 0"`,
+			"command exited with code 0",
+		},
+		{
+			"code 1",
+			`sh -c "exit 1"`,
+			"command exited with code 1",
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			pool := NewPool()
+			defer pool.Close()
+
+			exited := make(chan error, 1)
+
+			NewCmd(pool, ca.cmd, false, nil, func(err error) {
+				exited <- err
+			})
+
+			select {
+			case err := <-exited:
+				require.EqualError(t, err, ca.err)
+			case <-time.After(5 * time.Second):
+				t.Errorf("onExit was not called")
+			}
+		})
+	}
+}
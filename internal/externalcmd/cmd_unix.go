@@ -5,7 +5,6 @@ package externalcmd
 import (
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"syscall"
 
@@ -20,9 +19,12 @@ func (e *Cmd) runOSSpecific(env []string) error {
 
 	cmd := exec.Command(cmdParts[0], cmdParts[1:]...)
 
+	stdout, stderr, flushOutputs := e.outputs()
+	defer flushOutputs()
+
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	// set process group in order to allow killing subprocesses
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
@@ -41,7 +43,7 @@ func (e *Cmd) runOSSpecific(env []string) error {
 			}
 			var ee *exec.ExitError
 			if errors.As(err, &ee) {
-				ee.ExitCode()
+				return ee.ExitCode()
 			}
 			return 0
 		}()
@@ -87,9 +87,12 @@ func (e *Cmd) runOSSpecific(env []string) error {
 		cmd = exec.Command(cmdParts[0], cmdParts[1:]...)
 	}
 
+	stdout, stderr, flushOutputs := e.outputs()
+	defer flushOutputs()
+
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	// create a process group to kill all subprocesses
 	g, err := createProcessGroup()
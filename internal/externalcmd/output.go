@@ -0,0 +1,62 @@
+package externalcmd
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+const (
+	maxOutputLineSize = 4096
+)
+
+// outputLogger is a io.Writer that splits the output of a command into lines
+// and writes them into a logger.
+type outputLogger struct {
+	prefix string
+	parent logger.Writer
+
+	mutex sync.Mutex
+	buf   []byte
+}
+
+// Write implements io.Writer.
+func (w *outputLogger) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+
+		w.writeLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+
+	if len(w.buf) >= maxOutputLineSize {
+		w.writeLine(w.buf)
+		w.buf = nil
+	}
+
+	return len(p), nil
+}
+
+func (w *outputLogger) flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.writeLine(w.buf)
+	w.buf = nil
+}
+
+func (w *outputLogger) writeLine(line []byte) {
+	if len(line) == 0 {
+		return
+	}
+	w.parent.Log(logger.Info, "[%s] %s", w.prefix, line)
+}
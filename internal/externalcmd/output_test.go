@@ -0,0 +1,42 @@
+package externalcmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Log(_ logger.Level, format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestOutputLogger(t *testing.T) {
+	l := &testLogger{}
+
+	w := &outputLogger{
+		prefix: "ffmpeg",
+		parent: l,
+	}
+
+	_, err := w.Write([]byte("first line\nsecond "))
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("line\r\nprogress\rthird"))
+	require.NoError(t, err)
+
+	w.flush()
+
+	require.Equal(t, []string{
+		"[ffmpeg] first line",
+		"[ffmpeg] second line",
+		"[ffmpeg] progress",
+		"[ffmpeg] third",
+	}, l.lines)
+}
@@ -1,20 +1,72 @@
 package externalcmd
 
 import (
+	"sort"
 	"sync"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
 )
 
 // Pool is a pool of external commands.
 type Pool struct {
-	wg sync.WaitGroup
+	wg           sync.WaitGroup
+	mutex        sync.RWMutex
+	cmds         map[*Cmd]struct{}
+	outputLogger logger.Writer
 }
 
 // NewPool allocates a Pool.
 func NewPool() *Pool {
-	return &Pool{}
+	return &Pool{
+		cmds: make(map[*Cmd]struct{}),
+	}
 }
 
 // Close waits for all external commands to exit.
 func (p *Pool) Close() {
 	p.wg.Wait()
 }
+
+// SetOutputLogger sets a logger that receives the output of commands.
+// When nil, the output of commands is written to the standard output.
+// It applies to commands launched or restarted after the call.
+func (p *Pool) SetOutputLogger(l logger.Writer) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.outputLogger = l
+}
+
+func (p *Pool) getOutputLogger() logger.Writer {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.outputLogger
+}
+
+func (p *Pool) add(e *Cmd) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.cmds[e] = struct{}{}
+}
+
+func (p *Pool) remove(e *Cmd) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.cmds, e)
+}
+
+// List returns the status of all running commands, sorted by creation time.
+func (p *Pool) List() []*Status {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	ret := make([]*Status, 0, len(p.cmds))
+	for e := range p.cmds {
+		ret = append(ret, e.status())
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Created.Before(ret[j].Created)
+	})
+
+	return ret
+}
@@ -0,0 +1,271 @@
+// Package forwarder contains the forwarder.
+package forwarder
+
+import (
+	"context"
+	ctls "crypto/tls"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/rtmp"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+const (
+	minRetryPause = 2 * time.Second
+	maxRetryPause = 60 * time.Second
+)
+
+// IsSupportedURL checks whether an URL can be used as forward destination.
+func IsSupportedURL(u string) bool {
+	for _, prefix := range []string{"rtmp://", "rtmps://", "rtsp://", "rtsps://"} {
+		if strings.HasPrefix(u, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Forwarder pushes a stream to an external RTMP or RTSP server.
+// The connection is established again with an increasing pause in case of errors.
+type Forwarder struct {
+	URL          string
+	ReadTimeout  conf.Duration
+	WriteTimeout conf.Duration
+	Stream       *stream.Stream
+	Parent       logger.Writer
+
+	ctx       context.Context
+	ctxCancel func()
+	done      chan struct{}
+}
+
+// Initialize initializes Forwarder.
+func (f *Forwarder) Initialize() {
+	f.ctx, f.ctxCancel = context.WithCancel(context.Background())
+	f.done = make(chan struct{})
+
+	f.Log(logger.Info, "started")
+
+	go f.run()
+}
+
+// Close closes the Forwarder.
+func (f *Forwarder) Close() {
+	f.Log(logger.Info, "stopped")
+	f.ctxCancel()
+	<-f.done
+}
+
+// Log implements logger.Writer.
+func (f *Forwarder) Log(level logger.Level, format string, args ...interface{}) {
+	f.Parent.Log(level, "[forwarder %s] "+format, append([]interface{}{redactURL(f.URL)}, args...)...)
+}
+
+// redactURL removes credentials and RTMP stream keys from logs.
+func redactURL(ur string) string {
+	u, err := url.Parse(ur)
+	if err != nil {
+		return ur
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	// the stream key is appended after encoding, otherwise asterisks would be escaped
+	if u.Scheme == "rtmp" || u.Scheme == "rtmps" {
+		u.Path = u.Path[:strings.LastIndex(u.Path, "/")+1]
+		u.RawPath = ""
+		return u.String() + "***"
+	}
+
+	return u.String()
+}
+
+func (f *Forwarder) run() {
+	defer close(f.done)
+
+	pause := minRetryPause
+
+	for {
+		connected, err := f.runInner()
+		if err == nil {
+			return
+		}
+
+		if connected {
+			pause = minRetryPause
+		}
+
+		f.Log(logger.Error, "%v, retrying in %v", err, pause)
+
+		select {
+		case <-time.After(pause):
+		case <-f.ctx.Done():
+			return
+		}
+
+		pause *= 2
+		if pause > maxRetryPause {
+			pause = maxRetryPause
+		}
+	}
+}
+
+func (f *Forwarder) runInner() (bool, error) {
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		return false, err
+	}
+
+	switch u.Scheme {
+	case "rtmp", "rtmps":
+		return f.runRTMP(u)
+
+	default:
+		return f.runRTSP()
+	}
+}
+
+func (f *Forwarder) dialRTMP(u *url.URL) (net.Conn, error) {
+	// add default port
+	_, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		if u.Scheme == "rtmp" {
+			u.Host = net.JoinHostPort(u.Host, "1935")
+		} else {
+			u.Host = net.JoinHostPort(u.Host, "1936")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(f.ctx, time.Duration(f.ReadTimeout))
+	defer cancel()
+
+	nconn, err := (&net.Dialer{}).DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "rtmp" {
+		return nconn, nil
+	}
+
+	tconn := ctls.Client(nconn, &ctls.Config{ServerName: u.Hostname()})
+
+	err = tconn.HandshakeContext(ctx)
+	if err != nil {
+		nconn.Close()
+		return nil, err
+	}
+
+	return tconn, nil
+}
+
+func (f *Forwarder) runRTMP(u *url.URL) (bool, error) {
+	nconn, err := f.dialRTMP(u)
+	if err != nil {
+		return false, err
+	}
+	defer nconn.Close()
+
+	nconn.SetReadDeadline(time.Now().Add(time.Duration(f.ReadTimeout)))
+	nconn.SetWriteDeadline(time.Now().Add(time.Duration(f.WriteTimeout)))
+	conn, err := rtmp.NewClientConn(nconn, u, true)
+	if err != nil {
+		return false, err
+	}
+
+	err = rtmp.FromStream(f.Stream, f, conn, nconn, time.Duration(f.WriteTimeout))
+	if err != nil {
+		f.removeReader()
+		return false, err
+	}
+	defer f.Stream.RemoveReader(f)
+
+	// disable read deadline
+	nconn.SetReadDeadline(time.Time{})
+
+	f.Log(logger.Info, "is publishing, %s", defs.FormatsInfo(f.Stream.ReaderFormats(f)))
+
+	f.Stream.StartReader(f)
+
+	select {
+	case err := <-f.Stream.ReaderError(f):
+		return true, err
+
+	case <-f.ctx.Done():
+		return true, nil
+	}
+}
+
+func (f *Forwarder) runRTSP() (bool, error) {
+	c := &gortsplib.Client{
+		ReadTimeout:  time.Duration(f.ReadTimeout),
+		WriteTimeout: time.Duration(f.WriteTimeout),
+	}
+
+	desc := &description.Session{Medias: f.Stream.Desc().Medias}
+
+	err := c.StartRecording(f.URL, desc)
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	for _, medi := range desc.Medias {
+		for _, forma := range medi.Formats {
+			cmedi := medi
+
+			f.Stream.AddReader(f, medi, forma, func(u unit.Unit) error {
+				for _, pkt := range u.GetRTPPackets() {
+					err := c.WritePacketRTPWithNTP(cmedi, pkt, u.GetNTP())
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	}
+	defer f.Stream.RemoveReader(f)
+
+	f.Log(logger.Info, "is publishing, %s", defs.FormatsInfo(f.Stream.ReaderFormats(f)))
+
+	f.Stream.StartReader(f)
+
+	waitErr := make(chan error)
+	go func() {
+		waitErr <- c.Wait()
+	}()
+
+	select {
+	case err := <-f.Stream.ReaderError(f):
+		c.Close()
+		<-waitErr
+		return true, err
+
+	case err := <-waitErr:
+		return true, err
+
+	case <-f.ctx.Done():
+		c.Close()
+		<-waitErr
+		return true, nil
+	}
+}
+
+// removeReader removes the reader in case some of its tracks have been added before an error.
+func (f *Forwarder) removeReader() {
+	if len(f.Stream.ReaderFormats(f)) != 0 {
+		f.Stream.RemoveReader(f)
+	}
+}
@@ -1,6 +1,8 @@
 package hooks
 
 import (
+	"time"
+
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/logger"
@@ -13,6 +15,7 @@ type OnDemandParams struct {
 	Conf            *conf.Path
 	ExternalCmdEnv  externalcmd.Environment
 	Query           string
+	OnExit          func(error)
 }
 
 // OnDemand is the OnDemand hook.
@@ -28,13 +31,21 @@ func OnDemand(params OnDemandParams) func(string) {
 	if params.Conf.RunOnDemand != "" {
 		params.Logger.Log(logger.Info, "runOnDemand command started")
 
-		onDemandCmd = externalcmd.NewCmd(
+		onDemandCmd = externalcmd.NewCmdWithRestartPolicy(
 			params.ExternalCmdPool,
 			params.Conf.RunOnDemand,
-			params.Conf.RunOnDemandRestart,
+			externalcmd.RestartPolicy{
+				Enabled:  params.Conf.RunOnDemandRestart,
+				Pause:    time.Duration(params.Conf.RunOnDemandRestartPause),
+				MaxPause: time.Duration(params.Conf.RunOnDemandRestartMaxPause),
+			},
 			env,
 			func(err error) {
 				params.Logger.Log(logger.Info, "runOnDemand command exited: %v", err)
+
+				if params.OnExit != nil {
+					params.OnExit(err)
+				}
 			})
 	}
 
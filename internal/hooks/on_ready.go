@@ -18,7 +18,9 @@ type OnReadyParams struct {
 }
 
 // OnReady is the OnReady hook.
-func OnReady(params OnReadyParams) func() {
+// The returned function must be called when the stream is not ready anymore,
+// specifying whether the stream has ended cleanly.
+func OnReady(params OnReadyParams) func(bool) {
 	var env externalcmd.Environment
 	var onReadyCmd *externalcmd.Cmd
 
@@ -41,13 +43,19 @@ func OnReady(params OnReadyParams) func() {
 			})
 	}
 
-	return func() {
+	return func(ended bool) {
 		if onReadyCmd != nil {
 			onReadyCmd.Close()
 			params.Logger.Log(logger.Info, "runOnReady command stopped")
 		}
 
 		if params.Conf.RunOnNotReady != "" {
+			if ended {
+				env["MTX_END_OF_STREAM"] = "1"
+			} else {
+				env["MTX_END_OF_STREAM"] = "0"
+			}
+
 			params.Logger.Log(logger.Info, "runOnNotReady command launched")
 			externalcmd.NewCmd(
 				params.ExternalCmdPool,
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"time"
+)
+
+// destinationRing keeps the most recent log entries in memory,
+// regardless of the log level.
+type destinationRing struct {
+	entries [][]byte
+	next    int
+	full    bool
+
+	buf bytes.Buffer
+}
+
+func newDestinationRing(size int) *destinationRing {
+	return &destinationRing{
+		entries: make([][]byte, size),
+	}
+}
+
+func (d *destinationRing) log(t time.Time, level Level, format string, args ...interface{}) {
+	d.buf.Reset()
+	writeTime(&d.buf, t, false)
+	writeLevel(&d.buf, level, false)
+	writeContent(&d.buf, format, args)
+
+	d.entries[d.next] = append(d.entries[d.next][:0], d.buf.Bytes()...)
+	d.next++
+	if d.next == len(d.entries) {
+		d.next = 0
+		d.full = true
+	}
+}
+
+func (d *destinationRing) close() {
+}
+
+func (d *destinationRing) content() []byte {
+	var buf bytes.Buffer
+
+	if d.full {
+		for _, entry := range d.entries[d.next:] {
+			buf.Write(entry)
+		}
+	}
+
+	for _, entry := range d.entries[:d.next] {
+		buf.Write(entry)
+	}
+
+	return buf.Bytes()
+}
@@ -15,15 +15,22 @@ type Logger struct {
 	level Level
 
 	destinations []destination
+	ring         *destinationRing
 	mutex        sync.Mutex
 }
 
 // New allocates a log handler.
-func New(level Level, destinations []Destination, filePath string) (*Logger, error) {
+// If ringSize is greater than zero, the most recent ringSize entries,
+// including debug ones, are kept in memory and can be read with RecentEntries().
+func New(level Level, destinations []Destination, filePath string, ringSize int) (*Logger, error) {
 	lh := &Logger{
 		level: level,
 	}
 
+	if ringSize > 0 {
+		lh.ring = newDestinationRing(ringSize)
+	}
+
 	for _, destType := range destinations {
 		switch destType {
 		case DestinationStdout:
@@ -142,7 +149,7 @@ func writeContent(buf *bytes.Buffer, format string, args []interface{}) {
 
 // Log writes a log entry.
 func (lh *Logger) Log(level Level, format string, args ...interface{}) {
-	if level < lh.level {
+	if level < lh.level && lh.ring == nil {
 		return
 	}
 
@@ -151,7 +158,27 @@ func (lh *Logger) Log(level Level, format string, args ...interface{}) {
 
 	t := time.Now()
 
+	if lh.ring != nil {
+		lh.ring.log(t, level, format, args...)
+
+		if level < lh.level {
+			return
+		}
+	}
+
 	for _, dest := range lh.destinations {
 		dest.log(t, level, format, args...)
 	}
 }
+
+// RecentEntries returns the entries kept in memory.
+func (lh *Logger) RecentEntries() []byte {
+	lh.mutex.Lock()
+	defer lh.mutex.Unlock()
+
+	if lh.ring == nil {
+		return nil
+	}
+
+	return lh.ring.content()
+}
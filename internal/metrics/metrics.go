@@ -6,7 +6,8 @@ import (
 	"net"
 	"net/http"
 	"reflect"
-	"strconv"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/api"
 	"github.com/bluenviron/mediamtx/internal/auth"
 	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/protocols/httpp"
 	"github.com/bluenviron/mediamtx/internal/restrictnetwork"
@@ -24,18 +26,14 @@ func interfaceIsEmpty(i interface{}) bool {
 	return reflect.ValueOf(i).Kind() != reflect.Ptr || reflect.ValueOf(i).IsNil()
 }
 
-func metric(key string, tags string, value int64) string {
-	return key + tags + " " + strconv.FormatInt(value, 10) + "\n"
-}
-
-func metricFloat(key string, tags string, value float64) string {
-	return key + tags + " " + strconv.FormatFloat(value, 'f', -1, 64) + "\n"
-}
-
 type metricsAuthManager interface {
 	Authenticate(req *auth.Request) error
 }
 
+type metricsRecordVerifier interface {
+	CorruptedSegments() map[string]int
+}
+
 type metricsParent interface {
 	logger.Writer
 }
@@ -49,19 +47,21 @@ type Metrics struct {
 	AllowOrigin    string
 	TrustedProxies conf.IPNetworks
 	ReadTimeout    conf.Duration
+	LegacyNames    bool
 	AuthManager    metricsAuthManager
 	Parent         metricsParent
 
-	httpServer   *httpp.Server
-	mutex        sync.Mutex
-	pathManager  api.PathManager
-	rtspServer   api.RTSPServer
-	rtspsServer  api.RTSPServer
-	rtmpServer   api.RTMPServer
-	rtmpsServer  api.RTMPServer
-	srtServer    api.SRTServer
-	hlsManager   api.HLSServer
-	webRTCServer api.WebRTCServer
+	httpServer     *httpp.Server
+	mutex          sync.Mutex
+	pathManager    api.PathManager
+	rtspServer     api.RTSPServer
+	rtspsServer    api.RTSPServer
+	rtmpServer     api.RTMPServer
+	rtmpsServer    api.RTMPServer
+	srtServer      api.SRTServer
+	hlsManager     api.HLSServer
+	webRTCServer   api.WebRTCServer
+	recordVerifier metricsRecordVerifier
 }
 
 // Initialize initializes metrics.
@@ -144,306 +144,478 @@ func (m *Metrics) middlewareAuth(ctx *gin.Context) {
 	}
 }
 
-func (m *Metrics) onMetrics(ctx *gin.Context) {
-	out := ""
-
-	data, err := m.pathManager.APIPathsList()
-	if err == nil && len(data.Items) != 0 {
-		for _, i := range data.Items {
-			var state string
-			if i.Ready {
-				state = "ready"
-			} else {
-				state = "notReady"
-			}
+func pathState(ready bool) string {
+	if ready {
+		return "ready"
+	}
+	return "notReady"
+}
 
-			tags := "{name=\"" + i.Name + "\",state=\"" + state + "\"}"
-			out += metric("paths", tags, 1)
-			out += metric("paths_bytes_received", tags, int64(i.BytesReceived))
-			out += metric("paths_bytes_sent", tags, int64(i.BytesSent))
+func openSessions(sessions []*defs.APIHLSSession) int {
+	n := 0
+	for _, s := range sessions {
+		if s.Closed == nil {
+			n++
 		}
-	} else {
-		out += metric("paths", "", 0)
 	}
+	return n
+}
+
+func (m *Metrics) onMetrics(ctx *gin.Context) {
+	if m.LegacyNames {
+		ctx.Writer.WriteHeader(http.StatusOK)
+		io.WriteString(ctx.Writer, m.legacyMetrics()) //nolint:errcheck
+		return
+	}
+
+	w := &metricsWriter{}
+
+	m.writePaths(w)
 
 	if !interfaceIsEmpty(m.hlsManager) {
-		data, err := m.hlsManager.APIMuxersList()
-		if err == nil && len(data.Items) != 0 {
-			for _, i := range data.Items {
-				tags := "{name=\"" + i.Path + "\"}"
-				out += metric("hls_muxers", tags, 1)
-				out += metric("hls_muxers_bytes_sent", tags, int64(i.BytesSent))
-			}
-		} else {
-			out += metric("hls_muxers", "", 0)
-			out += metric("hls_muxers_bytes_sent", "", 0)
-		}
+		m.writeHLSMuxers(w)
 	}
 
-	if !interfaceIsEmpty(m.rtspServer) { //nolint:dupl
-		func() {
-			data, err := m.rtspServer.APIConnsList()
-			if err == nil && len(data.Items) != 0 {
-				for _, i := range data.Items {
-					tags := "{id=\"" + i.ID.String() + "\"}"
-					out += metric("rtsp_conns", tags, 1)
-					out += metric("rtsp_conns_bytes_received", tags, int64(i.BytesReceived))
-					out += metric("rtsp_conns_bytes_sent", tags, int64(i.BytesSent))
-				}
-			} else {
-				out += metric("rtsp_conns", "", 0)
-				out += metric("rtsp_conns_bytes_received", "", 0)
-				out += metric("rtsp_conns_bytes_sent", "", 0)
-			}
-		}()
-
-		func() {
-			data, err := m.rtspServer.APISessionsList()
-			if err == nil && len(data.Items) != 0 {
-				for _, i := range data.Items {
-					tags := "{id=\"" + i.ID.String() + "\",state=\"" + string(i.State) + "\"}"
-					out += metric("rtsp_sessions", tags, 1)
-					out += metric("rtsp_sessions_bytes_received", tags, int64(i.BytesReceived))
-					out += metric("rtsp_sessions_bytes_sent", tags, int64(i.BytesSent))
-					out += metric("rtsp_sessions_rtp_packets_received", tags, int64(i.RTPPacketsReceived))
-					out += metric("rtsp_sessions_rtp_packets_sent", tags, int64(i.RTPPacketsSent))
-					out += metric("rtsp_sessions_rtp_packets_lost", tags, int64(i.RTPPacketsLost))
-					out += metric("rtsp_sessions_rtp_packets_in_error", tags, int64(i.RTPPacketsInError))
-					out += metricFloat("rtsp_sessions_rtp_packets_jitter", tags, i.RTPPacketsJitter)
-					out += metric("rtsp_sessions_rtcp_packets_received", tags, int64(i.RTCPPacketsReceived))
-					out += metric("rtsp_sessions_rtcp_packets_sent", tags, int64(i.RTCPPacketsSent))
-					out += metric("rtsp_sessions_rtcp_packets_in_error", tags, int64(i.RTCPPacketsInError))
-				}
-			} else {
-				out += metric("rtsp_sessions", "", 0)
-				out += metric("rtsp_sessions_bytes_received", "", 0)
-				out += metric("rtsp_sessions_bytes_sent", "", 0)
-				out += metric("rtsp_sessions_rtp_packets_received", "", 0)
-				out += metric("rtsp_sessions_rtp_packets_sent", "", 0)
-				out += metric("rtsp_sessions_rtp_packets_lost", "", 0)
-				out += metric("rtsp_sessions_rtp_packets_in_error", "", 0)
-				out += metricFloat("rtsp_sessions_rtp_packets_jitter", "", 0)
-				out += metric("rtsp_sessions_rtcp_packets_received", "", 0)
-				out += metric("rtsp_sessions_rtcp_packets_sent", "", 0)
-				out += metric("rtsp_sessions_rtcp_packets_in_error", "", 0)
-			}
-		}()
+	sessions := w.gauge("sessions",
+		"Sessions that are publishing or reading a path, or are idle.")
+	sessionsBytes := w.counter("sessions_bytes",
+		"Bytes received or sent by sessions.")
+
+	addSession := func(protocol string, id string, path string, state string, bytesReceived uint64, bytesSent uint64) {
+		sessions.add(labels("protocol", protocol, "id", id, "path", path, "state", state), 1)
+		sessionsBytes.add(labels("protocol", protocol, "id", id, "path", path, "direction", "received"),
+			float64(bytesReceived))
+		sessionsBytes.add(labels("protocol", protocol, "id", id, "path", path, "direction", "sent"),
+			float64(bytesSent))
 	}
 
-	if !interfaceIsEmpty(m.rtspsServer) { //nolint:dupl
-		func() {
-			data, err := m.rtspsServer.APIConnsList()
-			if err == nil && len(data.Items) != 0 {
-				for _, i := range data.Items {
-					tags := "{id=\"" + i.ID.String() + "\"}"
-					out += metric("rtsps_conns", tags, 1)
-					out += metric("rtsps_conns_bytes_received", tags, int64(i.BytesReceived))
-					out += metric("rtsps_conns_bytes_sent", tags, int64(i.BytesSent))
-				}
-			} else {
-				out += metric("rtsps_conns", "", 0)
-				out += metric("rtsps_conns_bytes_received", "", 0)
-				out += metric("rtsps_conns_bytes_sent", "", 0)
-			}
-		}()
-
-		func() {
-			data, err := m.rtspsServer.APISessionsList()
-			if err == nil && len(data.Items) != 0 {
-				for _, i := range data.Items {
-					tags := "{id=\"" + i.ID.String() + "\",state=\"" + string(i.State) + "\"}"
-					out += metric("rtsps_sessions", tags, 1)
-					out += metric("rtsps_sessions_bytes_received", tags, int64(i.BytesReceived))
-					out += metric("rtsps_sessions_bytes_sent", tags, int64(i.BytesSent))
-					out += metric("rtsps_sessions_rtp_packets_received", tags, int64(i.RTPPacketsReceived))
-					out += metric("rtsps_sessions_rtp_packets_sent", tags, int64(i.RTPPacketsSent))
-					out += metric("rtsps_sessions_rtp_packets_lost", tags, int64(i.RTPPacketsLost))
-					out += metric("rtsps_sessions_rtp_packets_in_error", tags, int64(i.RTPPacketsInError))
-					out += metricFloat("rtsps_sessions_rtp_packets_jitter", tags, i.RTPPacketsJitter)
-					out += metric("rtsps_sessions_rtcp_packets_received", tags, int64(i.RTCPPacketsReceived))
-					out += metric("rtsps_sessions_rtcp_packets_sent", tags, int64(i.RTCPPacketsSent))
-					out += metric("rtsps_sessions_rtcp_packets_in_error", tags, int64(i.RTCPPacketsInError))
-				}
-			} else {
-				out += metric("rtsps_sessions", "", 0)
-				out += metric("rtsps_sessions_bytes_received", "", 0)
-				out += metric("rtsps_sessions_bytes_sent", "", 0)
-				out += metric("rtsps_sessions_rtp_packets_received", "", 0)
-				out += metric("rtsps_sessions_rtp_packets_sent", "", 0)
-				out += metric("rtsps_sessions_rtp_packets_lost", "", 0)
-				out += metric("rtsps_sessions_rtp_packets_in_error", "", 0)
-				out += metricFloat("rtsps_sessions_rtp_packets_jitter", "", 0)
-				out += metric("rtsps_sessions_rtcp_packets_received", "", 0)
-				out += metric("rtsps_sessions_rtcp_packets_sent", "", 0)
-				out += metric("rtsps_sessions_rtcp_packets_in_error", "", 0)
-			}
-		}()
+	if !interfaceIsEmpty(m.rtspServer) || !interfaceIsEmpty(m.rtspsServer) {
+		m.writeRTSP(w, addSession)
 	}
 
 	if !interfaceIsEmpty(m.rtmpServer) {
 		data, err := m.rtmpServer.APIConnsList()
-		if err == nil && len(data.Items) != 0 {
+		if err == nil {
 			for _, i := range data.Items {
-				tags := "{id=\"" + i.ID.String() + "\",state=\"" + string(i.State) + "\"}"
-				out += metric("rtmp_conns", tags, 1)
-				out += metric("rtmp_conns_bytes_received", tags, int64(i.BytesReceived))
-				out += metric("rtmp_conns_bytes_sent", tags, int64(i.BytesSent))
+				addSession("rtmp", i.ID.String(), i.Path, string(i.State), i.BytesReceived, i.BytesSent)
 			}
-		} else {
-			out += metric("rtmp_conns", "", 0)
-			out += metric("rtmp_conns_bytes_received", "", 0)
-			out += metric("rtmp_conns_bytes_sent", "", 0)
 		}
 	}
 
 	if !interfaceIsEmpty(m.rtmpsServer) {
 		data, err := m.rtmpsServer.APIConnsList()
-		if err == nil && len(data.Items) != 0 {
+		if err == nil {
 			for _, i := range data.Items {
-				tags := "{id=\"" + i.ID.String() + "\",state=\"" + string(i.State) + "\"}"
-				out += metric("rtmps_conns", tags, 1)
-				out += metric("rtmps_conns_bytes_received", tags, int64(i.BytesReceived))
-				out += metric("rtmps_conns_bytes_sent", tags, int64(i.BytesSent))
+				addSession("rtmps", i.ID.String(), i.Path, string(i.State), i.BytesReceived, i.BytesSent)
 			}
-		} else {
-			out += metric("rtmps_conns", "", 0)
-			out += metric("rtmps_conns_bytes_received", "", 0)
-			out += metric("rtmps_conns_bytes_sent", "", 0)
 		}
 	}
 
 	if !interfaceIsEmpty(m.srtServer) {
-		data, err := m.srtServer.APIConnsList()
-		if err == nil && len(data.Items) != 0 {
-			for _, i := range data.Items {
-				tags := "{id=\"" + i.ID.String() + "\",state=\"" + string(i.State) + "\"}"
-				out += metric("srt_conns", tags, 1)
-				out += metric("srt_conns_packets_sent", tags, int64(i.PacketsSent))
-				out += metric("srt_conns_packets_received", tags, int64(i.PacketsReceived))
-				out += metric("srt_conns_packets_sent_unique", tags, int64(i.PacketsSentUnique))
-				out += metric("srt_conns_packets_received_unique", tags, int64(i.PacketsReceivedUnique))
-				out += metric("srt_conns_packets_send_loss", tags, int64(i.PacketsSendLoss))
-				out += metric("srt_conns_packets_received_loss", tags, int64(i.PacketsReceivedLoss))
-				out += metric("srt_conns_packets_retrans", tags, int64(i.PacketsRetrans))
-				out += metric("srt_conns_packets_received_retrans", tags, int64(i.PacketsReceivedRetrans))
-				out += metric("srt_conns_packets_sent_ack", tags, int64(i.PacketsSentACK))
-				out += metric("srt_conns_packets_received_ack", tags, int64(i.PacketsReceivedACK))
-				out += metric("srt_conns_packets_sent_nak", tags, int64(i.PacketsSentNAK))
-				out += metric("srt_conns_packets_received_nak", tags, int64(i.PacketsReceivedNAK))
-				out += metric("srt_conns_packets_sent_km", tags, int64(i.PacketsSentKM))
-				out += metric("srt_conns_packets_received_km", tags, int64(i.PacketsReceivedKM))
-				out += metric("srt_conns_us_snd_duration", tags, int64(i.UsSndDuration))
-				out += metric("srt_conns_packets_send_drop", tags, int64(i.PacketsSendDrop))
-				out += metric("srt_conns_packets_received_drop", tags, int64(i.PacketsReceivedDrop))
-				out += metric("srt_conns_packets_received_undecrypt", tags, int64(i.PacketsReceivedUndecrypt))
-				out += metric("srt_conns_bytes_sent", tags, int64(i.BytesSent))
-				out += metric("srt_conns_bytes_received", tags, int64(i.BytesReceived))
-				out += metric("srt_conns_bytes_sent_unique", tags, int64(i.BytesSentUnique))
-				out += metric("srt_conns_bytes_received_unique", tags, int64(i.BytesReceivedUnique))
-				out += metric("srt_conns_bytes_received_loss", tags, int64(i.BytesReceivedLoss))
-				out += metric("srt_conns_bytes_retrans", tags, int64(i.BytesRetrans))
-				out += metric("srt_conns_bytes_received_retrans", tags, int64(i.BytesReceivedRetrans))
-				out += metric("srt_conns_bytes_send_drop", tags, int64(i.BytesSendDrop))
-				out += metric("srt_conns_bytes_received_drop", tags, int64(i.BytesReceivedDrop))
-				out += metric("srt_conns_bytes_received_undecrypt", tags, int64(i.BytesReceivedUndecrypt))
-				out += metricFloat("srt_conns_us_packets_send_period", tags, i.UsPacketsSendPeriod)
-				out += metric("srt_conns_packets_flow_window", tags, int64(i.PacketsFlowWindow))
-				out += metric("srt_conns_packets_flight_size", tags, int64(i.PacketsFlightSize))
-				out += metricFloat("srt_conns_ms_rtt", tags, i.MsRTT)
-				out += metricFloat("srt_conns_mbps_send_rate", tags, i.MbpsSendRate)
-				out += metricFloat("srt_conns_mbps_receive_rate", tags, i.MbpsReceiveRate)
-				out += metricFloat("srt_conns_mbps_link_capacity", tags, i.MbpsLinkCapacity)
-				out += metric("srt_conns_bytes_avail_send_buf", tags, int64(i.BytesAvailSendBuf))
-				out += metric("srt_conns_bytes_avail_receive_buf", tags, int64(i.BytesAvailReceiveBuf))
-				out += metricFloat("srt_conns_mbps_max_bw", tags, i.MbpsMaxBW)
-				out += metric("srt_conns_bytes_mss", tags, int64(i.ByteMSS))
-				out += metric("srt_conns_packets_send_buf", tags, int64(i.PacketsSendBuf))
-				out += metric("srt_conns_bytes_send_buf", tags, int64(i.BytesSendBuf))
-				out += metric("srt_conns_ms_send_buf", tags, int64(i.MsSendBuf))
-				out += metric("srt_conns_ms_send_tsb_pd_delay", tags, int64(i.MsSendTsbPdDelay))
-				out += metric("srt_conns_packets_receive_buf", tags, int64(i.PacketsReceiveBuf))
-				out += metric("srt_conns_bytes_receive_buf", tags, int64(i.BytesReceiveBuf))
-				out += metric("srt_conns_ms_receive_buf", tags, int64(i.MsReceiveBuf))
-				out += metric("srt_conns_ms_receive_tsb_pd_delay", tags, int64(i.MsReceiveTsbPdDelay))
-				out += metric("srt_conns_packets_reorder_tolerance", tags, int64(i.PacketsReorderTolerance))
-				out += metric("srt_conns_packets_received_avg_belated_time", tags, int64(i.PacketsReceivedAvgBelatedTime))
-				out += metricFloat("srt_conns_packets_send_loss_rate", tags, i.PacketsSendLossRate)
-				out += metricFloat("srt_conns_packets_received_loss_rate", tags, i.PacketsReceivedLossRate)
-			}
-		} else {
-			out += metric("srt_conns", "", 0)
-			out += metric("srt_conns_packets_sent", "", 0)
-			out += metric("srt_conns_packets_received", "", 0)
-			out += metric("srt_conns_packets_sent_unique", "", 0)
-			out += metric("srt_conns_packets_received_unique", "", 0)
-			out += metric("srt_conns_packets_send_loss", "", 0)
-			out += metric("srt_conns_packets_received_loss", "", 0)
-			out += metric("srt_conns_packets_retrans", "", 0)
-			out += metric("srt_conns_packets_received_retrans", "", 0)
-			out += metric("srt_conns_packets_sent_ack", "", 0)
-			out += metric("srt_conns_packets_received_ack", "", 0)
-			out += metric("srt_conns_packets_sent_nak", "", 0)
-			out += metric("srt_conns_packets_received_nak", "", 0)
-			out += metric("srt_conns_packets_sent_km", "", 0)
-			out += metric("srt_conns_packets_received_km", "", 0)
-			out += metric("srt_conns_us_snd_duration", "", 0)
-			out += metric("srt_conns_packets_send_drop", "", 0)
-			out += metric("srt_conns_packets_received_drop", "", 0)
-			out += metric("srt_conns_packets_received_undecrypt", "", 0)
-			out += metric("srt_conns_bytes_sent", "", 0)
-			out += metric("srt_conns_bytes_received", "", 0)
-			out += metric("srt_conns_bytes_sent_unique", "", 0)
-			out += metric("srt_conns_bytes_received_unique", "", 0)
-			out += metric("srt_conns_bytes_received_loss", "", 0)
-			out += metric("srt_conns_bytes_retrans", "", 0)
-			out += metric("srt_conns_bytes_received_retrans", "", 0)
-			out += metric("srt_conns_bytes_send_drop", "", 0)
-			out += metric("srt_conns_bytes_received_drop", "", 0)
-			out += metric("srt_conns_bytes_received_undecrypt", "", 0)
-			out += metricFloat("srt_conns_us_packets_send_period", "", 0)
-			out += metric("srt_conns_packets_flow_window", "", 0)
-			out += metric("srt_conns_packets_flight_size", "", 0)
-			out += metricFloat("srt_conns_ms_rtt", "", 0)
-			out += metricFloat("srt_conns_mbps_send_rate", "", 0)
-			out += metricFloat("srt_conns_mbps_receive_rate", "", 0)
-			out += metricFloat("srt_conns_mbps_link_capacity", "", 0)
-			out += metric("srt_conns_bytes_avail_send_buf", "", 0)
-			out += metric("srt_conns_bytes_avail_receive_buf", "", 0)
-			out += metricFloat("srt_conns_mbps_max_bw", "", 0)
-			out += metric("srt_conns_bytes_mss", "", 0)
-			out += metric("srt_conns_packets_send_buf", "", 0)
-			out += metric("srt_conns_bytes_send_buf", "", 0)
-			out += metric("srt_conns_ms_send_buf", "", 0)
-			out += metric("srt_conns_ms_send_tsb_pd_delay", "", 0)
-			out += metric("srt_conns_packets_receive_buf", "", 0)
-			out += metric("srt_conns_bytes_receive_buf", "", 0)
-			out += metric("srt_conns_ms_receive_buf", "", 0)
-			out += metric("srt_conns_ms_receive_tsb_pd_delay", "", 0)
-			out += metric("srt_conns_packets_reorder_tolerance", "", 0)
-			out += metric("srt_conns_packets_received_avg_belated_time", "", 0)
-			out += metricFloat("srt_conns_packets_send_loss_rate", "", 0)
-			out += metricFloat("srt_conns_packets_received_loss_rate", "", 0)
-		}
+		m.writeSRT(w, addSession)
 	}
 
 	if !interfaceIsEmpty(m.webRTCServer) {
 		data, err := m.webRTCServer.APISessionsList()
-		if err == nil && len(data.Items) != 0 {
+		if err == nil {
 			for _, i := range data.Items {
-				tags := "{id=\"" + i.ID.String() + "\",state=\"" + string(i.State) + "\"}"
-				out += metric("webrtc_sessions", tags, 1)
-				out += metric("webrtc_sessions_bytes_received", tags, int64(i.BytesReceived))
-				out += metric("webrtc_sessions_bytes_sent", tags, int64(i.BytesSent))
+				addSession("webrtc", i.ID.String(), i.Path, string(i.State), i.BytesReceived, i.BytesSent)
 			}
-		} else {
-			out += metric("webrtc_sessions", "", 0)
-			out += metric("webrtc_sessions_bytes_received", "", 0)
-			out += metric("webrtc_sessions_bytes_sent", "", 0)
 		}
 	}
 
+	if !interfaceIsEmpty(m.recordVerifier) {
+		corrupted := w.gauge("record_corrupted_segments",
+			"Recording segments that failed verification.")
+
+		data := m.recordVerifier.CorruptedSegments()
+		pathNames := make([]string, 0, len(data))
+		for pathName := range data {
+			pathNames = append(pathNames, pathName)
+		}
+		sort.Strings(pathNames)
+
+		for _, pathName := range pathNames {
+			corrupted.add(labels("path", pathName), float64(data[pathName]))
+		}
+	}
+
+	openMetrics := strings.Contains(ctx.GetHeader("Accept"), "application/openmetrics-text")
+
+	if openMetrics {
+		ctx.Header("Content-Type", contentTypeOpenMetrics)
+	} else {
+		ctx.Header("Content-Type", contentTypePrometheus)
+	}
+
 	ctx.Writer.WriteHeader(http.StatusOK)
-	io.WriteString(ctx.Writer, out) //nolint:errcheck
+	io.WriteString(ctx.Writer, w.write(openMetrics)) //nolint:errcheck
+}
+
+func (m *Metrics) writePaths(w *metricsWriter) {
+	paths := w.gauge("paths",
+		"Paths, with their state.")
+	pathsReaders := w.gauge("paths_readers",
+		"Readers of paths.")
+	pathsBytes := w.counter("paths_bytes",
+		"Bytes received or sent by paths.")
+
+	data, err := m.pathManager.APIPathsList()
+	if err != nil {
+		return
+	}
+
+	for _, i := range data.Items {
+		paths.add(labels("path", i.Name, "state", pathState(i.Ready)), 1)
+		pathsReaders.add(labels("path", i.Name), float64(len(i.Readers)))
+
+		pathsBytes.add(labels("path", i.Name, "direction", "received"),
+			float64(i.BytesReceived))
+		pathsBytes.add(labels("path", i.Name, "direction", "sent"),
+			float64(i.BytesSent))
+	}
+}
+
+func (m *Metrics) writeHLSMuxers(w *metricsWriter) {
+	muxers := w.gauge("hls_muxers",
+		"HLS muxers.")
+	muxersSessions := w.gauge("hls_muxers_sessions",
+		"Sessions reading from HLS muxers.")
+	muxersBytes := w.counter("hls_muxers_bytes",
+		"Bytes sent by HLS muxers.")
+
+	data, err := m.hlsManager.APIMuxersList()
+	if err != nil {
+		return
+	}
+
+	for _, i := range data.Items {
+		muxers.add(labels("path", i.Path), 1)
+		muxersSessions.add(labels("path", i.Path), float64(openSessions(i.Sessions)))
+		muxersBytes.add(labels("path", i.Path, "direction", "sent"), float64(i.BytesSent))
+	}
+}
+
+func (m *Metrics) writeRTSP(
+	w *metricsWriter,
+	addSession func(string, string, string, string, uint64, uint64),
+) {
+	conns := w.gauge("rtsp_conns",
+		"RTSP connections.")
+	connsBytes := w.counter("rtsp_conns_bytes",
+		"Bytes received or sent by RTSP connections.")
+	rtpPackets := w.counter("rtsp_sessions_rtp_packets",
+		"RTP packets received or sent by RTSP sessions.")
+	rtpPacketsLost := w.counter("rtsp_sessions_rtp_packets_lost",
+		"RTP packets lost by RTSP sessions.")
+	rtpPacketsInError := w.counter("rtsp_sessions_rtp_packets_in_error",
+		"RTP packets of RTSP sessions that could not be processed.")
+	rtpPacketsJitter := w.gauge("rtsp_sessions_rtp_packets_jitter",
+		"Jitter of RTP packets received by RTSP sessions.")
+	rtcpPackets := w.counter("rtsp_sessions_rtcp_packets",
+		"RTCP packets received or sent by RTSP sessions.")
+	rtcpPacketsInError := w.counter("rtsp_sessions_rtcp_packets_in_error",
+		"RTCP packets of RTSP sessions that could not be processed.")
+
+	for _, e := range []struct {
+		protocol string
+		server   api.RTSPServer
+	}{
+		{"rtsp", m.rtspServer},
+		{"rtsps", m.rtspsServer},
+	} {
+		if interfaceIsEmpty(e.server) {
+			continue
+		}
+
+		connsData, err := e.server.APIConnsList()
+		if err == nil {
+			for _, i := range connsData.Items {
+				id := i.ID.String()
+				conns.add(labels("protocol", e.protocol, "id", id), 1)
+				connsBytes.add(labels("protocol", e.protocol, "id", id, "direction", "received"),
+					float64(i.BytesReceived))
+				connsBytes.add(labels("protocol", e.protocol, "id", id, "direction", "sent"),
+					float64(i.BytesSent))
+			}
+		}
+
+		sessionsData, err := e.server.APISessionsList()
+		if err == nil {
+			for _, i := range sessionsData.Items {
+				id := i.ID.String()
+				addSession(e.protocol, id, i.Path, string(i.State), i.BytesReceived, i.BytesSent)
+
+				received := labels("protocol", e.protocol, "id", id, "path", i.Path, "direction", "received")
+				sent := labels("protocol", e.protocol, "id", id, "path", i.Path, "direction", "sent")
+				lbls := labels("protocol", e.protocol, "id", id, "path", i.Path)
+
+				rtpPackets.add(received, float64(i.RTPPacketsReceived))
+				rtpPackets.add(sent, float64(i.RTPPacketsSent))
+				rtpPacketsLost.add(lbls, float64(i.RTPPacketsLost))
+				rtpPacketsInError.add(lbls, float64(i.RTPPacketsInError))
+				rtpPacketsJitter.add(lbls, i.RTPPacketsJitter)
+				rtcpPackets.add(received, float64(i.RTCPPacketsReceived))
+				rtcpPackets.add(sent, float64(i.RTCPPacketsSent))
+				rtcpPacketsInError.add(lbls, float64(i.RTCPPacketsInError))
+			}
+		}
+	}
+}
+
+// srtMetric is a SRT statistic.
+// Statistics that are available for both directions fill sent and received,
+// the others fill value.
+type srtMetric struct {
+	name     string
+	typ      metricType
+	help     string
+	sent     func(*defs.APISRTConn) float64
+	received func(*defs.APISRTConn) float64
+	value    func(*defs.APISRTConn) float64
+}
+
+var srtMetrics = []srtMetric{
+	{
+		name:     "srt_conns_packets",
+		typ:      metricTypeCounter,
+		help:     "DATA packets, including retransmitted packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsSent) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceived) },
+	},
+	{
+		name:     "srt_conns_packets_unique",
+		typ:      metricTypeCounter,
+		help:     "Unique DATA packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsSentUnique) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedUnique) },
+	},
+	{
+		name:     "srt_conns_packets_loss",
+		typ:      metricTypeCounter,
+		help:     "DATA packets considered or reported as lost.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsSendLoss) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedLoss) },
+	},
+	{
+		name:     "srt_conns_packets_retrans",
+		typ:      metricTypeCounter,
+		help:     "Retransmitted packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsRetrans) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedRetrans) },
+	},
+	{
+		name:     "srt_conns_packets_ack",
+		typ:      metricTypeCounter,
+		help:     "ACK control packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsSentACK) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedACK) },
+	},
+	{
+		name:     "srt_conns_packets_nak",
+		typ:      metricTypeCounter,
+		help:     "NAK control packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsSentNAK) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedNAK) },
+	},
+	{
+		name:     "srt_conns_packets_km",
+		typ:      metricTypeCounter,
+		help:     "KM control packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsSentKM) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedKM) },
+	},
+	{
+		name:     "srt_conns_packets_drop",
+		typ:      metricTypeCounter,
+		help:     "DATA packets dropped because they were too late to be delivered.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsSendDrop) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedDrop) },
+	},
+	{
+		name:  "srt_conns_packets_received_undecrypt",
+		typ:   metricTypeCounter,
+		help:  "Received DATA packets that could not be decrypted.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedUndecrypt) },
+	},
+	{
+		name:  "srt_conns_us_snd_duration",
+		typ:   metricTypeCounter,
+		help:  "Time duration in microseconds during which the sender was sending data.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.UsSndDuration) },
+	},
+	{
+		name:     "srt_conns_bytes",
+		typ:      metricTypeCounter,
+		help:     "Bytes of DATA packets, including retransmitted packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.BytesSent) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.BytesReceived) },
+	},
+	{
+		name:     "srt_conns_bytes_unique",
+		typ:      metricTypeCounter,
+		help:     "Bytes of unique DATA packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.BytesSentUnique) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.BytesReceivedUnique) },
+	},
+	{
+		name:  "srt_conns_bytes_received_loss",
+		typ:   metricTypeCounter,
+		help:  "Bytes of received DATA packets detected as lost.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.BytesReceivedLoss) },
+	},
+	{
+		name:     "srt_conns_bytes_retrans",
+		typ:      metricTypeCounter,
+		help:     "Bytes of retransmitted packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.BytesRetrans) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.BytesReceivedRetrans) },
+	},
+	{
+		name:     "srt_conns_bytes_drop",
+		typ:      metricTypeCounter,
+		help:     "Bytes of DATA packets dropped because they were too late to be delivered.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.BytesSendDrop) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.BytesReceivedDrop) },
+	},
+	{
+		name:  "srt_conns_bytes_received_undecrypt",
+		typ:   metricTypeCounter,
+		help:  "Bytes of received DATA packets that could not be decrypted.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.BytesReceivedUndecrypt) },
+	},
+	{
+		name:  "srt_conns_us_packets_send_period",
+		typ:   metricTypeGauge,
+		help:  "Current minimum time interval between sent packets, in microseconds.",
+		value: func(c *defs.APISRTConn) float64 { return c.UsPacketsSendPeriod },
+	},
+	{
+		name:  "srt_conns_packets_flow_window",
+		typ:   metricTypeGauge,
+		help:  "Available receiver buffer size reported by the peer, in packets.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.PacketsFlowWindow) },
+	},
+	{
+		name:  "srt_conns_packets_flight_size",
+		typ:   metricTypeGauge,
+		help:  "Packets that have been sent but not acknowledged yet.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.PacketsFlightSize) },
+	},
+	{
+		name:  "srt_conns_ms_rtt",
+		typ:   metricTypeGauge,
+		help:  "Smoothed round-trip time, in milliseconds.",
+		value: func(c *defs.APISRTConn) float64 { return c.MsRTT },
+	},
+	{
+		name:     "srt_conns_mbps_rate",
+		typ:      metricTypeGauge,
+		help:     "Current send or receive rate, in Mbps.",
+		sent:     func(c *defs.APISRTConn) float64 { return c.MbpsSendRate },
+		received: func(c *defs.APISRTConn) float64 { return c.MbpsReceiveRate },
+	},
+	{
+		name:  "srt_conns_mbps_link_capacity",
+		typ:   metricTypeGauge,
+		help:  "Estimated capacity of the link, in Mbps.",
+		value: func(c *defs.APISRTConn) float64 { return c.MbpsLinkCapacity },
+	},
+	{
+		name:     "srt_conns_bytes_avail_buf",
+		typ:      metricTypeGauge,
+		help:     "Available space in the send or receive buffer, in bytes.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.BytesAvailSendBuf) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.BytesAvailReceiveBuf) },
+	},
+	{
+		name:  "srt_conns_mbps_max_bw",
+		typ:   metricTypeGauge,
+		help:  "Maximum bandwidth that can be used, in Mbps.",
+		value: func(c *defs.APISRTConn) float64 { return c.MbpsMaxBW },
+	},
+	{
+		name:  "srt_conns_bytes_mss",
+		typ:   metricTypeGauge,
+		help:  "Maximum segment size, in bytes.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.ByteMSS) },
+	},
+	{
+		name:     "srt_conns_packets_buf",
+		typ:      metricTypeGauge,
+		help:     "Packets in the send or receive buffer.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.PacketsSendBuf) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceiveBuf) },
+	},
+	{
+		name:     "srt_conns_bytes_buf",
+		typ:      metricTypeGauge,
+		help:     "Bytes in the send or receive buffer.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.BytesSendBuf) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.BytesReceiveBuf) },
+	},
+	{
+		name:     "srt_conns_ms_buf",
+		typ:      metricTypeGauge,
+		help:     "Timespan of packets in the send or receive buffer, in milliseconds.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.MsSendBuf) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.MsReceiveBuf) },
+	},
+	{
+		name:     "srt_conns_ms_tsb_pd_delay",
+		typ:      metricTypeGauge,
+		help:     "Timestamp-based packet delivery delay, in milliseconds.",
+		sent:     func(c *defs.APISRTConn) float64 { return float64(c.MsSendTsbPdDelay) },
+		received: func(c *defs.APISRTConn) float64 { return float64(c.MsReceiveTsbPdDelay) },
+	},
+	{
+		name:  "srt_conns_packets_reorder_tolerance",
+		typ:   metricTypeGauge,
+		help:  "Packet reorder tolerance.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReorderTolerance) },
+	},
+	{
+		name:  "srt_conns_packets_received_avg_belated_time",
+		typ:   metricTypeGauge,
+		help:  "Average time of belated packets.",
+		value: func(c *defs.APISRTConn) float64 { return float64(c.PacketsReceivedAvgBelatedTime) },
+	},
+	{
+		name:     "srt_conns_packets_loss_rate",
+		typ:      metricTypeGauge,
+		help:     "Percentage of lost packets.",
+		sent:     func(c *defs.APISRTConn) float64 { return c.PacketsSendLossRate },
+		received: func(c *defs.APISRTConn) float64 { return c.PacketsReceivedLossRate },
+	},
+}
+
+func (m *Metrics) writeSRT(
+	w *metricsWriter,
+	addSession func(string, string, string, string, uint64, uint64),
+) {
+	families := make([]*metricFamily, len(srtMetrics))
+	for i, sm := range srtMetrics {
+		families[i] = w.family(sm.name, sm.typ, "SRT connections: "+sm.help)
+	}
+
+	data, err := m.srtServer.APIConnsList()
+	if err != nil {
+		return
+	}
+
+	for _, i := range data.Items {
+		id := i.ID.String()
+		addSession("srt", id, i.Path, string(i.State), i.BytesReceived, i.BytesSent)
+
+		for j, sm := range srtMetrics {
+			if sm.value != nil {
+				families[j].add(labels("id", id, "path", i.Path), sm.value(i))
+			} else {
+				families[j].add(labels("id", id, "path", i.Path, "direction", "received"), sm.received(i))
+				families[j].add(labels("id", id, "path", i.Path, "direction", "sent"), sm.sent(i))
+			}
+		}
+	}
 }
 
 // SetPathManager is called by core.
@@ -501,3 +673,10 @@ func (m *Metrics) SetWebRTCServer(s api.WebRTCServer) {
 	defer m.mutex.Unlock()
 	m.webRTCServer = s
 }
+
+// SetRecordVerifier is called by core.
+func (m *Metrics) SetRecordVerifier(v metricsRecordVerifier) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.recordVerifier = v
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	contentTypePrometheus  = "text/plain; version=0.0.4; charset=utf-8"
+	contentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+type metricType int
+
+const (
+	metricTypeGauge metricType = iota
+	metricTypeCounter
+)
+
+func (t metricType) String() string {
+	if t == metricTypeCounter {
+		return "counter"
+	}
+	return "gauge"
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats a label set. Arguments are pairs of names and values.
+func labels(kv ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(kv); i += 2 {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(kv[i])
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(kv[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+type metricSample struct {
+	labels string
+	value  float64
+}
+
+type metricFamily struct {
+	name    string
+	typ     metricType
+	help    string
+	samples []metricSample
+}
+
+func (f *metricFamily) add(labels string, value float64) {
+	f.samples = append(f.samples, metricSample{
+		labels: labels,
+		value:  value,
+	})
+}
+
+// metricsWriter collects samples grouped by family,
+// since both the Prometheus and the OpenMetrics formats require samples
+// of the same family to be contiguous.
+type metricsWriter struct {
+	families []*metricFamily
+}
+
+func (w *metricsWriter) gauge(name string, help string) *metricFamily {
+	return w.family(name, metricTypeGauge, help)
+}
+
+// counter adds a counter family. The "_total" suffix must not be included in name.
+func (w *metricsWriter) counter(name string, help string) *metricFamily {
+	return w.family(name, metricTypeCounter, help)
+}
+
+func (w *metricsWriter) family(name string, typ metricType, help string) *metricFamily {
+	f := &metricFamily{
+		name: name,
+		typ:  typ,
+		help: help,
+	}
+	w.families = append(w.families, f)
+	return f
+}
+
+func (w *metricsWriter) write(openMetrics bool) string {
+	var b strings.Builder
+
+	for _, f := range w.families {
+		sampleName := f.name
+		if f.typ == metricTypeCounter {
+			sampleName += "_total"
+		}
+
+		// in the Prometheus format, the family name is the sample name
+		familyName := f.name
+		if !openMetrics {
+			familyName = sampleName
+		}
+
+		b.WriteString("# HELP " + familyName + " " + f.help + "\n")
+		b.WriteString("# TYPE " + familyName + " " + f.typ.String() + "\n")
+
+		for _, s := range f.samples {
+			b.WriteString(sampleName + s.labels + " " + strconv.FormatFloat(s.value, 'f', -1, 64) + "\n")
+		}
+	}
+
+	if openMetrics {
+		b.WriteString("# EOF\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	w := &metricsWriter{}
+
+	paths := w.gauge("paths", "Paths.")
+	paths.add(labels("path", `my"path`, "state", "ready"), 1)
+
+	bytes := w.counter("paths_bytes", "Bytes.")
+	bytes.add(labels("path", `my"path`, "direction", "received"), 1234)
+	bytes.add(labels("path", `my"path`, "direction", "sent"), 5678)
+
+	w.gauge("hls_muxers", "HLS muxers.")
+
+	require.Equal(t, "# HELP paths Paths.\n"+
+		"# TYPE paths gauge\n"+
+		`paths{path="my\"path",state="ready"} 1`+"\n"+
+		"# HELP paths_bytes_total Bytes.\n"+
+		"# TYPE paths_bytes_total counter\n"+
+		`paths_bytes_total{path="my\"path",direction="received"} 1234`+"\n"+
+		`paths_bytes_total{path="my\"path",direction="sent"} 5678`+"\n"+
+		"# HELP hls_muxers HLS muxers.\n"+
+		"# TYPE hls_muxers gauge\n",
+		w.write(false))
+
+	require.Equal(t, "# HELP paths Paths.\n"+
+		"# TYPE paths gauge\n"+
+		`paths{path="my\"path",state="ready"} 1`+"\n"+
+		"# HELP paths_bytes Bytes.\n"+
+		"# TYPE paths_bytes counter\n"+
+		`paths_bytes_total{path="my\"path",direction="received"} 1234`+"\n"+
+		`paths_bytes_total{path="my\"path",direction="sent"} 5678`+"\n"+
+		"# HELP hls_muxers HLS muxers.\n"+
+		"# TYPE hls_muxers gauge\n"+
+		"# EOF\n",
+		w.write(true))
+}
@@ -0,0 +1,218 @@
+// Package multicaster contains the multicaster.
+package multicaster
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/mpegts"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+	"golang.org/x/net/ipv4"
+)
+
+const (
+	writeTimeout = 10 * time.Second
+
+	// 7 MPEG-TS packets fill a standard 1316-bytes UDP payload.
+	mpegtsPayloadSize = 188 * 7
+)
+
+type packetWriter struct {
+	m    *Multicaster
+	addr *net.UDPAddr
+}
+
+// Write implements io.Writer.
+// Write errors are logged and discarded, in order not to stop the output
+// in case of temporary network issues.
+func (w *packetWriter) Write(p []byte) (int, error) {
+	_, err := w.m.conn.WriteTo(p, w.addr)
+	if err != nil {
+		w.m.writeErrLogger.Log(logger.Warn, err.Error())
+	}
+	return len(p), nil
+}
+
+// Multicaster sends a stream to a UDP multicast group.
+type Multicaster struct {
+	Address   string
+	Format    conf.MulticastOutputFormat
+	TTL       uint
+	Interface string
+	SAP       bool
+	PathName  string
+	Stream    *stream.Stream
+	Parent    logger.Writer
+
+	conn           *net.UDPConn
+	writeErrLogger logger.Writer
+	announcer      *sapAnnouncer
+
+	terminate chan struct{}
+	done      chan struct{}
+}
+
+// Initialize initializes Multicaster.
+func (m *Multicaster) Initialize() error {
+	addr, err := net.ResolveUDPAddr("udp4", m.Address)
+	if err != nil {
+		return err
+	}
+
+	m.conn, err = net.ListenUDP("udp4", nil)
+	if err != nil {
+		return err
+	}
+
+	err = m.setupConn()
+	if err != nil {
+		m.conn.Close() //nolint:errcheck
+		return err
+	}
+
+	m.writeErrLogger = logger.NewLimitedLogger(m)
+
+	switch m.Format {
+	case conf.MulticastOutputFormatRTP:
+		err = m.setupRTP(addr)
+
+	default:
+		err = m.setupMPEGTS(addr)
+	}
+	if err != nil {
+		m.conn.Close() //nolint:errcheck
+		return err
+	}
+
+	if m.SAP {
+		m.announcer = &sapAnnouncer{m: m}
+		m.announcer.initialize(addr)
+	}
+
+	m.terminate = make(chan struct{})
+	m.done = make(chan struct{})
+
+	m.Log(logger.Info, "sending to %s", m.Address)
+
+	m.Stream.StartReader(m)
+
+	go m.run()
+
+	return nil
+}
+
+// Close closes the Multicaster.
+func (m *Multicaster) Close() {
+	m.Log(logger.Info, "stopped")
+	close(m.terminate)
+	<-m.done
+}
+
+// Log implements logger.Writer.
+func (m *Multicaster) Log(level logger.Level, format string, args ...interface{}) {
+	m.Parent.Log(level, "[multicaster] "+format, args...)
+}
+
+func (m *Multicaster) setupConn() error {
+	connIP := ipv4.NewPacketConn(m.conn)
+
+	if m.Interface != "" {
+		intf, err := net.InterfaceByName(m.Interface)
+		if err != nil {
+			return err
+		}
+
+		err = connIP.SetMulticastInterface(intf)
+		if err != nil {
+			return err
+		}
+	}
+
+	return connIP.SetMulticastTTL(int(m.TTL))
+}
+
+func (m *Multicaster) setupMPEGTS(addr *net.UDPAddr) error {
+	bw := bufio.NewWriterSize(&packetWriter{m: m, addr: addr}, mpegtsPayloadSize)
+
+	return mpegts.FromStream(m.Stream, m, bw, m.conn, writeTimeout)
+}
+
+// setupRTP sends each media to a distinct port, starting from the configured one
+// and increasing by two, leaving odd ports to RTCP like RTSP does.
+func (m *Multicaster) setupRTP(addr *net.UDPAddr) error {
+	medias := m.Stream.Desc().Medias
+	if len(medias) == 0 {
+		return fmt.Errorf("stream has no medias")
+	}
+
+	for i, medi := range medias {
+		w := &packetWriter{
+			m: m,
+			addr: &net.UDPAddr{
+				IP:   addr.IP,
+				Port: addr.Port + i*2,
+			},
+		}
+
+		forma := medi.Formats[0]
+
+		m.Stream.AddReader(m, medi, forma, func(u unit.Unit) error {
+			for _, pkt := range u.GetRTPPackets() {
+				buf, err := pkt.Marshal()
+				if err != nil {
+					return err
+				}
+
+				w.Write(buf) //nolint:errcheck
+			}
+			return nil
+		})
+
+		m.Log(logger.Info, "%s (%s) is sent to port %d", medi.Type, forma.Codec(), w.addr.Port)
+	}
+
+	return nil
+}
+
+func (m *Multicaster) run() {
+	defer close(m.done)
+
+	m.runInner()
+
+	if m.announcer != nil {
+		m.announcer.delete()
+	}
+
+	m.Stream.RemoveReader(m)
+	m.conn.Close() //nolint:errcheck
+}
+
+func (m *Multicaster) runInner() {
+	var announceTicker <-chan time.Time
+
+	if m.announcer != nil {
+		m.announcer.announce()
+		t := time.NewTicker(sapInterval)
+		defer t.Stop()
+		announceTicker = t.C
+	}
+
+	for {
+		select {
+		case err := <-m.Stream.ReaderError(m):
+			m.Log(logger.Error, err.Error())
+			return
+
+		case <-announceTicker:
+			m.announcer.announce()
+
+		case <-m.terminate:
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package multicaster
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func TestMulticaster(t *testing.T) {
+	for _, ca := range []string{"mpegts", "rtp"} {
+		t.Run(ca, func(t *testing.T) {
+			desc := &description.Session{Medias: []*description.Media{test.MediaH264}}
+
+			str, err := stream.New(
+				512,
+				0,
+				0,
+				1460,
+				desc,
+				true,
+				test.NilLogger,
+			)
+			require.NoError(t, err)
+			defer str.Close()
+
+			pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer pc.Close()
+
+			var format conf.MulticastOutputFormat
+			if ca == "rtp" {
+				format = conf.MulticastOutputFormatRTP
+			}
+
+			m := &Multicaster{
+				Address: pc.LocalAddr().String(),
+				Format:  format,
+				TTL:     1,
+				Stream:  str,
+				Parent:  test.NilLogger,
+			}
+			err = m.Initialize()
+			require.NoError(t, err)
+			defer m.Close()
+
+			for i := 0; i < 4; i++ {
+				str.WriteUnit(test.MediaH264, test.FormatH264, &unit.H264{
+					Base: unit.Base{
+						PTS: int64(i) * 90000,
+					},
+					AU: [][]byte{
+						{5, 1}, // IDR
+					},
+				})
+			}
+
+			pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+			buf := make([]byte, 1500)
+			n, _, err := pc.ReadFrom(buf)
+			require.NoError(t, err)
+
+			if ca == "mpegts" {
+				require.Equal(t, 0, n%188)
+				require.Equal(t, byte(0x47), buf[0])
+			} else {
+				var pkt rtp.Packet
+				err = pkt.Unmarshal(buf[:n])
+				require.NoError(t, err)
+				require.Equal(t, uint8(96), pkt.PayloadType)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package multicaster
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+const (
+	sapPort     = 9875
+	sapInterval = 5 * time.Second
+)
+
+// destination of announcements, as described in RFC 2974.
+func sapAddress(group net.IP) *net.UDPAddr {
+	// administrative scope uses the highest address of the scope
+	if group.To4()[0] == 239 {
+		return &net.UDPAddr{IP: net.IPv4(239, 255, 255, 255), Port: sapPort}
+	}
+	return &net.UDPAddr{IP: net.IPv4(224, 2, 127, 254), Port: sapPort}
+}
+
+// SAP header, as described in RFC 2974.
+func marshalSAP(deletion bool, msgIDHash uint16, origin net.IP, sdp []byte) []byte {
+	flags := byte(1 << 5) // version 1
+	if deletion {
+		flags |= 1 << 2
+	}
+
+	buf := []byte{flags, 0, byte(msgIDHash >> 8), byte(msgIDHash)}
+	buf = append(buf, origin.To4()...)
+	buf = append(buf, []byte("application/sdp\x00")...)
+	return append(buf, sdp...)
+}
+
+func marshalSDP(
+	sessionID int64,
+	sessionName string,
+	origin net.IP,
+	group *net.UDPAddr,
+	ttl uint,
+	format conf.MulticastOutputFormat,
+	medias []*description.Media,
+) []byte {
+	var sb strings.Builder
+
+	sb.WriteString("v=0\r\n")
+	fmt.Fprintf(&sb, "o=- %d 1 IN IP4 %s\r\n", sessionID, origin)
+	fmt.Fprintf(&sb, "s=%s\r\n", sessionName)
+	fmt.Fprintf(&sb, "c=IN IP4 %s/%d\r\n", group.IP, ttl)
+	sb.WriteString("t=0 0\r\n")
+	sb.WriteString("a=tool:mediamtx\r\n")
+
+	if format == conf.MulticastOutputFormatRTP {
+		for i, medi := range medias {
+			forma := medi.Formats[0]
+			typ := strconv.FormatUint(uint64(forma.PayloadType()), 10)
+
+			fmt.Fprintf(&sb, "m=%s %d RTP/AVP %s\r\n", medi.Type, group.Port+i*2, typ)
+
+			if rtpmap := forma.RTPMap(); rtpmap != "" {
+				fmt.Fprintf(&sb, "a=rtpmap:%s %s\r\n", typ, rtpmap)
+			}
+
+			if fmtp := forma.FMTP(); len(fmtp) != 0 {
+				keys := make([]string, 0, len(fmtp))
+				for key := range fmtp {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+
+				tmp := make([]string, len(keys))
+				for j, key := range keys {
+					tmp[j] = key + "=" + fmtp[key]
+				}
+
+				fmt.Fprintf(&sb, "a=fmtp:%s %s\r\n", typ, strings.Join(tmp, "; "))
+			}
+		}
+	} else {
+		fmt.Fprintf(&sb, "m=video %d udp mpeg\r\n", group.Port)
+	}
+
+	return []byte(sb.String())
+}
+
+// originAddress returns the address packets are sent from.
+func originAddress(intf string, dest *net.UDPAddr) net.IP {
+	if intf != "" {
+		i, err := net.InterfaceByName(intf)
+		if err == nil {
+			addrs, _ := i.Addrs()
+			for _, addr := range addrs {
+				if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+					return ipnet.IP.To4()
+				}
+			}
+		}
+	}
+
+	conn, err := net.DialUDP("udp4", nil, dest)
+	if err == nil {
+		defer conn.Close()
+		return conn.LocalAddr().(*net.UDPAddr).IP.To4()
+	}
+
+	return net.IPv4zero.To4()
+}
+
+// sapAnnouncer periodically announces a multicast output with SAP,
+// allowing receivers to discover it.
+type sapAnnouncer struct {
+	m *Multicaster
+
+	addr         *net.UDPAddr
+	announcement []byte
+	deletion     []byte
+}
+
+func (a *sapAnnouncer) initialize(group *net.UDPAddr) {
+	a.addr = sapAddress(group.IP)
+
+	origin := originAddress(a.m.Interface, group)
+
+	sdp := marshalSDP(
+		time.Now().Unix(),
+		a.m.PathName,
+		origin,
+		group,
+		a.m.TTL,
+		a.m.Format,
+		a.m.Stream.Desc().Medias)
+
+	h := fnv.New32a()
+	h.Write(sdp)
+	msgIDHash := uint16(h.Sum32())
+
+	a.announcement = marshalSAP(false, msgIDHash, origin, sdp)
+	a.deletion = marshalSAP(true, msgIDHash, origin, sdp)
+}
+
+func (a *sapAnnouncer) announce() {
+	a.write(a.announcement)
+}
+
+func (a *sapAnnouncer) delete() {
+	a.write(a.deletion)
+}
+
+func (a *sapAnnouncer) write(buf []byte) {
+	_, err := a.m.conn.WriteTo(buf, a.addr)
+	if err != nil {
+		a.m.writeErrLogger.Log(logger.Warn, err.Error())
+	}
+}
@@ -0,0 +1,48 @@
+package multicaster
+
+import (
+	"net"
+	"testing"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/test"
+)
+
+func TestMarshalSDP(t *testing.T) {
+	group := &net.UDPAddr{IP: net.IPv4(239, 0, 0, 1), Port: 1234}
+	origin := net.IPv4(192, 168, 1, 10)
+
+	t.Run("mpegts", func(t *testing.T) {
+		sdp := marshalSDP(123, "mypath", origin, group, 4, conf.MulticastOutputFormatMPEGTS,
+			[]*description.Media{test.MediaH264})
+
+		require.Equal(t, "v=0\r\n"+
+			"o=- 123 1 IN IP4 192.168.1.10\r\n"+
+			"s=mypath\r\n"+
+			"c=IN IP4 239.0.0.1/4\r\n"+
+			"t=0 0\r\n"+
+			"a=tool:mediamtx\r\n"+
+			"m=video 1234 udp mpeg\r\n", string(sdp))
+	})
+
+	t.Run("rtp", func(t *testing.T) {
+		sdp := marshalSDP(123, "mypath", origin, group, 4, conf.MulticastOutputFormatRTP,
+			[]*description.Media{test.MediaH264, test.MediaMPEG4Audio})
+
+		require.Contains(t, string(sdp), "m=video 1234 RTP/AVP 96\r\n"+
+			"a=rtpmap:96 H264/90000\r\n")
+		require.Contains(t, string(sdp), "m=audio 1236 RTP/AVP 96\r\n"+
+			"a=rtpmap:96 mpeg4-generic/44100/2\r\n")
+	})
+}
+
+func TestMarshalSAP(t *testing.T) {
+	buf := marshalSAP(true, 0x1234, net.IPv4(192, 168, 1, 10), []byte("v=0\r\n"))
+	require.Equal(t, append([]byte{
+		0x24, 0x00, 0x12, 0x34,
+		192, 168, 1, 10,
+	}, []byte("application/sdp\x00v=0\r\n")...), buf)
+}
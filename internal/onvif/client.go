@@ -0,0 +1,198 @@
+package onvif
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	maxResponseSize = 1024 * 1024
+)
+
+const envelopeTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope">
+<s:Header>%s</s:Header>
+<s:Body>%s</s:Body>
+</s:Envelope>`
+
+const securityTemplate = `<Security s:mustUnderstand="1" ` +
+	`xmlns="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd">` +
+	`<UsernameToken>` +
+	`<Username>%s</Username>` +
+	`<Password Type="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest">` +
+	`%s</Password>` +
+	`<Nonce EncodingType="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary">` +
+	`%s</Nonce>` +
+	`<Created xmlns="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd">%s</Created>` +
+	`</UsernameToken>` +
+	`</Security>`
+
+const getCapabilitiesBody = `<GetCapabilities xmlns="http://www.onvif.org/ver10/device/wsdl">` +
+	`<Category>Media</Category>` +
+	`</GetCapabilities>`
+
+const getProfilesBody = `<GetProfiles xmlns="http://www.onvif.org/ver10/media/wsdl"/>`
+
+const getStreamURIBody = `<GetStreamUri xmlns="http://www.onvif.org/ver10/media/wsdl">` +
+	`<StreamSetup>` +
+	`<Stream xmlns="http://www.onvif.org/ver10/schema">RTP-Unicast</Stream>` +
+	`<Transport xmlns="http://www.onvif.org/ver10/schema"><Protocol>RTSP</Protocol></Transport>` +
+	`</StreamSetup>` +
+	`<ProfileToken>%s</ProfileToken>` +
+	`</GetStreamUri>`
+
+type getCapabilitiesResponse struct {
+	MediaXAddr string `xml:"Body>GetCapabilitiesResponse>Capabilities>Media>XAddr"`
+}
+
+type getProfilesResponse struct {
+	Profiles []struct {
+		Token string `xml:"token,attr"`
+	} `xml:"Body>GetProfilesResponse>Profiles"`
+}
+
+type getStreamURIResponse struct {
+	URI string `xml:"Body>GetStreamUriResponse>MediaUri>Uri"`
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s)) //nolint:errcheck
+	return buf.String()
+}
+
+// Client is an ONVIF client.
+type Client struct {
+	// address of the device service
+	URL string
+
+	// credentials, used with WS-Security UsernameToken authentication
+	User string
+	Pass string
+
+	HTTPClient *http.Client
+}
+
+// NewClientFromURL allocates a Client from the URL of the device service.
+// Credentials, if present in the URL, are moved into User and Pass.
+func NewClientFromURL(deviceURL string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(deviceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		HTTPClient: httpClient,
+	}
+
+	if u.User != nil {
+		c.User = u.User.Username()
+		c.Pass, _ = u.User.Password()
+		u.User = nil
+	}
+
+	c.URL = u.String()
+
+	return c, nil
+}
+
+func (c *Client) securityHeader() (string, error) {
+	if c.User == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, 16)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	created := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+
+	h := sha1.New() //nolint:gosec
+	h.Write(nonce)
+	h.Write([]byte(created))
+	h.Write([]byte(c.Pass))
+
+	return fmt.Sprintf(securityTemplate,
+		xmlEscape(c.User),
+		base64.StdEncoding.EncodeToString(h.Sum(nil)),
+		base64.StdEncoding.EncodeToString(nonce),
+		created), nil
+}
+
+func (c *Client) do(ctx context.Context, u string, body string, res interface{}) error {
+	header, err := c.securityHeader()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u,
+		bytes.NewReader([]byte(fmt.Sprintf(envelopeTemplate, header, body))))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/soap+xml; charset=utf-8")
+
+	hres, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer hres.Body.Close()
+
+	if hres.StatusCode != http.StatusOK {
+		return fmt.Errorf("bad status code: %d", hres.StatusCode)
+	}
+
+	byts, err := io.ReadAll(io.LimitReader(hres.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	return xml.Unmarshal(byts, res)
+}
+
+// GetStreamURI returns the RTSP URI of the first media profile of the device.
+func (c *Client) GetStreamURI(ctx context.Context) (string, error) {
+	var capRes getCapabilitiesResponse
+	err := c.do(ctx, c.URL, getCapabilitiesBody, &capRes)
+	if err != nil {
+		return "", fmt.Errorf("GetCapabilities failed: %w", err)
+	}
+
+	if capRes.MediaXAddr == "" {
+		return "", fmt.Errorf("device doesn't provide a media service")
+	}
+
+	var profRes getProfilesResponse
+	err = c.do(ctx, capRes.MediaXAddr, getProfilesBody, &profRes)
+	if err != nil {
+		return "", fmt.Errorf("GetProfiles failed: %w", err)
+	}
+
+	if len(profRes.Profiles) == 0 {
+		return "", fmt.Errorf("device doesn't provide any media profile")
+	}
+
+	var uriRes getStreamURIResponse
+	err = c.do(ctx, capRes.MediaXAddr, fmt.Sprintf(getStreamURIBody, xmlEscape(profRes.Profiles[0].Token)), &uriRes)
+	if err != nil {
+		return "", fmt.Errorf("GetStreamUri failed: %w", err)
+	}
+
+	if uriRes.URI == "" {
+		return "", fmt.Errorf("device returned an empty stream URI")
+	}
+
+	return uriRes.URI, nil
+}
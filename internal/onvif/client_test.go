@@ -0,0 +1,76 @@
+package onvif
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientGetStreamURI(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:9005")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	s := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			byts, err2 := io.ReadAll(r.Body)
+			require.NoError(t, err2)
+			body := string(byts)
+
+			require.Contains(t, body, "<Username>myuser</Username>")
+
+			w.Header().Set("Content-Type", "application/soap+xml")
+
+			switch {
+			case r.URL.Path == "/onvif/device_service" && strings.Contains(body, "GetCapabilities"):
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"
+	xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<s:Body><tds:GetCapabilitiesResponse><tds:Capabilities><tt:Media>
+<tt:XAddr>http://127.0.0.1:9005/onvif/media_service</tt:XAddr>
+</tt:Media></tds:Capabilities></tds:GetCapabilitiesResponse></s:Body>
+</s:Envelope>`))
+
+			case r.URL.Path == "/onvif/media_service" && strings.Contains(body, "GetProfiles"):
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:trt="http://www.onvif.org/ver10/media/wsdl">
+<s:Body><trt:GetProfilesResponse>
+<trt:Profiles token="profile_1"></trt:Profiles>
+<trt:Profiles token="profile_2"></trt:Profiles>
+</trt:GetProfilesResponse></s:Body>
+</s:Envelope>`))
+
+			case r.URL.Path == "/onvif/media_service" && strings.Contains(body, "GetStreamUri"):
+				require.Contains(t, body, "<ProfileToken>profile_1</ProfileToken>")
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"
+	xmlns:trt="http://www.onvif.org/ver10/media/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<s:Body><trt:GetStreamUriResponse><trt:MediaUri>
+<tt:Uri>rtsp://127.0.0.1:554/stream1</tt:Uri>
+</trt:MediaUri></trt:GetStreamUriResponse></s:Body>
+</s:Envelope>`))
+
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}),
+	}
+	go s.Serve(ln)
+	defer s.Shutdown(context.Background())
+
+	c := &Client{
+		URL:        "http://127.0.0.1:9005/onvif/device_service",
+		User:       "myuser",
+		Pass:       "mypass",
+		HTTPClient: &http.Client{},
+	}
+
+	uri, err := c.GetStreamURI(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "rtsp://127.0.0.1:554/stream1", uri)
+}
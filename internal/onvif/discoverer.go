@@ -0,0 +1,148 @@
+// Package onvif contains an ONVIF camera discoverer.
+package onvif
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+const (
+	probeTimeout   = 3 * time.Second
+	requestTimeout = 10 * time.Second
+)
+
+// PathName returns the name of the path of a discovered camera.
+func PathName(template string, host string) string {
+	return strings.ReplaceAll(template, "%host", host)
+}
+
+// DiscoveredCamera is a camera found by the Discoverer.
+type DiscoveredCamera struct {
+	// RTSP URL of the first media profile
+	Source string
+
+	// address of the device service, used to send PTZ commands
+	DeviceURL string
+}
+
+type discovererParent interface {
+	logger.Writer
+	ONVIFAddPaths(ctx context.Context, paths map[string]DiscoveredCamera)
+}
+
+// Discoverer periodically discovers ONVIF cameras in the local network
+// and asks its parent to create a path for each of them.
+type Discoverer struct {
+	Interval conf.Duration
+	User     string
+	Pass     string
+	PathName string
+	Parent   discovererParent
+
+	ctx       context.Context
+	ctxCancel func()
+
+	done chan struct{}
+}
+
+// Initialize initializes a Discoverer.
+func (d *Discoverer) Initialize() {
+	d.ctx, d.ctxCancel = context.WithCancel(context.Background())
+	d.done = make(chan struct{})
+
+	d.Log(logger.Info, "discoverer started")
+
+	go d.run()
+}
+
+// Close closes the Discoverer.
+func (d *Discoverer) Close() {
+	d.Log(logger.Info, "discoverer is shutting down")
+	d.ctxCancel()
+	<-d.done
+}
+
+// Log implements logger.Writer.
+func (d *Discoverer) Log(level logger.Level, format string, args ...interface{}) {
+	d.Parent.Log(level, "[ONVIF] "+format, args...)
+}
+
+func (d *Discoverer) run() {
+	defer close(d.done)
+
+	d.discover()
+
+	for {
+		select {
+		case <-time.After(time.Duration(d.Interval)):
+			d.discover()
+
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
+
+func (d *Discoverer) discover() {
+	addrs, err := Probe(d.ctx, probeTimeout)
+	if err != nil {
+		if d.ctx.Err() == nil {
+			d.Log(logger.Warn, "probe failed: %v", err)
+		}
+		return
+	}
+
+	d.Log(logger.Debug, "%d devices replied to probe", len(addrs))
+
+	paths := make(map[string]DiscoveredCamera)
+
+	for _, addr := range addrs {
+		u, err := url.Parse(addr)
+		if err != nil {
+			d.Log(logger.Warn, "invalid device address '%s'", addr)
+			continue
+		}
+
+		c := &Client{
+			URL:        addr,
+			User:       d.User,
+			Pass:       d.Pass,
+			HTTPClient: &http.Client{Timeout: requestTimeout},
+		}
+
+		streamURI, err := c.GetStreamURI(d.ctx)
+		if err != nil {
+			if d.ctx.Err() != nil {
+				return
+			}
+			d.Log(logger.Warn, "unable to get stream URI of device '%s': %v", addr, err)
+			continue
+		}
+
+		source, err := url.Parse(streamURI)
+		if err != nil {
+			d.Log(logger.Warn, "device '%s' returned an invalid stream URI: %v", addr, err)
+			continue
+		}
+
+		if d.User != "" {
+			source.User = url.UserPassword(d.User, d.Pass)
+			u.User = url.UserPassword(d.User, d.Pass)
+		}
+
+		paths[PathName(d.PathName, u.Hostname())] = DiscoveredCamera{
+			Source:    source.String(),
+			DeviceURL: u.String(),
+		}
+	}
+
+	if len(paths) != 0 {
+		d.Parent.ONVIFAddPaths(d.ctx, paths)
+	}
+}
@@ -0,0 +1,128 @@
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	discoveryAddress = "239.255.255.250:3702"
+)
+
+const probeTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:a="http://schemas.xmlsoap.org/ws/2004/08/addressing">
+<s:Header>
+<a:Action s:mustUnderstand="1">http://schemas.xmlsoap.org/ws/2005/04/discovery/Probe</a:Action>
+<a:MessageID>uuid:%s</a:MessageID>
+<a:ReplyTo><a:Address>http://schemas.xmlsoap.org/ws/2004/08/addressing/role/anonymous</a:Address></a:ReplyTo>
+<a:To s:mustUnderstand="1">urn:schemas-xmlsoap-org:ws:2005:04:discovery</a:To>
+</s:Header>
+<s:Body>
+<Probe xmlns="http://schemas.xmlsoap.org/ws/2005/04/discovery">
+<d:Types xmlns:d="http://schemas.xmlsoap.org/ws/2005/04/discovery" xmlns:dn="http://www.onvif.org/ver10/network/wsdl">dn:NetworkVideoTransmitter</d:Types>
+</Probe>
+</s:Body>
+</s:Envelope>`
+
+type probeMatches struct {
+	Matches []struct {
+		XAddrs string `xml:"XAddrs"`
+	} `xml:"Body>ProbeMatches>ProbeMatch"`
+}
+
+// parseProbeMatches returns the addresses of device services contained in a ProbeMatches message.
+// When a device provides multiple addresses, only the first one is returned.
+func parseProbeMatches(byts []byte) ([]string, error) {
+	var m probeMatches
+	err := xml.Unmarshal(byts, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+
+	for _, match := range m.Matches {
+		addrs := strings.Fields(match.XAddrs)
+		if len(addrs) != 0 {
+			ret = append(ret, addrs[0])
+		}
+	}
+
+	return ret, nil
+}
+
+// Probe sends a WS-Discovery probe to the local network and returns
+// the addresses of the device services of cameras that replied within timeout.
+func Probe(ctx context.Context, timeout time.Duration) ([]string, error) {
+	pc, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer pc.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		pc.Close()
+	})
+	defer stop()
+
+	addr, err := net.ResolveUDPAddr("udp4", discoveryAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = pc.WriteTo([]byte(fmt.Sprintf(probeTemplate, uuid.New())), addr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pc.SetReadDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	buf := make([]byte, 65536)
+
+	for {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				break
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		addrs, err := parseProbeMatches(buf[:n])
+		if err != nil {
+			continue
+		}
+
+		for _, a := range addrs {
+			if !contains(ret, a) {
+				ret = append(ret, a)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
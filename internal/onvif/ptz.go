@@ -0,0 +1,163 @@
+package onvif
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const getAllCapabilitiesBody = `<GetCapabilities xmlns="http://www.onvif.org/ver10/device/wsdl">` +
+	`<Category>All</Category>` +
+	`</GetCapabilities>`
+
+const continuousMoveBody = `<ContinuousMove xmlns="http://www.onvif.org/ver20/ptz/wsdl">` +
+	`<ProfileToken>%s</ProfileToken>` +
+	`<Velocity>` +
+	`<PanTilt xmlns="http://www.onvif.org/ver10/schema" x="%s" y="%s"/>` +
+	`<Zoom xmlns="http://www.onvif.org/ver10/schema" x="%s"/>` +
+	`</Velocity>` +
+	`%s` +
+	`</ContinuousMove>`
+
+const gotoPresetBody = `<GotoPreset xmlns="http://www.onvif.org/ver20/ptz/wsdl">` +
+	`<ProfileToken>%s</ProfileToken>` +
+	`<PresetToken>%s</PresetToken>` +
+	`</GotoPreset>`
+
+const stopBody = `<Stop xmlns="http://www.onvif.org/ver20/ptz/wsdl">` +
+	`<ProfileToken>%s</ProfileToken>` +
+	`<PanTilt>true</PanTilt>` +
+	`<Zoom>true</Zoom>` +
+	`</Stop>`
+
+const getPresetsBody = `<GetPresets xmlns="http://www.onvif.org/ver20/ptz/wsdl">` +
+	`<ProfileToken>%s</ProfileToken>` +
+	`</GetPresets>`
+
+type getAllCapabilitiesResponse struct {
+	MediaXAddr string `xml:"Body>GetCapabilitiesResponse>Capabilities>Media>XAddr"`
+	PTZXAddr   string `xml:"Body>GetCapabilitiesResponse>Capabilities>PTZ>XAddr"`
+}
+
+type getPresetsResponse struct {
+	Presets []struct {
+		Token string `xml:"token,attr"`
+		Name  string `xml:"Name"`
+	} `xml:"Body>GetPresetsResponse>Preset"`
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// Preset is a PTZ preset.
+type Preset struct {
+	Token string
+	Name  string
+}
+
+// PTZ is the PTZ service of a device.
+// Commands are applied to the first media profile of the device.
+type PTZ struct {
+	client       *Client
+	url          string
+	profileToken string
+}
+
+// PTZ returns the PTZ service of the device.
+func (c *Client) PTZ(ctx context.Context) (*PTZ, error) {
+	var capRes getAllCapabilitiesResponse
+	err := c.do(ctx, c.URL, getAllCapabilitiesBody, &capRes)
+	if err != nil {
+		return nil, fmt.Errorf("GetCapabilities failed: %w", err)
+	}
+
+	if capRes.PTZXAddr == "" {
+		return nil, fmt.Errorf("device doesn't provide a PTZ service")
+	}
+
+	if capRes.MediaXAddr == "" {
+		return nil, fmt.Errorf("device doesn't provide a media service")
+	}
+
+	var profRes getProfilesResponse
+	err = c.do(ctx, capRes.MediaXAddr, getProfilesBody, &profRes)
+	if err != nil {
+		return nil, fmt.Errorf("GetProfiles failed: %w", err)
+	}
+
+	if len(profRes.Profiles) == 0 {
+		return nil, fmt.Errorf("device doesn't provide any media profile")
+	}
+
+	return &PTZ{
+		client:       c,
+		url:          capRes.PTZXAddr,
+		profileToken: profRes.Profiles[0].Token,
+	}, nil
+}
+
+// ContinuousMove starts moving the camera with the given velocities,
+// that range from -1 to 1. If timeout is not zero, the camera stops after it.
+func (p *PTZ) ContinuousMove(ctx context.Context, pan float64, tilt float64, zoom float64, timeout time.Duration) error {
+	var timeoutElem string
+	if timeout != 0 {
+		timeoutElem = "<Timeout>PT" + formatFloat(timeout.Seconds()) + "S</Timeout>"
+	}
+
+	err := p.client.do(ctx, p.url, fmt.Sprintf(continuousMoveBody,
+		xmlEscape(p.profileToken),
+		formatFloat(pan),
+		formatFloat(tilt),
+		formatFloat(zoom),
+		timeoutElem), &struct{}{})
+	if err != nil {
+		return fmt.Errorf("ContinuousMove failed: %w", err)
+	}
+
+	return nil
+}
+
+// GotoPreset moves the camera to a preset.
+func (p *PTZ) GotoPreset(ctx context.Context, presetToken string) error {
+	err := p.client.do(ctx, p.url, fmt.Sprintf(gotoPresetBody,
+		xmlEscape(p.profileToken),
+		xmlEscape(presetToken)), &struct{}{})
+	if err != nil {
+		return fmt.Errorf("GotoPreset failed: %w", err)
+	}
+
+	return nil
+}
+
+// Stop stops any movement of the camera.
+func (p *PTZ) Stop(ctx context.Context) error {
+	err := p.client.do(ctx, p.url, fmt.Sprintf(stopBody,
+		xmlEscape(p.profileToken)), &struct{}{})
+	if err != nil {
+		return fmt.Errorf("Stop failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetPresets returns presets of the camera.
+func (p *PTZ) GetPresets(ctx context.Context) ([]Preset, error) {
+	var res getPresetsResponse
+	err := p.client.do(ctx, p.url, fmt.Sprintf(getPresetsBody,
+		xmlEscape(p.profileToken)), &res)
+	if err != nil {
+		return nil, fmt.Errorf("GetPresets failed: %w", err)
+	}
+
+	presets := make([]Preset, len(res.Presets))
+	for i, pr := range res.Presets {
+		presets[i] = Preset{
+			Token: pr.Token,
+			Name:  pr.Name,
+		}
+	}
+
+	return presets, nil
+}
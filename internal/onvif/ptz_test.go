@@ -0,0 +1,112 @@
+package onvif
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPTZ(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	var ptzBodies []string
+
+	s := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			byts, err2 := io.ReadAll(r.Body)
+			require.NoError(t, err2)
+			body := string(byts)
+
+			require.Contains(t, body, "<Username>myuser</Username>")
+
+			w.Header().Set("Content-Type", "application/soap+xml")
+
+			switch {
+			case r.URL.Path == "/onvif/device_service" && strings.Contains(body, "GetCapabilities"):
+				require.Contains(t, body, "<Category>All</Category>")
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"
+	xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<s:Body><tds:GetCapabilitiesResponse><tds:Capabilities>
+<tt:Media><tt:XAddr>http://` + ln.Addr().String() + `/onvif/media_service</tt:XAddr></tt:Media>
+<tt:PTZ><tt:XAddr>http://` + ln.Addr().String() + `/onvif/ptz_service</tt:XAddr></tt:PTZ>
+</tds:Capabilities></tds:GetCapabilitiesResponse></s:Body>
+</s:Envelope>`))
+
+			case r.URL.Path == "/onvif/media_service" && strings.Contains(body, "GetProfiles"):
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:trt="http://www.onvif.org/ver10/media/wsdl">
+<s:Body><trt:GetProfilesResponse>
+<trt:Profiles token="profile_1"></trt:Profiles>
+</trt:GetProfilesResponse></s:Body>
+</s:Envelope>`))
+
+			case r.URL.Path == "/onvif/ptz_service" && strings.Contains(body, "GetPresets"):
+				ptzBodies = append(ptzBodies, body)
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"
+	xmlns:tptz="http://www.onvif.org/ver20/ptz/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<s:Body><tptz:GetPresetsResponse>
+<tptz:Preset token="1"><tt:Name>door</tt:Name></tptz:Preset>
+<tptz:Preset token="2"><tt:Name>window</tt:Name></tptz:Preset>
+</tptz:GetPresetsResponse></s:Body>
+</s:Envelope>`))
+
+			case r.URL.Path == "/onvif/ptz_service":
+				ptzBodies = append(ptzBodies, body)
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope"><s:Body></s:Body></s:Envelope>`))
+
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}),
+	}
+	go s.Serve(ln)
+	defer s.Shutdown(context.Background())
+
+	c, err := NewClientFromURL("http://myuser:mypass@"+ln.Addr().String()+"/onvif/device_service", &http.Client{})
+	require.NoError(t, err)
+
+	ptz, err := c.PTZ(context.Background())
+	require.NoError(t, err)
+
+	err = ptz.ContinuousMove(context.Background(), 0.5, -0.25, 0, 1500*time.Millisecond)
+	require.NoError(t, err)
+
+	err = ptz.GotoPreset(context.Background(), "2")
+	require.NoError(t, err)
+
+	err = ptz.Stop(context.Background())
+	require.NoError(t, err)
+
+	presets, err := ptz.GetPresets(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []Preset{
+		{Token: "1", Name: "door"},
+		{Token: "2", Name: "window"},
+	}, presets)
+
+	require.Len(t, ptzBodies, 4)
+
+	require.Contains(t, ptzBodies[0], "<ProfileToken>profile_1</ProfileToken>")
+	require.Contains(t, ptzBodies[0], `<PanTilt xmlns="http://www.onvif.org/ver10/schema" x="0.5" y="-0.25"/>`)
+	require.Contains(t, ptzBodies[0], `<Zoom xmlns="http://www.onvif.org/ver10/schema" x="0"/>`)
+	require.Contains(t, ptzBodies[0], "<Timeout>PT1.5S</Timeout>")
+
+	require.Contains(t, ptzBodies[1], "<GotoPreset")
+	require.Contains(t, ptzBodies[1], "<PresetToken>2</PresetToken>")
+
+	require.Contains(t, ptzBodies[2], "<Stop")
+	require.Contains(t, ptzBodies[2], "<PanTilt>true</PanTilt>")
+
+	require.Contains(t, ptzBodies[3], "<ProfileToken>profile_1</ProfileToken>")
+}
@@ -0,0 +1,29 @@
+package playback
+
+import (
+	"io"
+	"time"
+)
+
+// segments can't last more than a day, therefore this includes all samples.
+const convertMaxDuration = 48 * time.Hour
+
+// ConvertFMP4ToMP4 converts a fMP4 segment into a regular MP4 file.
+// The moov box is written before media data (faststart), therefore
+// the file can be played back progressively by desktop players.
+func ConvertFMP4ToMP4(r readSeekerAt, w io.Writer) error {
+	init, _, err := segmentFMP4ReadHeader(r)
+	if err != nil {
+		return err
+	}
+
+	m := &muxerMP4{w: w}
+	m.writeInit(init)
+
+	_, err = segmentFMP4MuxParts(r, 0, convertMaxDuration, init, m)
+	if err != nil {
+		return err
+	}
+
+	return m.flush()
+}
@@ -0,0 +1,35 @@
+package playback
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertFMP4ToMP4(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-playback")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "2008-11-07_11-22-00-500000.mp4")
+	writeSegment1(t, fpath)
+
+	f, err := os.Open(fpath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var buf bytes.Buffer
+	err = ConvertFMP4ToMP4(f, &buf)
+	require.NoError(t, err)
+
+	byts := buf.Bytes()
+	require.Equal(t, []byte("ftyp"), byts[4:8])
+
+	// moov must be placed before mdat
+	ftypSize := binary.BigEndian.Uint32(byts[0:4])
+	require.Equal(t, []byte("moov"), byts[ftypSize+4:ftypSize+8])
+}
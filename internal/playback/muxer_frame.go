@@ -0,0 +1,174 @@
+package playback
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+)
+
+var errFrameNotFound = errors.New("frame not found")
+
+type muxerFrameSample struct {
+	dts             int64
+	ptsOffset       int32
+	isNonSyncSample bool
+	payloadSize     uint32
+	getPayload      func() ([]byte, error)
+}
+
+func (s *muxerFrameSample) pts() int64 {
+	return s.dts + int64(s.ptsOffset)
+}
+
+// muxerFrame extracts a single frame of the video track.
+type muxerFrame struct {
+	target time.Duration
+	step   int
+	mp4    bool
+
+	track      *fmp4.InitTrack
+	isCurTrack bool
+	samples    []*muxerFrameSample
+
+	// out
+	pts        time.Duration
+	prevPTS    *time.Duration
+	nextPTS    *time.Duration
+	isKeyFrame bool
+	payload    []byte
+}
+
+func (w *muxerFrame) writeInit(init *fmp4.Init) {
+	for _, track := range init.Tracks {
+		if track.Codec.IsVideo() {
+			w.track = track
+			return
+		}
+	}
+}
+
+func (w *muxerFrame) setTrack(trackID int) {
+	w.isCurTrack = (w.track != nil && w.track.ID == trackID)
+}
+
+func (w *muxerFrame) writeSample(
+	dts int64,
+	ptsOffset int32,
+	isNonSyncSample bool,
+	payloadSize uint32,
+	getPayload func() ([]byte, error),
+) error {
+	if !w.isCurTrack {
+		return nil
+	}
+
+	w.samples = append(w.samples, &muxerFrameSample{
+		dts:             dts,
+		ptsOffset:       ptsOffset,
+		isNonSyncSample: isNonSyncSample,
+		payloadSize:     payloadSize,
+		getPayload:      getPayload,
+	})
+
+	return nil
+}
+
+func (w *muxerFrame) writeFinalDTS(_ int64) {
+}
+
+func (w *muxerFrame) ptsGo(i int) time.Duration {
+	return durationMp4ToGo(w.samples[i].pts(), w.track.TimeScale)
+}
+
+func (w *muxerFrame) flush() error {
+	if w.track == nil || len(w.samples) == 0 {
+		return errFrameNotFound
+	}
+
+	// samples are in decoding order, sort them in presentation order
+	order := make([]int, len(w.samples))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return w.samples[order[i]].pts() < w.samples[order[j]].pts()
+	})
+
+	// find the frame that is displayed at the target time
+	pos := 0
+	for i, j := range order {
+		if w.ptsGo(j) > w.target {
+			break
+		}
+		pos = i
+	}
+
+	pos += w.step
+	if pos < 0 || pos >= len(order) {
+		return errFrameNotFound
+	}
+
+	i := order[pos]
+	w.pts = w.ptsGo(i)
+	w.isKeyFrame = !w.samples[i].isNonSyncSample
+
+	if pos > 0 {
+		v := w.ptsGo(order[pos-1])
+		w.prevPTS = &v
+	}
+	if pos < (len(order) - 1) {
+		v := w.ptsGo(order[pos+1])
+		w.nextPTS = &v
+	}
+
+	if !w.mp4 {
+		var err error
+		w.payload, err = w.samples[i].getPayload()
+		return err
+	}
+
+	return w.flushMP4(i)
+}
+
+// flushMP4 writes the frame into a MP4 file,
+// together with all the frames that are needed to decode it.
+func (w *muxerFrame) flushMP4(i int) error {
+	first := i
+	for first > 0 && w.samples[first].isNonSyncSample {
+		first--
+	}
+
+	var buf bytes.Buffer
+	m := &muxerMP4{w: &buf}
+	m.writeInit(&fmp4.Init{Tracks: []*fmp4.InitTrack{w.track}})
+	m.setTrack(w.track.ID)
+
+	baseDTS := w.samples[first].dts
+
+	for _, sample := range w.samples[first : i+1] {
+		err := m.writeSample(
+			sample.dts-baseDTS,
+			sample.ptsOffset,
+			sample.isNonSyncSample,
+			sample.payloadSize,
+			sample.getPayload)
+		if err != nil {
+			return err
+		}
+	}
+
+	if i < (len(w.samples) - 1) {
+		m.writeFinalDTS(w.samples[i+1].dts - baseDTS)
+	}
+
+	err := m.flush()
+	if err != nil {
+		return err
+	}
+
+	w.payload = buf.Bytes()
+	return nil
+}
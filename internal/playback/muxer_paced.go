@@ -0,0 +1,69 @@
+package playback
+
+import (
+	"context"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+)
+
+// muxerPaced is a muxer wrapper that writes samples at a multiple of real time,
+// in order to prevent downloads from reading the disk at full speed.
+type muxerPaced struct {
+	ctx   context.Context
+	rate  float64
+	inner muxer
+
+	timeScales   map[int]uint32
+	curTimeScale uint32
+	start        time.Time
+}
+
+func (m *muxerPaced) writeInit(init *fmp4.Init) {
+	m.timeScales = make(map[int]uint32)
+	for _, track := range init.Tracks {
+		m.timeScales[track.ID] = track.TimeScale
+	}
+	m.start = time.Now()
+
+	m.inner.writeInit(init)
+}
+
+func (m *muxerPaced) setTrack(trackID int) {
+	m.curTimeScale = m.timeScales[trackID]
+	m.inner.setTrack(trackID)
+}
+
+func (m *muxerPaced) writeSample(
+	dts int64,
+	ptsOffset int32,
+	isNonSyncSample bool,
+	payloadSize uint32,
+	getPayload func() ([]byte, error),
+) error {
+	if dts > 0 && m.curTimeScale != 0 {
+		elapsed := time.Duration(float64(dts) * float64(time.Second) / float64(m.curTimeScale) / m.rate)
+		wait := time.Until(m.start.Add(elapsed))
+
+		if wait > 0 {
+			t := time.NewTimer(wait)
+			defer t.Stop()
+
+			select {
+			case <-t.C:
+			case <-m.ctx.Done():
+				return m.ctx.Err()
+			}
+		}
+	}
+
+	return m.inner.writeSample(dts, ptsOffset, isNonSyncSample, payloadSize, getPayload)
+}
+
+func (m *muxerPaced) writeFinalDTS(dts int64) {
+	m.inner.writeFinalDTS(dts)
+}
+
+func (m *muxerPaced) flush() error {
+	return m.inner.flush()
+}
@@ -0,0 +1,101 @@
+package playback
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/recordstore"
+	"github.com/gin-gonic/gin"
+)
+
+// frames are searched in a window around the requested time.
+const frameSearchWindow = 10 * time.Second
+
+func (s *Server) onFrame(ctx *gin.Context) {
+	pathName := ctx.Query("path")
+
+	if !s.doAuth(ctx, pathName) {
+		return
+	}
+
+	t, err := time.Parse(time.RFC3339, ctx.Query("time"))
+	if err != nil {
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid time: %w", err))
+		return
+	}
+
+	step := 0
+	if v := ctx.Query("step"); v != "" {
+		step, err = strconv.Atoi(v)
+		if err != nil {
+			s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid step: %w", err))
+			return
+		}
+	}
+
+	m := &muxerFrame{
+		target: frameSearchWindow,
+		step:   step,
+	}
+	var contentType string
+
+	format := ctx.Query("format")
+	switch format {
+	case "", "raw":
+		contentType = "application/octet-stream"
+
+	case "mp4":
+		m.mp4 = true
+		contentType = "video/mp4"
+
+	default:
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid format: %s", format))
+		return
+	}
+
+	pathConf, err := s.safeFindPathConf(pathName)
+	if err != nil {
+		s.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	start := t.Add(-frameSearchWindow)
+	end := t.Add(frameSearchWindow)
+
+	segments, err := recordstore.FindSegments(pathConf, pathName, &start, &end)
+	if err != nil {
+		if errors.Is(err, recordstore.ErrNoSegmentsFound) {
+			s.writeError(ctx, http.StatusNotFound, err)
+		} else {
+			s.writeError(ctx, http.StatusBadRequest, err)
+		}
+		return
+	}
+
+	err = seekAndMux(pathConf.RecordFormat, segments, start, 2*frameSearchWindow, m)
+	if err != nil {
+		if errors.Is(err, recordstore.ErrNoSegmentsFound) || errors.Is(err, errFrameNotFound) {
+			s.writeError(ctx, http.StatusNotFound, err)
+		} else {
+			s.writeError(ctx, http.StatusBadRequest, err)
+		}
+		return
+	}
+
+	ctx.Header("Access-Control-Expose-Headers",
+		"X-Frame-Time, X-Frame-Previous-Time, X-Frame-Next-Time, X-Frame-Key")
+	ctx.Header("X-Frame-Time", start.Add(m.pts).Format(time.RFC3339Nano))
+	if m.prevPTS != nil {
+		ctx.Header("X-Frame-Previous-Time", start.Add(*m.prevPTS).Format(time.RFC3339Nano))
+	}
+	if m.nextPTS != nil {
+		ctx.Header("X-Frame-Next-Time", start.Add(*m.nextPTS).Format(time.RFC3339Nano))
+	}
+	ctx.Header("X-Frame-Key", strconv.FormatBool(m.isKeyFrame))
+	ctx.Header("Content-Type", contentType)
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(m.payload)
+}
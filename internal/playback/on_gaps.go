@@ -0,0 +1,118 @@
+package playback
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/recordstore"
+	"github.com/gin-gonic/gin"
+)
+
+type gapEntry struct {
+	Start    time.Time         `json:"start"`
+	Duration listEntryDuration `json:"duration"`
+}
+
+// findGaps returns intervals of the window between start and end
+// that are not covered by any entry and that are longer than minDuration.
+func findGaps(entries []listEntry, start time.Time, end time.Time, minDuration time.Duration) []gapEntry {
+	out := []gapEntry{}
+	cur := start
+
+	addGap := func(gapEnd time.Time) {
+		if gapEnd.After(end) {
+			gapEnd = end
+		}
+		if d := gapEnd.Sub(cur); d > minDuration {
+			out = append(out, gapEntry{
+				Start:    cur,
+				Duration: listEntryDuration(d),
+			})
+		}
+	}
+
+	for _, entry := range entries {
+		if !entry.Start.Before(end) {
+			break
+		}
+
+		if entry.Start.After(cur) {
+			addGap(entry.Start)
+		}
+
+		entryEnd := entry.Start.Add(time.Duration(entry.Duration))
+		if entryEnd.After(cur) {
+			cur = entryEnd
+		}
+	}
+
+	if cur.Before(end) {
+		addGap(end)
+	}
+
+	return out
+}
+
+func (s *Server) onGaps(ctx *gin.Context) {
+	pathName := ctx.Query("path")
+
+	if !s.doAuth(ctx, pathName) {
+		return
+	}
+
+	pathConf, err := s.safeFindPathConf(pathName)
+	if err != nil {
+		s.writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	start, err := time.Parse(time.RFC3339, ctx.Query("start"))
+	if err != nil {
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid start: %w", err))
+		return
+	}
+
+	end, err := time.Parse(time.RFC3339, ctx.Query("end"))
+	if err != nil {
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid end: %w", err))
+		return
+	}
+
+	if !end.After(start) {
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("end must be after start"))
+		return
+	}
+
+	var minDuration time.Duration
+	rawMinDuration := ctx.Query("minDuration")
+	if rawMinDuration != "" {
+		var tmp float64
+		tmp, err = strconv.ParseFloat(rawMinDuration, 64)
+		if err != nil || tmp < 0 {
+			s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid minDuration"))
+			return
+		}
+		minDuration = time.Duration(tmp * float64(time.Second))
+	}
+
+	var entries []listEntry
+
+	segments, err := recordstore.FindSegments(pathConf, pathName, &start, &end)
+	if err != nil {
+		if !errors.Is(err, recordstore.ErrNoSegmentsFound) {
+			s.writeError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		entries, err = parseAndConcatenate(pathConf.RecordFormat, segments)
+		if err != nil {
+			s.writeError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, findGaps(entries, start, end, minDuration))
+}
@@ -1,6 +1,7 @@
 package playback
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -164,11 +165,25 @@ func (s *Server) onGet(ctx *gin.Context) {
 		return
 	}
 
+	if !s.acquireRequest() {
+		s.writeError(ctx, http.StatusTooManyRequests, fmt.Errorf("too many concurrent requests"))
+		return
+	}
+	defer s.releaseRequest()
+
+	if s.Rate != 0 {
+		m = &muxerPaced{
+			ctx:   ctx.Request.Context(),
+			rate:  s.Rate,
+			inner: m,
+		}
+	}
+
 	err = seekAndMux(pathConf.RecordFormat, segments, start, duration, m)
 	if err != nil {
 		// user aborted the download
 		var neterr *net.OpError
-		if errors.As(err, &neterr) {
+		if errors.As(err, &neterr) || errors.Is(err, context.Canceled) {
 			return
 		}
 
@@ -0,0 +1,128 @@
+package playback
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/recordstore"
+	"github.com/gin-gonic/gin"
+)
+
+type syncStream struct {
+	Path   string            `json:"path"`
+	Offset listEntryDuration `json:"offset"`
+	URL    string            `json:"url"`
+}
+
+type syncResponse struct {
+	Start    time.Time         `json:"start"`
+	Duration listEntryDuration `json:"duration"`
+	Streams  []syncStream      `json:"streams"`
+}
+
+// onSync returns, for each of multiple paths, the URL of a stream
+// that covers a common time range.
+// Timestamps of all streams are relative to the start of the range,
+// therefore they can be used as shared clock reference to play them in sync.
+func (s *Server) onSync(ctx *gin.Context) {
+	pathNames := ctx.QueryArray("path")
+	if len(pathNames) == 0 {
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("at least one path is required"))
+		return
+	}
+
+	for _, pathName := range pathNames {
+		if !s.doAuth(ctx, pathName) {
+			return
+		}
+	}
+
+	start, err := time.Parse(time.RFC3339, ctx.Query("start"))
+	if err != nil {
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid start: %w", err))
+		return
+	}
+
+	duration, err := parseDuration(ctx.Query("duration"))
+	if err != nil {
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid duration: %w", err))
+		return
+	}
+
+	format := ctx.Query("format")
+	switch format {
+	case "", "fmp4", "mp4":
+	default:
+		s.writeError(ctx, http.StatusBadRequest, fmt.Errorf("invalid format: %s", format))
+		return
+	}
+
+	var scheme string
+	if s.Encryption {
+		scheme = "https"
+	} else {
+		scheme = "http"
+	}
+
+	end := start.Add(duration)
+
+	res := syncResponse{
+		Start:    start,
+		Duration: listEntryDuration(duration),
+		Streams:  []syncStream{},
+	}
+
+	for _, pathName := range pathNames {
+		pathConf, err2 := s.safeFindPathConf(pathName)
+		if err2 != nil {
+			s.writeError(ctx, http.StatusBadRequest, err2)
+			return
+		}
+
+		segments, err2 := recordstore.FindSegments(pathConf, pathName, &start, &end)
+		if err2 != nil {
+			// paths without recordings in the range are omitted
+			if errors.Is(err2, recordstore.ErrNoSegmentsFound) {
+				continue
+			}
+			s.writeError(ctx, http.StatusBadRequest, err2)
+			return
+		}
+
+		var offset time.Duration
+		if segments[0].Start.After(start) {
+			offset = segments[0].Start.Sub(start)
+		}
+
+		v := url.Values{}
+		v.Add("path", pathName)
+		v.Add("start", start.Format(time.RFC3339Nano))
+		v.Add("duration", strconv.FormatFloat(duration.Seconds(), 'f', -1, 64))
+		if format != "" {
+			v.Add("format", format)
+		}
+		u := &url.URL{
+			Scheme:   scheme,
+			Host:     ctx.Request.Host,
+			Path:     "/get",
+			RawQuery: v.Encode(),
+		}
+
+		res.Streams = append(res.Streams, syncStream{
+			Path:   pathName,
+			Offset: listEntryDuration(offset),
+			URL:    u.String(),
+		})
+	}
+
+	if len(res.Streams) == 0 {
+		s.writeError(ctx, http.StatusNotFound, recordstore.ErrNoSegmentsFound)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
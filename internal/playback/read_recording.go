@@ -0,0 +1,94 @@
+package playback
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/recordstore"
+)
+
+// muxerCallback is a muxer that passes samples to callbacks.
+// Samples that precede the start of the playback are discarded.
+type muxerCallback struct {
+	onInit   func(*fmp4.Init) error
+	onSample func(trackID int, dts int64, ptsOffset int32, payload []byte) error
+
+	curTrack int
+	err      error
+}
+
+func (m *muxerCallback) writeInit(init *fmp4.Init) {
+	m.err = m.onInit(init)
+}
+
+func (m *muxerCallback) setTrack(trackID int) {
+	m.curTrack = trackID
+}
+
+func (m *muxerCallback) writeSample(
+	dts int64,
+	ptsOffset int32,
+	_ bool,
+	_ uint32,
+	getPayload func() ([]byte, error),
+) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	if dts < 0 {
+		return nil
+	}
+
+	pl, err := getPayload()
+	if err != nil {
+		return err
+	}
+
+	return m.onSample(m.curTrack, dts, ptsOffset, pl)
+}
+
+func (m *muxerCallback) writeFinalDTS(_ int64) {
+}
+
+func (m *muxerCallback) flush() error {
+	return m.err
+}
+
+// ReadRecording reads the recording of a path at real-time speed,
+// starting from start and for the given duration.
+// onInit is called with the tracks of the first segment,
+// onSample is called for each sample, with timestamps relative to start.
+func ReadRecording(
+	ctx context.Context,
+	pathConf *conf.Path,
+	pathName string,
+	start time.Time,
+	duration time.Duration,
+	onInit func(*fmp4.Init) error,
+	onSample func(trackID int, dts int64, ptsOffset int32, payload []byte) error,
+) error {
+	if pathConf.RecordFormat != conf.RecordFormatFMP4 {
+		return fmt.Errorf("only the fMP4 record format is supported")
+	}
+
+	end := start.Add(duration)
+	segments, err := recordstore.FindSegments(pathConf, pathName, &start, &end)
+	if err != nil {
+		return err
+	}
+
+	m := &muxerPaced{
+		ctx:  ctx,
+		rate: 1,
+		inner: &muxerCallback{
+			onInit:   onInit,
+			onSample: onSample,
+		},
+	}
+
+	return seekAndMux(pathConf.RecordFormat, segments, start, duration, m)
+}
@@ -0,0 +1,77 @@
+package playback
+
+import (
+	"io"
+	"os"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+)
+
+func fmp4CodecName(codec fmp4.Codec) string {
+	switch codec.(type) {
+	case *fmp4.CodecAV1:
+		return "AV1"
+	case *fmp4.CodecVP9:
+		return "VP9"
+	case *fmp4.CodecH265:
+		return "H265"
+	case *fmp4.CodecH264:
+		return "H264"
+	case *fmp4.CodecMPEG4Video:
+		return "MPEG-4 Video"
+	case *fmp4.CodecMPEG1Video:
+		return "MPEG-1/2 Video"
+	case *fmp4.CodecMJPEG:
+		return "M-JPEG"
+	case *fmp4.CodecOpus:
+		return "Opus"
+	case *fmp4.CodecMPEG4Audio:
+		return "MPEG-4 Audio"
+	case *fmp4.CodecMPEG1Audio:
+		return "MPEG-1/2 Audio"
+	case *fmp4.CodecAC3:
+		return "AC-3"
+	case *fmp4.CodecLPCM:
+		return "LPCM"
+	}
+	return "unknown"
+}
+
+// SegmentFMP4Validate checks whether a fMP4 file can be served by the playback server.
+func SegmentFMP4Validate(r io.ReadSeeker) error {
+	init, _, err := segmentFMP4ReadHeader(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = segmentFMP4ReadDurationFromParts(r, init)
+	return err
+}
+
+// SegmentCodecs returns the codecs of a recording segment.
+func SegmentCodecs(fpath string, format conf.RecordFormat) ([]string, error) {
+	// codecs of MPEG-TS segments can't be obtained without parsing the whole file
+	if format != conf.RecordFormatFMP4 {
+		return []string{}, nil
+	}
+
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	init, _, err := segmentFMP4ReadHeader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	codecs := make([]string, len(init.Tracks))
+	for i, track := range init.Tracks {
+		codecs[i] = fmp4CodecName(track.Codec)
+	}
+
+	return codecs, nil
+}
@@ -1,13 +1,17 @@
 package playback
 
 import (
+	"bytes"
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/bluenviron/mediacommon/pkg/codecs/mpeg4audio"
 	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4/seekablebuffer"
 	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/stretchr/testify/require"
 )
 
 func writeBenchInit(f io.WriteSeeker) {
@@ -73,3 +77,65 @@ func BenchmarkFMP4ReadHeader(b *testing.B) {
 		}()
 	}
 }
+
+func TestSegmentFMP4ReadDurationFromPartsNativeTimeScale(t *testing.T) {
+	init := fmp4.Init{
+		Tracks: []*fmp4.InitTrack{
+			{
+				ID:        1,
+				TimeScale: 90000,
+				Codec: &fmp4.CodecH264{
+					SPS: test.FormatH264.SPS,
+					PPS: test.FormatH264.PPS,
+				},
+			},
+			{
+				ID:        2,
+				TimeScale: 44100,
+				Codec: &fmp4.CodecMPEG4Audio{
+					Config: mpeg4audio.Config{
+						Type:         mpeg4audio.ObjectTypeAACLC,
+						SampleRate:   44100,
+						ChannelCount: 2,
+					},
+				},
+			},
+		},
+	}
+
+	var buf seekablebuffer.Buffer
+	err := init.Marshal(&buf)
+	require.NoError(t, err)
+
+	part := fmp4.Part{
+		SequenceNumber: 1,
+		Tracks: []*fmp4.PartTrack{
+			{
+				ID: 1,
+				Samples: []*fmp4.PartSample{{
+					Duration: 2 * 90000,
+					Payload:  []byte{1, 2},
+				}},
+			},
+			{
+				ID:       2,
+				BaseTime: 44100,
+				Samples: []*fmp4.PartSample{{
+					Duration: 2 * 44100,
+					Payload:  []byte{1, 2},
+				}},
+			},
+		},
+	}
+	err = part.Marshal(&buf)
+	require.NoError(t, err)
+
+	r := bytes.NewReader(buf.Bytes())
+
+	init2, _, err := segmentFMP4ReadHeader(r)
+	require.NoError(t, err)
+
+	duration, err := segmentFMP4ReadDurationFromParts(r, init2)
+	require.NoError(t, err)
+	require.Equal(t, 3*time.Second, duration)
+}
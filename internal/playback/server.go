@@ -28,12 +28,16 @@ type Server struct {
 	AllowOrigin    string
 	TrustedProxies conf.IPNetworks
 	ReadTimeout    conf.Duration
+	MaxRequests    int
+	Rate           float64
 	PathConfs      map[string]*conf.Path
 	AuthManager    serverAuthManager
 	Parent         logger.Writer
 
-	httpServer *httpp.Server
-	mutex      sync.RWMutex
+	httpServer    *httpp.Server
+	mutex         sync.RWMutex
+	requestsMutex sync.Mutex
+	requests      int
 }
 
 // Initialize initializes Server.
@@ -44,7 +48,10 @@ func (s *Server) Initialize() error {
 	router.Use(s.middlewareOrigin)
 
 	router.GET("/list", s.onList)
+	router.GET("/gaps", s.onGaps)
 	router.GET("/get", s.onGet)
+	router.GET("/sync", s.onSync)
+	router.GET("/frame", s.onFrame)
 
 	network, address := restrictnetwork.Restrict("tcp", s.Address)
 
@@ -102,6 +109,26 @@ func (s *Server) safeFindPathConf(name string) (*conf.Path, error) {
 	return pathConf, err
 }
 
+// acquireRequest reserves a slot for a download request.
+// It returns false when the maximum number of concurrent requests has been reached.
+func (s *Server) acquireRequest() bool {
+	s.requestsMutex.Lock()
+	defer s.requestsMutex.Unlock()
+
+	if s.MaxRequests != 0 && s.requests >= s.MaxRequests {
+		return false
+	}
+
+	s.requests++
+	return true
+}
+
+func (s *Server) releaseRequest() {
+	s.requestsMutex.Lock()
+	defer s.requestsMutex.Unlock()
+	s.requests--
+}
+
 func (s *Server) middlewareOrigin(ctx *gin.Context) {
 	ctx.Header("Access-Control-Allow-Origin", s.AllowOrigin)
 	ctx.Header("Access-Control-Allow-Credentials", "true")
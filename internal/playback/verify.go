@@ -0,0 +1,141 @@
+package playback
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+)
+
+type muxerVerify struct {
+	curTrack int
+	lastDTS  map[int]int64
+}
+
+func (m *muxerVerify) writeInit(_ *fmp4.Init) {
+}
+
+func (m *muxerVerify) setTrack(trackID int) {
+	m.curTrack = trackID
+}
+
+func (m *muxerVerify) writeSample(
+	dts int64,
+	_ int32,
+	_ bool,
+	_ uint32,
+	getPayload func() ([]byte, error),
+) error {
+	if prev, ok := m.lastDTS[m.curTrack]; ok && dts < prev {
+		return fmt.Errorf("DTS of track %d is not monotonic", m.curTrack)
+	}
+	m.lastDTS[m.curTrack] = dts
+
+	// make sure that sample data is present and readable
+	_, err := getPayload()
+	return err
+}
+
+func (m *muxerVerify) writeFinalDTS(_ int64) {
+}
+
+func (m *muxerVerify) flush() error {
+	return nil
+}
+
+// segmentFMP4VerifyBoxes checks that the segment is made of
+// ftyp, moov and a sequence of moof and mdat boxes, and that no box is truncated.
+func segmentFMP4VerifyBoxes(r io.ReadSeeker, size int64) error {
+	buf := make([]byte, 8)
+	pos := int64(0)
+	i := 0
+
+	for pos < size {
+		_, err := r.Seek(pos, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.ReadFull(r, buf)
+		if err != nil {
+			return fmt.Errorf("truncated box header at offset %d", pos)
+		}
+
+		boxSize := int64(uint32(buf[0])<<24 | uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3]))
+		boxType := string(buf[4:])
+
+		var expected string
+		switch {
+		case i == 0:
+			expected = "ftyp"
+		case i == 1:
+			expected = "moov"
+		case i%2 == 0:
+			expected = "moof"
+		default:
+			expected = "mdat"
+		}
+
+		if boxType != expected {
+			return fmt.Errorf("expected box '%s' at offset %d, found '%x'", expected, pos, buf[4:])
+		}
+
+		if boxSize < 8 || (pos+boxSize) > size {
+			return fmt.Errorf("box '%s' at offset %d is truncated", boxType, pos)
+		}
+
+		pos += boxSize
+		i++
+	}
+
+	if i < 4 {
+		return fmt.Errorf("no parts found")
+	}
+
+	if (i % 2) != 0 {
+		return fmt.Errorf("last moof box is not followed by a mdat box")
+	}
+
+	return nil
+}
+
+// VerifyFMP4 checks the integrity of a fMP4 segment.
+// Box structure is checked, every sample is read, and the duration
+// stored in the header is compared with the one of parts.
+func VerifyFMP4(r readSeekerAt) error {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	err = segmentFMP4VerifyBoxes(r, size)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	init, headerDuration, err := segmentFMP4ReadHeader(r)
+	if err != nil {
+		return err
+	}
+
+	m := &muxerVerify{lastDTS: make(map[int]int64)}
+
+	partsDuration, err := segmentFMP4MuxParts(r, 0, convertMaxDuration, init, m)
+	if err != nil {
+		return err
+	}
+
+	// duration is missing when the segment was not closed properly,
+	// for instance after a system failure. This is not considered an error.
+	if headerDuration != 0 && headerDuration > (partsDuration+concatenationTolerance) {
+		return fmt.Errorf("duration in header (%v) is greater than duration of parts (%v)",
+			headerDuration, partsDuration)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package playback
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyFMP4(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-playback")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "2008-11-07_11-22-00-500000.mp4")
+	writeSegment1(t, fpath)
+
+	f, err := os.Open(fpath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	err = VerifyFMP4(f)
+	require.NoError(t, err)
+}
+
+func TestVerifyFMP4Truncated(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-playback")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "2008-11-07_11-22-00-500000.mp4")
+	writeSegment1(t, fpath)
+
+	fi, err := os.Stat(fpath)
+	require.NoError(t, err)
+
+	err = os.Truncate(fpath, fi.Size()-2)
+	require.NoError(t, err)
+
+	f, err := os.Open(fpath)
+	require.NoError(t, err)
+	defer f.Close()
+
+	err = VerifyFMP4(f)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "box 'mdat' at offset")
+}
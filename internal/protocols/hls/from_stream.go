@@ -9,6 +9,7 @@ import (
 	"github.com/bluenviron/gohlslib/v2/pkg/codecs"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediamtx/internal/codecpriority"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/stream"
 	"github.com/bluenviron/mediamtx/internal/unit"
@@ -23,6 +24,7 @@ func setupVideoTrack(
 	reader stream.Reader,
 	muxer *gohlslib.Muxer,
 	setuppedFormats map[format.Format]struct{},
+	codecPriority []string,
 ) {
 	addTrack := func(
 		media *description.Media,
@@ -35,10 +37,18 @@ func setupVideoTrack(
 		strea.AddReader(reader, media, forma, readFunc)
 	}
 
+	selected := codecpriority.Select(strea.Desc(), codecPriority, func(forma format.Format) bool {
+		switch forma.(type) {
+		case *format.AV1, *format.VP9, *format.H265, *format.H264:
+			return true
+		}
+		return false
+	})
+
 	var videoFormatAV1 *format.AV1
 	videoMedia := strea.Desc().FindFormat(&videoFormatAV1)
 
-	if videoFormatAV1 != nil {
+	if videoFormatAV1 != nil && codecpriority.Allowed(selected, videoFormatAV1) {
 		track := &gohlslib.Track{
 			Codec:     &codecs.AV1{},
 			ClockRate: videoFormatAV1.ClockRate(),
@@ -73,7 +83,7 @@ func setupVideoTrack(
 	var videoFormatVP9 *format.VP9
 	videoMedia = strea.Desc().FindFormat(&videoFormatVP9)
 
-	if videoFormatVP9 != nil {
+	if videoFormatVP9 != nil && codecpriority.Allowed(selected, videoFormatVP9) {
 		track := &gohlslib.Track{
 			Codec:     &codecs.VP9{},
 			ClockRate: videoFormatVP9.ClockRate(),
@@ -108,7 +118,7 @@ func setupVideoTrack(
 	var videoFormatH265 *format.H265
 	videoMedia = strea.Desc().FindFormat(&videoFormatH265)
 
-	if videoFormatH265 != nil {
+	if videoFormatH265 != nil && codecpriority.Allowed(selected, videoFormatH265) {
 		vps, sps, pps := videoFormatH265.SafeParams()
 		track := &gohlslib.Track{
 			Codec: &codecs.H265{
@@ -148,7 +158,7 @@ func setupVideoTrack(
 	var videoFormatH264 *format.H264
 	videoMedia = strea.Desc().FindFormat(&videoFormatH264)
 
-	if videoFormatH264 != nil {
+	if videoFormatH264 != nil && codecpriority.Allowed(selected, videoFormatH264) {
 		sps, pps := videoFormatH264.SafeParams()
 		track := &gohlslib.Track{
 			Codec: &codecs.H264{
@@ -271,10 +281,13 @@ func setupAudioTracks(
 }
 
 // FromStream maps a MediaMTX stream to a HLS muxer.
+// When the stream contains multiple video tracks, codecPriority
+// sets which one is used.
 func FromStream(
 	stream *stream.Stream,
 	reader stream.Reader,
 	muxer *gohlslib.Muxer,
+	codecPriority []string,
 ) error {
 	setuppedFormats := make(map[format.Format]struct{})
 
@@ -283,6 +296,7 @@ func FromStream(
 		reader,
 		muxer,
 		setuppedFormats,
+		codecPriority,
 	)
 
 	setupAudioTracks(
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/bluenviron/gohlslib/v2"
+	"github.com/bluenviron/gohlslib/v2/pkg/codecs"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/mediamtx/internal/logger"
@@ -16,6 +17,8 @@ import (
 func TestFromStreamNoSupportedCodecs(t *testing.T) {
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		&description.Session{Medias: []*description.Media{{
 			Type:    description.MediaTypeVideo,
@@ -32,13 +35,15 @@ func TestFromStreamNoSupportedCodecs(t *testing.T) {
 
 	m := &gohlslib.Muxer{}
 
-	err = FromStream(stream, l, m)
+	err = FromStream(stream, l, m, nil)
 	require.Equal(t, ErrNoSupportedCodecs, err)
 }
 
 func TestFromStreamSkipUnsupportedTracks(t *testing.T) {
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		&description.Session{Medias: []*description.Media{
 			{
@@ -74,9 +79,47 @@ func TestFromStreamSkipUnsupportedTracks(t *testing.T) {
 		n++
 	})
 
-	err = FromStream(stream, l, m)
+	err = FromStream(stream, l, m, nil)
 	require.NoError(t, err)
 	defer stream.RemoveReader(l)
 
 	require.Equal(t, 2, n)
 }
+
+func TestFromStreamCodecPriority(t *testing.T) {
+	stream, err := stream.New(
+		512,
+		0,
+		0,
+		1460,
+		&description.Session{Medias: []*description.Media{
+			{
+				Type:    description.MediaTypeVideo,
+				Formats: []format.Format{test.FormatH265},
+			},
+			{
+				Type:    description.MediaTypeVideo,
+				Formats: []format.Format{test.FormatH264},
+			},
+		}},
+		true,
+		test.NilLogger,
+	)
+	require.NoError(t, err)
+
+	m := &gohlslib.Muxer{}
+
+	var logs []string
+
+	l := test.Logger(func(_ logger.Level, format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+
+	err = FromStream(stream, l, m, []string{"H264"})
+	require.NoError(t, err)
+	defer stream.RemoveReader(l)
+
+	require.Len(t, m.Tracks, 1)
+	require.IsType(t, &codecs.H264{}, m.Tracks[0].Codec)
+	require.Equal(t, []string{"skipping track 1 (H265)"}, logs)
+}
@@ -12,7 +12,7 @@ import (
 type loggerWriter struct {
 	w      http.ResponseWriter
 	status int
-	buf    bytes.Buffer
+	size   int
 }
 
 func (w *loggerWriter) Header() http.Header {
@@ -23,8 +23,9 @@ func (w *loggerWriter) Write(b []byte) (int, error) {
 	if w.status == 0 {
 		w.status = http.StatusOK
 	}
-	w.buf.Write(b)
-	return w.w.Write(b)
+	n, err := w.w.Write(b)
+	w.size += n
+	return n, err
 }
 
 func (w *loggerWriter) WriteHeader(statusCode int) {
@@ -32,13 +33,24 @@ func (w *loggerWriter) WriteHeader(statusCode int) {
 	w.w.WriteHeader(statusCode)
 }
 
+// Flush implements http.Flusher.
+// This is needed to send streaming responses.
+func (w *loggerWriter) Flush() {
+	http.NewResponseController(w.w).Flush() //nolint:errcheck
+}
+
+// Unwrap allows http.ResponseController to access the underlying writer.
+func (w *loggerWriter) Unwrap() http.ResponseWriter {
+	return w.w
+}
+
 func (w *loggerWriter) dump() string {
 	var buf bytes.Buffer
 	fmt.Fprintf(&buf, "%s %d %s\n", "HTTP/1.1", w.status, http.StatusText(w.status))
 	w.w.Header().Write(&buf) //nolint:errcheck
 	buf.Write([]byte("\n"))
-	if w.buf.Len() > 0 {
-		fmt.Fprintf(&buf, "(body of %d bytes)", w.buf.Len())
+	if w.size > 0 {
+		fmt.Fprintf(&buf, "(body of %d bytes)", w.size)
 	}
 	return buf.String()
 }
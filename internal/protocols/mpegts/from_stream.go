@@ -11,7 +11,6 @@ import (
 	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
 	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
 	mcmpegts "github.com/bluenviron/mediacommon/pkg/formats/mpegts"
-	srt "github.com/datarhei/gosrt"
 
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/stream"
@@ -24,12 +23,16 @@ func multiplyAndDivide(v, m, d int64) int64 {
 	return (secs*m + dec*m/d)
 }
 
+type writeDeadlineSetter interface {
+	SetWriteDeadline(t time.Time) error
+}
+
 // FromStream maps a MediaMTX stream to a MPEG-TS writer.
 func FromStream(
 	strea *stream.Stream,
 	reader stream.Reader,
 	bw *bufio.Writer,
-	sconn srt.Conn,
+	sconn writeDeadlineSetter,
 	writeTimeout time.Duration,
 ) error {
 	var w *mcmpegts.Writer
@@ -15,6 +15,8 @@ import (
 func TestFromStreamNoSupportedCodecs(t *testing.T) {
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		&description.Session{Medias: []*description.Media{{
 			Type:    description.MediaTypeVideo,
@@ -36,6 +38,8 @@ func TestFromStreamNoSupportedCodecs(t *testing.T) {
 func TestFromStreamSkipUnsupportedTracks(t *testing.T) {
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		&description.Session{Medias: []*description.Media{
 			{
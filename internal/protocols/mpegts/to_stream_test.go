@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/asticode/go-astits"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/mediacommon/pkg/formats/mpegts"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/test"
@@ -75,3 +76,44 @@ func TestToStreamSkipUnsupportedTracks(t *testing.T) {
 	_, err = ToStream(r, nil, l)
 	require.NoError(t, err)
 }
+
+func TestToStreamMultipleAudioTracks(t *testing.T) {
+	var buf bytes.Buffer
+	mux := astits.NewMuxer(context.Background(), &buf)
+
+	err := mux.AddElementaryStream(astits.PMTElementaryStream{
+		ElementaryPID: 122,
+		StreamType:    astits.StreamTypeH264Video,
+	})
+	require.NoError(t, err)
+
+	err = mux.AddElementaryStream(astits.PMTElementaryStream{
+		ElementaryPID: 123,
+		StreamType:    astits.StreamTypeMPEG1Audio,
+	})
+	require.NoError(t, err)
+
+	err = mux.AddElementaryStream(astits.PMTElementaryStream{
+		ElementaryPID: 124,
+		StreamType:    astits.StreamTypeMPEG1Audio,
+	})
+	require.NoError(t, err)
+
+	mux.SetPCRPID(122)
+
+	_, err = mux.WriteTables()
+	require.NoError(t, err)
+
+	r, err := mpegts.NewReader(&buf)
+	require.NoError(t, err)
+
+	l := test.Logger(func(logger.Level, string, ...interface{}) {
+		t.Error("should not happen")
+	})
+	medias, err := ToStream(r, nil, l)
+	require.NoError(t, err)
+	require.Len(t, medias, 3)
+	require.Equal(t, description.MediaTypeVideo, medias[0].Type)
+	require.Equal(t, description.MediaTypeAudio, medias[1].Type)
+	require.Equal(t, description.MediaTypeAudio, medias[2].Type)
+}
@@ -0,0 +1,113 @@
+// Package proxy contains utilities to connect through a proxy.
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+// DialContextFunc is a function that opens a connection.
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+type bufferedConn struct {
+	net.Conn
+	br *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.br.Read(p)
+}
+
+func dialHTTPConnect(ctx context.Context, u *url.URL, address string) (net.Conn, error) {
+	host := u.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "80")
+	}
+
+	nconn, err := (&net.Dialer{}).DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		nconn.SetDeadline(deadline)          //nolint:errcheck
+		defer nconn.SetDeadline(time.Time{}) //nolint:errcheck
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+
+	if u.User != nil {
+		pass, _ := u.User.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString([]byte(u.User.Username()+":"+pass)))
+	}
+
+	err = req.Write(nconn)
+	if err != nil {
+		nconn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(nconn)
+
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		nconn.Close()
+		return nil, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		nconn.Close()
+		return nil, fmt.Errorf("proxy replied with status code %d", res.StatusCode)
+	}
+
+	if br.Buffered() != 0 {
+		return &bufferedConn{Conn: nconn, br: br}, nil
+	}
+
+	return nconn, nil
+}
+
+// NewDialContext returns a function that opens connections through the given proxy.
+// Supported proxies are socks5://, socks5h:// and http://.
+// When the proxy URL is empty, connections are opened directly.
+func NewDialContext(proxyURL string) (DialContextFunc, error) {
+	if proxyURL == "" {
+		return (&net.Dialer{}).DialContext, nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		d, err := xproxy.FromURL(u, xproxy.Direct)
+		if err != nil {
+			return nil, err
+		}
+		return d.(xproxy.ContextDialer).DialContext, nil
+
+	case "http":
+		return func(ctx context.Context, _ string, address string) (net.Conn, error) {
+			return dialHTTPConnect(ctx, u, address)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported proxy scheme: '%s'", u.Scheme)
+}
@@ -18,6 +18,8 @@ import (
 func TestFromStreamNoSupportedCodecs(t *testing.T) {
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		&description.Session{Medias: []*description.Media{{
 			Type:    description.MediaTypeVideo,
@@ -39,6 +41,8 @@ func TestFromStreamNoSupportedCodecs(t *testing.T) {
 func TestFromStreamSkipUnsupportedTracks(t *testing.T) {
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		&description.Session{Medias: []*description.Media{
 			{
@@ -0,0 +1,97 @@
+// Package rtp contains utilities to receive raw RTP streams.
+package rtp
+
+import (
+	"net"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+	"github.com/bluenviron/gortsplib/v4/pkg/rtpreorderer"
+	"github.com/bluenviron/gortsplib/v4/pkg/rtptime"
+	"github.com/pion/rtp"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+)
+
+const (
+	// UDPKernelReadBufferSize is the size of the kernel read buffer of UDP sockets.
+	// It is the same size as GStreamer's rtspsrc.
+	UDPKernelReadBufferSize = 0x80000
+
+	// 1500 (UDP MTU) - 20 (IP header) - 8 (UDP header)
+	udpMaxPayloadSize = 1472
+)
+
+func findFormat(medi *description.Media, payloadType uint8) format.Format {
+	for _, forma := range medi.Formats {
+		if forma.PayloadType() == payloadType {
+			return forma
+		}
+	}
+	return nil
+}
+
+// ReadRTP reads RTP packets of a media from a connection and writes them to the stream.
+func ReadRTP(
+	conn net.PacketConn,
+	readTimeout time.Duration,
+	medi *description.Media,
+	timeDecoder *rtptime.GlobalDecoder2,
+	decodeErrLogger logger.Writer,
+	stream *stream.Stream,
+) error {
+	reorderer := rtpreorderer.New()
+	buf := make([]byte, udpMaxPayloadSize+1)
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		var pkt rtp.Packet
+		err = pkt.Unmarshal(buf[:n])
+		if err != nil {
+			decodeErrLogger.Log(logger.Warn, "invalid RTP packet: %v", err)
+			continue
+		}
+
+		forma := findFormat(medi, pkt.PayloadType)
+		if forma == nil {
+			decodeErrLogger.Log(logger.Warn, "received RTP packet with unknown payload type: %d", pkt.PayloadType)
+			continue
+		}
+
+		packets, lost := reorderer.Process(&pkt)
+		if lost != 0 {
+			decodeErrLogger.Log(logger.Warn, (liberrors.ErrClientRTPPacketsLost{Lost: lost}).Error())
+			// do not return
+		}
+
+		for _, pkt := range packets {
+			pts, ok := timeDecoder.Decode(forma, pkt)
+			if !ok {
+				continue
+			}
+
+			stream.WriteRTPPacket(medi, forma, pkt, time.Now(), pts)
+		}
+	}
+}
+
+// DiscardRTCP reads and discards RTCP packets,
+// in order to prevent the sender from receiving ICMP errors.
+func DiscardRTCP(conn net.PacketConn) error {
+	buf := make([]byte, udpMaxPayloadSize+1)
+
+	for {
+		_, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+	}
+}
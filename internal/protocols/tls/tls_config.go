@@ -33,3 +33,26 @@ func ConfigForFingerprint(fingerprint string) *tls.Config {
 		},
 	}
 }
+
+// ConfigForClientCert returns a tls.Config that supports given fingerprint
+// and authenticates with given client certificate.
+// It returns nil when both fingerprint and certificate are not provided.
+func ConfigForClientCert(fingerprint string, certFile string, keyFile string) (*tls.Config, error) {
+	conf := ConfigForFingerprint(fingerprint)
+
+	if certFile == "" {
+		return conf, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load client certificate: %w", err)
+	}
+
+	if conf == nil {
+		conf = &tls.Config{}
+	}
+	conf.Certificates = []tls.Certificate{cert}
+
+	return conf, nil
+}
@@ -0,0 +1,38 @@
+package tls
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/test"
+)
+
+func TestConfigForClientCert(t *testing.T) {
+	conf, err := ConfigForClientCert("", "", "")
+	require.NoError(t, err)
+	require.Nil(t, conf)
+
+	certFpath, err := test.CreateTempFile(test.TLSCertPub)
+	require.NoError(t, err)
+	defer os.Remove(certFpath)
+
+	keyFpath, err := test.CreateTempFile(test.TLSCertKey)
+	require.NoError(t, err)
+	defer os.Remove(keyFpath)
+
+	conf, err = ConfigForClientCert("", certFpath, keyFpath)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(conf.Certificates))
+	require.Equal(t, false, conf.InsecureSkipVerify)
+
+	conf, err = ConfigForClientCert("33949E05FFFB5FF3E8AA16F8213A6251B4D9363804BA53233C4DA9A46D6F2739",
+		certFpath, keyFpath)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(conf.Certificates))
+	require.Equal(t, true, conf.InsecureSkipVerify)
+
+	_, err = ConfigForClientCert("", certFpath, "/nonexisting")
+	require.Error(t, err)
+}
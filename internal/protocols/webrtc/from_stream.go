@@ -13,6 +13,7 @@ import (
 	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpvp8"
 	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpvp9"
 	"github.com/bluenviron/mediacommon/pkg/codecs/g711"
+	"github.com/bluenviron/mediamtx/internal/codecpriority"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/stream"
 	"github.com/bluenviron/mediamtx/internal/unit"
@@ -40,15 +41,37 @@ func randUint32() (uint32, error) {
 	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]), nil
 }
 
+func videoMimeType(forma format.Format) string {
+	switch forma.(type) {
+	case *format.AV1:
+		return webrtc.MimeTypeAV1
+	case *format.VP9:
+		return webrtc.MimeTypeVP9
+	case *format.VP8:
+		return webrtc.MimeTypeVP8
+	case *format.H265:
+		return webrtc.MimeTypeH265
+	case *format.H264:
+		return webrtc.MimeTypeH264
+	}
+	return ""
+}
+
 func setupVideoTrack(
 	stream *stream.Stream,
 	reader stream.Reader,
 	pc *PeerConnection,
+	codecPriority []string,
 ) (format.Format, error) {
+	selected := codecpriority.Select(stream.Desc(), codecPriority, func(forma format.Format) bool {
+		mime := videoMimeType(forma)
+		return mime != "" && pc.remoteSupports(mime)
+	})
+
 	var av1Format *format.AV1
 	media := stream.Desc().FindFormat(&av1Format)
 
-	if av1Format != nil {
+	if av1Format != nil && codecpriority.Allowed(selected, av1Format) && pc.remoteSupports(webrtc.MimeTypeAV1) {
 		track := &OutgoingTrack{
 			Caps: webrtc.RTPCodecCapability{
 				MimeType:  webrtc.MimeTypeAV1,
@@ -96,7 +119,7 @@ func setupVideoTrack(
 	var vp9Format *format.VP9
 	media = stream.Desc().FindFormat(&vp9Format)
 
-	if vp9Format != nil {
+	if vp9Format != nil && codecpriority.Allowed(selected, vp9Format) && pc.remoteSupports(webrtc.MimeTypeVP9) {
 		track := &OutgoingTrack{
 			Caps: webrtc.RTPCodecCapability{
 				MimeType:    webrtc.MimeTypeVP9,
@@ -146,7 +169,7 @@ func setupVideoTrack(
 	var vp8Format *format.VP8
 	media = stream.Desc().FindFormat(&vp8Format)
 
-	if vp8Format != nil {
+	if vp8Format != nil && codecpriority.Allowed(selected, vp8Format) && pc.remoteSupports(webrtc.MimeTypeVP8) {
 		track := &OutgoingTrack{
 			Caps: webrtc.RTPCodecCapability{
 				MimeType:  webrtc.MimeTypeVP8,
@@ -194,7 +217,7 @@ func setupVideoTrack(
 	var h265Format *format.H265
 	media = stream.Desc().FindFormat(&h265Format)
 
-	if h265Format != nil { //nolint:dupl
+	if h265Format != nil && codecpriority.Allowed(selected, h265Format) && pc.remoteSupports(webrtc.MimeTypeH265) { //nolint:dupl
 		track := &OutgoingTrack{
 			Caps: webrtc.RTPCodecCapability{
 				MimeType:    webrtc.MimeTypeH265,
@@ -253,7 +276,7 @@ func setupVideoTrack(
 	var h264Format *format.H264
 	media = stream.Desc().FindFormat(&h264Format)
 
-	if h264Format != nil { //nolint:dupl
+	if h264Format != nil && codecpriority.Allowed(selected, h264Format) && pc.remoteSupports(webrtc.MimeTypeH264) { //nolint:dupl
 		track := &OutgoingTrack{
 			Caps: webrtc.RTPCodecCapability{
 				MimeType:    webrtc.MimeTypeH264,
@@ -316,11 +339,20 @@ func setupAudioTrack(
 	stream *stream.Stream,
 	reader stream.Reader,
 	pc *PeerConnection,
+	codecPriority []string,
 ) (format.Format, error) {
+	selected := codecpriority.Select(stream.Desc(), codecPriority, func(forma format.Format) bool {
+		switch forma.(type) {
+		case *format.Opus, *format.G722, *format.G711, *format.LPCM:
+			return true
+		}
+		return false
+	})
+
 	var opusFormat *format.Opus
 	media := stream.Desc().FindFormat(&opusFormat)
 
-	if opusFormat != nil {
+	if opusFormat != nil && codecpriority.Allowed(selected, opusFormat) {
 		var caps webrtc.RTPCodecCapability
 
 		switch opusFormat.ChannelCount {
@@ -373,7 +405,7 @@ func setupAudioTrack(
 	var g722Format *format.G722
 	media = stream.Desc().FindFormat(&g722Format)
 
-	if g722Format != nil {
+	if g722Format != nil && codecpriority.Allowed(selected, g722Format) {
 		track := &OutgoingTrack{
 			Caps: webrtc.RTPCodecCapability{
 				MimeType:  webrtc.MimeTypeG722,
@@ -400,7 +432,7 @@ func setupAudioTrack(
 	var g711Format *format.G711
 	media = stream.Desc().FindFormat(&g711Format)
 
-	if g711Format != nil {
+	if g711Format != nil && codecpriority.Allowed(selected, g711Format) {
 		// These are the sample rates and channels supported by Chrome.
 		// Different sample rates and channels can be streamed too but we don't want compatibility issues.
 		// https://webrtc.googlesource.com/src/+/refs/heads/main/modules/audio_coding/codecs/pcm16b/audio_decoder_pcm16b.cc#23
@@ -536,7 +568,7 @@ func setupAudioTrack(
 	var lpcmFormat *format.LPCM
 	media = stream.Desc().FindFormat(&lpcmFormat)
 
-	if lpcmFormat != nil {
+	if lpcmFormat != nil && codecpriority.Allowed(selected, lpcmFormat) {
 		if lpcmFormat.BitDepth != 16 {
 			return nil, fmt.Errorf("unsupported LPCM bit depth: %d", lpcmFormat.BitDepth)
 		}
@@ -611,18 +643,21 @@ func setupAudioTrack(
 	return nil, nil
 }
 
-// FromStream maps a MediaMTX stream to a WebRTC connection
+// FromStream maps a MediaMTX stream to a WebRTC connection.
+// When the stream contains multiple video or audio tracks, codecPriority
+// sets which ones are used.
 func FromStream(
 	stream *stream.Stream,
 	reader stream.Reader,
 	pc *PeerConnection,
+	codecPriority []string,
 ) error {
-	videoFormat, err := setupVideoTrack(stream, reader, pc)
+	videoFormat, err := setupVideoTrack(stream, reader, pc, codecPriority)
 	if err != nil {
 		return err
 	}
 
-	audioFormat, err := setupAudioTrack(stream, reader, pc)
+	audioFormat, err := setupAudioTrack(stream, reader, pc, codecPriority)
 	if err != nil {
 		return err
 	}
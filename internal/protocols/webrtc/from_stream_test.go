@@ -9,12 +9,15 @@ import (
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/stream"
 	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/pion/webrtc/v4"
 	"github.com/stretchr/testify/require"
 )
 
 func TestFromStreamNoSupportedCodecs(t *testing.T) {
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		&description.Session{Medias: []*description.Media{{
 			Type:    description.MediaTypeVideo,
@@ -29,13 +32,15 @@ func TestFromStreamNoSupportedCodecs(t *testing.T) {
 		t.Error("should not happen")
 	})
 
-	err = FromStream(stream, l, nil)
+	err = FromStream(stream, l, nil, nil)
 	require.Equal(t, errNoSupportedCodecsFrom, err)
 }
 
 func TestFromStreamSkipUnsupportedTracks(t *testing.T) {
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		&description.Session{Medias: []*description.Media{
 			{
@@ -64,18 +69,72 @@ func TestFromStreamSkipUnsupportedTracks(t *testing.T) {
 
 	pc := &PeerConnection{}
 
-	err = FromStream(stream, l, pc)
+	err = FromStream(stream, l, pc, nil)
 	require.NoError(t, err)
 	defer stream.RemoveReader(l)
 
 	require.Equal(t, 1, n)
 }
 
+func TestFromStreamRemoteCodecs(t *testing.T) {
+	stream, err := stream.New(
+		512,
+		0,
+		0,
+		1460,
+		&description.Session{Medias: []*description.Media{
+			{
+				Type:    description.MediaTypeVideo,
+				Formats: []format.Format{&format.H265{}},
+			},
+			{
+				Type:    description.MediaTypeVideo,
+				Formats: []format.Format{&format.H264{}},
+			},
+		}},
+		true,
+		test.NilLogger,
+	)
+	require.NoError(t, err)
+
+	n := 0
+
+	l := test.Logger(func(l logger.Level, format string, args ...interface{}) {
+		require.Equal(t, logger.Warn, l)
+		if n == 0 {
+			require.Equal(t, "skipping track 1 (H265)", fmt.Sprintf(format, args...))
+		}
+		n++
+	})
+
+	pc := &PeerConnection{
+		RemoteCodecs: OfferCodecs(&webrtc.SessionDescription{
+			Type: webrtc.SDPTypeOffer,
+			SDP: "v=0\r\n" +
+				"o=- 0 0 IN IP4 127.0.0.1\r\n" +
+				"s=-\r\n" +
+				"t=0 0\r\n" +
+				"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+				"a=rtpmap:96 H264/90000\r\n",
+		}),
+	}
+
+	err = FromStream(stream, l, pc, nil)
+	require.NoError(t, err)
+	defer stream.RemoveReader(l)
+
+	require.Equal(t, 1, n)
+	require.Len(t, pc.OutgoingTracks, 1)
+	require.Equal(t, webrtc.MimeTypeH264, pc.OutgoingTracks[0].Caps.MimeType)
+}
+
 func TestFromStream(t *testing.T) {
 	for _, ca := range toFromStreamCases {
 		t.Run(ca.name, func(t *testing.T) {
 			stream, err := stream.New(
 				512,
+				0,
+				0,
 				1460,
 				&description.Session{
 					Medias: []*description.Media{{
@@ -90,7 +149,7 @@ func TestFromStream(t *testing.T) {
 
 			pc := &PeerConnection{}
 
-			err = FromStream(stream, nil, pc)
+			err = FromStream(stream, nil, pc, nil)
 			require.NoError(t, err)
 			defer stream.RemoveReader(nil)
 
@@ -1,12 +1,14 @@
 package webrtc
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtpreorderer"
 	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
 	"github.com/pion/webrtc/v4"
 
 	"github.com/bluenviron/mediamtx/internal/logger"
@@ -238,10 +240,11 @@ var incomingAudioCodecs = []webrtc.RTPCodecParameters{
 type IncomingTrack struct {
 	OnPacketRTP func(*rtp.Packet)
 
-	track     *webrtc.TrackRemote
-	receiver  *webrtc.RTPReceiver
-	writeRTCP func([]rtcp.Packet) error
-	log       logger.Writer
+	track      *webrtc.TrackRemote
+	receiver   *webrtc.RTPReceiver
+	writeRTCP  func([]rtcp.Packet) error
+	log        logger.Writer
+	audioLevel *int32
 }
 
 func (t *IncomingTrack) initialize() {
@@ -289,6 +292,15 @@ func (t *IncomingTrack) start() {
 		}()
 	}
 
+	var audioLevelExtID uint8
+	if t.track.Kind() == webrtc.RTPCodecTypeAudio {
+		for _, ext := range t.receiver.GetParameters().HeaderExtensions {
+			if ext.URI == sdp.AudioLevelURI {
+				audioLevelExtID = uint8(ext.ID)
+			}
+		}
+	}
+
 	// read incoming RTP packets
 	go func() {
 		reorderer := rtpreorderer.New()
@@ -299,6 +311,10 @@ func (t *IncomingTrack) start() {
 				return
 			}
 
+			if audioLevelExtID != 0 {
+				t.storeAudioLevel(pkt, audioLevelExtID)
+			}
+
 			packets, lost := reorderer.Process(pkt)
 			if lost != 0 {
 				t.log.Log(logger.Warn, (liberrors.ErrClientRTPPacketsLost{Lost: lost}).Error())
@@ -316,3 +332,18 @@ func (t *IncomingTrack) start() {
 		}
 	}()
 }
+
+func (t *IncomingTrack) storeAudioLevel(pkt *rtp.Packet, id uint8) {
+	buf := pkt.GetExtension(id)
+	if buf == nil {
+		return
+	}
+
+	var ext rtp.AudioLevelExtension
+	err := ext.Unmarshal(buf)
+	if err != nil {
+		return
+	}
+
+	atomic.StoreInt32(t.audioLevel, int32(ext.Level))
+}
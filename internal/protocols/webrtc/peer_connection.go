@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pion/ice/v4"
@@ -82,21 +84,66 @@ type PeerConnection struct {
 	OutgoingTracks        []*OutgoingTrack
 	Log                   logger.Writer
 
-	wr                *webrtc.PeerConnection
-	stateChangeMutex  sync.Mutex
-	newLocalCandidate chan *webrtc.ICECandidateInit
-	ready             chan struct{}
-	failed            chan struct{}
-	done              chan struct{}
-	gatheringDone     chan struct{}
-	incomingTrack     chan trackRecvPair
-	ctx               context.Context
-	ctxCancel         context.CancelFunc
-	incomingTracks    []*IncomingTrack
+	// codecs supported by the remote peer, used to pick outgoing tracks.
+	// When nil, all codecs are assumed to be supported.
+	RemoteCodecs map[string]struct{}
+
+	wr                 *webrtc.PeerConnection
+	stateChangeMutex   sync.Mutex
+	newLocalCandidate  chan *webrtc.ICECandidateInit
+	ready              chan struct{}
+	failed             chan struct{}
+	done               chan struct{}
+	gatheringDoneMutex sync.Mutex
+	gatheringDone      chan struct{}
+	incomingTrack      chan trackRecvPair
+	ctx                context.Context
+	ctxCancel          context.CancelFunc
+	incomingTracks     []*IncomingTrack
+	audioLevel         int32
+}
+
+// OfferCodecs returns the codecs contained in an offer, in MIME type format.
+func OfferCodecs(offer *webrtc.SessionDescription) map[string]struct{} {
+	var desc sdp.SessionDescription
+	err := desc.Unmarshal([]byte(offer.SDP))
+	if err != nil {
+		return nil
+	}
+
+	ret := make(map[string]struct{})
+
+	for _, media := range desc.MediaDescriptions {
+		for _, attr := range media.Attributes {
+			if attr.Key != "rtpmap" {
+				continue
+			}
+
+			_, codec, ok := strings.Cut(attr.Value, " ")
+			if !ok {
+				continue
+			}
+
+			codec, _, _ = strings.Cut(codec, "/")
+			ret[strings.ToLower(media.MediaName.Media+"/"+codec)] = struct{}{}
+		}
+	}
+
+	return ret
+}
+
+func (co *PeerConnection) remoteSupports(mimeType string) bool {
+	if co == nil || co.RemoteCodecs == nil {
+		return true
+	}
+	_, ok := co.RemoteCodecs[strings.ToLower(mimeType)]
+	return ok
 }
 
 // Start starts the peer connection.
 func (co *PeerConnection) Start() error {
+	co.audioLevel = -1
+
 	settingsEngine := webrtc.SettingEngine{}
 
 	settingsEngine.SetIncludeLoopbackCandidate(true)
@@ -206,6 +253,14 @@ func (co *PeerConnection) Start() error {
 				return err
 			}
 		}
+
+		// allow publishers to send the level of audio tracks.
+		err := mediaEngine.RegisterHeaderExtension(
+			webrtc.RTPHeaderExtensionCapability{URI: sdp.AudioLevelURI},
+			webrtc.RTPCodecTypeAudio)
+		if err != nil {
+			return err
+		}
 	}
 
 	interceptorRegistry := &interceptor.Registry{}
@@ -314,7 +369,9 @@ func (co *PeerConnection) Start() error {
 			case <-co.ctx.Done():
 			}
 		} else {
+			co.gatheringDoneMutex.Lock()
 			close(co.gatheringDone)
+			co.gatheringDoneMutex.Unlock()
 		}
 	})
 
@@ -384,6 +441,106 @@ func (co *PeerConnection) CreateFullAnswer(
 	return co.wr.LocalDescription(), nil
 }
 
+// RemoteICEUfrag returns the ICE username fragment of the remote peer.
+func (co *PeerConnection) RemoteICEUfrag() string {
+	var desc sdp.SessionDescription
+	err := desc.Unmarshal([]byte(co.wr.RemoteDescription().SDP))
+	if err != nil {
+		return ""
+	}
+
+	if v, ok := desc.Attribute("ice-ufrag"); ok {
+		return v
+	}
+
+	for _, media := range desc.MediaDescriptions {
+		if v, ok := media.Attribute("ice-ufrag"); ok {
+			return v
+		}
+	}
+
+	return ""
+}
+
+func replaceICECredentials(attrs []sdp.Attribute, ufrag string, pwd string) []sdp.Attribute {
+	var ret []sdp.Attribute
+
+	for _, attr := range attrs {
+		switch attr.Key {
+		case "ice-ufrag":
+			ret = append(ret, sdp.Attribute{Key: attr.Key, Value: ufrag})
+
+		case "ice-pwd":
+			ret = append(ret, sdp.Attribute{Key: attr.Key, Value: pwd})
+
+		// candidates of the previous ICE session are not valid anymore
+		case "candidate", "end-of-candidates":
+
+		default:
+			ret = append(ret, attr)
+		}
+	}
+
+	return ret
+}
+
+// RestartICE restarts ICE after the remote peer changed its ICE credentials,
+// as it happens when it switches network.
+// The offer of the remote peer is applied again with the new credentials,
+// and the returned answer contains new local credentials and candidates.
+func (co *PeerConnection) RestartICE(
+	ctx context.Context,
+	ufrag string,
+	pwd string,
+) (*webrtc.SessionDescription, error) {
+	var desc sdp.SessionDescription
+	err := desc.Unmarshal([]byte(co.wr.RemoteDescription().SDP))
+	if err != nil {
+		return nil, err
+	}
+
+	desc.Origin.SessionVersion++
+	desc.Attributes = replaceICECredentials(desc.Attributes, ufrag, pwd)
+
+	for _, media := range desc.MediaDescriptions {
+		media.Attributes = replaceICECredentials(media.Attributes, ufrag, pwd)
+	}
+
+	byts, err := desc.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	co.gatheringDoneMutex.Lock()
+	co.gatheringDone = make(chan struct{})
+	co.gatheringDoneMutex.Unlock()
+
+	err = co.wr.SetRemoteDescription(webrtc.SessionDescription{
+		Type: webrtc.SDPTypeOffer,
+		SDP:  string(byts),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	answer, err := co.wr.CreateAnswer(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = co.wr.SetLocalDescription(answer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = co.waitGatheringDone(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return co.wr.LocalDescription(), nil
+}
+
 func (co *PeerConnection) waitGatheringDone(ctx context.Context) error {
 	for {
 		select {
@@ -422,10 +579,10 @@ outer:
 
 // GatherIncomingTracks gathers incoming tracks.
 func (co *PeerConnection) GatherIncomingTracks(ctx context.Context) ([]*IncomingTrack, error) {
-	var sdp sdp.SessionDescription
-	sdp.Unmarshal([]byte(co.wr.RemoteDescription().SDP)) //nolint:errcheck
+	var desc sdp.SessionDescription
+	desc.Unmarshal([]byte(co.wr.RemoteDescription().SDP)) //nolint:errcheck
 
-	maxTrackCount := len(sdp.MediaDescriptions)
+	maxTrackCount := len(desc.MediaDescriptions)
 
 	t := time.NewTimer(time.Duration(co.TrackGatherTimeout))
 	defer t.Stop()
@@ -440,10 +597,11 @@ func (co *PeerConnection) GatherIncomingTracks(ctx context.Context) ([]*Incoming
 
 		case pair := <-co.incomingTrack:
 			t := &IncomingTrack{
-				track:     pair.track,
-				receiver:  pair.receiver,
-				writeRTCP: co.wr.WriteRTCP,
-				log:       co.Log,
+				track:      pair.track,
+				receiver:   pair.receiver,
+				writeRTCP:  co.wr.WriteRTCP,
+				log:        co.Log,
+				audioLevel: &co.audioLevel,
 			}
 			t.initialize()
 			co.incomingTracks = append(co.incomingTracks, t)
@@ -478,6 +636,8 @@ func (co *PeerConnection) NewLocalCandidate() <-chan *webrtc.ICECandidateInit {
 
 // GatheringDone returns when candidate gathering is complete.
 func (co *PeerConnection) GatheringDone() <-chan struct{} {
+	co.gatheringDoneMutex.Lock()
+	defer co.gatheringDoneMutex.Unlock()
 	return co.gatheringDone
 }
 
@@ -544,6 +704,16 @@ func (co *PeerConnection) BytesReceived() uint64 {
 	return 0
 }
 
+// AudioLevel returns the last audio level sent by the remote peer, in -dBov.
+// It is available only when the peer sends the audio level RTP header extension.
+func (co *PeerConnection) AudioLevel() (int, bool) {
+	v := atomic.LoadInt32(&co.audioLevel)
+	if v < 0 {
+		return 0, false
+	}
+	return int(v), true
+}
+
 // BytesSent returns sent bytes.
 func (co *PeerConnection) BytesSent() uint64 {
 	for _, stats := range co.wr.GetStats() {
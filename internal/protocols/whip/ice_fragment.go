@@ -8,8 +8,7 @@ import (
 	"github.com/pion/webrtc/v4"
 )
 
-// ICEFragmentUnmarshal decodes an ICE fragment.
-func ICEFragmentUnmarshal(buf []byte) ([]*webrtc.ICECandidateInit, error) {
+func iceFragmentParse(buf []byte) (*sdp.SessionDescription, error) {
 	buf = append([]byte("v=0\r\no=- 0 0 IN IP4 0.0.0.0\r\ns=-\r\nt=0 0\r\n"), buf...)
 
 	var sdp sdp.SessionDescription
@@ -18,6 +17,16 @@ func ICEFragmentUnmarshal(buf []byte) ([]*webrtc.ICECandidateInit, error) {
 		return nil, err
 	}
 
+	return &sdp, nil
+}
+
+// ICEFragmentUnmarshal decodes an ICE fragment.
+func ICEFragmentUnmarshal(buf []byte) ([]*webrtc.ICECandidateInit, error) {
+	sdp, err := iceFragmentParse(buf)
+	if err != nil {
+		return nil, err
+	}
+
 	var ret []*webrtc.ICECandidateInit
 
 	for _, media := range sdp.MediaDescriptions {
@@ -46,6 +55,20 @@ func ICEFragmentUnmarshal(buf []byte) ([]*webrtc.ICECandidateInit, error) {
 	return ret, nil
 }
 
+// ICEFragmentUnmarshalCredentials decodes the ICE username fragment and password of an ICE fragment.
+// A change of credentials means that the remote peer is requesting an ICE restart.
+func ICEFragmentUnmarshalCredentials(buf []byte) (string, string, error) {
+	sdp, err := iceFragmentParse(buf)
+	if err != nil {
+		return "", "", err
+	}
+
+	ufrag, _ := sdp.Attribute("ice-ufrag")
+	pwd, _ := sdp.Attribute("ice-pwd")
+
+	return ufrag, pwd, nil
+}
+
 // ICEFragmentMarshal encodes an ICE fragment.
 func ICEFragmentMarshal(offer string, candidates []*webrtc.ICECandidateInit) ([]byte, error) {
 	var sdp sdp.SessionDescription
@@ -84,3 +107,32 @@ func ICEFragmentMarshal(offer string, candidates []*webrtc.ICECandidateInit) ([]
 
 	return []byte(frag), nil
 }
+
+// ICEFragmentMarshalDescription encodes the ICE credentials and candidates
+// contained in a session description into an ICE fragment.
+func ICEFragmentMarshalDescription(desc string) ([]byte, error) {
+	var sdp sdp.SessionDescription
+	err := sdp.Unmarshal([]byte(desc))
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*webrtc.ICECandidateInit
+
+	for i, media := range sdp.MediaDescriptions {
+		mid := strconv.FormatUint(uint64(i), 10)
+		midNum := uint16(i)
+
+		for _, attr := range media.Attributes {
+			if attr.Key == "candidate" {
+				candidates = append(candidates, &webrtc.ICECandidateInit{
+					Candidate:     attr.Value,
+					SDPMid:        &mid,
+					SDPMLineIndex: &midNum,
+				})
+			}
+		}
+	}
+
+	return ICEFragmentMarshal(desc, candidates)
+}
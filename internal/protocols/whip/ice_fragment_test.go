@@ -206,3 +206,10 @@ func TestICEFragmentMarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestICEFragmentUnmarshalCredentials(t *testing.T) {
+	ufrag, pwd, err := ICEFragmentUnmarshalCredentials([]byte(iceFragmentCases[0].enc))
+	require.NoError(t, err)
+	require.Equal(t, "tUQMzoQAVLzlvBys", ufrag)
+	require.Equal(t, "pimyGfJcjjRwvUjnmGOODSjtIxyDljQj", pwd)
+}
@@ -127,6 +127,10 @@ func (c *Cleaner) processPath(now time.Time, pathName string) error {
 	for _, seg := range segments {
 		c.Log(logger.Debug, "removing %s", seg.Fpath)
 		os.Remove(seg.Fpath)
+
+		if pathConf.RecordFormat == conf.RecordFormatFMP4 {
+			os.Remove(recordstore.ConvertedPath(seg.Fpath))
+		}
 	}
 
 	return nil
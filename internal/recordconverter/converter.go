@@ -0,0 +1,179 @@
+// Package recordconverter contains the recording converter.
+package recordconverter
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/playback"
+	"github.com/bluenviron/mediamtx/internal/recordstore"
+)
+
+var timeNow = time.Now
+
+// Converter converts closed fMP4 recording segments into faststart MP4 files.
+// Original segments are left in place, since the playback server and the cleaner
+// still work with them, and converted files are not added to the playback index.
+type Converter struct {
+	PathConfs map[string]*conf.Path
+	Parent    logger.Writer
+
+	ctx       context.Context
+	ctxCancel func()
+
+	chReloadConf chan map[string]*conf.Path
+	done         chan struct{}
+}
+
+// Initialize initializes a Converter.
+func (c *Converter) Initialize() {
+	c.ctx, c.ctxCancel = context.WithCancel(context.Background())
+	c.chReloadConf = make(chan map[string]*conf.Path)
+	c.done = make(chan struct{})
+
+	go c.run()
+}
+
+// Close closes the Converter.
+func (c *Converter) Close() {
+	c.ctxCancel()
+	<-c.done
+}
+
+// Log implements logger.Writer.
+func (c *Converter) Log(level logger.Level, format string, args ...interface{}) {
+	c.Parent.Log(level, "[record converter] "+format, args...)
+}
+
+// ReloadPathConfs is called by core.Core.
+func (c *Converter) ReloadPathConfs(pathConfs map[string]*conf.Path) {
+	select {
+	case c.chReloadConf <- pathConfs:
+	case <-c.ctx.Done():
+	}
+}
+
+func (c *Converter) run() {
+	defer close(c.done)
+
+	c.doRun()
+
+	for {
+		select {
+		case <-time.After(c.convertInterval()):
+			c.doRun()
+
+		case cnf := <-c.chReloadConf:
+			c.PathConfs = cnf
+
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Converter) atLeastOneRecordConvertAfter() bool {
+	for _, e := range c.PathConfs {
+		if e.RecordConvertAfter != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Converter) convertInterval() time.Duration {
+	if !c.atLeastOneRecordConvertAfter() {
+		return 365 * 24 * time.Hour
+	}
+
+	interval := 30 * 60 * time.Second
+
+	for _, e := range c.PathConfs {
+		if e.RecordConvertAfter != 0 &&
+			interval > (time.Duration(e.RecordConvertAfter)/2) {
+			interval = time.Duration(e.RecordConvertAfter) / 2
+		}
+	}
+
+	return interval
+}
+
+func (c *Converter) doRun() {
+	now := timeNow()
+
+	pathNames := recordstore.FindAllPathsWithSegments(c.PathConfs)
+
+	for _, pathName := range pathNames {
+		c.processPath(now, pathName) //nolint:errcheck
+	}
+}
+
+func (c *Converter) processPath(now time.Time, pathName string) error {
+	pathConf, _, err := conf.FindPathConf(c.PathConfs, pathName)
+	if err != nil {
+		return err
+	}
+
+	if pathConf.RecordConvertAfter == 0 || pathConf.RecordFormat != conf.RecordFormatFMP4 {
+		return nil
+	}
+
+	end := now.Add(-time.Duration(pathConf.RecordConvertAfter))
+	segments, err := recordstore.FindSegments(pathConf, pathName, nil, &end)
+	if err != nil {
+		return err
+	}
+
+	for _, seg := range segments {
+		dest := recordstore.ConvertedPath(seg.Fpath)
+
+		_, err = os.Stat(dest)
+		if err == nil {
+			continue
+		}
+
+		// make sure that the segment is not being written anymore
+		var fi os.FileInfo
+		fi, err = os.Stat(seg.Fpath)
+		if err != nil || fi.ModTime().After(end) {
+			continue
+		}
+
+		c.Log(logger.Debug, "converting %s", seg.Fpath)
+
+		err = convertSegment(seg.Fpath, dest)
+		if err != nil {
+			c.Log(logger.Warn, "unable to convert %s: %v", seg.Fpath, err)
+		}
+	}
+
+	return nil
+}
+
+func convertSegment(src string, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// write into a temporary file, in order to never expose partial files
+	tmp := dest + ".tmp"
+
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	err = playback.ConvertFMP4ToMP4(in, out)
+	out.Close()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, dest)
+}
@@ -0,0 +1,150 @@
+package recordconverter
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4/seekablebuffer"
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSegment(t *testing.T, fpath string) {
+	init := fmp4.Init{
+		Tracks: []*fmp4.InitTrack{{
+			ID:        1,
+			TimeScale: 90000,
+			Codec: &fmp4.CodecH264{
+				SPS: test.FormatH264.SPS,
+				PPS: test.FormatH264.PPS,
+			},
+		}},
+	}
+
+	var buf1 seekablebuffer.Buffer
+	err := init.Marshal(&buf1)
+	require.NoError(t, err)
+
+	var buf2 seekablebuffer.Buffer
+	parts := fmp4.Parts{{
+		SequenceNumber: 1,
+		Tracks: []*fmp4.PartTrack{{
+			ID:       1,
+			BaseTime: 0,
+			Samples: []*fmp4.PartSample{
+				{
+					Duration: 1 * 90000,
+					Payload:  []byte{1, 2},
+				},
+				{
+					Duration:        1 * 90000,
+					IsNonSyncSample: true,
+					Payload:         []byte{3, 4},
+				},
+			},
+		}},
+	}}
+	err = parts.Marshal(&buf2)
+	require.NoError(t, err)
+
+	err = os.WriteFile(fpath, append(buf1.Bytes(), buf2.Bytes()...), 0o644)
+	require.NoError(t, err)
+}
+
+func TestConverter(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-converter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "mypath"), 0o755)
+	require.NoError(t, err)
+
+	fpath := filepath.Join(dir, "mypath", "2008-05-20_22-15-25-000125.mp4")
+	writeSegment(t, fpath)
+
+	// segment is not being written anymore
+	err = os.Chtimes(fpath, time.Now().Add(-time.Minute), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	c := &Converter{
+		PathConfs: map[string]*conf.Path{
+			"mypath": {
+				Name:               "mypath",
+				RecordPath:         filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f"),
+				RecordFormat:       conf.RecordFormatFMP4,
+				RecordConvertAfter: conf.Duration(10 * time.Second),
+			},
+		},
+		Parent: test.NilLogger,
+	}
+	c.Initialize()
+	defer c.Close()
+
+	dest := filepath.Join(dir, "mypath", "2008-05-20_22-15-25-000125.faststart.mp4")
+
+	var byts []byte
+	for i := 0; i < 50; i++ {
+		byts, err = os.ReadFile(dest)
+		if err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.NoError(t, err)
+
+	require.Equal(t, []byte("ftyp"), byts[4:8])
+
+	// moov must be placed before mdat
+	ftypSize := binary.BigEndian.Uint32(byts[0:4])
+	require.Equal(t, []byte("moov"), byts[ftypSize+4:ftypSize+8])
+
+	// the temporary file must be removed
+	_, err = os.Stat(dest + ".tmp")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// the original segment is kept, since it's still used by playback and by the cleaner
+	_, err = os.Stat(fpath)
+	require.NoError(t, err)
+}
+
+func TestConverterSkipsRecentSegments(t *testing.T) {
+	timeNow = func() time.Time {
+		return time.Date(2009, 5, 20, 22, 15, 25, 427000, time.Local)
+	}
+	defer func() { timeNow = time.Now }()
+
+	dir, err := os.MkdirTemp("", "mediamtx-converter")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "mypath"), 0o755)
+	require.NoError(t, err)
+
+	// segment has just been modified, therefore it may still be written
+	err = os.WriteFile(filepath.Join(dir, "mypath", "2008-05-20_22-15-25-000125.mp4"), []byte{1}, 0o644)
+	require.NoError(t, err)
+
+	c := &Converter{
+		PathConfs: map[string]*conf.Path{
+			"mypath": {
+				Name:               "mypath",
+				RecordPath:         filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f"),
+				RecordFormat:       conf.RecordFormatFMP4,
+				RecordConvertAfter: conf.Duration(10 * time.Second),
+			},
+		},
+		Parent: test.NilLogger,
+	}
+	c.Initialize()
+	defer c.Close()
+
+	time.Sleep(500 * time.Millisecond)
+
+	_, err = os.Stat(filepath.Join(dir, "mypath", "2008-05-20_22-15-25-000125.faststart.mp4"))
+	require.Error(t, err)
+}
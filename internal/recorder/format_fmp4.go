@@ -136,6 +136,10 @@ func (f *formatFMP4) initialize() bool {
 	}
 
 	for _, media := range f.ri.rec.Stream.Desc().Medias {
+		if f.ri.rec.skipMedia(media) {
+			continue
+		}
+
 		for _, forma := range media.Formats {
 			clockRate := forma.ClockRate()
 
@@ -679,7 +683,7 @@ func (f *formatFMP4) initialize() bool {
 							return nil
 						}
 
-						var dt time.Duration
+						var elapsed int64
 
 						for _, frame := range tunit.Frames {
 							var h mpeg1audio.FrameHeader
@@ -692,22 +696,28 @@ func (f *formatFMP4) initialize() bool {
 								parsed = true
 								codec.SampleRate = h.SampleRate
 								codec.ChannelCount = mpeg1audioChannelCount(h.ChannelMode)
+
+								// use the sample rate as time scale instead of the RTP clock rate (90khz),
+								// in order to make sample durations integer numbers.
+								track.initTrack.TimeScale = uint32(h.SampleRate)
+
 								updateCodecs()
 							}
 
+							pts := multiplyAndDivide(tunit.PTS, int64(track.initTrack.TimeScale), int64(clockRate)) + elapsed
+
 							err = track.write(&sample{
 								PartSample: &fmp4.PartSample{
 									Payload: frame,
 								},
-								dts: tunit.PTS + tunit.PTS,
-								ntp: tunit.NTP,
+								dts: pts,
+								ntp: tunit.NTP.Add(timestampToDuration(elapsed, int(track.initTrack.TimeScale))),
 							})
 							if err != nil {
 								return err
 							}
 
-							dt += time.Duration(h.SampleCount()) *
-								time.Second / time.Duration(h.SampleRate)
+							elapsed += int64(h.SampleCount())
 						}
 
 						return nil
@@ -858,7 +868,7 @@ func (f *formatFMP4) initialize() bool {
 	n := 1
 	for _, medi := range f.ri.rec.Stream.Desc().Medias {
 		for _, forma := range medi.Formats {
-			if _, ok := setuppedFormatsMap[forma]; !ok {
+			if _, ok := setuppedFormatsMap[forma]; !ok && !f.ri.rec.skipMedia(medi) {
 				f.ri.Log(logger.Warn, "skipping track %d (%s)", n, forma.Codec())
 			}
 			n++
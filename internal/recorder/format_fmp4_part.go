@@ -69,17 +69,19 @@ func (p *formatFMP4Part) close() error {
 		}
 
 		p.s.f.ri.rec.OnSegmentCreate(p.s.path)
+		p.s.f.ri.rec.segmentCreated(p.s.startNTP)
 
-		err = writeInit(fi, p.s.f.tracks)
+		err = writeInit(&statusWriter{w: fi, rec: p.s.f.ri.rec}, p.s.f.tracks)
 		if err != nil {
 			fi.Close()
+			p.s.f.ri.rec.segmentClosed()
 			return err
 		}
 
 		p.s.fi = fi
 	}
 
-	return writePart(p.s.fi, p.sequenceNumber, p.partTracks)
+	return writePart(&statusWriter{w: p.s.fi, rec: p.s.f.ri.rec}, p.sequenceNumber, p.partTracks)
 }
 
 func (p *formatFMP4Part) write(track *formatFMP4Track, sample *sample, dtsDuration time.Duration) error {
@@ -135,6 +135,8 @@ func (s *formatFMP4Segment) close() error {
 			err = err2
 		}
 
+		s.f.ri.rec.segmentClosed()
+
 		if err2 == nil {
 			s.f.ri.rec.OnSegmentComplete(s.path, duration)
 		}
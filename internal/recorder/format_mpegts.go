@@ -78,6 +78,10 @@ func (f *formatMPEGTS) initialize() bool {
 	}
 
 	for _, media := range f.ri.rec.Stream.Desc().Medias {
+		if f.ri.rec.skipMedia(media) {
+			continue
+		}
+
 		for _, forma := range media.Formats {
 			clockRate := forma.ClockRate()
 
@@ -385,7 +389,7 @@ func (f *formatMPEGTS) initialize() bool {
 	n := 1
 	for _, medi := range f.ri.rec.Stream.Desc().Medias {
 		for _, forma := range medi.Formats {
-			if _, ok := setuppedFormatsMap[forma]; !ok {
+			if _, ok := setuppedFormatsMap[forma]; !ok && !f.ri.rec.skipMedia(medi) {
 				f.ri.Log(logger.Warn, "skipping track %d (%s)", n, forma.Codec())
 			}
 			n++
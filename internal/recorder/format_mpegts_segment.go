@@ -36,6 +36,8 @@ func (s *formatMPEGTSSegment) close() error {
 			err = err2
 		}
 
+		s.f.ri.rec.segmentClosed()
+
 		if err2 == nil {
 			duration := s.lastDTS - s.startDTS
 			s.f.ri.rec.OnSegmentComplete(s.path, duration)
@@ -61,9 +63,12 @@ func (s *formatMPEGTSSegment) Write(p []byte) (int, error) {
 		}
 
 		s.f.ri.rec.OnSegmentCreate(s.path)
+		s.f.ri.rec.segmentCreated(s.startNTP)
 
 		s.fi = fi
 	}
 
-	return s.fi.Write(p)
+	n, err := s.fi.Write(p)
+	s.f.ri.rec.segmentWritten(n)
+	return n, err
 }
@@ -2,8 +2,12 @@
 package recorder
 
 import (
+	"io"
+	"sync"
 	"time"
 
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/stream"
@@ -15,12 +19,42 @@ type OnSegmentCreateFunc = func(path string)
 // OnSegmentCompleteFunc is the prototype of the function passed as OnSegmentComplete
 type OnSegmentCompleteFunc = func(path string, duration time.Duration)
 
+// Status is the status of a Recorder.
+type Status struct {
+	// whether a segment is currently being written
+	Writing bool
+
+	// start time of the current segment
+	SegmentStart time.Time
+
+	// bytes written into the current segment
+	SegmentBytes uint64
+
+	// last error that interrupted the recording
+	LastError error
+}
+
+// statusWriter is a io.Writer that counts written bytes.
+type statusWriter struct {
+	w   io.Writer
+	rec *Recorder
+}
+
+// Write implements io.Writer.
+func (w *statusWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.rec.segmentWritten(n)
+	return n, err
+}
+
 // Recorder writes recordings to disk.
 type Recorder struct {
 	PathFormat        string
 	Format            conf.RecordFormat
 	PartDuration      time.Duration
 	SegmentDuration   time.Duration
+	SkipVideo         bool
+	SkipAudio         bool
 	PathName          string
 	Stream            *stream.Stream
 	OnSegmentCreate   OnSegmentCreateFunc
@@ -30,6 +64,8 @@ type Recorder struct {
 	restartPause time.Duration
 
 	currentInstance *recorderInstance
+	statusMutex     sync.Mutex
+	status          Status
 
 	terminate chan struct{}
 	done      chan struct{}
@@ -60,6 +96,16 @@ func (r *Recorder) Initialize() {
 	go r.run()
 }
 
+func (r *Recorder) skipMedia(medi *description.Media) bool {
+	switch medi.Type {
+	case description.MediaTypeVideo:
+		return r.SkipVideo
+	case description.MediaTypeAudio:
+		return r.SkipAudio
+	}
+	return false
+}
+
 // Log implements logger.Writer.
 func (r *Recorder) Log(level logger.Level, format string, args ...interface{}) {
 	r.Parent.Log(level, "[recorder] "+format, args...)
@@ -72,6 +118,39 @@ func (r *Recorder) Close() {
 	<-r.done
 }
 
+// Status returns the status of the recorder.
+func (r *Recorder) Status() Status {
+	r.statusMutex.Lock()
+	defer r.statusMutex.Unlock()
+	return r.status
+}
+
+func (r *Recorder) segmentCreated(start time.Time) {
+	r.statusMutex.Lock()
+	defer r.statusMutex.Unlock()
+	r.status.Writing = true
+	r.status.SegmentStart = start
+	r.status.SegmentBytes = 0
+}
+
+func (r *Recorder) segmentWritten(n int) {
+	r.statusMutex.Lock()
+	defer r.statusMutex.Unlock()
+	r.status.SegmentBytes += uint64(n)
+}
+
+func (r *Recorder) segmentClosed() {
+	r.statusMutex.Lock()
+	defer r.statusMutex.Unlock()
+	r.status.Writing = false
+}
+
+func (r *Recorder) setError(err error) {
+	r.statusMutex.Lock()
+	defer r.statusMutex.Unlock()
+	r.status.LastError = err
+}
+
 func (r *Recorder) run() {
 	defer close(r.done)
 
@@ -79,6 +79,7 @@ func (ri *recorderInstance) run() {
 		select {
 		case err := <-ri.rec.Stream.ReaderError(ri):
 			ri.Log(logger.Error, err.Error())
+			ri.rec.setError(err)
 
 		case <-ri.terminate:
 		}
@@ -1,6 +1,7 @@
 package recorder
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -126,6 +127,8 @@ func TestRecorder(t *testing.T) {
 		t.Run(ca, func(t *testing.T) {
 			stream, err := stream.New(
 				512,
+				0,
+				0,
 				1460,
 				desc,
 				true,
@@ -220,6 +223,10 @@ func TestRecorder(t *testing.T) {
 				<-segDone
 			}
 
+			status := w.Status()
+			require.Equal(t, false, status.Writing)
+			require.Error(t, status.LastError)
+
 			if ca == "fmp4" {
 				var init fmp4.Init
 
@@ -339,6 +346,8 @@ func TestRecorderFMP4NegativeDTS(t *testing.T) {
 
 	stream, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		desc,
 		true,
@@ -410,6 +419,86 @@ func TestRecorderFMP4NegativeDTS(t *testing.T) {
 	require.Equal(t, true, found)
 }
 
+func TestRecorderFMP4MPEG1AudioTimeScale(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{
+		{
+			Type:    description.MediaTypeAudio,
+			Formats: []rtspformat.Format{&rtspformat.MPEG1Audio{}},
+		},
+	}}
+
+	stream, err := stream.New(
+		512,
+		0,
+		0,
+		1460,
+		desc,
+		true,
+		test.NilLogger,
+	)
+	require.NoError(t, err)
+	defer stream.Close()
+
+	dir, err := os.MkdirTemp("", "mediamtx-agent")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	recordPath := filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f")
+
+	w := &Recorder{
+		PathFormat:      recordPath,
+		Format:          conf.RecordFormatFMP4,
+		PartDuration:    100 * time.Millisecond,
+		SegmentDuration: 1 * time.Second,
+		PathName:        "mypath",
+		Stream:          stream,
+		Parent:          test.NilLogger,
+	}
+	w.Initialize()
+
+	// MPEG-1 layer 3, 48khz
+	frame := []byte{0xff, 0xfb, 0x94, 0x64, 0x00}
+
+	for i := 0; i < 3; i++ {
+		stream.WriteUnit(desc.Medias[0], desc.Medias[0].Formats[0], &unit.MPEG1Audio{
+			Base: unit.Base{
+				PTS: int64(i) * 2 * 1152 * 90000 / 48000,
+				NTP: time.Date(2008, 5, 20, 22, 15, 25, 0, time.UTC),
+			},
+			Frames: [][]byte{frame, frame},
+		})
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	w.Close()
+
+	byts, err := os.ReadFile(filepath.Join(dir, "mypath", "2008-05-20_22-15-25-000000.mp4"))
+	require.NoError(t, err)
+
+	var init fmp4.Init
+	err = init.Unmarshal(bytes.NewReader(byts))
+	require.NoError(t, err)
+	require.Equal(t, uint32(48000), init.Tracks[0].TimeScale)
+
+	var parts fmp4.Parts
+	err = parts.Unmarshal(byts)
+	require.NoError(t, err)
+
+	count := 0
+
+	for _, part := range parts {
+		for _, track := range part.Tracks {
+			for _, sample := range track.Samples {
+				require.Equal(t, uint32(1152), sample.Duration)
+				count++
+			}
+		}
+	}
+
+	require.Equal(t, 5, count)
+}
+
 func TestRecorderSkipTracksPartial(t *testing.T) {
 	for _, ca := range []string{"fmp4", "mpegts"} {
 		t.Run(ca, func(t *testing.T) {
@@ -426,6 +515,8 @@ func TestRecorderSkipTracksPartial(t *testing.T) {
 
 			stream, err := stream.New(
 				512,
+				0,
+				0,
 				1460,
 				desc,
 				true,
@@ -486,6 +577,8 @@ func TestRecorderSkipTracksFull(t *testing.T) {
 
 			stream, err := stream.New(
 				512,
+				0,
+				0,
 				1460,
 				desc,
 				true,
@@ -533,3 +626,64 @@ func TestRecorderSkipTracksFull(t *testing.T) {
 		})
 	}
 }
+
+func TestRecorderSkipAudio(t *testing.T) {
+	for _, ca := range []string{"fmp4", "mpegts"} {
+		t.Run(ca, func(t *testing.T) {
+			desc := &description.Session{Medias: []*description.Media{
+				test.UniqueMediaH264(),
+				test.UniqueMediaMPEG4Audio(),
+			}}
+
+			stream, err := stream.New(
+				512,
+				0,
+				0,
+				1460,
+				desc,
+				true,
+				test.NilLogger,
+			)
+			require.NoError(t, err)
+			defer stream.Close()
+
+			dir, err := os.MkdirTemp("", "mediamtx-agent")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			recordPath := filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f")
+
+			n := 0
+
+			l := test.Logger(func(l logger.Level, format string, args ...interface{}) {
+				if n == 0 {
+					require.Equal(t, logger.Info, l)
+					require.Equal(t, "[recorder] recording 1 track (H264)", fmt.Sprintf(format, args...))
+				}
+				n++
+			})
+
+			var fo conf.RecordFormat
+			if ca == "fmp4" {
+				fo = conf.RecordFormatFMP4
+			} else {
+				fo = conf.RecordFormatMPEGTS
+			}
+
+			w := &Recorder{
+				PathFormat:      recordPath,
+				Format:          fo,
+				PartDuration:    100 * time.Millisecond,
+				SegmentDuration: 1 * time.Second,
+				SkipAudio:       true,
+				PathName:        "mypath",
+				Stream:          stream,
+				Parent:          l,
+			}
+			w.Initialize()
+			defer w.Close()
+
+			require.Equal(t, 1, n)
+		})
+	}
+}
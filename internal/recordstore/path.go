@@ -34,6 +34,11 @@ func PathAddExtension(path string, format conf.RecordFormat) string {
 	}
 }
 
+// ConvertedPath returns the path of the faststart MP4 version of a fMP4 segment.
+func ConvertedPath(fpath string) string {
+	return strings.TrimSuffix(fpath, ".mp4") + ".faststart.mp4"
+}
+
 // CommonPath returns the common path between all segments with given recording path.
 func CommonPath(v string) string {
 	common := ""
@@ -0,0 +1,220 @@
+// Package recordverifier contains the recording verifier.
+package recordverifier
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/playback"
+	"github.com/bluenviron/mediamtx/internal/recordstore"
+)
+
+// segments modified more recently than this may still be written.
+const minSegmentAge = 30 * time.Second
+
+var timeNow = time.Now
+
+// Verifier periodically checks the integrity of fMP4 recording segments.
+type Verifier struct {
+	PathConfs       map[string]*conf.Path
+	ExternalCmdPool *externalcmd.Pool
+	Parent          logger.Writer
+
+	ctx       context.Context
+	ctxCancel func()
+	mutex     sync.Mutex
+	corrupted map[string]string // segment path -> path name
+
+	chReloadConf chan map[string]*conf.Path
+	done         chan struct{}
+}
+
+// Initialize initializes a Verifier.
+func (v *Verifier) Initialize() {
+	v.ctx, v.ctxCancel = context.WithCancel(context.Background())
+	v.corrupted = make(map[string]string)
+	v.chReloadConf = make(chan map[string]*conf.Path)
+	v.done = make(chan struct{})
+
+	go v.run()
+}
+
+// Close closes the Verifier.
+func (v *Verifier) Close() {
+	v.ctxCancel()
+	<-v.done
+}
+
+// Log implements logger.Writer.
+func (v *Verifier) Log(level logger.Level, format string, args ...interface{}) {
+	v.Parent.Log(level, "[record verifier] "+format, args...)
+}
+
+// ReloadPathConfs is called by core.Core.
+func (v *Verifier) ReloadPathConfs(pathConfs map[string]*conf.Path) {
+	select {
+	case v.chReloadConf <- pathConfs:
+	case <-v.ctx.Done():
+	}
+}
+
+// CorruptedSegments returns the number of corrupted segments of each path.
+func (v *Verifier) CorruptedSegments() map[string]int {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	out := make(map[string]int)
+	for _, pathName := range v.corrupted {
+		out[pathName]++
+	}
+	return out
+}
+
+func (v *Verifier) run() {
+	defer close(v.done)
+
+	v.doRun()
+
+	for {
+		select {
+		case <-time.After(v.verifyInterval()):
+			v.doRun()
+
+		case cnf := <-v.chReloadConf:
+			v.PathConfs = cnf
+
+		case <-v.ctx.Done():
+			return
+		}
+	}
+}
+
+func (v *Verifier) atLeastOneRecordVerifyInterval() bool {
+	for _, e := range v.PathConfs {
+		if e.RecordVerifyInterval != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Verifier) verifyInterval() time.Duration {
+	if !v.atLeastOneRecordVerifyInterval() {
+		return 365 * 24 * time.Hour
+	}
+
+	var interval time.Duration
+
+	for _, e := range v.PathConfs {
+		if e.RecordVerifyInterval != 0 &&
+			(interval == 0 || interval > time.Duration(e.RecordVerifyInterval)) {
+			interval = time.Duration(e.RecordVerifyInterval)
+		}
+	}
+
+	return interval
+}
+
+func (v *Verifier) doRun() {
+	now := timeNow()
+
+	pathNames := recordstore.FindAllPathsWithSegments(v.PathConfs)
+
+	found := make(map[string]struct{})
+
+	for _, pathName := range pathNames {
+		v.processPath(now, pathName, found) //nolint:errcheck
+	}
+
+	// forget segments that have been deleted or whose verification has been disabled
+	v.mutex.Lock()
+	for fpath := range v.corrupted {
+		if _, ok := found[fpath]; !ok {
+			delete(v.corrupted, fpath)
+		}
+	}
+	v.mutex.Unlock()
+}
+
+func (v *Verifier) processPath(now time.Time, pathName string, found map[string]struct{}) error {
+	pathConf, _, err := conf.FindPathConf(v.PathConfs, pathName)
+	if err != nil {
+		return err
+	}
+
+	if pathConf.RecordVerifyInterval == 0 || pathConf.RecordFormat != conf.RecordFormatFMP4 {
+		return nil
+	}
+
+	end := now.Add(-minSegmentAge)
+	segments, err := recordstore.FindSegments(pathConf, pathName, nil, &end)
+	if err != nil {
+		return err
+	}
+
+	for _, seg := range segments {
+		// make sure that the segment is not being written anymore
+		var fi os.FileInfo
+		fi, err = os.Stat(seg.Fpath)
+		if err != nil || fi.ModTime().After(end) {
+			continue
+		}
+
+		found[seg.Fpath] = struct{}{}
+
+		v.Log(logger.Debug, "verifying %s", seg.Fpath)
+
+		err = verifySegment(seg.Fpath)
+		if err != nil {
+			v.onCorrupted(pathConf, pathName, seg.Fpath, err)
+		} else {
+			v.mutex.Lock()
+			delete(v.corrupted, seg.Fpath)
+			v.mutex.Unlock()
+		}
+	}
+
+	return nil
+}
+
+func (v *Verifier) onCorrupted(pathConf *conf.Path, pathName string, fpath string, err error) {
+	v.mutex.Lock()
+	_, alreadyReported := v.corrupted[fpath]
+	v.corrupted[fpath] = pathName
+	v.mutex.Unlock()
+
+	if alreadyReported {
+		return
+	}
+
+	v.Log(logger.Warn, "segment %s is corrupted: %v", fpath, err)
+
+	if pathConf.RunOnRecordSegmentCorrupted != "" {
+		v.Log(logger.Info, "runOnRecordSegmentCorrupted command launched")
+		externalcmd.NewCmd(
+			v.ExternalCmdPool,
+			pathConf.RunOnRecordSegmentCorrupted,
+			false,
+			externalcmd.Environment{
+				"MTX_PATH":          pathName,
+				"MTX_SEGMENT_PATH":  fpath,
+				"MTX_SEGMENT_ERROR": err.Error(),
+			},
+			nil)
+	}
+}
+
+func verifySegment(fpath string) error {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return playback.VerifyFMP4(f)
+}
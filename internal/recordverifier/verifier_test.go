@@ -0,0 +1,87 @@
+package recordverifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifierCorrupted(t *testing.T) {
+	timeNow = func() time.Time {
+		return time.Date(2009, 5, 20, 22, 15, 25, 427000, time.Local)
+	}
+
+	dir, err := os.MkdirTemp("", "mediamtx-verifier")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "mypath"), 0o755)
+	require.NoError(t, err)
+
+	fpath := filepath.Join(dir, "mypath", "2008-05-20_22-15-25-000125.mp4")
+
+	err = os.WriteFile(fpath, []byte{0, 0, 0, 8, 'f', 't', 'y', 'p', 1, 2, 3}, 0o644)
+	require.NoError(t, err)
+
+	mtime := time.Date(2008, 5, 20, 22, 16, 25, 0, time.Local)
+	err = os.Chtimes(fpath, mtime, mtime)
+	require.NoError(t, err)
+
+	v := &Verifier{
+		PathConfs: map[string]*conf.Path{
+			"mypath": {
+				Name:                 "mypath",
+				RecordPath:           filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f"),
+				RecordFormat:         conf.RecordFormatFMP4,
+				RecordVerifyInterval: conf.Duration(24 * time.Hour),
+			},
+		},
+		Parent: test.NilLogger,
+	}
+	v.Initialize()
+	defer v.Close()
+
+	time.Sleep(500 * time.Millisecond)
+
+	require.Equal(t, map[string]int{"mypath": 1}, v.CorruptedSegments())
+}
+
+func TestVerifierSkipsRecentSegments(t *testing.T) {
+	timeNow = func() time.Time {
+		return time.Date(2009, 5, 20, 22, 15, 25, 427000, time.Local)
+	}
+
+	dir, err := os.MkdirTemp("", "mediamtx-verifier")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "mypath"), 0o755)
+	require.NoError(t, err)
+
+	// segment has just been modified, therefore it may still be written
+	err = os.WriteFile(filepath.Join(dir, "mypath", "2008-05-20_22-15-25-000125.mp4"), []byte{1}, 0o644)
+	require.NoError(t, err)
+
+	v := &Verifier{
+		PathConfs: map[string]*conf.Path{
+			"mypath": {
+				Name:                 "mypath",
+				RecordPath:           filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f"),
+				RecordFormat:         conf.RecordFormatFMP4,
+				RecordVerifyInterval: conf.Duration(24 * time.Hour),
+			},
+		},
+		Parent: test.NilLogger,
+	}
+	v.Initialize()
+	defer v.Close()
+
+	time.Sleep(500 * time.Millisecond)
+
+	require.Equal(t, map[string]int{}, v.CorruptedSegments())
+}
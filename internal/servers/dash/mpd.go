@@ -0,0 +1,167 @@
+package dash
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+)
+
+type mpdSegment struct {
+	T uint64 `xml:"t,attr"`
+	D uint64 `xml:"d,attr"`
+}
+
+type mpdSegmentTemplate struct {
+	Timescale      int          `xml:"timescale,attr"`
+	Initialization string       `xml:"initialization,attr"`
+	Media          string       `xml:"media,attr"`
+	StartNumber    uint64       `xml:"startNumber,attr"`
+	Segments       []mpdSegment `xml:"SegmentTimeline>S"`
+}
+
+type mpdRepresentation struct {
+	ID                string             `xml:"id,attr"`
+	Codecs            string             `xml:"codecs,attr"`
+	Bandwidth         int                `xml:"bandwidth,attr"`
+	Width             int                `xml:"width,attr,omitempty"`
+	Height            int                `xml:"height,attr,omitempty"`
+	AudioSamplingRate int                `xml:"audioSamplingRate,attr,omitempty"`
+	SegmentTemplate   mpdSegmentTemplate `xml:"SegmentTemplate"`
+}
+
+type mpdAdaptationSet struct {
+	ID             int               `xml:"id,attr"`
+	ContentType    string            `xml:"contentType,attr"`
+	MimeType       string            `xml:"mimeType,attr"`
+	StartWithSAP   int               `xml:"startWithSAP,attr"`
+	Representation mpdRepresentation `xml:"Representation"`
+}
+
+type mpdPeriod struct {
+	ID             string             `xml:"id,attr"`
+	Start          string             `xml:"start,attr"`
+	AdaptationSets []mpdAdaptationSet `xml:"AdaptationSet"`
+}
+
+type mpdUTCTiming struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       string `xml:"value,attr"`
+}
+
+type mpd struct {
+	XMLName                    xml.Name     `xml:"urn:mpeg:dash:schema:mpd:2011 MPD"`
+	Profiles                   string       `xml:"profiles,attr"`
+	Type                       string       `xml:"type,attr"`
+	AvailabilityStartTime      string       `xml:"availabilityStartTime,attr"`
+	PublishTime                string       `xml:"publishTime,attr"`
+	MinimumUpdatePeriod        string       `xml:"minimumUpdatePeriod,attr"`
+	MinBufferTime              string       `xml:"minBufferTime,attr"`
+	TimeShiftBufferDepth       string       `xml:"timeShiftBufferDepth,attr"`
+	SuggestedPresentationDelay string       `xml:"suggestedPresentationDelay,attr"`
+	Period                     mpdPeriod    `xml:"Period"`
+	UTCTiming                  mpdUTCTiming `xml:"UTCTiming"`
+}
+
+func (m mpd) marshal() ([]byte, error) {
+	byts, err := xml.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), byts...), nil
+}
+
+func mpdDuration(d time.Duration) string {
+	return "PT" + strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+}
+
+func mpdTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// codecStringH265 returns the codec string of a H265 track.
+// Specification: ISO 14496-15, Annex E.3
+func codecStringH265(sps []byte) string {
+	if len(sps) < 2 {
+		return "hvc1"
+	}
+
+	// skip NALU header, then VPS ID, max sub layers and temporal ID nesting
+	buf := h264.EmulationPreventionRemove(sps[2:])
+	if len(buf) < 13 {
+		return "hvc1"
+	}
+	ptl := buf[1:13]
+
+	profileSpace := ptl[0] >> 6
+	tierFlag := (ptl[0] >> 5) & 0x01
+	profileIdc := ptl[0] & 0x1F
+	compatibilityFlags := bits.Reverse32(binary.BigEndian.Uint32(ptl[1:5]))
+	constraintFlags := ptl[5:11]
+	levelIdc := ptl[11]
+
+	var sb strings.Builder
+	sb.WriteString("hvc1.")
+	sb.WriteString([]string{"", "A", "B", "C"}[profileSpace])
+	sb.WriteString(strconv.FormatUint(uint64(profileIdc), 10))
+	sb.WriteString(fmt.Sprintf(".%X", compatibilityFlags))
+
+	if tierFlag == 0 {
+		sb.WriteString(".L")
+	} else {
+		sb.WriteString(".H")
+	}
+	sb.WriteString(strconv.FormatUint(uint64(levelIdc), 10))
+
+	// trailing zero bytes are omitted
+	n := len(constraintFlags)
+	for n > 0 && constraintFlags[n-1] == 0 {
+		n--
+	}
+	for _, b := range constraintFlags[:n] {
+		sb.WriteString(fmt.Sprintf(".%X", b))
+	}
+
+	return sb.String()
+}
+
+// codecParameters returns the codec string and the size of a track.
+func codecParameters(codec fmp4.Codec) (string, int, int) {
+	switch codec := codec.(type) {
+	case *fmp4.CodecH265:
+		var sps h265.SPS
+		err := sps.Unmarshal(codec.SPS)
+		if err != nil {
+			return codecStringH265(codec.SPS), 0, 0
+		}
+		return codecStringH265(codec.SPS), sps.Width(), sps.Height()
+
+	case *fmp4.CodecH264:
+		if len(codec.SPS) < 4 {
+			return "avc1", 0, 0
+		}
+		str := fmt.Sprintf("avc1.%02x%02x%02x", codec.SPS[1], codec.SPS[2], codec.SPS[3])
+
+		var sps h264.SPS
+		err := sps.Unmarshal(codec.SPS)
+		if err != nil {
+			return str, 0, 0
+		}
+		return str, sps.Width(), sps.Height()
+
+	case *fmp4.CodecOpus:
+		return "opus", 0, 0
+
+	case *fmp4.CodecMPEG4Audio:
+		return fmt.Sprintf("mp4a.40.%d", codec.Config.Type), 0, 0
+	}
+
+	return "", 0, 0
+}
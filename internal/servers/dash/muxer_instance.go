@@ -0,0 +1,595 @@
+package dash
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
+	"github.com/bluenviron/mediacommon/pkg/codecs/mpeg4audio"
+	"github.com/bluenviron/mediacommon/pkg/codecs/opus"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+	"github.com/gin-gonic/gin"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+const (
+	mpdName = "index.mpd"
+)
+
+// ErrNoSupportedCodecs is returned when the stream doesn't contain any supported codec.
+var ErrNoSupportedCodecs = errors.New(
+	"the stream doesn't contain any supported codec, which are currently H265, H264, Opus, MPEG-4 Audio")
+
+var errCodecParamsChanged = errors.New("codec parameters changed")
+
+var reInit = regexp.MustCompile(`^init_([0-9]+)\.mp4$`)
+
+var reSegment = regexp.MustCompile(`^seg_([0-9]+)_([0-9]+)\.mp4$`)
+
+type responseWriterWithCounter struct {
+	http.ResponseWriter
+	bytesSent *uint64
+}
+
+func (w *responseWriterWithCounter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	atomic.AddUint64(w.bytesSent, uint64(n))
+	return n, err
+}
+
+type muxerInstance struct {
+	segmentCount    int
+	segmentDuration conf.Duration
+	writeTimeout    conf.Duration
+	stream          *stream.Stream
+	bytesSent       *uint64
+	parent          logger.Writer
+
+	tracks       []*muxerTrack
+	leadingTrack *muxerTrack
+	ready        chan struct{}
+
+	mutex                 sync.RWMutex
+	started               bool
+	startDTS              time.Duration
+	segmentStart          time.Duration
+	availabilityStartTime time.Time
+}
+
+func (mi *muxerInstance) initialize() error {
+	mi.ready = make(chan struct{})
+
+	mi.setupVideoTrack()
+	mi.setupAudioTrack()
+
+	if len(mi.tracks) == 0 {
+		return ErrNoSupportedCodecs
+	}
+
+	// segments are split on random access points of the first track,
+	// that is the video track if present.
+	mi.leadingTrack = mi.tracks[0]
+
+	mi.Log(logger.Info, "is converting into DASH, %s",
+		defs.FormatsInfo(mi.stream.ReaderFormats(mi)))
+
+	mi.stream.StartReader(mi)
+
+	return nil
+}
+
+// Log implements logger.Writer.
+func (mi *muxerInstance) Log(level logger.Level, format string, args ...interface{}) {
+	mi.parent.Log(level, format, args...)
+}
+
+func (mi *muxerInstance) close() {
+	mi.stream.RemoveReader(mi)
+}
+
+func (mi *muxerInstance) errorChan() chan error {
+	return mi.stream.ReaderError(mi)
+}
+
+func (mi *muxerInstance) addTrack(
+	medi *description.Media,
+	forma format.Format,
+	isVideo bool,
+	codec fmp4.Codec,
+	readFunc func(track *muxerTrack) stream.ReadFunc,
+) {
+	track := &muxerTrack{
+		id:           len(mi.tracks) + 1,
+		isVideo:      isVideo,
+		clockRate:    forma.ClockRate(),
+		codec:        codec,
+		segmentCount: mi.segmentCount,
+	}
+	track.initialize()
+	mi.tracks = append(mi.tracks, track)
+
+	mi.stream.AddReader(mi, medi, forma, readFunc(track))
+}
+
+func (mi *muxerInstance) setupVideoTrack() {
+	for _, medi := range mi.stream.Desc().Medias {
+		for _, forma := range medi.Formats {
+			switch forma := forma.(type) {
+			case *format.H265:
+				vps, sps, pps := forma.SafeParams()
+				codec := &fmp4.CodecH265{
+					VPS: vps,
+					SPS: sps,
+					PPS: pps,
+				}
+
+				mi.addTrack(medi, forma, true, codec, func(track *muxerTrack) stream.ReadFunc {
+					var dtsExtractor *h265.DTSExtractor2
+
+					return func(u unit.Unit) error {
+						tunit := u.(*unit.H265)
+						if tunit.AU == nil {
+							return nil
+						}
+
+						randomAccess := false
+
+						for _, nalu := range tunit.AU {
+							typ := h265.NALUType((nalu[0] >> 1) & 0b111111)
+
+							switch typ {
+							case h265.NALUType_VPS_NUT:
+								if !bytes.Equal(codec.VPS, nalu) {
+									if track.init != nil {
+										return errCodecParamsChanged
+									}
+									codec.VPS = nalu
+								}
+
+							case h265.NALUType_SPS_NUT:
+								if !bytes.Equal(codec.SPS, nalu) {
+									if track.init != nil {
+										return errCodecParamsChanged
+									}
+									codec.SPS = nalu
+								}
+
+							case h265.NALUType_PPS_NUT:
+								if !bytes.Equal(codec.PPS, nalu) {
+									if track.init != nil {
+										return errCodecParamsChanged
+									}
+									codec.PPS = nalu
+								}
+
+							case h265.NALUType_IDR_W_RADL, h265.NALUType_IDR_N_LP, h265.NALUType_CRA_NUT:
+								randomAccess = true
+							}
+						}
+
+						if dtsExtractor == nil {
+							if !randomAccess || codec.VPS == nil || codec.SPS == nil || codec.PPS == nil {
+								return nil
+							}
+							dtsExtractor = h265.NewDTSExtractor2()
+						}
+
+						dts, err := dtsExtractor.Extract(tunit.AU, tunit.PTS)
+						if err != nil {
+							return err
+						}
+
+						sampl, err := fmp4.NewPartSampleH26x(
+							int32(tunit.PTS-dts),
+							randomAccess,
+							tunit.AU)
+						if err != nil {
+							return err
+						}
+
+						return mi.writeSample(track, randomAccess, dts, sampl)
+					}
+				})
+				return
+
+			case *format.H264:
+				sps, pps := forma.SafeParams()
+				codec := &fmp4.CodecH264{
+					SPS: sps,
+					PPS: pps,
+				}
+
+				mi.addTrack(medi, forma, true, codec, func(track *muxerTrack) stream.ReadFunc {
+					var dtsExtractor *h264.DTSExtractor2
+
+					return func(u unit.Unit) error {
+						tunit := u.(*unit.H264)
+						if tunit.AU == nil {
+							return nil
+						}
+
+						randomAccess := false
+
+						for _, nalu := range tunit.AU {
+							typ := h264.NALUType(nalu[0] & 0x1F)
+
+							switch typ {
+							case h264.NALUTypeSPS:
+								if !bytes.Equal(codec.SPS, nalu) {
+									if track.init != nil {
+										return errCodecParamsChanged
+									}
+									codec.SPS = nalu
+								}
+
+							case h264.NALUTypePPS:
+								if !bytes.Equal(codec.PPS, nalu) {
+									if track.init != nil {
+										return errCodecParamsChanged
+									}
+									codec.PPS = nalu
+								}
+
+							case h264.NALUTypeIDR:
+								randomAccess = true
+							}
+						}
+
+						if dtsExtractor == nil {
+							if !randomAccess || codec.SPS == nil || codec.PPS == nil {
+								return nil
+							}
+							dtsExtractor = h264.NewDTSExtractor2()
+						}
+
+						dts, err := dtsExtractor.Extract(tunit.AU, tunit.PTS)
+						if err != nil {
+							return err
+						}
+
+						sampl, err := fmp4.NewPartSampleH26x(
+							int32(tunit.PTS-dts),
+							randomAccess,
+							tunit.AU)
+						if err != nil {
+							return err
+						}
+
+						return mi.writeSample(track, randomAccess, dts, sampl)
+					}
+				})
+				return
+			}
+		}
+	}
+}
+
+func (mi *muxerInstance) setupAudioTrack() {
+	for _, medi := range mi.stream.Desc().Medias {
+		for _, forma := range medi.Formats {
+			switch forma := forma.(type) {
+			case *format.Opus:
+				codec := &fmp4.CodecOpus{
+					ChannelCount: forma.ChannelCount,
+				}
+
+				mi.addTrack(medi, forma, false, codec, func(track *muxerTrack) stream.ReadFunc {
+					return func(u unit.Unit) error {
+						tunit := u.(*unit.Opus)
+						if tunit.Packets == nil {
+							return nil
+						}
+
+						pts := tunit.PTS
+
+						for _, packet := range tunit.Packets {
+							err := mi.writeSample(track, true, pts, &fmp4.PartSample{
+								Payload: packet,
+							})
+							if err != nil {
+								return err
+							}
+
+							pts += durationToTimestamp(opus.PacketDuration(packet), track.clockRate)
+						}
+
+						return nil
+					}
+				})
+				return
+
+			case *format.MPEG4Audio:
+				co := forma.GetConfig()
+				if co == nil {
+					continue
+				}
+
+				codec := &fmp4.CodecMPEG4Audio{
+					Config: *co,
+				}
+
+				mi.addTrack(medi, forma, false, codec, func(track *muxerTrack) stream.ReadFunc {
+					return func(u unit.Unit) error {
+						tunit := u.(*unit.MPEG4Audio)
+						if tunit.AUs == nil {
+							return nil
+						}
+
+						for i, au := range tunit.AUs {
+							err := mi.writeSample(
+								track,
+								true,
+								tunit.PTS+int64(i)*mpeg4audio.SamplesPerAccessUnit,
+								&fmp4.PartSample{
+									Payload: au,
+								})
+							if err != nil {
+								return err
+							}
+						}
+
+						return nil
+					}
+				})
+				return
+			}
+		}
+	}
+}
+
+func (mi *muxerInstance) writeSample(
+	track *muxerTrack,
+	randomAccess bool,
+	dts int64,
+	sampl *fmp4.PartSample,
+) error {
+	mi.mutex.Lock()
+	defer mi.mutex.Unlock()
+
+	dtsGo := timestampToDuration(dts, track.clockRate)
+
+	if !mi.started {
+		if track != mi.leadingTrack || !randomAccess {
+			return nil
+		}
+
+		mi.started = true
+		mi.startDTS = dtsGo
+		mi.segmentStart = dtsGo
+		mi.availabilityStartTime = time.Now()
+	} else if track == mi.leadingTrack && randomAccess &&
+		(dtsGo-mi.segmentStart) >= time.Duration(mi.segmentDuration) {
+		mi.segmentStart = dtsGo
+	}
+
+	err := track.write(mi.startDTS, mi.segmentStart, dts, sampl)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-mi.ready:
+	default:
+		if mi.allTracksHaveSegments() {
+			close(mi.ready)
+		}
+	}
+
+	return nil
+}
+
+func (mi *muxerInstance) allTracksHaveSegments() bool {
+	for _, track := range mi.tracks {
+		if len(track.segments) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (mi *muxerInstance) generateMPD(query string) ([]byte, error) {
+	mi.mutex.RLock()
+	defer mi.mutex.RUnlock()
+
+	// propagate query parameters, that may contain credentials, into segment URLs.
+	if query != "" {
+		query = "?" + strings.ReplaceAll(query, "$", "$$")
+	}
+
+	var maxDuration time.Duration
+
+	m := mpd{
+		Profiles:                   "urn:mpeg:dash:profile:isoff-live:2011",
+		Type:                       "dynamic",
+		AvailabilityStartTime:      mpdTime(mi.availabilityStartTime),
+		PublishTime:                mpdTime(time.Now()),
+		MinimumUpdatePeriod:        mpdDuration(time.Duration(mi.segmentDuration)),
+		MinBufferTime:              mpdDuration(2 * time.Duration(mi.segmentDuration)),
+		SuggestedPresentationDelay: mpdDuration(3 * time.Duration(mi.segmentDuration)),
+		Period: mpdPeriod{
+			ID:    "0",
+			Start: "PT0S",
+		},
+		UTCTiming: mpdUTCTiming{
+			SchemeIDURI: "urn:mpeg:dash:utc:direct:2014",
+			Value:       mpdTime(time.Now()),
+		},
+	}
+
+	for _, track := range mi.tracks {
+		var duration time.Duration
+
+		representation := mpdRepresentation{
+			ID:        strconv.FormatInt(int64(track.id), 10),
+			Codecs:    track.codecs,
+			Bandwidth: track.bandwidth(),
+			SegmentTemplate: mpdSegmentTemplate{
+				Timescale:      track.clockRate,
+				Initialization: "init_" + strconv.FormatInt(int64(track.id), 10) + ".mp4" + query,
+				Media:          "seg_" + strconv.FormatInt(int64(track.id), 10) + "_$Number$.mp4" + query,
+				StartNumber:    track.segments[0].number,
+			},
+		}
+
+		for _, seg := range track.segments {
+			representation.SegmentTemplate.Segments = append(representation.SegmentTemplate.Segments, mpdSegment{
+				T: seg.baseTime,
+				D: seg.duration,
+			})
+			duration += timestampToDuration(int64(seg.duration), track.clockRate)
+		}
+
+		if duration > maxDuration {
+			maxDuration = duration
+		}
+
+		adaptationSet := mpdAdaptationSet{
+			ID:           track.id,
+			StartWithSAP: 1,
+		}
+
+		if track.isVideo {
+			adaptationSet.ContentType = "video"
+			adaptationSet.MimeType = "video/mp4"
+			representation.Width = track.width
+			representation.Height = track.height
+		} else {
+			adaptationSet.ContentType = "audio"
+			adaptationSet.MimeType = "audio/mp4"
+			representation.AudioSamplingRate = track.clockRate
+		}
+
+		adaptationSet.Representation = representation
+		m.Period.AdaptationSets = append(m.Period.AdaptationSets, adaptationSet)
+	}
+
+	m.TimeShiftBufferDepth = mpdDuration(maxDuration)
+
+	return m.marshal()
+}
+
+func (mi *muxerInstance) findTrack(id string) *muxerTrack {
+	tmp, err := strconv.ParseUint(id, 10, 31)
+	if err != nil {
+		return nil
+	}
+
+	for _, track := range mi.tracks {
+		if track.id == int(tmp) {
+			return track
+		}
+	}
+	return nil
+}
+
+func (mi *muxerInstance) getInit(id string) []byte {
+	mi.mutex.RLock()
+	defer mi.mutex.RUnlock()
+
+	track := mi.findTrack(id)
+	if track == nil {
+		return nil
+	}
+
+	return track.init
+}
+
+func (mi *muxerInstance) getSegment(id string, number string) []byte {
+	tmp, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	mi.mutex.RLock()
+	defer mi.mutex.RUnlock()
+
+	track := mi.findTrack(id)
+	if track == nil {
+		return nil
+	}
+
+	seg := track.findSegment(tmp)
+	if seg == nil {
+		return nil
+	}
+
+	return seg.content
+}
+
+func (mi *muxerInstance) handleRequest(ctx *gin.Context, fname string) {
+	w := &responseWriterWithCounter{
+		ResponseWriter: ctx.Writer,
+		bytesSent:      mi.bytesSent,
+	}
+
+	switch {
+	case fname == mpdName:
+		// wait for the first segment of every track
+		select {
+		case <-mi.ready:
+		case <-time.After(time.Duration(mi.writeTimeout)):
+			ctx.Writer.WriteHeader(http.StatusNotFound)
+			return
+		case <-ctx.Request.Context().Done():
+			return
+		}
+
+		byts, err := mi.generateMPD(ctx.Request.URL.RawQuery)
+		if err != nil {
+			mi.Log(logger.Error, "unable to generate MPD: %v", err)
+			ctx.Writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Content-Type", "application/dash+xml")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		w.Write(byts) //nolint:errcheck
+
+	case reInit.MatchString(fname):
+		m := reInit.FindStringSubmatch(fname)
+
+		byts := mi.getInit(m[1])
+		if byts == nil {
+			ctx.Writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		ctx.Header("Cache-Control", "max-age=30")
+		ctx.Header("Content-Type", "video/mp4")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		w.Write(byts) //nolint:errcheck
+
+	case reSegment.MatchString(fname):
+		m := reSegment.FindStringSubmatch(fname)
+
+		byts := mi.getSegment(m[1], m[2])
+		if byts == nil {
+			ctx.Writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		ctx.Header("Cache-Control", "max-age=3600")
+		ctx.Header("Content-Type", "video/mp4")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		w.Write(byts) //nolint:errcheck
+
+	default:
+		ctx.Writer.WriteHeader(http.StatusNotFound)
+	}
+}
@@ -0,0 +1,190 @@
+package dash
+
+import (
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4/seekablebuffer"
+)
+
+func multiplyAndDivide(v, m, d int64) int64 {
+	secs := v / d
+	dec := v % d
+	return (secs*m + dec*m/d)
+}
+
+func timestampToDuration(t int64, clockRate int) time.Duration {
+	return time.Duration(multiplyAndDivide(t, int64(time.Second), int64(clockRate)))
+}
+
+func durationToTimestamp(d time.Duration, clockRate int) int64 {
+	return multiplyAndDivide(int64(d), int64(clockRate), int64(time.Second))
+}
+
+type muxerSegment struct {
+	number   uint64
+	start    time.Duration
+	baseTime uint64
+	duration uint64
+	samples  []*fmp4.PartSample
+	content  []byte
+}
+
+type muxerTrack struct {
+	id           int
+	isVideo      bool
+	clockRate    int
+	codec        fmp4.Codec
+	segmentCount int
+
+	// filled when the first sample is received
+	init     []byte
+	codecs   string
+	width    int
+	height   int
+	startDTS int64
+
+	segments   []*muxerSegment
+	nextNumber uint64
+	cur        *muxerSegment
+	pending    *fmp4.PartSample
+	pendingDTS int64
+}
+
+func (t *muxerTrack) initialize() {
+	t.nextNumber = 1
+}
+
+func (t *muxerTrack) generateInit() error {
+	fmp4Init := fmp4.Init{
+		Tracks: []*fmp4.InitTrack{{
+			ID:        1,
+			TimeScale: uint32(t.clockRate),
+			Codec:     t.codec,
+		}},
+	}
+
+	var buf seekablebuffer.Buffer
+	err := fmp4Init.Marshal(&buf)
+	if err != nil {
+		return err
+	}
+
+	t.init = buf.Bytes()
+	t.codecs, t.width, t.height = codecParameters(t.codec)
+	return nil
+}
+
+// write adds a sample to the track.
+// The current segment is closed when the sample comes after segmentStart,
+// that is the start of the latest segment of the leading track.
+func (t *muxerTrack) write(
+	startDTS time.Duration,
+	segmentStart time.Duration,
+	dts int64,
+	sampl *fmp4.PartSample,
+) error {
+	dtsGo := timestampToDuration(dts, t.clockRate)
+
+	if t.cur == nil {
+		t.startDTS = durationToTimestamp(startDTS, t.clockRate)
+		if dts < t.startDTS {
+			return nil
+		}
+
+		err := t.generateInit()
+		if err != nil {
+			return err
+		}
+
+		t.cur = &muxerSegment{
+			number:   t.nextNumber,
+			start:    dtsGo,
+			baseTime: uint64(dts - t.startDTS),
+		}
+		t.nextNumber++
+		t.pending = sampl
+		t.pendingDTS = dts
+		return nil
+	}
+
+	diff := dts - t.pendingDTS
+	if diff < 0 {
+		diff = 0
+	}
+
+	t.pending.Duration = uint32(diff)
+	t.cur.samples = append(t.cur.samples, t.pending)
+	t.cur.duration += uint64(diff)
+
+	if segmentStart > t.cur.start && dtsGo >= segmentStart {
+		err := t.closeSegment()
+		if err != nil {
+			return err
+		}
+
+		t.cur = &muxerSegment{
+			number:   t.nextNumber,
+			start:    dtsGo,
+			baseTime: t.cur.baseTime + t.cur.duration,
+		}
+		t.nextNumber++
+	}
+
+	t.pending = sampl
+	t.pendingDTS = dts
+	return nil
+}
+
+func (t *muxerTrack) closeSegment() error {
+	part := fmp4.Part{
+		SequenceNumber: uint32(t.cur.number),
+		Tracks: []*fmp4.PartTrack{{
+			ID:       1,
+			BaseTime: t.cur.baseTime,
+			Samples:  t.cur.samples,
+		}},
+	}
+
+	var buf seekablebuffer.Buffer
+	err := part.Marshal(&buf)
+	if err != nil {
+		return err
+	}
+
+	t.cur.content = buf.Bytes()
+	t.cur.samples = nil
+
+	t.segments = append(t.segments, t.cur)
+	if len(t.segments) > t.segmentCount {
+		t.segments = t.segments[1:]
+	}
+
+	return nil
+}
+
+func (t *muxerTrack) findSegment(number uint64) *muxerSegment {
+	for _, seg := range t.segments {
+		if seg.number == number {
+			return seg
+		}
+	}
+	return nil
+}
+
+// bandwidth returns the average bitrate of stored segments.
+func (t *muxerTrack) bandwidth() int {
+	var size uint64
+	var duration uint64
+
+	for _, seg := range t.segments {
+		size += uint64(len(seg.content))
+		duration += seg.duration
+	}
+
+	if duration == 0 {
+		return 1
+	}
+
+	return int(size * 8 * uint64(t.clockRate) / duration)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	"github.com/bluenviron/mediamtx/internal/auth"
 	"github.com/bluenviron/mediamtx/internal/conf"
@@ -43,6 +44,8 @@ type httpServer struct {
 	allowOrigin    string
 	trustedProxies conf.IPNetworks
 	readTimeout    conf.Duration
+	writeTimeout   conf.Duration
+	sessionTokens  *sessionTokens
 	pathManager    serverPathManager
 	parent         *Server
 
@@ -187,12 +190,37 @@ func (s *httpServer) onRequest(ctx *gin.Context) {
 		ctx.Writer.WriteHeader(http.StatusOK)
 		ctx.Writer.Write(hlsIndex)
 
+	case mpegtsStreamName:
+		r := &mpegtsReader{
+			writeTimeout: s.writeTimeout,
+			pathManager:  s.pathManager,
+			parent:       s,
+		}
+		r.initialize()
+		r.run(ctx, dir)
+
 	default:
+		startOffset, err := parseStartOffset(ctx.Query(startOffsetParam))
+		if err != nil {
+			s.Log(logger.Info, "request from %v rejected: %v", httpp.RemoteAddr(ctx), err)
+			ctx.Writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var sessionID uuid.UUID
+		if s.sessionTokens != nil {
+			var ok bool
+			sessionID, ok = s.checkSessionToken(ctx, fname)
+			if !ok {
+				return
+			}
+		}
+
 		mux, err := s.parent.getMuxer(serverGetMuxerReq{
-			path:           dir,
-			remoteAddr:     httpp.RemoteAddr(ctx),
-			query:          ctx.Request.URL.RawQuery,
-			sourceOnDemand: pathConf.SourceOnDemand,
+			path:        dir,
+			remoteAddr:  httpp.RemoteAddr(ctx),
+			query:       ctx.Request.URL.RawQuery,
+			alwaysRemux: s.parent.alwaysRemux(pathConf),
 		})
 		if err != nil {
 			ctx.Writer.WriteHeader(http.StatusNotFound)
@@ -205,7 +233,44 @@ func (s *httpServer) onRequest(ctx *gin.Context) {
 			return
 		}
 
+		sess := mux.getSession(ctx, sessionID)
+
 		ctx.Request.URL.Path = fname
-		mi.handleRequest(ctx)
+		mi.handleRequest(ctx, sess.bytesSent, startOffset)
 	}
 }
+
+// checkSessionToken checks the session token of a request.
+// Requests without a token, or with an expired one, are redirected to an URL that contains a new one;
+// the token is then propagated by the muxer into all playlist and segment URLs.
+func (s *httpServer) checkSessionToken(ctx *gin.Context, fname string) (uuid.UUID, bool) {
+	token := ctx.Query(sessionTokenParam)
+
+	if token != "" {
+		id, err := s.sessionTokens.verify(token)
+		if err == nil {
+			if s.sessionTokens.isRevoked(id) {
+				ctx.Writer.WriteHeader(http.StatusForbidden)
+				return uuid.Nil, false
+			}
+
+			return id, true
+		}
+
+		if !errors.Is(err, errSessionTokenExpired) || !strings.HasSuffix(fname, ".m3u8") {
+			s.Log(logger.Info, "request from %v rejected: %v", httpp.RemoteAddr(ctx), err)
+			ctx.Writer.WriteHeader(http.StatusUnauthorized)
+			return uuid.Nil, false
+		}
+	} else if !strings.HasSuffix(fname, ".m3u8") {
+		ctx.Writer.WriteHeader(http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+
+	q := ctx.Request.URL.Query()
+	q.Set(sessionTokenParam, s.sessionTokens.generate(uuid.New()))
+
+	ctx.Header("Location", mergePathAndQuery(ctx.Request.URL.Path, q.Encode()))
+	ctx.Writer.WriteHeader(http.StatusFound)
+	return uuid.Nil, false
+}
@@ -0,0 +1,125 @@
+package hls
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/httpp"
+	"github.com/bluenviron/mediamtx/internal/protocols/mpegts"
+)
+
+// name of the file that contains a continuous MPEG-TS stream.
+const mpegtsStreamName = "stream.ts"
+
+// size of the write buffer, a multiple of the MPEG-TS packet size.
+const mpegtsBufferSize = 188 * 7
+
+type flushWriter struct {
+	w http.ResponseWriter
+	f *http.ResponseController
+}
+
+func (w *flushWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, w.f.Flush()
+}
+
+// mpegtsReader serves a path as a continuous MPEG-TS stream,
+// for clients that don't support HLS.
+type mpegtsReader struct {
+	writeTimeout conf.Duration
+	pathManager  serverPathManager
+	parent       logger.Writer
+
+	uuid      uuid.UUID
+	ctx       context.Context
+	ctxCancel func()
+}
+
+func (r *mpegtsReader) initialize() {
+	r.uuid = uuid.New()
+	r.ctx, r.ctxCancel = context.WithCancel(context.Background())
+}
+
+// Close implements defs.Reader.
+func (r *mpegtsReader) Close() {
+	r.ctxCancel()
+}
+
+// APIReaderDescribe implements defs.Reader.
+func (r *mpegtsReader) APIReaderDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "mpegtsHTTPReader",
+		ID:   r.uuid.String(),
+	}
+}
+
+// Log implements logger.Writer.
+func (r *mpegtsReader) Log(level logger.Level, format string, args ...interface{}) {
+	id := hex.EncodeToString(r.uuid[:4])
+	r.parent.Log(level, "[mpegts %s] "+format, append([]interface{}{id}, args...)...)
+}
+
+func (r *mpegtsReader) run(ctx *gin.Context, pathName string) {
+	defer r.ctxCancel()
+
+	path, stream, err := r.pathManager.AddReader(defs.PathAddReaderReq{
+		Author: r,
+		AccessRequest: defs.PathAccessRequest{
+			Name:     pathName,
+			Query:    ctx.Request.URL.RawQuery,
+			SkipAuth: true,
+		},
+	})
+	if err != nil {
+		ctx.Writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	defer path.RemoveReader(defs.PathRemoveReaderReq{Author: r})
+
+	rc := http.NewResponseController(ctx.Writer)
+	bw := bufio.NewWriterSize(&flushWriter{w: ctx.Writer, f: rc}, mpegtsBufferSize)
+
+	err = mpegts.FromStream(stream, r, bw, rc, time.Duration(r.writeTimeout))
+	if err != nil {
+		r.Log(logger.Warn, err.Error())
+		ctx.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	defer stream.RemoveReader(r)
+
+	r.Log(logger.Info, "opened by %v, reading from path '%s', %s",
+		httpp.RemoteAddr(ctx), path.Name(), defs.FormatsInfo(stream.ReaderFormats(r)))
+
+	ctx.Header("Content-Type", "video/mp2t")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Flush()
+
+	stream.StartReader(r)
+
+	select {
+	case <-r.ctx.Done():
+		r.Log(logger.Info, "closed: terminated")
+
+	case <-ctx.Request.Context().Done():
+		r.Log(logger.Info, "closed by client")
+
+	case err = <-stream.ReaderError(r):
+		r.Log(logger.Info, "closed: %v", err)
+	}
+}
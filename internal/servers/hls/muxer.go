@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +14,8 @@ import (
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/protocols/hls"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
@@ -32,12 +35,14 @@ func emptyTimer() *time.Timer {
 
 type responseWriterWithCounter struct {
 	http.ResponseWriter
-	bytesSent *uint64
+	bytesSent        *uint64
+	sessionBytesSent *uint64
 }
 
 func (w *responseWriterWithCounter) Write(p []byte) (int, error) {
 	n, err := w.ResponseWriter.Write(p)
 	atomic.AddUint64(w.bytesSent, uint64(n))
+	atomic.AddUint64(w.sessionBytesSent, uint64(n))
 	return n, err
 }
 
@@ -67,6 +72,9 @@ type muxer struct {
 	path            defs.Path
 	lastRequestTime *int64
 	bytesSent       *uint64
+	sessionsMutex   sync.Mutex
+	sessions        map[string]*muxerSession
+	closedSessions  []*muxerSession
 
 	// in
 	chGetInstance chan muxerGetInstanceReq
@@ -80,6 +88,7 @@ func (m *muxer) initialize() {
 	m.created = time.Now()
 	m.lastRequestTime = int64Ptr(time.Now().UnixNano())
 	m.bytesSent = new(uint64)
+	m.sessions = make(map[string]*muxerSession)
 	m.chGetInstance = make(chan muxerGetInstanceReq)
 
 	m.Log(logger.Info, "created %s", func() string {
@@ -136,6 +145,12 @@ func (m *muxer) runInner() error {
 
 	defer m.path.RemoveReader(defs.PathRemoveReaderReq{Author: m})
 
+	pathConf := path.SafeConf()
+	codecPriority := pathConf.HLSCodecPriority
+	if len(codecPriority) == 0 {
+		codecPriority = pathConf.CodecPriority
+	}
+
 	var instanceError chan error
 	var recreateTimer *time.Timer
 
@@ -147,6 +162,7 @@ func (m *muxer) runInner() error {
 		segmentMaxSize:  m.segmentMaxSize,
 		directory:       m.directory,
 		pathName:        m.pathName,
+		codecPriority:   codecPriority,
 		stream:          stream,
 		bytesSent:       m.bytesSent,
 		parent:          m,
@@ -179,6 +195,9 @@ func (m *muxer) runInner() error {
 		activityCheckTimer = emptyTimer()
 	}
 
+	sessionsCheckTicker := time.NewTicker(closeCheckPeriod)
+	defer sessionsCheckTicker.Stop()
+
 	for {
 		select {
 		case req := <-m.chGetInstance:
@@ -204,6 +223,7 @@ func (m *muxer) runInner() error {
 				segmentMaxSize:  m.segmentMaxSize,
 				directory:       m.directory,
 				pathName:        m.pathName,
+				codecPriority:   codecPriority,
 				stream:          stream,
 				bytesSent:       m.bytesSent,
 				parent:          m,
@@ -217,6 +237,11 @@ func (m *muxer) runInner() error {
 				instanceError = mi.errorChan()
 			}
 
+		case <-sessionsCheckTicker.C:
+			m.sessionsMutex.Lock()
+			m.closeExpiredSessions()
+			m.sessionsMutex.Unlock()
+
 		case <-activityCheckTimer.C:
 			t := time.Unix(0, atomic.LoadInt64(m.lastRequestTime))
 			if time.Since(t) >= time.Duration(m.closeAfter) {
@@ -244,6 +269,62 @@ func (m *muxer) getInstance() *muxerInstance {
 	}
 }
 
+func (m *muxer) getSession(ctx *gin.Context, id uuid.UUID) *muxerSession {
+	m.sessionsMutex.Lock()
+	defer m.sessionsMutex.Unlock()
+
+	m.closeExpiredSessions()
+
+	key := sessionKey(ctx, id)
+
+	s, ok := m.sessions[key]
+	if !ok {
+		s = newMuxerSession(ctx, id)
+		m.sessions[key] = s
+		m.Log(logger.Debug, "session %s opened by %s", s.id, s.remoteAddr)
+	}
+
+	atomic.StoreInt64(s.lastRequestTime, time.Now().UnixNano())
+
+	return s
+}
+
+func (m *muxer) closeExpiredSessions() {
+	for key, s := range m.sessions {
+		t := time.Unix(0, atomic.LoadInt64(s.lastRequestTime))
+		if time.Since(t) >= sessionCloseAfter {
+			m.closeSession(key, s)
+			m.Log(logger.Info, "session %s closed after %v of inactivity (%d bytes sent)",
+				s.id, sessionCloseAfter, atomic.LoadUint64(s.bytesSent))
+		}
+	}
+}
+
+func (m *muxer) closeSession(key string, s *muxerSession) {
+	delete(m.sessions, key)
+	s.closed = time.Now()
+
+	m.closedSessions = append(m.closedSessions, s)
+	if len(m.closedSessions) > closedSessionsMaxCount {
+		m.closedSessions = m.closedSessions[1:]
+	}
+}
+
+func (m *muxer) kickSession(id uuid.UUID) bool {
+	m.sessionsMutex.Lock()
+	defer m.sessionsMutex.Unlock()
+
+	for key, s := range m.sessions {
+		if s.id == id {
+			m.closeSession(key, s)
+			m.Log(logger.Info, "session %s kicked", s.id)
+			return true
+		}
+	}
+
+	return false
+}
+
 // APIReaderDescribe implements reader.
 func (m *muxer) APIReaderDescribe() defs.APIPathSourceOrReader {
 	return defs.APIPathSourceOrReader{
@@ -253,10 +334,28 @@ func (m *muxer) APIReaderDescribe() defs.APIPathSourceOrReader {
 }
 
 func (m *muxer) apiItem() *defs.APIHLSMuxer {
+	m.sessionsMutex.Lock()
+	defer m.sessionsMutex.Unlock()
+
+	m.closeExpiredSessions()
+
+	sessions := []*defs.APIHLSSession{}
+	for _, s := range m.closedSessions {
+		sessions = append(sessions, s.apiItem())
+	}
+	for _, s := range m.sessions {
+		sessions = append(sessions, s.apiItem())
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Created.Before(sessions[j].Created)
+	})
+
 	return &defs.APIHLSMuxer{
 		Path:        m.pathName,
 		Created:     m.created,
 		LastRequest: time.Unix(0, atomic.LoadInt64(m.lastRequestTime)),
 		BytesSent:   atomic.LoadUint64(m.bytesSent),
+		Sessions:    sessions,
 	}
 }
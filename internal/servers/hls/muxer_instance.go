@@ -3,6 +3,7 @@ package hls
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/bluenviron/gohlslib/v2"
@@ -22,6 +23,7 @@ type muxerInstance struct {
 	segmentMaxSize  conf.StringSize
 	directory       string
 	pathName        string
+	codecPriority   []string
 	stream          *stream.Stream
 	bytesSent       *uint64
 	parent          logger.Writer
@@ -48,7 +50,7 @@ func (mi *muxerInstance) initialize() error {
 		},
 	}
 
-	err := hls.FromStream(mi.stream, mi, mi.hmuxer)
+	err := hls.FromStream(mi.stream, mi, mi.hmuxer, mi.codecPriority)
 	if err != nil {
 		return err
 	}
@@ -84,10 +86,21 @@ func (mi *muxerInstance) errorChan() chan error {
 	return mi.stream.ReaderError(mi)
 }
 
-func (mi *muxerInstance) handleRequest(ctx *gin.Context) {
+func (mi *muxerInstance) handleRequest(ctx *gin.Context, sessionBytesSent *uint64, startOffset time.Duration) {
 	w := &responseWriterWithCounter{
-		ResponseWriter: ctx.Writer,
-		bytesSent:      mi.bytesSent,
+		ResponseWriter:   ctx.Writer,
+		bytesSent:        mi.bytesSent,
+		sessionBytesSent: sessionBytesSent,
+	}
+
+	if startOffset != 0 && strings.HasSuffix(ctx.Request.URL.Path, ".m3u8") {
+		pw := &playlistStartWriter{
+			ResponseWriter: w,
+			offset:         startOffset,
+		}
+		mi.hmuxer.Handle(pw, ctx.Request)
+		pw.flush()
+		return
 	}
 
 	mi.hmuxer.Handle(w, ctx.Request)
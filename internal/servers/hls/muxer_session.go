@@ -0,0 +1,86 @@
+package hls
+
+import (
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/protocols/httpp"
+)
+
+const (
+	// a session is closed when the viewer stops requesting playlists and segments.
+	sessionCloseAfter = 30 * time.Second
+
+	// closed sessions are kept in order to show past viewers.
+	closedSessionsMaxCount = 20
+)
+
+// muxerSession is a logical HLS viewer, built by
+// correlating the playlist and segment requests of a single client.
+type muxerSession struct {
+	id              uuid.UUID
+	created         time.Time
+	remoteAddr      string
+	userAgent       string
+	lastRequestTime *int64
+	bytesSent       *uint64
+	closed          time.Time
+}
+
+func (s *muxerSession) apiItem() *defs.APIHLSSession {
+	return &defs.APIHLSSession{
+		ID:          s.id,
+		Created:     s.created,
+		LastRequest: time.Unix(0, atomic.LoadInt64(s.lastRequestTime)),
+		RemoteAddr:  s.remoteAddr,
+		UserAgent:   s.userAgent,
+		BytesSent:   atomic.LoadUint64(s.bytesSent),
+		Closed: func() *time.Time {
+			if s.closed.IsZero() {
+				return nil
+			}
+			v := s.closed
+			return &v
+		}(),
+	}
+}
+
+// sessionKey returns the key used to correlate requests.
+// Requests are correlated by session token or JWT when available, otherwise by IP and user agent.
+func sessionKey(ctx *gin.Context, id uuid.UUID) string {
+	if id != uuid.Nil {
+		return "session:" + id.String()
+	}
+
+	if v, err := url.ParseQuery(ctx.Request.URL.RawQuery); err == nil {
+		if jwt := v.Get("jwt"); jwt != "" {
+			return "jwt:" + jwt
+		}
+	}
+
+	if user, _, ok := ctx.Request.BasicAuth(); ok {
+		return "user:" + user + "|" + ctx.ClientIP() + "|" + ctx.Request.UserAgent()
+	}
+
+	return ctx.ClientIP() + "|" + ctx.Request.UserAgent()
+}
+
+func newMuxerSession(ctx *gin.Context, id uuid.UUID) *muxerSession {
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+
+	return &muxerSession{
+		id:              id,
+		created:         time.Now(),
+		remoteAddr:      httpp.RemoteAddr(ctx),
+		userAgent:       ctx.Request.UserAgent(),
+		lastRequestTime: int64Ptr(time.Now().UnixNano()),
+		bytesSent:       new(uint64),
+	}
+}
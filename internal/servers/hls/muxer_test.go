@@ -0,0 +1,62 @@
+package hls
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/test"
+)
+
+func TestMuxerSessionExpiry(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+
+	m := &muxer{
+		pathName:        "mypath",
+		parent:          &Server{Parent: test.NilLogger},
+		lastRequestTime: int64Ptr(time.Now().UnixNano()),
+		bytesSent:       new(uint64),
+		sessions:        make(map[string]*muxerSession),
+	}
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/mypath/index.m3u8", nil)
+	ctx.Request.Header.Set("User-Agent", "myagent")
+
+	s := m.getSession(ctx, uuid.Nil)
+
+	item := m.apiItem()
+	require.Len(t, item.Sessions, 1)
+	require.Nil(t, item.Sessions[0].Closed)
+
+	atomic.StoreInt64(s.lastRequestTime, time.Now().Add(-sessionCloseAfter).UnixNano())
+
+	item = m.apiItem()
+	require.Len(t, item.Sessions, 1)
+	require.Equal(t, s.id, item.Sessions[0].ID)
+	require.Equal(t, "myagent", item.Sessions[0].UserAgent)
+	require.NotNil(t, item.Sessions[0].Closed)
+	require.Empty(t, m.sessions)
+
+	// a new request from the same viewer opens a new session
+	s2 := m.getSession(ctx, uuid.Nil)
+	require.NotEqual(t, s.id, s2.id)
+
+	item = m.apiItem()
+	require.Len(t, item.Sessions, 2)
+	require.NotNil(t, item.Sessions[0].Closed)
+	require.Nil(t, item.Sessions[1].Closed)
+
+	for i := 0; i < closedSessionsMaxCount+5; i++ {
+		s3 := newMuxerSession(ctx, uuid.Nil)
+		m.sessions[s3.id.String()] = s3
+		m.closeSession(s3.id.String(), s3)
+	}
+	require.Len(t, m.closedSessions, closedSessionsMaxCount)
+}
@@ -8,6 +8,8 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/google/uuid"
+
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
@@ -17,17 +19,23 @@ import (
 // ErrMuxerNotFound is returned when a muxer is not found.
 var ErrMuxerNotFound = errors.New("muxer not found")
 
+// ErrSessionNotFound is returned when a session is not found.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrSessionTokensDisabled is returned when kicking a session while session tokens are disabled.
+var ErrSessionTokensDisabled = errors.New("session tokens are disabled")
+
 type serverGetMuxerRes struct {
 	muxer *muxer
 	err   error
 }
 
 type serverGetMuxerReq struct {
-	path           string
-	remoteAddr     string
-	query          string
-	sourceOnDemand bool
-	res            chan serverGetMuxerRes
+	path        string
+	remoteAddr  string
+	query       string
+	alwaysRemux bool
+	res         chan serverGetMuxerRes
 }
 
 type serverAPIMuxersListRes struct {
@@ -49,6 +57,15 @@ type serverAPIMuxersGetReq struct {
 	res  chan serverAPIMuxersGetRes
 }
 
+type serverAPISessionsKickRes struct {
+	err error
+}
+
+type serverAPISessionsKickReq struct {
+	id  uuid.UUID
+	res chan serverAPISessionsKickRes
+}
+
 type serverPathManager interface {
 	FindPathConf(req defs.PathFindPathConfReq) (*conf.Path, error)
 	AddReader(req defs.PathAddReaderReq) (defs.Path, *stream.Stream, error)
@@ -74,7 +91,9 @@ type Server struct {
 	SegmentMaxSize  conf.StringSize
 	Directory       string
 	ReadTimeout     conf.Duration
+	WriteTimeout    conf.Duration
 	MuxerCloseAfter conf.Duration
+	SessionTokens   bool
 	PathManager     serverPathManager
 	Parent          serverParent
 
@@ -85,12 +104,13 @@ type Server struct {
 	muxers     map[string]*muxer
 
 	// in
-	chPathReady    chan defs.Path
-	chPathNotReady chan defs.Path
-	chGetMuxer     chan serverGetMuxerReq
-	chCloseMuxer   chan *muxer
-	chAPIMuxerList chan serverAPIMuxersListReq
-	chAPIMuxerGet  chan serverAPIMuxersGetReq
+	chPathReady       chan defs.Path
+	chPathNotReady    chan defs.Path
+	chGetMuxer        chan serverGetMuxerReq
+	chCloseMuxer      chan *muxer
+	chAPIMuxerList    chan serverAPIMuxersListReq
+	chAPIMuxerGet     chan serverAPIMuxersGetReq
+	chAPISessionsKick chan serverAPISessionsKickReq
 }
 
 // Initialize initializes the server.
@@ -106,6 +126,17 @@ func (s *Server) Initialize() error {
 	s.chCloseMuxer = make(chan *muxer)
 	s.chAPIMuxerList = make(chan serverAPIMuxersListReq)
 	s.chAPIMuxerGet = make(chan serverAPIMuxersGetReq)
+	s.chAPISessionsKick = make(chan serverAPISessionsKickReq)
+
+	var tokens *sessionTokens
+	if s.SessionTokens {
+		tokens = &sessionTokens{}
+		err := tokens.initialize()
+		if err != nil {
+			ctxCancel()
+			return err
+		}
+	}
 
 	s.httpServer = &httpServer{
 		address:        s.Address,
@@ -115,6 +146,8 @@ func (s *Server) Initialize() error {
 		allowOrigin:    s.AllowOrigin,
 		trustedProxies: s.TrustedProxies,
 		readTimeout:    s.ReadTimeout,
+		writeTimeout:   s.WriteTimeout,
+		sessionTokens:  tokens,
 		pathManager:    s.PathManager,
 		parent:         s,
 	}
@@ -151,7 +184,7 @@ outer:
 	for {
 		select {
 		case pa := <-s.chPathReady:
-			if s.AlwaysRemux && !pa.SafeConf().SourceOnDemand {
+			if s.alwaysRemux(pa.SafeConf()) {
 				if _, ok := s.muxers[pa.Name()]; !ok {
 					s.createMuxer(pa.Name(), "", "")
 				}
@@ -169,7 +202,7 @@ outer:
 			switch {
 			case ok:
 				req.res <- serverGetMuxerRes{muxer: mux}
-			case s.AlwaysRemux && !req.sourceOnDemand:
+			case req.alwaysRemux:
 				req.res <- serverGetMuxerRes{err: fmt.Errorf("muxer is waiting to be created")}
 			default:
 				req.res <- serverGetMuxerRes{muxer: s.createMuxer(req.path, req.remoteAddr, req.query)}
@@ -206,6 +239,31 @@ outer:
 
 			req.res <- serverAPIMuxersGetRes{data: muxer.apiItem()}
 
+		case req := <-s.chAPISessionsKick:
+			// without session tokens, a kicked viewer would immediately reopen the session
+			if s.httpServer.sessionTokens == nil {
+				req.res <- serverAPISessionsKickRes{err: ErrSessionTokensDisabled}
+				continue
+			}
+
+			found := false
+			for _, muxer := range s.muxers {
+				if muxer.kickSession(req.id) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				req.res <- serverAPISessionsKickRes{err: ErrSessionNotFound}
+				continue
+			}
+
+			// prevent the viewer from resuming the session with the same token
+			s.httpServer.sessionTokens.revoke(req.id)
+
+			req.res <- serverAPISessionsKickRes{}
+
 		case <-s.ctx.Done():
 			break outer
 		}
@@ -216,6 +274,12 @@ outer:
 	s.httpServer.close()
 }
 
+// alwaysRemux returns whether the muxer of a path must be created
+// as soon as the path is ready, instead of on the first request.
+func (s *Server) alwaysRemux(pathConf *conf.Path) bool {
+	return (s.AlwaysRemux || pathConf.HLSAlwaysRemux) && !pathConf.SourceOnDemand
+}
+
 func (s *Server) createMuxer(pathName string, remoteAddr string, query string) *muxer {
 	r := &muxer{
 		parentCtx:       s.ctx,
@@ -307,3 +371,20 @@ func (s *Server) APIMuxersGet(name string) (*defs.APIHLSMuxer, error) {
 		return nil, fmt.Errorf("terminated")
 	}
 }
+
+// APISessionsKick is called by api.
+func (s *Server) APISessionsKick(id uuid.UUID) error {
+	req := serverAPISessionsKickReq{
+		id:  id,
+		res: make(chan serverAPISessionsKickRes),
+	}
+
+	select {
+	case s.chAPISessionsKick <- req:
+		res := <-req.res
+		return res.err
+
+	case <-s.ctx.Done():
+		return fmt.Errorf("terminated")
+	}
+}
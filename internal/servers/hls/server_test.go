@@ -18,6 +18,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/stream"
 	"github.com/bluenviron/mediamtx/internal/test"
 	"github.com/bluenviron/mediamtx/internal/unit"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -150,12 +151,28 @@ func TestServerNotFound(t *testing.T) {
 	}
 }
 
+func TestServerKickSessionTokensDisabled(t *testing.T) {
+	s := &Server{
+		Address:     "127.0.0.1:8888",
+		ReadTimeout: conf.Duration(10 * time.Second),
+		Parent:      test.NilLogger,
+	}
+	err := s.Initialize()
+	require.NoError(t, err)
+	defer s.Close()
+
+	err = s.APISessionsKick(uuid.New())
+	require.ErrorIs(t, err, ErrSessionTokensDisabled)
+}
+
 func TestServerRead(t *testing.T) {
 	t.Run("always remux off", func(t *testing.T) {
 		desc := &description.Session{Medias: []*description.Media{test.MediaH264}}
 
 		str, err := stream.New(
 			512,
+			0,
+			0,
 			1460,
 			desc,
 			true,
@@ -255,6 +272,8 @@ func TestServerRead(t *testing.T) {
 
 		str, err := stream.New(
 			512,
+			0,
+			0,
 			1460,
 			desc,
 			true,
@@ -347,9 +366,91 @@ func TestServerRead(t *testing.T) {
 		defer c.Close()
 
 		<-recv
+
+		list, err := s.APIMuxersList()
+		require.NoError(t, err)
+		require.Len(t, list.Items, 1)
+		require.Len(t, list.Items[0].Sessions, 1)
 	})
 }
 
+func TestServerReadMPEGTS(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{test.MediaH264}}
+
+	str, err := stream.New(
+		512,
+		0,
+		0,
+		1460,
+		desc,
+		true,
+		test.NilLogger,
+	)
+	require.NoError(t, err)
+
+	pm := &test.PathManager{
+		FindPathConfImpl: func(req defs.PathFindPathConfReq) (*conf.Path, error) {
+			require.Equal(t, "teststream", req.AccessRequest.Name)
+			return &conf.Path{}, nil
+		},
+		AddReaderImpl: func(req defs.PathAddReaderReq) (defs.Path, *stream.Stream, error) {
+			require.Equal(t, "teststream", req.AccessRequest.Name)
+			return &dummyPath{}, str, nil
+		},
+	}
+
+	s := &Server{
+		Address:         "127.0.0.1:8888",
+		Encryption:      false,
+		ServerKey:       "",
+		ServerCert:      "",
+		AlwaysRemux:     false,
+		Variant:         conf.HLSVariant(gohlslib.MuxerVariantMPEGTS),
+		SegmentCount:    7,
+		SegmentDuration: conf.Duration(1 * time.Second),
+		PartDuration:    conf.Duration(200 * time.Millisecond),
+		SegmentMaxSize:  50 * 1024 * 1024,
+		AllowOrigin:     "",
+		TrustedProxies:  conf.IPNetworks{},
+		Directory:       "",
+		ReadTimeout:     conf.Duration(10 * time.Second),
+		WriteTimeout:    conf.Duration(10 * time.Second),
+		PathManager:     pm,
+		Parent:          test.NilLogger,
+	}
+	err = s.Initialize()
+	require.NoError(t, err)
+	defer s.Close()
+
+	hc := &http.Client{Transport: &http.Transport{}}
+
+	res, err := hc.Get("http://127.0.0.1:8888/teststream/stream.ts")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "video/mp2t", res.Header.Get("Content-Type"))
+
+	str.WaitRunningReader()
+
+	for i := 0; i < 16; i++ {
+		str.WriteUnit(test.MediaH264, test.FormatH264, &unit.H264{
+			Base: unit.Base{
+				NTP: time.Time{},
+				PTS: int64(i) * 90000,
+			},
+			AU: [][]byte{
+				{5, 1}, // IDR
+			},
+		})
+	}
+
+	buf := make([]byte, 188)
+	_, err = io.ReadFull(res.Body, buf)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x47), buf[0])
+}
+
 func TestDirectory(t *testing.T) {
 	dir, err := os.MkdirTemp("", "mediamtx-playback")
 	require.NoError(t, err)
@@ -359,6 +460,8 @@ func TestDirectory(t *testing.T) {
 
 	str, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		desc,
 		true,
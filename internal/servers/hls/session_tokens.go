@@ -0,0 +1,121 @@
+package hls
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var timeNow = time.Now
+
+// name of the query parameter that contains the session token.
+const sessionTokenParam = "session"
+
+// a token can't be used after this amount of time has passed since its generation.
+const sessionTokenLifetime = 12 * time.Hour
+
+var errSessionTokenExpired = errors.New("session token is expired")
+
+// sessionTokens issues and verifies per-viewer session tokens.
+// A token is made of a session ID, of an expiration time and of their signature,
+// therefore it can't be forged by clients.
+type sessionTokens struct {
+	key     []byte
+	mutex   sync.Mutex
+	revoked map[uuid.UUID]time.Time
+}
+
+func (t *sessionTokens) initialize() error {
+	t.key = make([]byte, 32)
+	_, err := rand.Read(t.key)
+	if err != nil {
+		return err
+	}
+
+	t.revoked = make(map[uuid.UUID]time.Time)
+
+	return nil
+}
+
+func (t *sessionTokens) sign(payload string) string {
+	h := hmac.New(sha256.New, t.key)
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func (t *sessionTokens) generate(id uuid.UUID) string {
+	payload := id.String() + "." + strconv.FormatInt(timeNow().Add(sessionTokenLifetime).Unix(), 10)
+	return payload + "." + t.sign(payload)
+}
+
+func (t *sessionTokens) verify(token string) (uuid.UUID, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return uuid.Nil, fmt.Errorf("invalid session token")
+	}
+	payload, signature := token[:i], token[i+1:]
+
+	if !hmac.Equal([]byte(signature), []byte(t.sign(payload))) {
+		return uuid.Nil, fmt.Errorf("invalid session token")
+	}
+
+	rawID, rawExpiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return uuid.Nil, fmt.Errorf("invalid session token")
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid session token")
+	}
+
+	expiry, err := strconv.ParseInt(rawExpiry, 10, 64)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid session token")
+	}
+
+	if timeNow().Unix() >= expiry {
+		return uuid.Nil, errSessionTokenExpired
+	}
+
+	return id, nil
+}
+
+// revoke prevents tokens of a session from being used.
+// Since every token of the session expires within sessionTokenLifetime,
+// the session doesn't need to be remembered after that.
+func (t *sessionTokens) revoke(id uuid.UUID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.removeExpired()
+	t.revoked[id] = timeNow().Add(sessionTokenLifetime)
+}
+
+func (t *sessionTokens) isRevoked(id uuid.UUID) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.removeExpired()
+	_, ok := t.revoked[id]
+	return ok
+}
+
+func (t *sessionTokens) removeExpired() {
+	now := timeNow()
+
+	for id, expiry := range t.revoked {
+		if !now.Before(expiry) {
+			delete(t.revoked, id)
+		}
+	}
+}
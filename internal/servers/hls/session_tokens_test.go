@@ -0,0 +1,59 @@
+package hls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionTokens(t *testing.T) {
+	st := &sessionTokens{}
+	err := st.initialize()
+	require.NoError(t, err)
+
+	id := uuid.New()
+	tok := st.generate(id)
+
+	id2, err := st.verify(tok)
+	require.NoError(t, err)
+	require.Equal(t, id, id2)
+
+	_, err = st.verify(id.String() + ".1.0123456789abcdef0123456789abcdef")
+	require.Error(t, err)
+
+	_, err = st.verify("invalid")
+	require.Error(t, err)
+
+	require.False(t, st.isRevoked(id))
+	st.revoke(id)
+	require.True(t, st.isRevoked(id))
+}
+
+func TestSessionTokensExpiry(t *testing.T) {
+	now := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	st := &sessionTokens{}
+	err := st.initialize()
+	require.NoError(t, err)
+
+	id := uuid.New()
+	tok := st.generate(id)
+	st.revoke(id)
+
+	now = now.Add(sessionTokenLifetime - time.Second)
+
+	_, err = st.verify(tok)
+	require.NoError(t, err)
+	require.True(t, st.isRevoked(id))
+
+	now = now.Add(time.Second)
+
+	_, err = st.verify(tok)
+	require.ErrorIs(t, err, errSessionTokenExpired)
+	require.False(t, st.isRevoked(id))
+	require.Empty(t, st.revoked)
+}
@@ -0,0 +1,85 @@
+package hls
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// name of the query parameter that allows readers to start behind the live edge.
+const startOffsetParam = "startOffset"
+
+func parseStartOffset(v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", startOffsetParam, err)
+	}
+
+	if d < 0 {
+		return 0, fmt.Errorf("invalid %s: must be positive", startOffsetParam)
+	}
+
+	return d, nil
+}
+
+// addPlaylistStart inserts a EXT-X-START tag into a playlist,
+// that tells players to start playback before the live edge.
+func addPlaylistStart(playlist []byte, offset time.Duration) []byte {
+	if !bytes.HasPrefix(playlist, []byte("#EXTM3U")) {
+		return playlist
+	}
+
+	i := bytes.IndexByte(playlist, '\n')
+	if i < 0 {
+		return playlist
+	}
+
+	tag := "#EXT-X-START:TIME-OFFSET=-" + strconv.FormatFloat(offset.Seconds(), 'f', -1, 64) + "\n"
+
+	ret := make([]byte, 0, len(playlist)+len(tag))
+	ret = append(ret, playlist[:i+1]...)
+	ret = append(ret, tag...)
+	ret = append(ret, playlist[i+1:]...)
+	return ret
+}
+
+// playlistStartWriter buffers a playlist in order to insert a EXT-X-START tag into it.
+type playlistStartWriter struct {
+	http.ResponseWriter
+	offset time.Duration
+
+	statusCode int
+	buf        bytes.Buffer
+}
+
+func (w *playlistStartWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+}
+
+func (w *playlistStartWriter) Write(p []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.buf.Write(p)
+}
+
+func (w *playlistStartWriter) flush() {
+	if w.statusCode == 0 {
+		return
+	}
+
+	body := w.buf.Bytes()
+	if w.statusCode == http.StatusOK {
+		body = addPlaylistStart(body, w.offset)
+	}
+
+	w.ResponseWriter.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	w.ResponseWriter.Write(body) //nolint:errcheck
+}
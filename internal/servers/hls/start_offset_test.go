@@ -0,0 +1,34 @@
+package hls
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStartOffset(t *testing.T) {
+	d, err := parseStartOffset("")
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), d)
+
+	d, err = parseStartOffset("10s")
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Second, d)
+
+	_, err = parseStartOffset("-10s")
+	require.Error(t, err)
+
+	_, err = parseStartOffset("abc")
+	require.Error(t, err)
+}
+
+func TestAddPlaylistStart(t *testing.T) {
+	require.Equal(t, "#EXTM3U\n"+
+		"#EXT-X-START:TIME-OFFSET=-12.5\n"+
+		"#EXT-X-VERSION:9\n",
+		string(addPlaylistStart([]byte("#EXTM3U\n#EXT-X-VERSION:9\n"), 12500*time.Millisecond)))
+
+	require.Equal(t, "not a playlist",
+		string(addPlaylistStart([]byte("not a playlist"), 10*time.Second)))
+}
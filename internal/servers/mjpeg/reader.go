@@ -0,0 +1,136 @@
+package mjpeg
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+// reader is a HTTP client that is reading a stream.
+type reader struct {
+	parentCtx    context.Context
+	writeTimeout conf.Duration
+	remoteAddr   string
+	parent       logger.Writer
+
+	ctx       context.Context
+	ctxCancel func()
+	uuid      uuid.UUID
+}
+
+func (r *reader) initialize() {
+	r.ctx, r.ctxCancel = context.WithCancel(r.parentCtx)
+	r.uuid = uuid.New()
+}
+
+// Close implements defs.Reader.
+func (r *reader) Close() {
+	r.ctxCancel()
+}
+
+// Log implements logger.Writer.
+func (r *reader) Log(level logger.Level, format string, args ...interface{}) {
+	id := hex.EncodeToString(r.uuid[:4])
+	r.parent.Log(level, "[reader %s] "+format, append([]interface{}{id}, args...)...)
+}
+
+func (r *reader) run(ctx *gin.Context, path defs.Path, strm *stream.Stream) {
+	defer r.ctxCancel()
+
+	var forma *format.MJPEG
+	medi := strm.Desc().FindFormat(&forma)
+	if medi == nil {
+		r.Log(logger.Warn, "%v", ErrNoMJPEGTrack)
+		ctx.Writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	r.Log(logger.Info, "opened by %s, is reading from path '%s', %s",
+		r.remoteAddr, path.Name(), defs.FormatsInfo([]format.Format{forma}))
+
+	err := r.runInner(ctx, medi, forma, strm)
+
+	r.Log(logger.Info, "closed: %v", err)
+}
+
+func (r *reader) runInner(
+	ctx *gin.Context,
+	medi *description.Media,
+	forma *format.MJPEG,
+	strm *stream.Stream,
+) error {
+	rc := http.NewResponseController(ctx.Writer)
+	mw := multipart.NewWriter(ctx.Writer)
+
+	ctx.Header("Content-Type", "multipart/x-mixed-replace; boundary="+mw.Boundary())
+	ctx.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	ctx.Writer.WriteHeader(http.StatusOK)
+
+	rc.SetWriteDeadline(time.Now().Add(time.Duration(r.writeTimeout))) //nolint:errcheck
+	err := rc.Flush()
+	if err != nil {
+		return err
+	}
+
+	strm.AddReader(r, medi, forma, func(u unit.Unit) error {
+		tunit := u.(*unit.MJPEG)
+		if tunit.Frame == nil {
+			return nil
+		}
+
+		rc.SetWriteDeadline(time.Now().Add(time.Duration(r.writeTimeout))) //nolint:errcheck
+
+		pw, err2 := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":   []string{"image/jpeg"},
+			"Content-Length": []string{strconv.FormatInt(int64(len(tunit.Frame)), 10)},
+		})
+		if err2 != nil {
+			return err2
+		}
+
+		_, err2 = pw.Write(tunit.Frame)
+		if err2 != nil {
+			return err2
+		}
+
+		return rc.Flush()
+	})
+
+	strm.StartReader(r)
+	defer strm.RemoveReader(r)
+
+	select {
+	case err = <-strm.ReaderError(r):
+		return err
+
+	case <-ctx.Request.Context().Done():
+		return errors.New("client disconnected")
+
+	case <-r.ctx.Done():
+		return errors.New("terminated")
+	}
+}
+
+// APIReaderDescribe implements defs.Reader.
+func (r *reader) APIReaderDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "mjpegReader",
+		ID:   r.uuid.String(),
+	}
+}
@@ -0,0 +1,82 @@
+// Package mjpeg contains a M-JPEG over HTTP server.
+package mjpeg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/stream"
+)
+
+// ErrNoMJPEGTrack is returned when the stream doesn't contain a M-JPEG track.
+var ErrNoMJPEGTrack = errors.New("the stream doesn't contain a M-JPEG track")
+
+type serverPathManager interface {
+	AddReader(req defs.PathAddReaderReq) (defs.Path, *stream.Stream, error)
+}
+
+type serverParent interface {
+	logger.Writer
+}
+
+// Server is a M-JPEG over HTTP server.
+// Streams are served with the multipart/x-mixed-replace content type,
+// that can be displayed by browsers without any client-side code.
+type Server struct {
+	Address        string
+	Encryption     bool
+	ServerKey      string
+	ServerCert     string
+	AllowOrigin    string
+	TrustedProxies conf.IPNetworks
+	ReadTimeout    conf.Duration
+	WriteTimeout   conf.Duration
+	PathManager    serverPathManager
+	Parent         serverParent
+
+	ctx        context.Context
+	ctxCancel  func()
+	httpServer *httpServer
+}
+
+// Initialize initializes the server.
+func (s *Server) Initialize() error {
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+
+	s.httpServer = &httpServer{
+		address:        s.Address,
+		encryption:     s.Encryption,
+		serverKey:      s.ServerKey,
+		serverCert:     s.ServerCert,
+		allowOrigin:    s.AllowOrigin,
+		trustedProxies: s.TrustedProxies,
+		readTimeout:    s.ReadTimeout,
+		writeTimeout:   s.WriteTimeout,
+		pathManager:    s.PathManager,
+		parent:         s,
+	}
+	err := s.httpServer.initialize()
+	if err != nil {
+		s.ctxCancel()
+		return err
+	}
+
+	s.Log(logger.Info, "listener opened on "+s.Address)
+
+	return nil
+}
+
+// Log implements logger.Writer.
+func (s *Server) Log(level logger.Level, format string, args ...interface{}) {
+	s.Parent.Log(level, "[MJPEG] "+format, args...)
+}
+
+// Close closes the server.
+func (s *Server) Close() {
+	s.Log(logger.Info, "listener is closing")
+	s.ctxCancel()
+	s.httpServer.close()
+}
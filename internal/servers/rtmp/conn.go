@@ -2,6 +2,7 @@ package rtmp
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
@@ -86,7 +87,8 @@ func (c *conn) remoteAddr() net.Addr {
 
 // Log implements logger.Writer.
 func (c *conn) Log(level logger.Level, format string, args ...interface{}) {
-	c.parent.Log(level, "[conn %v] "+format, append([]interface{}{c.nconn.RemoteAddr()}, args...)...)
+	id := hex.EncodeToString(c.uuid[:4])
+	c.parent.Log(level, "[conn %v %s] "+format, append([]interface{}{c.nconn.RemoteAddr(), id}, args...)...)
 }
 
 func (c *conn) ip() net.IP {
@@ -206,7 +208,7 @@ func (c *conn) runRead(conn *rtmp.Conn, u *url.URL) error {
 	// disable read deadline
 	c.nconn.SetReadDeadline(time.Time{})
 
-	stream.StartReader(c)
+	stream.StartReaderFromGOPCache(c)
 	defer stream.RemoveReader(c)
 
 	select {
@@ -41,6 +41,8 @@ func (p *dummyPath) StartPublisher(req defs.PathStartPublisherReq) (*stream.Stre
 	var err error
 	p.stream, err = stream.New(
 		512,
+		0,
+		0,
 		1460,
 		req.Desc,
 		true,
@@ -196,6 +198,8 @@ func TestServerRead(t *testing.T) {
 
 			str, err := stream.New(
 				512,
+				0,
+				0,
 				1460,
 				desc,
 				true,
@@ -0,0 +1,267 @@
+// Package rtppush contains a server that allows to push raw RTP streams.
+package rtppush
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+// ErrSessionNotFound is returned when a session is not found.
+var ErrSessionNotFound = errors.New("session not found")
+
+type serverAPISessionsListRes struct {
+	data *defs.APIRTPPushSessionList
+	err  error
+}
+
+type serverAPISessionsListReq struct {
+	res chan serverAPISessionsListRes
+}
+
+type serverAPISessionsGetRes struct {
+	data *defs.APIRTPPushSession
+	err  error
+}
+
+type serverAPISessionsGetReq struct {
+	uuid uuid.UUID
+	res  chan serverAPISessionsGetRes
+}
+
+type serverAPISessionsKickRes struct {
+	err error
+}
+
+type serverAPISessionsKickReq struct {
+	uuid uuid.UUID
+	res  chan serverAPISessionsKickRes
+}
+
+type serverPathManager interface {
+	AddPublisher(req defs.PathAddPublisherReq) (defs.Path, error)
+}
+
+type serverParent interface {
+	logger.Writer
+}
+
+// Server is a server that allows to push raw RTP streams.
+// UDP ports are allocated on demand, through the API.
+type Server struct {
+	Address     string
+	ReadTimeout conf.Duration
+	PathManager serverPathManager
+	Parent      serverParent
+
+	ctx       context.Context
+	ctxCancel func()
+	wg        sync.WaitGroup
+	sessions  map[*session]struct{}
+
+	// in
+	chNewSession      chan *session
+	chCloseSession    chan *session
+	chAPISessionsList chan serverAPISessionsListReq
+	chAPISessionsGet  chan serverAPISessionsGetReq
+	chAPISessionsKick chan serverAPISessionsKickReq
+}
+
+// Initialize initializes the server.
+func (s *Server) Initialize() error {
+	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
+
+	s.sessions = make(map[*session]struct{})
+	s.chNewSession = make(chan *session)
+	s.chCloseSession = make(chan *session)
+	s.chAPISessionsList = make(chan serverAPISessionsListReq)
+	s.chAPISessionsGet = make(chan serverAPISessionsGetReq)
+	s.chAPISessionsKick = make(chan serverAPISessionsKickReq)
+
+	s.Log(logger.Info, "server started")
+
+	s.wg.Add(1)
+	go s.run()
+
+	return nil
+}
+
+// Log implements logger.Writer.
+func (s *Server) Log(level logger.Level, format string, args ...interface{}) {
+	s.Parent.Log(level, "[RTP push] "+format, args...)
+}
+
+// Close closes the server.
+func (s *Server) Close() {
+	s.Log(logger.Info, "server is shutting down")
+	s.ctxCancel()
+	s.wg.Wait()
+}
+
+func (s *Server) run() {
+	defer s.wg.Done()
+
+outer:
+	for {
+		select {
+		case sx := <-s.chNewSession:
+			s.sessions[sx] = struct{}{}
+
+		case sx := <-s.chCloseSession:
+			delete(s.sessions, sx)
+
+		case req := <-s.chAPISessionsList:
+			data := &defs.APIRTPPushSessionList{
+				Items: []*defs.APIRTPPushSession{},
+			}
+
+			for sx := range s.sessions {
+				data.Items = append(data.Items, sx.apiItem())
+			}
+
+			sort.Slice(data.Items, func(i, j int) bool {
+				return data.Items[i].Created.Before(data.Items[j].Created)
+			})
+
+			req.res <- serverAPISessionsListRes{data: data}
+
+		case req := <-s.chAPISessionsGet:
+			sx := s.findSessionByUUID(req.uuid)
+			if sx == nil {
+				req.res <- serverAPISessionsGetRes{err: ErrSessionNotFound}
+				continue
+			}
+
+			req.res <- serverAPISessionsGetRes{data: sx.apiItem()}
+
+		case req := <-s.chAPISessionsKick:
+			sx := s.findSessionByUUID(req.uuid)
+			if sx == nil {
+				req.res <- serverAPISessionsKickRes{err: ErrSessionNotFound}
+				continue
+			}
+
+			delete(s.sessions, sx)
+			sx.Close()
+			req.res <- serverAPISessionsKickRes{}
+
+		case <-s.ctx.Done():
+			break outer
+		}
+	}
+
+	s.ctxCancel()
+}
+
+func (s *Server) findSessionByUUID(uuid uuid.UUID) *session {
+	for sx := range s.sessions {
+		if sx.uuid == uuid {
+			return sx
+		}
+	}
+	return nil
+}
+
+// closeSession is called by session.
+func (s *Server) closeSession(sx *session) {
+	select {
+	case s.chCloseSession <- sx:
+	case <-s.ctx.Done():
+	}
+}
+
+// APISessionsCreate is called by api.
+// It allocates UDP ports for each media described by the SDP,
+// starts publishing to the path and returns a SDP that contains allocated ports.
+// localIP is inserted into the SDP when Address is empty.
+func (s *Server) APISessionsCreate(
+	pathName string,
+	offer []byte,
+	localIP string,
+) (*defs.APIRTPPushSession, []byte, error) {
+	address := s.Address
+	if address == "" {
+		address = localIP
+	}
+
+	sx := &session{
+		parentCtx:   s.ctx,
+		readTimeout: s.ReadTimeout,
+		pathName:    pathName,
+		address:     address,
+		wg:          &s.wg,
+		pathManager: s.PathManager,
+		parent:      s,
+	}
+	answer, err := sx.initialize(offer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	select {
+	case s.chNewSession <- sx:
+		return sx.apiItem(), answer, nil
+
+	case <-s.ctx.Done():
+		sx.Close()
+		return nil, nil, fmt.Errorf("terminated")
+	}
+}
+
+// APISessionsList is called by api.
+func (s *Server) APISessionsList() (*defs.APIRTPPushSessionList, error) {
+	req := serverAPISessionsListReq{
+		res: make(chan serverAPISessionsListRes),
+	}
+
+	select {
+	case s.chAPISessionsList <- req:
+		res := <-req.res
+		return res.data, res.err
+
+	case <-s.ctx.Done():
+		return nil, fmt.Errorf("terminated")
+	}
+}
+
+// APISessionsGet is called by api.
+func (s *Server) APISessionsGet(uuid uuid.UUID) (*defs.APIRTPPushSession, error) {
+	req := serverAPISessionsGetReq{
+		uuid: uuid,
+		res:  make(chan serverAPISessionsGetRes),
+	}
+
+	select {
+	case s.chAPISessionsGet <- req:
+		res := <-req.res
+		return res.data, res.err
+
+	case <-s.ctx.Done():
+		return nil, fmt.Errorf("terminated")
+	}
+}
+
+// APISessionsKick is called by api.
+func (s *Server) APISessionsKick(uuid uuid.UUID) error {
+	req := serverAPISessionsKickReq{
+		uuid: uuid,
+		res:  make(chan serverAPISessionsKickRes),
+	}
+
+	select {
+	case s.chAPISessionsKick <- req:
+		res := <-req.res
+		return res.err
+
+	case <-s.ctx.Done():
+		return fmt.Errorf("terminated")
+	}
+}
@@ -0,0 +1,152 @@
+package rtppush
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/externalcmd"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/bluenviron/mediamtx/internal/unit"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+type dummyPath struct {
+	stream *stream.Stream
+}
+
+func (p *dummyPath) Name() string {
+	return "teststream"
+}
+
+func (p *dummyPath) SafeConf() *conf.Path {
+	return &conf.Path{}
+}
+
+func (p *dummyPath) ExternalCmdEnv() externalcmd.Environment {
+	return externalcmd.Environment{}
+}
+
+func (p *dummyPath) StartPublisher(req defs.PathStartPublisherReq) (*stream.Stream, error) {
+	var err error
+	p.stream, err = stream.New(
+		512,
+		0,
+		0,
+		1460,
+		req.Desc,
+		true,
+		test.NilLogger,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p.stream, nil
+}
+
+func (p *dummyPath) StopPublisher(_ defs.PathStopPublisherReq) {
+}
+
+func (p *dummyPath) RemovePublisher(_ defs.PathRemovePublisherReq) {
+}
+
+func (p *dummyPath) RemoveReader(_ defs.PathRemoveReaderReq) {
+}
+
+func TestServerPublish(t *testing.T) {
+	path := &dummyPath{}
+
+	pathManager := &test.PathManager{
+		AddPublisherImpl: func(req defs.PathAddPublisherReq) (defs.Path, error) {
+			require.Equal(t, "teststream", req.AccessRequest.Name)
+			require.True(t, req.AccessRequest.Publish)
+			return path, nil
+		},
+	}
+
+	s := &Server{
+		Address:     "127.0.0.1",
+		ReadTimeout: conf.Duration(10 * time.Second),
+		PathManager: pathManager,
+		Parent:      test.NilLogger,
+	}
+	err := s.Initialize()
+	require.NoError(t, err)
+	defer s.Close()
+
+	item, answer, err := s.APISessionsCreate("teststream", []byte("v=0\r\n"+
+		"o=- 0 0 IN IP4 192.168.0.1\r\n"+
+		"s=Stream\r\n"+
+		"c=IN IP4 192.168.0.1\r\n"+
+		"t=0 0\r\n"+
+		"m=video 0 RTP/AVP 96\r\n"+
+		"a=rtpmap:96 H264/90000\r\n"+
+		"a=fmtp:96 packetization-mode=1\r\n"), "")
+	require.NoError(t, err)
+	require.Equal(t, "teststream", item.Path)
+	require.Len(t, item.Ports, 1)
+	require.Zero(t, item.Ports[0]%2)
+
+	var sd sdp.SessionDescription
+	err = sd.Unmarshal(answer)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", sd.ConnectionInformation.Address.Address)
+	require.Equal(t, item.Ports[0], sd.MediaDescriptions[0].MediaName.Port.Value)
+
+	list, err := s.APISessionsList()
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+
+	reader := test.NilLogger
+
+	recv := make(chan struct{})
+
+	path.stream.AddReader(
+		reader,
+		path.stream.Desc().Medias[0],
+		path.stream.Desc().Medias[0].Formats[0],
+		func(u unit.Unit) error {
+			require.Equal(t, [][]byte{{5, 1}}, u.(*unit.H264).AU)
+			close(recv)
+			return nil
+		})
+
+	path.stream.StartReader(reader)
+	defer path.stream.RemoveReader(reader)
+
+	conn, err := net.Dial("udp", "127.0.0.1:"+strconv.FormatInt(int64(item.Ports[0]), 10))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	pkt := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    96,
+			SequenceNumber: 123,
+			Timestamp:      45343,
+			SSRC:           563423,
+		},
+		Payload: []byte{5, 1}, // IDR
+	}
+
+	byts, err := pkt.Marshal()
+	require.NoError(t, err)
+
+	_, err = conn.Write(byts)
+	require.NoError(t, err)
+
+	<-recv
+
+	err = s.APISessionsKick(item.ID)
+	require.NoError(t, err)
+
+	_, err = s.APISessionsGet(item.ID)
+	require.Equal(t, ErrSessionNotFound, err)
+}
@@ -0,0 +1,275 @@
+package rtppush
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/rtptime"
+	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
+	"github.com/google/uuid"
+	psdp "github.com/pion/sdp/v3"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/rtp"
+	"github.com/bluenviron/mediamtx/internal/stream"
+)
+
+const (
+	maxPortAllocationAttempts = 20
+)
+
+// allocatePorts allocates a pair of consecutive UDP ports,
+// the first of which is even, as required by RTP.
+func allocatePorts() (*net.UDPConn, *net.UDPConn, error) {
+	for i := 0; i < maxPortAllocationAttempts; i++ {
+		tmp, err := net.ListenPacket("udp", ":0")
+		if err != nil {
+			return nil, nil, err
+		}
+		rtpConn := tmp.(*net.UDPConn)
+
+		port := rtpConn.LocalAddr().(*net.UDPAddr).Port
+		if (port % 2) != 0 {
+			rtpConn.Close()
+			continue
+		}
+
+		tmp, err = net.ListenPacket("udp", ":"+strconv.FormatInt(int64(port+1), 10))
+		if err != nil {
+			rtpConn.Close()
+			continue
+		}
+		rtcpConn := tmp.(*net.UDPConn)
+
+		return rtpConn, rtcpConn, nil
+	}
+
+	return nil, nil, fmt.Errorf("unable to allocate a pair of UDP ports")
+}
+
+func connectionInformation(address string) *psdp.ConnectionInformation {
+	addressType := "IP4"
+	if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+		addressType = "IP6"
+	}
+
+	return &psdp.ConnectionInformation{
+		NetworkType: "IN",
+		AddressType: addressType,
+		Address:     &psdp.Address{Address: address},
+	}
+}
+
+type sessionMedia struct {
+	media    *description.Media
+	rtpConn  *net.UDPConn
+	rtcpConn *net.UDPConn
+}
+
+func (sm *sessionMedia) port() int {
+	return sm.rtpConn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func (sm *sessionMedia) close() {
+	sm.rtpConn.Close()
+	sm.rtcpConn.Close()
+}
+
+type session struct {
+	parentCtx   context.Context
+	readTimeout conf.Duration
+	pathName    string
+	address     string
+	wg          *sync.WaitGroup
+	pathManager serverPathManager
+	parent      *Server
+
+	ctx       context.Context
+	ctxCancel func()
+	created   time.Time
+	uuid      uuid.UUID
+	medias    []*sessionMedia
+	path      defs.Path
+	stream    *stream.Stream
+}
+
+func (s *session) initialize(offer []byte) ([]byte, error) {
+	var sd sdp.SessionDescription
+	err := sd.Unmarshal(offer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SDP: %w", err)
+	}
+
+	var desc description.Session
+	err = desc.Unmarshal(&sd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SDP: %w", err)
+	}
+
+	s.ctx, s.ctxCancel = context.WithCancel(s.parentCtx)
+	s.created = time.Now()
+	s.uuid = uuid.New()
+
+	for _, medi := range desc.Medias {
+		var sm sessionMedia
+		sm.media = medi
+
+		sm.rtpConn, sm.rtcpConn, err = allocatePorts()
+		if err != nil {
+			s.closeMedias()
+			return nil, err
+		}
+
+		s.medias = append(s.medias, &sm)
+
+		err = sm.rtpConn.SetReadBuffer(rtp.UDPKernelReadBufferSize)
+		if err != nil {
+			s.closeMedias()
+			return nil, err
+		}
+	}
+
+	s.path, err = s.pathManager.AddPublisher(defs.PathAddPublisherReq{
+		Author: s,
+		AccessRequest: defs.PathAccessRequest{
+			Name:     s.pathName,
+			Publish:  true,
+			SkipAuth: true,
+		},
+	})
+	if err != nil {
+		s.closeMedias()
+		return nil, err
+	}
+
+	s.stream, err = s.path.StartPublisher(defs.PathStartPublisherReq{
+		Author:             s,
+		Desc:               &desc,
+		GenerateRTPPackets: false,
+	})
+	if err != nil {
+		s.path.RemovePublisher(defs.PathRemovePublisherReq{Author: s})
+		s.closeMedias()
+		return nil, err
+	}
+
+	sd.ConnectionInformation = connectionInformation(s.address)
+	sd.Origin.AddressType = sd.ConnectionInformation.AddressType
+	sd.Origin.UnicastAddress = s.address
+
+	for i, md := range sd.MediaDescriptions {
+		md.ConnectionInformation = nil
+		md.MediaName.Port = psdp.RangedPort{Value: s.medias[i].port()}
+	}
+
+	answer, err := sd.Marshal()
+	if err != nil {
+		s.path.RemovePublisher(defs.PathRemovePublisherReq{Author: s})
+		s.closeMedias()
+		return nil, err
+	}
+
+	s.Log(logger.Info, "created, is publishing to path '%s', %s",
+		s.path.Name(), defs.MediasInfo(desc.Medias))
+
+	s.wg.Add(1)
+	go s.run()
+
+	return answer, nil
+}
+
+// Close closes a session.
+func (s *session) Close() {
+	s.ctxCancel()
+}
+
+// Log implements logger.Writer.
+func (s *session) Log(level logger.Level, format string, args ...interface{}) {
+	id := hex.EncodeToString(s.uuid[:4])
+	s.parent.Log(level, "[session %s] "+format, append([]interface{}{id}, args...)...)
+}
+
+func (s *session) closeMedias() {
+	for _, sm := range s.medias {
+		sm.close()
+	}
+}
+
+func (s *session) run() {
+	defer s.wg.Done()
+
+	err := s.runInner()
+
+	s.ctxCancel()
+
+	s.path.RemovePublisher(defs.PathRemovePublisherReq{Author: s})
+
+	s.parent.closeSession(s)
+
+	s.Log(logger.Info, "closed: %v", err)
+}
+
+func (s *session) runInner() error {
+	timeDecoder := rtptime.NewGlobalDecoder2()
+	decodeErrLogger := logger.NewLimitedLogger(s)
+	readerErr := make(chan error)
+
+	for _, sm := range s.medias {
+		go func(sm *sessionMedia) {
+			readerErr <- rtp.ReadRTP(sm.rtpConn, time.Duration(s.readTimeout), sm.media,
+				timeDecoder, decodeErrLogger, s.stream)
+		}(sm)
+
+		go func(sm *sessionMedia) {
+			readerErr <- rtp.DiscardRTCP(sm.rtcpConn)
+		}(sm)
+	}
+
+	// readers must exit before returning, since they write to the stream
+	select {
+	case err := <-readerErr:
+		s.closeMedias()
+		for i := 1; i < len(s.medias)*2; i++ {
+			<-readerErr
+		}
+		return err
+
+	case <-s.ctx.Done():
+		s.closeMedias()
+		for i := 0; i < len(s.medias)*2; i++ {
+			<-readerErr
+		}
+		return fmt.Errorf("terminated")
+	}
+}
+
+// APISourceDescribe implements defs.Source.
+func (s *session) APISourceDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "rtpPushSession",
+		ID:   s.uuid.String(),
+	}
+}
+
+func (s *session) apiItem() *defs.APIRTPPushSession {
+	ports := make([]int, len(s.medias))
+	for i, sm := range s.medias {
+		ports[i] = sm.port()
+	}
+
+	return &defs.APIRTPPushSession{
+		ID:            s.uuid,
+		Created:       s.created,
+		Path:          s.pathName,
+		Ports:         ports,
+		BytesReceived: s.stream.BytesReceived(),
+	}
+}
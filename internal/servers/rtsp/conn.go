@@ -1,14 +1,17 @@
 package rtsp
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4"
 	rtspauth "github.com/bluenviron/gortsplib/v4/pkg/auth"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/google/uuid"
 
 	"github.com/bluenviron/mediamtx/internal/auth"
@@ -23,6 +26,36 @@ const (
 	rtspAuthRealm = "IPCAM"
 )
 
+// cachedDescription generates a SDP from a cached description,
+// using the same control attributes of gortsplib.ServerStream.
+func cachedDescription(desc *description.Session, contentBase *base.URL) ([]byte, error) {
+	out := &description.Session{
+		Title:     desc.Title,
+		FECGroups: desc.FECGroups,
+		Medias:    make([]*description.Media, len(desc.Medias)),
+	}
+
+	for i, medi := range desc.Medias {
+		mc := &description.Media{
+			Type:          medi.Type,
+			ID:            medi.ID,
+			IsBackChannel: medi.IsBackChannel,
+			Control:       "trackID=" + strconv.FormatInt(int64(i), 10),
+			Formats:       medi.Formats,
+		}
+
+		u, err := mc.URL(contentBase)
+		if err != nil {
+			return nil, err
+		}
+		mc.Control = u.String()
+
+		out.Medias[i] = mc
+	}
+
+	return out.Marshal(false)
+}
+
 type connParent interface {
 	logger.Writer
 	findSessionByRSession(rsession *gortsplib.ServerSession) *session
@@ -78,7 +111,8 @@ func (c *conn) initialize() {
 
 // Log implements logger.Writer.
 func (c *conn) Log(level logger.Level, format string, args ...interface{}) {
-	c.parent.Log(level, "[conn %v] "+format, append([]interface{}{c.rconn.NetConn().RemoteAddr()}, args...)...)
+	id := hex.EncodeToString(c.uuid[:4])
+	c.parent.Log(level, "[conn %v %s] "+format, append([]interface{}{c.rconn.NetConn().RemoteAddr(), id}, args...)...)
 }
 
 // Conn returns the RTSP connection.
@@ -174,6 +208,22 @@ func (c *conn) onDescribe(ctx *gortsplib.ServerHandlerOnDescribeCtx,
 		}, nil, nil
 	}
 
+	// stream is not ready yet, answer with the cached description.
+	// The source has been started and readers will be put on hold during SETUP.
+	if res.Stream == nil {
+		byts, err := cachedDescription(res.Desc, ctx.Request.URL)
+		if err != nil {
+			return &base.Response{
+				StatusCode: base.StatusInternalServerError,
+			}, nil, err
+		}
+
+		return &base.Response{
+			StatusCode: base.StatusOK,
+			Body:       byts,
+		}, nil, nil
+	}
+
 	var stream *gortsplib.ServerStream
 	if !c.isTLS {
 		stream = res.Stream.RTSPStream(c.rserver)
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4"
 	rtspauth "github.com/bluenviron/gortsplib/v4/pkg/auth"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 	"github.com/google/uuid"
 	"github.com/pion/rtp"
 
@@ -33,18 +35,19 @@ type session struct {
 	pathManager     serverPathManager
 	parent          logger.Writer
 
-	uuid            uuid.UUID
-	created         time.Time
-	path            defs.Path
-	stream          *stream.Stream
-	onUnreadHook    func()
-	mutex           sync.Mutex
-	state           gortsplib.ServerSessionState
-	transport       *gortsplib.Transport
-	pathName        string
-	query           string
-	decodeErrLogger logger.Writer
-	writeErrLogger  logger.Writer
+	uuid              uuid.UUID
+	created           time.Time
+	path              defs.Path
+	stream            *stream.Stream
+	onUnreadHook      func()
+	mutex             sync.Mutex
+	state             gortsplib.ServerSessionState
+	transport         *gortsplib.Transport
+	pathName          string
+	query             string
+	decodeErrLogger   logger.Writer
+	writeErrLogger    logger.Writer
+	rtpPacketsDropped *uint64
 }
 
 func (s *session) initialize() {
@@ -53,6 +56,7 @@ func (s *session) initialize() {
 
 	s.decodeErrLogger = logger.NewLimitedLogger(s)
 	s.writeErrLogger = logger.NewLimitedLogger(s)
+	s.rtpPacketsDropped = new(uint64)
 
 	s.Log(logger.Info, "created by %v", s.rconn.NetConn().RemoteAddr())
 }
@@ -83,7 +87,10 @@ func (s *session) onClose(err error) {
 		s.path.RemoveReader(defs.PathRemoveReaderReq{Author: s})
 
 	case gortsplib.ServerSessionStatePreRecord, gortsplib.ServerSessionStateRecord:
-		s.path.RemovePublisher(defs.PathRemovePublisherReq{Author: s})
+		s.path.RemovePublisher(defs.PathRemovePublisherReq{
+			Author: s,
+			Ended:  errors.As(err, &liberrors.ErrServerSessionTornDown{}),
+		})
 	}
 
 	s.path = nil
@@ -371,6 +378,7 @@ func (s *session) onDecodeError(ctx *gortsplib.ServerHandlerOnDecodeErrorCtx) {
 
 // onStreamWriteError is called by rtspServer.
 func (s *session) onStreamWriteError(ctx *gortsplib.ServerHandlerOnStreamWriteErrorCtx) {
+	atomic.AddUint64(s.rtpPacketsDropped, 1)
 	s.writeErrLogger.Log(logger.Warn, ctx.Error.Error())
 }
 
@@ -415,6 +423,7 @@ func (s *session) apiItem() *defs.APIRTSPSession {
 		RTPPacketsLost:      stats.RTPPacketsLost,
 		RTPPacketsInError:   stats.RTPPacketsInError,
 		RTPPacketsJitter:    stats.RTPPacketsJitter,
+		RTPPacketsDropped:   atomic.LoadUint64(s.rtpPacketsDropped),
 		RTCPPacketsReceived: stats.RTCPPacketsReceived,
 		RTCPPacketsSent:     stats.RTCPPacketsSent,
 		RTCPPacketsInError:  stats.RTCPPacketsInError,
@@ -3,6 +3,7 @@ package srt
 import (
 	"bufio"
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
@@ -92,7 +93,8 @@ func (c *conn) Close() {
 
 // Log implements logger.Writer.
 func (c *conn) Log(level logger.Level, format string, args ...interface{}) {
-	c.parent.Log(level, "[conn %v] "+format, append([]interface{}{c.connReq.RemoteAddr()}, args...)...)
+	id := hex.EncodeToString(c.uuid[:4])
+	c.parent.Log(level, "[conn %v %s] "+format, append([]interface{}{c.connReq.RemoteAddr(), id}, args...)...)
 }
 
 func (c *conn) ip() net.IP {
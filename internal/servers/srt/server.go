@@ -66,6 +66,8 @@ type serverParent interface {
 // Server is a SRT server.
 type Server struct {
 	Address             string
+	Latency             conf.Duration
+	OverheadBW          int64
 	RTSPAddress         string
 	ReadTimeout         conf.Duration
 	WriteTimeout        conf.Duration
@@ -97,6 +99,9 @@ func (s *Server) Initialize() error {
 	conf := srt.DefaultConfig()
 	conf.ConnectionTimeout = time.Duration(s.ReadTimeout)
 	conf.PayloadSize = uint32(srtMaxPayloadSize(s.UDPMaxPayloadSize))
+	conf.ReceiverLatency = time.Duration(s.Latency)
+	conf.PeerLatency = time.Duration(s.Latency)
+	conf.OverheadBW = s.OverheadBW
 
 	var err error
 	s.ln, err = srt.Listen("srt", s.Address, conf)
@@ -38,6 +38,8 @@ func (p *dummyPath) StartPublisher(req defs.PathStartPublisherReq) (*stream.Stre
 	var err error
 	p.stream, err = stream.New(
 		512,
+		0,
+		0,
 		1460,
 		req.Desc,
 		true,
@@ -82,6 +84,7 @@ func TestServerPublish(t *testing.T) {
 		RTSPAddress:         "",
 		ReadTimeout:         conf.Duration(10 * time.Second),
 		WriteTimeout:        conf.Duration(10 * time.Second),
+		OverheadBW:          25,
 		UDPMaxPayloadSize:   1472,
 		RunOnConnect:        "",
 		RunOnConnectRestart: false,
@@ -167,6 +170,8 @@ func TestServerRead(t *testing.T) {
 
 	str, err := stream.New(
 		512,
+		0,
+		0,
 		1460,
 		desc,
 		true,
@@ -191,6 +196,7 @@ func TestServerRead(t *testing.T) {
 		RTSPAddress:         "",
 		ReadTimeout:         conf.Duration(10 * time.Second),
 		WriteTimeout:        conf.Duration(10 * time.Second),
+		OverheadBW:          25,
 		UDPMaxPayloadSize:   1472,
 		RunOnConnect:        "",
 		RunOnConnectRestart: false,
@@ -123,7 +123,7 @@ func (s *httpServer) close() {
 	s.inner.Close()
 }
 
-func (s *httpServer) checkAuthOutsideSession(ctx *gin.Context, pathName string, publish bool) bool {
+func (s *httpServer) checkAuthOutsideSession(ctx *gin.Context, pathName string, publish bool) (*conf.Path, bool) {
 	req := defs.PathAccessRequest{
 		Name:    pathName,
 		Publish: publish,
@@ -132,7 +132,7 @@ func (s *httpServer) checkAuthOutsideSession(ctx *gin.Context, pathName string,
 	}
 	req.FillFromHTTPRequest(ctx.Request)
 
-	_, err := s.pathManager.FindPathConf(defs.PathFindPathConfReq{
+	pathConf, err := s.pathManager.FindPathConf(defs.PathFindPathConfReq{
 		AccessRequest: req,
 	})
 	if err != nil {
@@ -141,7 +141,7 @@ func (s *httpServer) checkAuthOutsideSession(ctx *gin.Context, pathName string,
 			if terr.AskCredentials {
 				ctx.Header("WWW-Authenticate", `Basic realm="mediamtx"`)
 				ctx.Writer.WriteHeader(http.StatusUnauthorized)
-				return false
+				return nil, false
 			}
 
 			s.Log(logger.Info, "connection %v failed to authenticate: %v", httpp.RemoteAddr(ctx), terr.Message)
@@ -150,22 +150,23 @@ func (s *httpServer) checkAuthOutsideSession(ctx *gin.Context, pathName string,
 			<-time.After(auth.PauseAfterError)
 
 			writeError(ctx, http.StatusUnauthorized, terr)
-			return false
+			return nil, false
 		}
 
 		writeError(ctx, http.StatusInternalServerError, err)
-		return false
+		return nil, false
 	}
 
-	return true
+	return pathConf, true
 }
 
 func (s *httpServer) onWHIPOptions(ctx *gin.Context, pathName string, publish bool) {
-	if !s.checkAuthOutsideSession(ctx, pathName, publish) {
+	pathConf, ok := s.checkAuthOutsideSession(ctx, pathName, publish)
+	if !ok {
 		return
 	}
 
-	servers, err := s.parent.generateICEServers(true)
+	servers, err := s.parent.generateICEServers(pathConf, true)
 	if err != nil {
 		writeError(ctx, http.StatusInternalServerError, err)
 		return
@@ -219,7 +220,7 @@ func (s *httpServer) onWHIPPost(ctx *gin.Context, pathName string, publish bool)
 		return
 	}
 
-	servers, err := s.parent.generateICEServers(true)
+	servers, err := s.parent.generateICEServers(res.pathConf, true)
 	if err != nil {
 		writeError(ctx, http.StatusInternalServerError, err)
 		return
@@ -260,9 +261,17 @@ func (s *httpServer) onWHIPPatch(ctx *gin.Context, pathName string, rawSecret st
 		return
 	}
 
+	iceUfrag, icePwd, err := whip.ICEFragmentUnmarshalCredentials(byts)
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
 	res := s.parent.addSessionCandidates(webRTCAddSessionCandidatesReq{
 		pathName:   pathName,
 		secret:     secret,
+		iceUfrag:   iceUfrag,
+		icePwd:     icePwd,
 		candidates: candidates,
 	})
 	if res.err != nil {
@@ -274,6 +283,15 @@ func (s *httpServer) onWHIPPatch(ctx *gin.Context, pathName string, rawSecret st
 		return
 	}
 
+	// RFC 9725, ICE restart
+	if res.answerFrag != nil {
+		ctx.Header("Content-Type", "application/trickle-ice-sdpfrag")
+		ctx.Header("ETag", "*")
+		ctx.Writer.WriteHeader(http.StatusOK)
+		ctx.Writer.Write(res.answerFrag)
+		return
+	}
+
 	ctx.Writer.WriteHeader(http.StatusNoContent)
 }
 
@@ -301,7 +319,7 @@ func (s *httpServer) onWHIPDelete(ctx *gin.Context, pathName string, rawSecret s
 }
 
 func (s *httpServer) onPage(ctx *gin.Context, pathName string, publish bool) {
-	if !s.checkAuthOutsideSession(ctx, pathName, publish) {
+	if _, ok := s.checkAuthOutsideSession(ctx, pathName, publish); !ok {
 		return
 	}
 
@@ -267,6 +267,54 @@
     return frag;
   };
 
+  const applySdpFragment = (sdp, frag) => {
+    let iceUfrag = null;
+    let icePwd = null;
+    const candidatesByMedia = {};
+    let mid = null;
+
+    for (const line of frag.split('\r\n')) {
+      if (line.startsWith('a=ice-ufrag:')) {
+        iceUfrag = line.slice('a=ice-ufrag:'.length);
+      } else if (line.startsWith('a=ice-pwd:')) {
+        icePwd = line.slice('a=ice-pwd:'.length);
+      } else if (line.startsWith('a=mid:')) {
+        mid = line.slice('a=mid:'.length);
+        candidatesByMedia[mid] = [];
+      } else if (line.startsWith('a=candidate:') && mid !== null) {
+        candidatesByMedia[mid].push(line);
+      }
+    }
+
+    return sdp.split('\r\nm=').map((section, i) => {
+      // candidates of the previous ICE session are not valid anymore
+      const lines = section.split('\r\n')
+        .filter((line) => !line.startsWith('a=candidate:') && line !== 'a=end-of-candidates')
+        .map((line) => {
+          if (line.startsWith('a=ice-ufrag:') && iceUfrag !== null) {
+            return 'a=ice-ufrag:' + iceUfrag;
+          }
+          if (line.startsWith('a=ice-pwd:') && icePwd !== null) {
+            return 'a=ice-pwd:' + icePwd;
+          }
+          return line;
+        });
+
+      if (i !== 0) {
+        const midLine = lines.find((line) => line.startsWith('a=mid:'));
+        if (midLine !== undefined) {
+          const candidates = candidatesByMedia[midLine.slice('a=mid:'.length)];
+          if (candidates !== undefined) {
+            const end = (lines[lines.length - 1] === '') ? lines.length - 1 : lines.length;
+            lines.splice(end, 0, ...candidates);
+          }
+        }
+      }
+
+      return lines.join('\r\n');
+    }).join('\r\nm=');
+  };
+
   const retryPause = 2000;
 
   class MediaMTXWebRTCReader {
@@ -278,6 +326,7 @@
       this.offerData = null;
       this.sessionUrl = null;
       this.queuedCandidates = [];
+      this.iceRestarting = false;
 
       this.getNonAdvertisedCodecs()
         .then(() => this.start())
@@ -306,6 +355,7 @@
       }
 
       this.queuedCandidates = [];
+      this.iceRestarting = false;
 
       if (this.state === 'running') {
         this.state = 'restarting';
@@ -431,7 +481,7 @@
       }
 
       if (evt.candidate !== null) {
-        if (this.sessionUrl === null) {
+        if (this.sessionUrl === null || this.iceRestarting) {
           this.queuedCandidates.push(evt.candidate);
         } else {
           this.sendLocalCandidates([evt.candidate]);
@@ -463,6 +513,53 @@
         });
     };
 
+    restartICE = () => {
+      this.iceRestarting = true;
+
+      this.pc.createOffer({ iceRestart: true })
+        .then((offer) => {
+          offer.sdp = editOffer(offer.sdp, this.nonAdvertisedCodecs);
+          this.offerData = parseOffer(offer.sdp);
+
+          return this.pc.setLocalDescription(offer);
+        })
+        .then(() => fetch(this.sessionUrl, {
+          method: 'PATCH',
+          headers: {
+            'Content-Type': 'application/trickle-ice-sdpfrag',
+            'If-Match': '*',
+          },
+          body: generateSdpFragment(this.offerData, []),
+        }))
+        .then((res) => {
+          switch (res.status) {
+          case 200:
+            break;
+          case 404:
+            throw new Error('stream not found');
+          default:
+            throw new Error(`bad status code ${res.status}`);
+          }
+
+          return res.text();
+        })
+        .then((frag) => this.pc.setRemoteDescription(new RTCSessionDescription({
+          type: 'answer',
+          sdp: applySdpFragment(this.pc.remoteDescription.sdp, frag),
+        })))
+        .then(() => {
+          this.iceRestarting = false;
+
+          if (this.queuedCandidates.length !== 0) {
+            this.sendLocalCandidates(this.queuedCandidates);
+            this.queuedCandidates = [];
+          }
+        })
+        .catch((err) => {
+          this.handleError(err.toString());
+        });
+    };
+
     onConnectionState = () => {
       if (this.state !== 'running') {
         return;
@@ -470,6 +567,12 @@
 
       if (this.pc.connectionState === 'failed') {
         this.handleError('peer connection closed');
+      } else if (this.pc.connectionState === 'disconnected') {
+        // the network may have changed (i.e. from Wi-Fi to LTE),
+        // try to restore the connection without creating a new session.
+        if (!this.iceRestarting && this.sessionUrl !== null) {
+          this.restartICE();
+        }
       }
     };
 
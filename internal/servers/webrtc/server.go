@@ -128,6 +128,7 @@ type serverAPISessionsKickReq struct {
 type webRTCNewSessionRes struct {
 	sx            *session
 	answer        []byte
+	pathConf      *conf.Path
 	errStatusCode int
 	err           error
 }
@@ -142,13 +143,16 @@ type webRTCNewSessionReq struct {
 }
 
 type webRTCAddSessionCandidatesRes struct {
-	sx  *session
-	err error
+	sx         *session
+	answerFrag []byte
+	err        error
 }
 
 type webRTCAddSessionCandidatesReq struct {
 	pathName   string
 	secret     uuid.UUID
+	iceUfrag   string
+	icePwd     string
 	candidates []*pwebrtc.ICECandidateInit
 	res        chan webRTCAddSessionCandidatesRes
 }
@@ -417,10 +421,17 @@ func (s *Server) findSessionByUUID(uuid uuid.UUID) *session {
 	return nil
 }
 
-func (s *Server) generateICEServers(clientConfig bool) ([]pwebrtc.ICEServer, error) {
-	ret := make([]pwebrtc.ICEServer, 0, len(s.ICEServers))
+// generateICEServers generates the ICE servers of a path.
+// ICE servers of the path, if any, replace global ones.
+func (s *Server) generateICEServers(pathConf *conf.Path, clientConfig bool) ([]pwebrtc.ICEServer, error) {
+	servers := s.ICEServers
+	if pathConf != nil && len(pathConf.WebRTCICEServers) != 0 {
+		servers = pathConf.WebRTCICEServers
+	}
+
+	ret := make([]pwebrtc.ICEServer, 0, len(servers))
 
-	for _, server := range s.ICEServers {
+	for _, server := range servers {
 		if !server.ClientOnly || clientConfig {
 			if server.Username == "AUTH_SECRET" {
 				expireDate := time.Now().Add(webrtcTurnSecretExpiration).Unix()
@@ -55,6 +55,8 @@ func (p *dummyPath) StartPublisher(req defs.PathStartPublisherReq) (*stream.Stre
 	var err error
 	p.stream, err = stream.New(
 		512,
+		0,
+		0,
 		1460,
 		req.Desc,
 		true,
@@ -502,6 +504,8 @@ func TestServerRead(t *testing.T) {
 
 			str, err := stream.New(
 				512,
+				0,
+				0,
 				1460,
 				desc,
 				reflect.TypeOf(ca.unit) != reflect.TypeOf(&unit.Generic{}),
@@ -704,6 +708,124 @@ func TestServerPatchNotFound(t *testing.T) {
 	require.Equal(t, http.StatusNotFound, res.StatusCode)
 }
 
+func TestServerICERestart(t *testing.T) {
+	desc := &description.Session{Medias: []*description.Media{test.MediaH264}}
+
+	str, err := stream.New(
+		512,
+		0,
+		0,
+		1460,
+		desc,
+		true,
+		test.NilLogger,
+	)
+	require.NoError(t, err)
+	defer str.Close()
+
+	path := &dummyPath{stream: str}
+
+	pathManager := &test.PathManager{
+		FindPathConfImpl: func(_ defs.PathFindPathConfReq) (*conf.Path, error) {
+			return &conf.Path{}, nil
+		},
+		AddReaderImpl: func(_ defs.PathAddReaderReq) (defs.Path, *stream.Stream, error) {
+			return path, str, nil
+		},
+	}
+
+	s := &Server{
+		Address:               "127.0.0.1:8886",
+		ReadTimeout:           conf.Duration(10 * time.Second),
+		LocalUDPAddress:       "127.0.0.1:8887",
+		IPsFromInterfaces:     true,
+		IPsFromInterfacesList: []string{},
+		AdditionalHosts:       []string{},
+		ICEServers:            []conf.WebRTCICEServer{},
+		HandshakeTimeout:      conf.Duration(10 * time.Second),
+		TrackGatherTimeout:    conf.Duration(2 * time.Second),
+		PathManager:           pathManager,
+		Parent:                test.NilLogger,
+	}
+	err = s.Initialize()
+	require.NoError(t, err)
+	defer s.Close()
+
+	u, err := url.Parse("http://localhost:8886/teststream/whep")
+	require.NoError(t, err)
+
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	hc := &http.Client{Transport: tr}
+
+	wc := &whip.Client{
+		HTTPClient: hc,
+		URL:        u,
+		Log:        test.NilLogger,
+	}
+
+	writerDone := make(chan struct{})
+	defer func() { <-writerDone }()
+
+	go func() {
+		defer close(writerDone)
+
+		str.WaitRunningReader()
+
+		str.WriteUnit(desc.Medias[0], desc.Medias[0].Formats[0], &unit.H264{
+			Base: unit.Base{
+				NTP: time.Time{},
+			},
+			AU: [][]byte{
+				{5, 1}, // IDR
+			},
+		})
+	}()
+
+	_, err = wc.Read(context.Background())
+	require.NoError(t, err)
+	defer checkClose(t, wc.Close)
+
+	patch := func(frag string) *http.Response {
+		req, err2 := http.NewRequest(http.MethodPatch, wc.URL.String(), bytes.NewReader([]byte(frag)))
+		require.NoError(t, err2)
+
+		req.Header.Set("Content-Type", "application/trickle-ice-sdpfrag")
+		req.Header.Set("If-Match", "*")
+
+		res, err2 := hc.Do(req)
+		require.NoError(t, err2)
+		return res
+	}
+
+	frag := "a=ice-ufrag:NEWUFRAG\r\n" +
+		"a=ice-pwd:NEWPASSWORDNEWPASSWORDNEW\r\n"
+
+	res := patch(frag)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "application/trickle-ice-sdpfrag", res.Header.Get("Content-Type"))
+
+	byts, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	ufrag, pwd, err := whip.ICEFragmentUnmarshalCredentials(byts)
+	require.NoError(t, err)
+	require.NotEmpty(t, ufrag)
+	require.NotEmpty(t, pwd)
+
+	candidates, err := whip.ICEFragmentUnmarshal(byts)
+	require.NoError(t, err)
+	require.NotEmpty(t, candidates)
+
+	// credentials didn't change, therefore this is a regular trickle request
+	res2 := patch(frag)
+	defer res2.Body.Close()
+
+	require.Equal(t, http.StatusNoContent, res2.StatusCode)
+}
+
 func TestServerDeleteNotFound(t *testing.T) {
 	s := initializeTestServer(t)
 	defer s.Close()
@@ -733,10 +855,10 @@ func TestICEServerNoClientOnly(t *testing.T) {
 			},
 		},
 	}
-	clientICEServers, err := s.generateICEServers(true)
+	clientICEServers, err := s.generateICEServers(nil, true)
 	require.NoError(t, err)
 	require.Equal(t, len(s.ICEServers), len(clientICEServers))
-	serverICEServers, err := s.generateICEServers(false)
+	serverICEServers, err := s.generateICEServers(nil, false)
 	require.NoError(t, err)
 	require.Equal(t, len(s.ICEServers), len(serverICEServers))
 }
@@ -752,10 +874,36 @@ func TestICEServerClientOnly(t *testing.T) {
 			},
 		},
 	}
-	clientICEServers, err := s.generateICEServers(true)
+	clientICEServers, err := s.generateICEServers(nil, true)
 	require.NoError(t, err)
 	require.Equal(t, len(s.ICEServers), len(clientICEServers))
-	serverICEServers, err := s.generateICEServers(false)
+	serverICEServers, err := s.generateICEServers(nil, false)
 	require.NoError(t, err)
 	require.Empty(t, serverICEServers)
 }
+
+func TestICEServerPathOverride(t *testing.T) {
+	s := &Server{
+		ICEServers: []conf.WebRTCICEServer{
+			{
+				URL: "stun:myurl:1234",
+			},
+		},
+	}
+	pathConf := &conf.Path{
+		WebRTCICEServers: []conf.WebRTCICEServer{
+			{
+				URL:      "turn:myurl2:1234",
+				Username: "myuser",
+				Password: "mypass",
+			},
+		},
+	}
+	iceServers, err := s.generateICEServers(pathConf, true)
+	require.NoError(t, err)
+	require.Equal(t, []pwebrtc.ICEServer{{
+		URLs:       []string{"turn:myurl2:1234"},
+		Username:   "myuser",
+		Credential: "mypass",
+	}}, iceServers)
+}
@@ -17,11 +17,13 @@ import (
 	pwebrtc "github.com/pion/webrtc/v4"
 
 	"github.com/bluenviron/mediamtx/internal/auth"
+	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/externalcmd"
 	"github.com/bluenviron/mediamtx/internal/hooks"
 	"github.com/bluenviron/mediamtx/internal/logger"
 	"github.com/bluenviron/mediamtx/internal/protocols/webrtc"
+	"github.com/bluenviron/mediamtx/internal/protocols/whip"
 	"github.com/bluenviron/mediamtx/internal/stream"
 )
 
@@ -145,7 +147,7 @@ func (s *session) runPublish() (int, error) {
 
 	defer path.RemovePublisher(defs.PathRemovePublisherReq{Author: s})
 
-	iceServers, err := s.parent.generateICEServers(false)
+	iceServers, err := s.parent.generateICEServers(path.SafeConf(), false)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -191,7 +193,7 @@ func (s *session) runPublish() (int, error) {
 		return http.StatusBadRequest, err
 	}
 
-	s.writeAnswer(answer)
+	s.writeAnswer(answer, path.SafeConf())
 
 	go s.readRemoteCandidates(pc)
 
@@ -262,7 +264,7 @@ func (s *session) runRead() (int, error) {
 
 	defer path.RemoveReader(defs.PathRemoveReaderReq{Author: s})
 
-	iceServers, err := s.parent.generateICEServers(false)
+	iceServers, err := s.parent.generateICEServers(path.SafeConf(), false)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -278,9 +280,16 @@ func (s *session) runRead() (int, error) {
 		ICETCPMux:             s.iceTCPMux,
 		Publish:               true,
 		Log:                   s,
+		RemoteCodecs:          webrtc.OfferCodecs(whipOffer(s.req.offer)),
 	}
 
-	err = webrtc.FromStream(stream, s, pc)
+	pathConf := path.SafeConf()
+	codecPriority := pathConf.WebRTCCodecPriority
+	if len(codecPriority) == 0 {
+		codecPriority = pathConf.CodecPriority
+	}
+
+	err = webrtc.FromStream(stream, s, pc, codecPriority)
 	if err != nil {
 		return http.StatusBadRequest, err
 	}
@@ -300,7 +309,7 @@ func (s *session) runRead() (int, error) {
 		return http.StatusBadRequest, err
 	}
 
-	s.writeAnswer(answer)
+	s.writeAnswer(answer, path.SafeConf())
 
 	go s.readRemoteCandidates(pc)
 
@@ -342,10 +351,11 @@ func (s *session) runRead() (int, error) {
 	}
 }
 
-func (s *session) writeAnswer(answer *pwebrtc.SessionDescription) {
+func (s *session) writeAnswer(answer *pwebrtc.SessionDescription, pathConf *conf.Path) {
 	s.req.res <- webRTCNewSessionRes{
-		sx:     s,
-		answer: []byte(answer.SDP),
+		sx:       s,
+		answer:   []byte(answer.SDP),
+		pathConf: pathConf,
 	}
 }
 
@@ -353,13 +363,7 @@ func (s *session) readRemoteCandidates(pc *webrtc.PeerConnection) {
 	for {
 		select {
 		case req := <-s.chAddCandidates:
-			for _, candidate := range req.candidates {
-				err := pc.AddRemoteCandidate(candidate)
-				if err != nil {
-					req.res <- webRTCAddSessionCandidatesRes{err: err}
-				}
-			}
-			req.res <- webRTCAddSessionCandidatesRes{}
+			req.res <- s.addRemoteCandidates(pc, req)
 
 		case <-s.ctx.Done():
 			return
@@ -367,6 +371,37 @@ func (s *session) readRemoteCandidates(pc *webrtc.PeerConnection) {
 	}
 }
 
+func (s *session) addRemoteCandidates(
+	pc *webrtc.PeerConnection,
+	req webRTCAddSessionCandidatesReq,
+) webRTCAddSessionCandidatesRes {
+	var answerFrag []byte
+
+	// the remote peer changed its credentials in order to request an ICE restart
+	if req.iceUfrag != "" && req.iceUfrag != pc.RemoteICEUfrag() {
+		answer, err := pc.RestartICE(s.ctx, req.iceUfrag, req.icePwd)
+		if err != nil {
+			return webRTCAddSessionCandidatesRes{err: err}
+		}
+
+		answerFrag, err = whip.ICEFragmentMarshalDescription(answer.SDP)
+		if err != nil {
+			return webRTCAddSessionCandidatesRes{err: err}
+		}
+
+		s.Log(logger.Info, "ICE restarted")
+	}
+
+	for _, candidate := range req.candidates {
+		err := pc.AddRemoteCandidate(candidate)
+		if err != nil {
+			return webRTCAddSessionCandidatesRes{err: err}
+		}
+	}
+
+	return webRTCAddSessionCandidatesRes{answerFrag: answerFrag}
+}
+
 // new is called by webRTCHTTPServer through Server.
 func (s *session) new(req webRTCNewSessionReq) webRTCNewSessionRes {
 	select {
@@ -413,6 +448,7 @@ func (s *session) apiItem() *defs.APIWebRTCSession {
 	remoteCandidate := ""
 	bytesReceived := uint64(0)
 	bytesSent := uint64(0)
+	var audioLevel *int
 
 	if s.pc != nil {
 		peerConnectionEstablished = true
@@ -420,6 +456,10 @@ func (s *session) apiItem() *defs.APIWebRTCSession {
 		remoteCandidate = s.pc.RemoteCandidate()
 		bytesReceived = s.pc.BytesReceived()
 		bytesSent = s.pc.BytesSent()
+
+		if v, ok := s.pc.AudioLevel(); ok {
+			audioLevel = &v
+		}
 	}
 
 	return &defs.APIWebRTCSession{
@@ -439,5 +479,6 @@ func (s *session) apiItem() *defs.APIWebRTCSession {
 		Query:         s.req.httpRequest.URL.RawQuery,
 		BytesReceived: bytesReceived,
 		BytesSent:     bytesSent,
+		AudioLevel:    audioLevel,
 	}
 }
@@ -0,0 +1,120 @@
+package bonded
+
+import (
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	reordererBufferSize = 1024 // must be a power of two
+)
+
+type reordererEntry struct {
+	pkt      *rtp.Packet
+	received time.Time
+}
+
+// reorderer merges packets received from multiple links.
+// Duplicates are dropped, packets are emitted in sequence order
+// and gaps are skipped once the packet after them has waited for longer than latency.
+type reorderer struct {
+	latency time.Duration
+
+	initialized bool
+	expected    uint16
+	buffer      [reordererBufferSize]*reordererEntry
+	count       int
+}
+
+func (r *reorderer) push(pkt *rtp.Packet, now time.Time) []*rtp.Packet {
+	if !r.initialized {
+		r.initialized = true
+		r.expected = pkt.SequenceNumber
+	}
+
+	rel := pkt.SequenceNumber - r.expected
+
+	// packet is older than the expected one: it's a duplicate or it arrived too late
+	if rel >= 0x8000 {
+		return nil
+	}
+
+	var out []*rtp.Packet
+
+	// packet is too far in the future: the sender has restarted or too many packets were lost
+	if rel >= reordererBufferSize {
+		out = r.drain()
+		r.expected = pkt.SequenceNumber
+	}
+
+	i := pkt.SequenceNumber % reordererBufferSize
+
+	if r.buffer[i] != nil {
+		return out
+	}
+
+	r.buffer[i] = &reordererEntry{
+		pkt:      pkt,
+		received: now,
+	}
+	r.count++
+
+	return append(out, r.pop()...)
+}
+
+// flush skips gaps that have been pending for longer than latency.
+// It returns packets that can be emitted and the number of lost packets.
+func (r *reorderer) flush(now time.Time) ([]*rtp.Packet, uint) {
+	var out []*rtp.Packet
+	var lost uint
+
+	for r.count != 0 {
+		next := r.expected
+		for r.buffer[next%reordererBufferSize] == nil {
+			next++
+		}
+
+		if now.Sub(r.buffer[next%reordererBufferSize].received) < r.latency {
+			break
+		}
+
+		lost += uint(next - r.expected)
+		r.expected = next
+		out = append(out, r.pop()...)
+	}
+
+	return out, lost
+}
+
+func (r *reorderer) pop() []*rtp.Packet {
+	var out []*rtp.Packet
+
+	for {
+		i := r.expected % reordererBufferSize
+		if r.buffer[i] == nil {
+			return out
+		}
+
+		out = append(out, r.buffer[i].pkt)
+		r.buffer[i] = nil
+		r.count--
+		r.expected++
+	}
+}
+
+func (r *reorderer) drain() []*rtp.Packet {
+	var out []*rtp.Packet
+
+	for n := 0; r.count != 0 && n < reordererBufferSize; n++ {
+		i := r.expected % reordererBufferSize
+		if r.buffer[i] != nil {
+			out = append(out, r.buffer[i].pkt)
+			r.buffer[i] = nil
+			r.count--
+		}
+		r.expected++
+	}
+
+	return out
+}
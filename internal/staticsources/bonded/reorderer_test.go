@@ -0,0 +1,89 @@
+package bonded
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func sequenceNumbers(pkts []*rtp.Packet) []uint16 {
+	out := []uint16{}
+	for _, pkt := range pkts {
+		out = append(out, pkt.SequenceNumber)
+	}
+	return out
+}
+
+func packetWithSeq(seq uint16) *rtp.Packet {
+	return &rtp.Packet{Header: rtp.Header{SequenceNumber: seq}}
+}
+
+func TestReordererDuplicates(t *testing.T) {
+	r := &reorderer{latency: 200 * time.Millisecond}
+	now := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var out []*rtp.Packet
+	for _, seq := range []uint16{65534, 65534, 65535, 0, 65535, 0, 1, 1} {
+		out = append(out, r.push(packetWithSeq(seq), now)...)
+	}
+
+	require.Equal(t, []uint16{65534, 65535, 0, 1}, sequenceNumbers(out))
+}
+
+func TestReordererReorder(t *testing.T) {
+	r := &reorderer{latency: 200 * time.Millisecond}
+	now := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	out := r.push(packetWithSeq(100), now)
+	require.Equal(t, []uint16{100}, sequenceNumbers(out))
+
+	out = r.push(packetWithSeq(103), now)
+	require.Equal(t, []uint16{}, sequenceNumbers(out))
+
+	out = r.push(packetWithSeq(102), now)
+	require.Equal(t, []uint16{}, sequenceNumbers(out))
+
+	out = r.push(packetWithSeq(101), now)
+	require.Equal(t, []uint16{101, 102, 103}, sequenceNumbers(out))
+
+	// late packet
+	out = r.push(packetWithSeq(99), now)
+	require.Equal(t, []uint16{}, sequenceNumbers(out))
+}
+
+func TestReordererGap(t *testing.T) {
+	r := &reorderer{latency: 200 * time.Millisecond}
+	now := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r.push(packetWithSeq(100), now)
+	r.push(packetWithSeq(103), now)
+	r.push(packetWithSeq(106), now.Add(100*time.Millisecond))
+
+	out, lost := r.flush(now.Add(150 * time.Millisecond))
+	require.Equal(t, []uint16{}, sequenceNumbers(out))
+	require.Equal(t, uint(0), lost)
+
+	out, lost = r.flush(now.Add(250 * time.Millisecond))
+	require.Equal(t, []uint16{103}, sequenceNumbers(out))
+	require.Equal(t, uint(2), lost)
+
+	out, lost = r.flush(now.Add(350 * time.Millisecond))
+	require.Equal(t, []uint16{106}, sequenceNumbers(out))
+	require.Equal(t, uint(2), lost)
+
+	out = r.push(packetWithSeq(107), now.Add(350*time.Millisecond))
+	require.Equal(t, []uint16{107}, sequenceNumbers(out))
+}
+
+func TestReordererJump(t *testing.T) {
+	r := &reorderer{latency: 200 * time.Millisecond}
+	now := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r.push(packetWithSeq(100), now)
+	r.push(packetWithSeq(102), now)
+
+	out := r.push(packetWithSeq(5000), now)
+	require.Equal(t, []uint16{102, 5000}, sequenceNumbers(out))
+}
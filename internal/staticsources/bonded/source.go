@@ -0,0 +1,308 @@
+// Package bonded contains the bonded static source.
+package bonded
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+	"github.com/bluenviron/gortsplib/v4/pkg/multicast"
+	mcmpegts "github.com/bluenviron/mediacommon/pkg/formats/mpegts"
+	"github.com/pion/rtp"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/mpegts"
+	"github.com/bluenviron/mediamtx/internal/restrictnetwork"
+	"github.com/bluenviron/mediamtx/internal/stream"
+)
+
+const (
+	// same size as GStreamer's rtspsrc
+	udpKernelReadBufferSize = 0x80000
+
+	udpMaxPayloadSize = 1472
+)
+
+type packetConn interface {
+	net.PacketConn
+	SetReadBuffer(int) error
+}
+
+func listenLink(link string) (packetConn, error) {
+	hostPort := link[len("udp://"):]
+
+	addr, err := net.ResolveUDPAddr("udp", hostPort)
+	if err != nil {
+		return nil, err
+	}
+
+	var pc packetConn
+
+	if ip4 := addr.IP.To4(); ip4 != nil && addr.IP.IsMulticast() {
+		pc, err = multicast.NewMultiConn(hostPort, true, net.ListenPacket)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var tmp net.PacketConn
+		tmp, err = net.ListenPacket(restrictnetwork.Restrict("udp", addr.String()))
+		if err != nil {
+			return nil, err
+		}
+		pc = tmp.(*net.UDPConn)
+	}
+
+	err = pc.SetReadBuffer(udpKernelReadBufferSize)
+	if err != nil {
+		pc.Close()
+		return nil, err
+	}
+
+	return pc, nil
+}
+
+// payloadReader is a io.Reader that returns a MPEG-TS payload for each Read() call.
+type payloadReader struct {
+	payloads  chan []byte
+	terminate chan struct{}
+}
+
+func (r *payloadReader) Read(p []byte) (int, error) {
+	select {
+	case payload := <-r.payloads:
+		if len(payload) > len(p) {
+			return 0, fmt.Errorf("payload is too big")
+		}
+		return copy(p, payload), nil
+
+	case <-r.terminate:
+		return 0, io.EOF
+	}
+}
+
+// Source is a bonded static source.
+// It receives the same MPEG-TS over RTP stream from multiple links,
+// merges packets by sequence number and drops duplicates.
+type Source struct {
+	ReadTimeout conf.Duration
+	Parent      defs.StaticSourceParent
+}
+
+// Log implements logger.Writer.
+func (s *Source) Log(level logger.Level, format string, args ...interface{}) {
+	s.Parent.Log(level, "[bonded source] "+format, args...)
+}
+
+// Run implements StaticSource.
+func (s *Source) Run(params defs.StaticSourceRunParams) error {
+	s.Log(logger.Debug, "connecting")
+
+	var conns []packetConn
+
+	closeConns := func() {
+		for _, pc := range conns {
+			pc.Close()
+		}
+	}
+
+	for _, link := range params.Conf.BondedLinks {
+		pc, err := listenLink(link)
+		if err != nil {
+			closeConns()
+			return err
+		}
+		conns = append(conns, pc)
+	}
+
+	packets := make(chan *rtp.Packet)
+	terminate := make(chan struct{})
+	var wg sync.WaitGroup
+
+	decodeErrLogger := logger.NewLimitedLogger(s)
+
+	for _, pc := range conns {
+		wg.Add(1)
+		go func(pc packetConn) {
+			defer wg.Done()
+			s.runLink(pc, packets, terminate, decodeErrLogger)
+		}(pc)
+	}
+
+	payloads := make(chan []byte)
+	readerErr := make(chan error, 1)
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		readerErr <- s.runReader(&payloadReader{
+			payloads:  payloads,
+			terminate: terminate,
+		})
+	}()
+
+	err := s.runInner(params, packets, payloads, readerErr, decodeErrLogger)
+
+	// reader must exit before returning, since it writes to the stream
+	close(terminate)
+	closeConns()
+	wg.Wait()
+	<-readerDone
+
+	return err
+}
+
+func (s *Source) runInner(
+	params defs.StaticSourceRunParams,
+	packets chan *rtp.Packet,
+	payloads chan []byte,
+	readerErr chan error,
+	decodeErrLogger logger.Writer,
+) error {
+	latency := time.Duration(params.Conf.BondedLatency)
+
+	r := &reorderer{
+		latency: latency,
+	}
+
+	flushPeriod := latency / 4
+	if flushPeriod < time.Millisecond {
+		flushPeriod = time.Millisecond
+	}
+
+	flushTicker := time.NewTicker(flushPeriod)
+	defer flushTicker.Stop()
+
+	readTimer := time.NewTimer(time.Duration(s.ReadTimeout))
+	defer readTimer.Stop()
+
+	write := func(pkts []*rtp.Packet) error {
+		for _, pkt := range pkts {
+			select {
+			case payloads <- pkt.Payload:
+			case err := <-readerErr:
+				return err
+			case <-params.Context.Done():
+				return fmt.Errorf("terminated")
+			}
+		}
+		return nil
+	}
+
+	for {
+		select {
+		case pkt := <-packets:
+			readTimer.Reset(time.Duration(s.ReadTimeout))
+
+			err := write(r.push(pkt, time.Now()))
+			if err != nil {
+				return err
+			}
+
+		case <-flushTicker.C:
+			pkts, lost := r.flush(time.Now())
+			if lost != 0 {
+				decodeErrLogger.Log(logger.Warn, (liberrors.ErrClientRTPPacketsLost{Lost: lost}).Error())
+			}
+
+			err := write(pkts)
+			if err != nil {
+				return err
+			}
+
+		case <-readTimer.C:
+			return fmt.Errorf("no packets received from any link in %v", time.Duration(s.ReadTimeout))
+
+		case err := <-readerErr:
+			return err
+
+		case <-params.Context.Done():
+			return fmt.Errorf("terminated")
+		}
+	}
+}
+
+func (s *Source) runLink(
+	pc packetConn,
+	packets chan *rtp.Packet,
+	terminate chan struct{},
+	decodeErrLogger logger.Writer,
+) {
+	buf := make([]byte, udpMaxPayloadSize+1)
+
+	for {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		if n > udpMaxPayloadSize {
+			decodeErrLogger.Log(logger.Warn, "received packet is too big")
+			continue
+		}
+
+		var pkt rtp.Packet
+		err = pkt.Unmarshal(append([]byte(nil), buf[:n]...))
+		if err != nil {
+			decodeErrLogger.Log(logger.Warn, err.Error())
+			continue
+		}
+
+		select {
+		case packets <- &pkt:
+		case <-terminate:
+			return
+		}
+	}
+}
+
+func (s *Source) runReader(pr *payloadReader) error {
+	r, err := mcmpegts.NewReader(mcmpegts.NewBufferedReader(pr))
+	if err != nil {
+		return err
+	}
+
+	decodeErrLogger := logger.NewLimitedLogger(s)
+
+	r.OnDecodeError(func(err error) {
+		decodeErrLogger.Log(logger.Warn, err.Error())
+	})
+
+	var stream *stream.Stream
+
+	medias, err := mpegts.ToStream(r, &stream, s)
+	if err != nil {
+		return err
+	}
+
+	res := s.Parent.SetReady(defs.PathSourceStaticSetReadyReq{
+		Desc:               &description.Session{Medias: medias},
+		GenerateRTPPackets: true,
+	})
+	if res.Err != nil {
+		return res.Err
+	}
+
+	defer s.Parent.SetNotReady(defs.PathSourceStaticSetNotReadyReq{})
+
+	stream = res.Stream
+
+	for {
+		err := r.Read()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// APISourceDescribe implements StaticSource.
+func (*Source) APISourceDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "bondedSource",
+		ID:   "",
+	}
+}
@@ -0,0 +1,99 @@
+package bonded
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/mpegts"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/test"
+)
+
+func TestSource(t *testing.T) {
+	te := test.NewSourceTester(
+		func(p defs.StaticSourceParent) defs.StaticSource {
+			return &Source{
+				ReadTimeout: conf.Duration(10 * time.Second),
+				Parent:      p,
+			}
+		},
+		"bonded",
+		&conf.Path{
+			BondedLinks:   []string{"udp://127.0.0.1:9001", "udp://127.0.0.1:9002"},
+			BondedLatency: conf.Duration(200 * time.Millisecond),
+		},
+	)
+	defer te.Close()
+
+	time.Sleep(50 * time.Millisecond)
+
+	var buf bytes.Buffer
+
+	track := &mpegts.Track{
+		Codec: &mpegts.CodecH264{},
+	}
+
+	w := mpegts.NewWriter(&buf, []*mpegts.Track{track})
+
+	err := w.WriteH2642(track, 0, 0, [][]byte{{ // IDR
+		5, 1,
+	}})
+	require.NoError(t, err)
+
+	err = w.WriteH2642(track, 0, 0, [][]byte{{ // non-IDR
+		5, 2,
+	}})
+	require.NoError(t, err)
+
+	var pkts [][]byte
+
+	for i := 0; buf.Len() != 0; i++ {
+		pkt := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    33,
+				SequenceNumber: uint16(1000 + i),
+				Timestamp:      0,
+				SSRC:           1234,
+			},
+			Payload: buf.Next(188),
+		}
+		var byts []byte
+		byts, err = pkt.Marshal()
+		require.NoError(t, err)
+		pkts = append(pkts, byts)
+	}
+
+	link1, err := net.Dial("udp", "127.0.0.1:9001")
+	require.NoError(t, err)
+	defer link1.Close()
+
+	link2, err := net.Dial("udp", "127.0.0.1:9002")
+	require.NoError(t, err)
+	defer link2.Close()
+
+	_, err = link1.Write(pkts[0])
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	// out of order
+	for i := len(pkts) - 1; i >= 1; i-- {
+		_, err = link2.Write(pkts[i])
+		require.NoError(t, err)
+	}
+
+	// duplicates
+	for _, pkt := range pkts {
+		_, err = link1.Write(pkt)
+		require.NoError(t, err)
+	}
+
+	<-te.Unit
+}
@@ -0,0 +1,299 @@
+// Package recording contains the recording static source.
+package recording
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/av1"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/playback"
+	"github.com/bluenviron/mediamtx/internal/stream"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func multiplyAndDivide(v, m, d int64) int64 {
+	secs := v / d
+	dec := v % d
+	return (secs*m + dec*m/d)
+}
+
+// parseURL parses a recording source URL, in the format
+// recording://pathName?start=RFC3339&duration=GoDuration
+func parseURL(raw string) (string, time.Time, time.Duration, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", time.Time{}, 0, err
+	}
+
+	pathName := strings.TrimSuffix(u.Host+u.Path, "/")
+	if pathName == "" {
+		return "", time.Time{}, 0, fmt.Errorf("path name is missing")
+	}
+
+	start, err := time.Parse(time.RFC3339, u.Query().Get("start"))
+	if err != nil {
+		return "", time.Time{}, 0, fmt.Errorf("invalid start: %w", err)
+	}
+
+	duration, err := time.ParseDuration(u.Query().Get("duration"))
+	if err != nil {
+		return "", time.Time{}, 0, fmt.Errorf("invalid duration: %w", err)
+	}
+
+	if duration <= 0 {
+		return "", time.Time{}, 0, fmt.Errorf("invalid duration: must be greater than zero")
+	}
+
+	return pathName, start, duration, nil
+}
+
+type track struct {
+	media     *description.Media
+	timeScale uint32
+	toUnit    func(base unit.Base, payload []byte) (unit.Unit, error)
+}
+
+func newTrack(initTrack *fmp4.InitTrack) *track {
+	switch codec := initTrack.Codec.(type) {
+	case *fmp4.CodecAV1:
+		return &track{
+			media: &description.Media{
+				Type: description.MediaTypeVideo,
+				Formats: []format.Format{&format.AV1{
+					PayloadTyp: 96,
+				}},
+			},
+			toUnit: func(base unit.Base, payload []byte) (unit.Unit, error) {
+				tu, err := av1.BitstreamUnmarshal(payload, true)
+				if err != nil {
+					return nil, err
+				}
+				return &unit.AV1{Base: base, TU: tu}, nil
+			},
+		}
+
+	case *fmp4.CodecVP9:
+		return &track{
+			media: &description.Media{
+				Type: description.MediaTypeVideo,
+				Formats: []format.Format{&format.VP9{
+					PayloadTyp: 96,
+				}},
+			},
+			toUnit: func(base unit.Base, payload []byte) (unit.Unit, error) {
+				return &unit.VP9{Base: base, Frame: payload}, nil
+			},
+		}
+
+	case *fmp4.CodecH265:
+		return &track{
+			media: &description.Media{
+				Type: description.MediaTypeVideo,
+				Formats: []format.Format{&format.H265{
+					PayloadTyp: 96,
+					VPS:        codec.VPS,
+					SPS:        codec.SPS,
+					PPS:        codec.PPS,
+				}},
+			},
+			toUnit: func(base unit.Base, payload []byte) (unit.Unit, error) {
+				au, err := h264.AVCCUnmarshal(payload)
+				if err != nil {
+					return nil, err
+				}
+				return &unit.H265{Base: base, AU: au}, nil
+			},
+		}
+
+	case *fmp4.CodecH264:
+		return &track{
+			media: &description.Media{
+				Type: description.MediaTypeVideo,
+				Formats: []format.Format{&format.H264{
+					PayloadTyp:        96,
+					PacketizationMode: 1,
+					SPS:               codec.SPS,
+					PPS:               codec.PPS,
+				}},
+			},
+			toUnit: func(base unit.Base, payload []byte) (unit.Unit, error) {
+				au, err := h264.AVCCUnmarshal(payload)
+				if err != nil {
+					return nil, err
+				}
+				return &unit.H264{Base: base, AU: au}, nil
+			},
+		}
+
+	case *fmp4.CodecOpus:
+		return &track{
+			media: &description.Media{
+				Type: description.MediaTypeAudio,
+				Formats: []format.Format{&format.Opus{
+					PayloadTyp:   96,
+					ChannelCount: codec.ChannelCount,
+				}},
+			},
+			toUnit: func(base unit.Base, payload []byte) (unit.Unit, error) {
+				return &unit.Opus{Base: base, Packets: [][]byte{payload}}, nil
+			},
+		}
+
+	case *fmp4.CodecMPEG4Audio:
+		return &track{
+			media: &description.Media{
+				Type: description.MediaTypeAudio,
+				Formats: []format.Format{&format.MPEG4Audio{
+					PayloadTyp:       96,
+					SizeLength:       13,
+					IndexLength:      3,
+					IndexDeltaLength: 3,
+					Config:           &codec.Config,
+				}},
+			},
+			toUnit: func(base unit.Base, payload []byte) (unit.Unit, error) {
+				return &unit.MPEG4Audio{Base: base, AUs: [][]byte{payload}}, nil
+			},
+		}
+	}
+
+	return nil
+}
+
+// Source is a recording static source.
+// It reads a time range of the recordings of a path at real-time speed.
+type Source struct {
+	Parent defs.StaticSourceParent
+}
+
+// Log implements logger.Writer.
+func (s *Source) Log(level logger.Level, format string, args ...interface{}) {
+	s.Parent.Log(level, "[recording source] "+format, args...)
+}
+
+// Run implements StaticSource.
+func (s *Source) Run(params defs.StaticSourceRunParams) error {
+	pathName, start, duration, err := parseURL(params.ResolvedSource)
+	if err != nil {
+		return err
+	}
+
+	s.Log(logger.Debug, "reading recordings of '%s' from %v", pathName, start)
+
+	readErr := make(chan error)
+
+	go func() {
+		readErr <- s.runReader(params, pathName, start, duration)
+	}()
+
+	for {
+		select {
+		case err := <-readErr:
+			return err
+
+		case <-params.ReloadConf:
+
+		case <-params.Context.Done():
+			// the reader stops as soon as the context is canceled
+			<-readErr
+			return fmt.Errorf("terminated")
+		}
+	}
+}
+
+func (s *Source) runReader(
+	params defs.StaticSourceRunParams,
+	pathName string,
+	start time.Time,
+	duration time.Duration,
+) error {
+	var strm *stream.Stream
+	tracks := make(map[int]*track)
+	ended := false
+
+	defer func() {
+		if strm != nil {
+			s.Parent.SetNotReady(defs.PathSourceStaticSetNotReadyReq{Ended: ended})
+		}
+	}()
+
+	err := playback.ReadRecording(
+		params.Context,
+		params.Conf,
+		pathName,
+		start,
+		duration,
+		func(init *fmp4.Init) error {
+			var medias []*description.Media //nolint:prealloc
+
+			for _, initTrack := range init.Tracks {
+				tr := newTrack(initTrack)
+				if tr == nil {
+					s.Log(logger.Warn, "skipping track %d (unsupported codec)", initTrack.ID)
+					continue
+				}
+
+				tr.timeScale = initTrack.TimeScale
+				tracks[initTrack.ID] = tr
+				medias = append(medias, tr.media)
+			}
+
+			if len(medias) == 0 {
+				return fmt.Errorf("the recording does not contain any supported codec")
+			}
+
+			res := s.Parent.SetReady(defs.PathSourceStaticSetReadyReq{
+				Desc:               &description.Session{Medias: medias},
+				GenerateRTPPackets: true,
+			})
+			if res.Err != nil {
+				return res.Err
+			}
+
+			strm = res.Stream
+			return nil
+		},
+		func(trackID int, dts int64, ptsOffset int32, payload []byte) error {
+			tr, ok := tracks[trackID]
+			if !ok {
+				return nil
+			}
+
+			forma := tr.media.Formats[0]
+
+			u, err := tr.toUnit(unit.Base{
+				NTP: start.Add(time.Duration(multiplyAndDivide(dts, int64(time.Second), int64(tr.timeScale)))),
+				PTS: multiplyAndDivide(dts+int64(ptsOffset), int64(forma.ClockRate()), int64(tr.timeScale)),
+			}, payload)
+			if err != nil {
+				return err
+			}
+
+			strm.WriteUnit(tr.media, forma, u)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	ended = true
+	return fmt.Errorf("end of recording reached")
+}
+
+// APISourceDescribe implements StaticSource.
+func (*Source) APISourceDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "recordingSource",
+		ID:   "",
+	}
+}
@@ -0,0 +1,95 @@
+package recording
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4"
+	"github.com/bluenviron/mediacommon/pkg/formats/fmp4/seekablebuffer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/test"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func TestSource(t *testing.T) {
+	dir, err := os.MkdirTemp("", "mediamtx-recording-source")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "mypath"), 0o755)
+	require.NoError(t, err)
+
+	init := fmp4.Init{
+		Tracks: []*fmp4.InitTrack{{
+			ID:        1,
+			TimeScale: 90000,
+			Codec: &fmp4.CodecH264{
+				SPS: test.FormatH264.SPS,
+				PPS: test.FormatH264.PPS,
+			},
+		}},
+	}
+
+	var buf1 seekablebuffer.Buffer
+	err = init.Marshal(&buf1)
+	require.NoError(t, err)
+
+	var buf2 seekablebuffer.Buffer
+	parts := fmp4.Parts{{
+		SequenceNumber: 1,
+		Tracks: []*fmp4.PartTrack{{
+			ID:       1,
+			BaseTime: 0,
+			Samples: []*fmp4.PartSample{
+				{
+					Duration: 90000,
+					Payload:  []byte{0, 0, 0, 2, 5, 1}, // IDR
+				},
+				{
+					Duration:        90000,
+					IsNonSyncSample: true,
+					Payload:         []byte{0, 0, 0, 2, 1, 2}, // non-IDR
+				},
+			},
+		}},
+	}}
+	err = parts.Marshal(&buf2)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "mypath", "2008-11-07_11-22-00-500000.mp4"),
+		append(buf1.Bytes(), buf2.Bytes()...), 0o644)
+	require.NoError(t, err)
+
+	te := test.NewSourceTester(
+		func(p defs.StaticSourceParent) defs.StaticSource {
+			return &Source{
+				Parent: p,
+			}
+		},
+		"recording://mypath?start=2008-11-07T11:22:00.5Z&duration=10s",
+		&conf.Path{
+			RecordPath:   filepath.Join(dir, "%path/%Y-%m-%d_%H-%M-%S-%f"),
+			RecordFormat: conf.RecordFormatFMP4,
+		},
+	)
+	defer te.Close()
+
+	u := <-te.Unit
+	au := u.(*unit.H264).AU
+	require.Equal(t, []byte{5, 1}, au[len(au)-1])
+}
+
+func TestParseURL(t *testing.T) {
+	pathName, start, duration, err := parseURL("recording://my/path?start=2008-11-07T11:22:00Z&duration=1h")
+	require.NoError(t, err)
+	require.Equal(t, "my/path", pathName)
+	require.Equal(t, "2008-11-07T11:22:00Z", start.Format("2006-01-02T15:04:05Z07:00"))
+	require.Equal(t, "1h0m0s", duration.String())
+
+	_, _, _, err = parseURL("recording://my/path?start=2008-11-07T11:22:00Z")
+	require.Error(t, err)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/proxy"
 	"github.com/bluenviron/mediamtx/internal/protocols/rtmp"
 	"github.com/bluenviron/mediamtx/internal/protocols/tls"
 	"github.com/bluenviron/mediamtx/internal/stream"
@@ -40,6 +41,18 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 		return err
 	}
 
+	// RTMP doesn't provide a standard way to send credentials,
+	// therefore they are moved into the query, where most servers
+	// (including this one) look for them.
+	if u.User != nil {
+		q := u.Query()
+		q.Set("user", u.User.Username())
+		pass, _ := u.User.Password()
+		q.Set("pass", pass)
+		u.RawQuery = q.Encode()
+		u.User = nil
+	}
+
 	// add default port
 	_, _, err = net.SplitHostPort(u.Host)
 	if err != nil {
@@ -50,17 +63,39 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 		}
 	}
 
+	dialContext, err := proxy.NewDialContext(params.Conf.SourceProxy)
+	if err != nil {
+		return err
+	}
+
 	nconn, err := func() (net.Conn, error) {
 		ctx2, cancel2 := context.WithTimeout(params.Context, time.Duration(s.ReadTimeout))
 		defer cancel2()
 
+		nconn, err := dialContext(ctx2, "tcp", u.Host)
+		if err != nil {
+			return nil, err
+		}
+
 		if u.Scheme == "rtmp" {
-			return (&net.Dialer{}).DialContext(ctx2, "tcp", u.Host)
+			return nconn, nil
+		}
+
+		tlsConfig := tls.ConfigForFingerprint(params.Conf.SourceFingerprint)
+		if tlsConfig == nil {
+			tlsConfig = &ctls.Config{}
+		}
+		tlsConfig.ServerName = u.Hostname()
+
+		tconn := ctls.Client(nconn, tlsConfig)
+
+		err = tconn.HandshakeContext(ctx2)
+		if err != nil {
+			nconn.Close()
+			return nil, err
 		}
 
-		return (&ctls.Dialer{
-			Config: tls.ConfigForFingerprint(params.Conf.SourceFingerprint),
-		}).DialContext(ctx2, "tcp", u.Host)
+		return tconn, nil
 	}()
 	if err != nil {
 		return err
@@ -0,0 +1,185 @@
+// Package rtp contains the RTP static source.
+package rtp
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/multicast"
+	"github.com/bluenviron/gortsplib/v4/pkg/rtptime"
+	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
+	psdp "github.com/pion/sdp/v3"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/rtp"
+)
+
+type packetConn interface {
+	net.PacketConn
+	SetReadBuffer(int) error
+}
+
+func connectionAddress(sd *sdp.SessionDescription, md *psdp.MediaDescription) string {
+	if md.ConnectionInformation != nil && md.ConnectionInformation.Address != nil {
+		return md.ConnectionInformation.Address.Address
+	}
+	if sd.ConnectionInformation != nil && sd.ConnectionInformation.Address != nil {
+		return sd.ConnectionInformation.Address.Address
+	}
+	return ""
+}
+
+func listenUDP(ip string, port int) (packetConn, error) {
+	// strip TTL and number of addresses
+	host := strings.Split(ip, "/")[0]
+
+	if parsed := net.ParseIP(host); parsed != nil && parsed.To4() != nil && parsed.IsMulticast() {
+		return multicast.NewMultiConn(net.JoinHostPort(host, strconv.FormatInt(int64(port), 10)), true, net.ListenPacket)
+	}
+
+	// unicast addresses in the SDP are the ones of this server. Listen on all interfaces.
+	tmp, err := net.ListenPacket("udp", ":"+strconv.FormatInt(int64(port), 10))
+	if err != nil {
+		return nil, err
+	}
+	return tmp.(*net.UDPConn), nil
+}
+
+type sourceMedia struct {
+	media    *description.Media
+	rtpConn  packetConn
+	rtcpConn packetConn
+}
+
+// Source is a RTP static source.
+// Medias and ports are read from a SDP file.
+type Source struct {
+	ReadTimeout conf.Duration
+	Parent      defs.StaticSourceParent
+}
+
+// Log implements logger.Writer.
+func (s *Source) Log(level logger.Level, format string, args ...interface{}) {
+	s.Parent.Log(level, "[RTP source] "+format, args...)
+}
+
+// Run implements StaticSource.
+func (s *Source) Run(params defs.StaticSourceRunParams) error {
+	s.Log(logger.Debug, "reading SDP")
+
+	byts, err := os.ReadFile(params.ResolvedSource[len("file://"):])
+	if err != nil {
+		return err
+	}
+
+	var sd sdp.SessionDescription
+	err = sd.Unmarshal(byts)
+	if err != nil {
+		return fmt.Errorf("invalid SDP: %w", err)
+	}
+
+	var desc description.Session
+	err = desc.Unmarshal(&sd)
+	if err != nil {
+		return fmt.Errorf("invalid SDP: %w", err)
+	}
+
+	medias := make([]*sourceMedia, len(desc.Medias))
+
+	closeConns := func() {
+		for _, sm := range medias {
+			if sm != nil {
+				sm.rtpConn.Close()
+				if sm.rtcpConn != nil {
+					sm.rtcpConn.Close()
+				}
+			}
+		}
+	}
+
+	for i, md := range sd.MediaDescriptions {
+		ip := connectionAddress(&sd, md)
+		port := md.MediaName.Port.Value
+
+		sm := &sourceMedia{
+			media: desc.Medias[i],
+		}
+
+		sm.rtpConn, err = listenUDP(ip, port)
+		if err != nil {
+			closeConns()
+			return err
+		}
+		medias[i] = sm
+
+		err = sm.rtpConn.SetReadBuffer(rtp.UDPKernelReadBufferSize)
+		if err != nil {
+			closeConns()
+			return err
+		}
+
+		sm.rtcpConn, err = listenUDP(ip, port+1)
+		if err != nil {
+			closeConns()
+			return err
+		}
+	}
+
+	res := s.Parent.SetReady(defs.PathSourceStaticSetReadyReq{
+		Desc:               &desc,
+		GenerateRTPPackets: false,
+	})
+	if res.Err != nil {
+		closeConns()
+		return res.Err
+	}
+
+	defer s.Parent.SetNotReady(defs.PathSourceStaticSetNotReadyReq{})
+
+	timeDecoder := rtptime.NewGlobalDecoder2()
+	decodeErrLogger := logger.NewLimitedLogger(s)
+	readerErr := make(chan error)
+
+	for _, sm := range medias {
+		go func(sm *sourceMedia) {
+			readerErr <- rtp.ReadRTP(sm.rtpConn, time.Duration(s.ReadTimeout), sm.media,
+				timeDecoder, decodeErrLogger, res.Stream)
+		}(sm)
+
+		go func(sm *sourceMedia) {
+			readerErr <- rtp.DiscardRTCP(sm.rtcpConn)
+		}(sm)
+	}
+
+	// readers must exit before returning, since they write to the stream
+	select {
+	case err := <-readerErr:
+		closeConns()
+		for i := 1; i < len(medias)*2; i++ {
+			<-readerErr
+		}
+		return err
+
+	case <-params.Context.Done():
+		closeConns()
+		for i := 0; i < len(medias)*2; i++ {
+			<-readerErr
+		}
+		return fmt.Errorf("terminated")
+	}
+}
+
+// APISourceDescribe implements StaticSource.
+func (*Source) APISourceDescribe() defs.APIPathSourceOrReader {
+	return defs.APIPathSourceOrReader{
+		Type: "rtpSource",
+		ID:   "",
+	}
+}
@@ -0,0 +1,68 @@
+package rtp
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/conf"
+	"github.com/bluenviron/mediamtx/internal/defs"
+	"github.com/bluenviron/mediamtx/internal/test"
+)
+
+func TestSource(t *testing.T) {
+	fpath := filepath.Join(t.TempDir(), "stream.sdp")
+
+	err := os.WriteFile(fpath, []byte("v=0\r\n"+
+		"o=- 0 0 IN IP4 127.0.0.1\r\n"+
+		"s=Stream\r\n"+
+		"c=IN IP4 127.0.0.1\r\n"+
+		"t=0 0\r\n"+
+		"m=video 9002 RTP/AVP 96\r\n"+
+		"a=rtpmap:96 H264/90000\r\n"+
+		"a=fmtp:96 packetization-mode=1\r\n"), 0o644)
+	require.NoError(t, err)
+
+	te := test.NewSourceTester(
+		func(p defs.StaticSourceParent) defs.StaticSource {
+			return &Source{
+				ReadTimeout: conf.Duration(10 * time.Second),
+				Parent:      p,
+			}
+		},
+		"file://"+fpath,
+		&conf.Path{},
+	)
+	defer te.Close()
+
+	time.Sleep(50 * time.Millisecond)
+
+	conn, err := net.Dial("udp", "127.0.0.1:9002")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	pkt := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    96,
+			SequenceNumber: 123,
+			Timestamp:      45343,
+			SSRC:           563423,
+		},
+		Payload: []byte{5, 1}, // IDR
+	}
+
+	byts, err := pkt.Marshal()
+	require.NoError(t, err)
+
+	_, err = conn.Write(byts)
+	require.NoError(t, err)
+
+	<-te.Unit
+}
@@ -2,19 +2,31 @@
 package rtsp
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
+	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 
 	"github.com/bluenviron/mediamtx/internal/conf"
 	"github.com/bluenviron/mediamtx/internal/defs"
 	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/protocols/proxy"
 	"github.com/bluenviron/mediamtx/internal/protocols/tls"
 )
 
+const (
+	setupRetryAttempts = 3
+	setupRetryPause    = 1 * time.Second
+)
+
 func createRangeHeader(cnf *conf.Path) (*headers.Range, error) {
 	switch cnf.RTSPRangeType {
 	case conf.RTSPRangeTypeClock:
@@ -66,6 +78,9 @@ type Source struct {
 	WriteTimeout   conf.Duration
 	WriteQueueSize int
 	Parent         defs.StaticSourceParent
+
+	trackErrors      []defs.APIPathSourceTrackError
+	trackErrorsMutex sync.RWMutex
 }
 
 // Log implements logger.Writer.
@@ -79,9 +94,29 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 
 	decodeErrLogger := logger.NewLimitedLogger(s)
 
+	dialContext, err := proxy.NewDialContext(params.Conf.SourceProxy)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := tls.ConfigForClientCert(params.Conf.SourceFingerprint,
+		params.Conf.SourceClientCert, params.Conf.SourceClientKey)
+	if err != nil {
+		return err
+	}
+
+	transport := params.Conf.RTSPTransport.Transport
+
+	// UDP packets can't pass through the proxy
+	if params.Conf.SourceProxy != "" {
+		v := gortsplib.TransportTCP
+		transport = &v
+	}
+
 	c := &gortsplib.Client{
-		Transport:      params.Conf.RTSPTransport.Transport,
-		TLSConfig:      tls.ConfigForFingerprint(params.Conf.SourceFingerprint),
+		Transport:      transport,
+		DialContext:    dialContext,
+		TLSConfig:      tlsConfig,
 		ReadTimeout:    time.Duration(s.ReadTimeout),
 		WriteTimeout:   time.Duration(s.WriteTimeout),
 		WriteQueueSize: s.WriteQueueSize,
@@ -114,6 +149,10 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 	}
 	defer c.Close()
 
+	// closed when the server notifies that the stream has ended
+	ended := make(chan struct{})
+	var endedOnce sync.Once
+
 	readErr := make(chan error)
 	go func() {
 		readErr <- func() error {
@@ -122,7 +161,7 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 				return err
 			}
 
-			err = c.SetupAll(desc.BaseURL, desc.Medias)
+			desc, err = s.setupMedias(params.Context, c, desc, params.Conf.RTSPSetupErrorPolicy)
 			if err != nil {
 				return err
 			}
@@ -135,7 +174,22 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 				return res.Err
 			}
 
-			defer s.Parent.SetNotReady(defs.PathSourceStaticSetNotReadyReq{})
+			defer func() {
+				select {
+				case <-ended:
+					s.Parent.SetNotReady(defs.PathSourceStaticSetNotReadyReq{Ended: true})
+				default:
+					s.Parent.SetNotReady(defs.PathSourceStaticSetNotReadyReq{})
+				}
+			}()
+
+			c.OnPacketRTCPAny(func(_ *description.Media, pkt rtcp.Packet) {
+				if _, ok := pkt.(*rtcp.Goodbye); ok {
+					endedOnce.Do(func() {
+						close(ended)
+					})
+				}
+			})
 
 			for _, medi := range desc.Medias {
 				for _, forma := range medi.Formats {
@@ -172,6 +226,11 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 		case err := <-readErr:
 			return err
 
+		case <-ended:
+			c.Close()
+			<-readErr
+			return fmt.Errorf("source has ended the stream")
+
 		case <-params.ReloadConf:
 
 		case <-params.Context.Done():
@@ -182,10 +241,89 @@ func (s *Source) Run(params defs.StaticSourceRunParams) error {
 	}
 }
 
+// setupMedias sets up medias one by one, applying the SETUP error policy
+// to the medias that fail.
+func (s *Source) setupMedias(
+	ctx context.Context,
+	c *gortsplib.Client,
+	desc *description.Session,
+	policy conf.RTSPSetupErrorPolicy,
+) (*description.Session, error) {
+	var medias []*description.Media
+	var trackErrors []defs.APIPathSourceTrackError
+
+	defer func() {
+		s.trackErrorsMutex.Lock()
+		s.trackErrors = trackErrors
+		s.trackErrorsMutex.Unlock()
+	}()
+
+	for _, medi := range desc.Medias {
+		err := s.setupMedia(ctx, c, desc.BaseURL, medi, policy)
+		if err != nil {
+			trackErrors = append(trackErrors, defs.APIPathSourceTrackError{
+				Track: strings.Join(defs.MediasToCodecs([]*description.Media{medi}), ", "),
+				Error: err.Error(),
+			})
+
+			if policy != conf.RTSPSetupErrorPolicySkip {
+				return nil, err
+			}
+
+			s.Log(logger.Warn, "skipping media %s: %v", defs.MediasInfo([]*description.Media{medi}), err)
+			continue
+		}
+
+		medias = append(medias, medi)
+	}
+
+	if medias == nil {
+		return nil, fmt.Errorf("all medias failed SETUP")
+	}
+
+	return &description.Session{
+		BaseURL: desc.BaseURL,
+		Title:   desc.Title,
+		Medias:  medias,
+	}, nil
+}
+
+func (s *Source) setupMedia(
+	ctx context.Context,
+	c *gortsplib.Client,
+	baseURL *base.URL,
+	medi *description.Media,
+	policy conf.RTSPSetupErrorPolicy,
+) error {
+	attempts := 1
+	if policy == conf.RTSPSetupErrorPolicyRetry {
+		attempts = setupRetryAttempts
+	}
+
+	for i := 1; ; i++ {
+		_, err := c.Setup(baseURL, medi, 0, 0)
+		if err == nil || i >= attempts {
+			return err
+		}
+
+		s.Log(logger.Warn, "SETUP of media %s failed, retrying: %v", defs.MediasInfo([]*description.Media{medi}), err)
+
+		select {
+		case <-time.After(setupRetryPause):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
 // APISourceDescribe implements StaticSource.
-func (*Source) APISourceDescribe() defs.APIPathSourceOrReader {
+func (s *Source) APISourceDescribe() defs.APIPathSourceOrReader {
+	s.trackErrorsMutex.RLock()
+	defer s.trackErrorsMutex.RUnlock()
+
 	return defs.APIPathSourceOrReader{
-		Type: "rtspSource",
-		ID:   "",
+		Type:        "rtspSource",
+		ID:          "",
+		TrackErrors: s.trackErrors,
 	}
 }
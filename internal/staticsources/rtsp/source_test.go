@@ -257,6 +257,93 @@ func TestRTSPSourceNoPassword(t *testing.T) {
 	<-te.Unit
 }
 
+func TestRTSPSourceSkipFailedSetup(t *testing.T) {
+	var stream *gortsplib.ServerStream
+
+	media0 := test.UniqueMediaH264()
+	media1 := test.UniqueMediaMPEG4Audio()
+	setupCount := 0
+
+	s := gortsplib.Server{
+		Handler: &testServer{
+			onDescribe: func(_ *gortsplib.ServerHandlerOnDescribeCtx) (*base.Response, *gortsplib.ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *gortsplib.ServerHandlerOnSetupCtx) (*base.Response, *gortsplib.ServerStream, error) {
+				setupCount++
+				if setupCount == 2 {
+					return &base.Response{
+						StatusCode: base.StatusUnsupportedTransport,
+					}, nil, nil
+				}
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(_ *gortsplib.ServerHandlerOnPlayCtx) (*base.Response, error) {
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					err := stream.WritePacketRTP(media0, &rtp.Packet{
+						Header: rtp.Header{
+							Version:        0x02,
+							PayloadType:    96,
+							SequenceNumber: 57899,
+							Timestamp:      345234345,
+							SSRC:           978651231,
+							Marker:         true,
+						},
+						Payload: []byte{5, 1, 2, 3, 4},
+					})
+					require.NoError(t, err)
+				}()
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress: "127.0.0.1:8555",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = gortsplib.NewServerStream(&s, &description.Session{Medias: []*description.Media{media0, media1}})
+	defer stream.Close()
+
+	var sp conf.RTSPTransport
+	sp.UnmarshalJSON([]byte(`"tcp"`)) //nolint:errcheck
+
+	var so *Source
+
+	te := test.NewSourceTester(
+		func(p defs.StaticSourceParent) defs.StaticSource {
+			so = &Source{
+				ReadTimeout:    conf.Duration(10 * time.Second),
+				WriteTimeout:   conf.Duration(10 * time.Second),
+				WriteQueueSize: 2048,
+				Parent:         p,
+			}
+			return so
+		},
+		"rtsp://127.0.0.1:8555/teststream",
+		&conf.Path{
+			RTSPTransport: sp,
+		},
+	)
+	defer te.Close()
+
+	<-te.Unit
+
+	trackErrors := so.APISourceDescribe().TrackErrors
+	require.Len(t, trackErrors, 1)
+	require.Equal(t, "MPEG-4 Audio", trackErrors[0].Track)
+}
+
 func TestRTSPSourceRange(t *testing.T) {
 	for _, ca := range []string{"clock", "npt", "smpte"} {
 		t.Run(ca, func(t *testing.T) {
@@ -9,6 +9,7 @@ import (
 	"github.com/bluenviron/gortsplib/v4"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 
 	"github.com/bluenviron/mediamtx/internal/logger"
@@ -26,11 +27,17 @@ type ReadFunc func(unit.Unit) error
 // Stream is a media stream.
 // It stores tracks, readers and allows to write data to readers.
 type Stream struct {
-	writeQueueSize int
-	desc           *description.Session
+	writeQueueSize    int
+	readerIdleTimeout time.Duration
+	desc              *description.Session
+	decodeErrLogger   logger.Writer
+	ntpFromPTS        bool
+	gopCache          *gopCache
 
 	bytesReceived *uint64
+	lastReceived  *int64
 	bytesSent     *uint64
+	delay         *streamDelay
 	streamMedias  map[*description.Media]*streamMedia
 	mutex         sync.RWMutex
 	rtspStream    *gortsplib.ServerStream
@@ -43,16 +50,21 @@ type Stream struct {
 // New allocates a Stream.
 func New(
 	writeQueueSize int,
+	readerIdleTimeout time.Duration,
+	delay time.Duration,
 	udpMaxPayloadSize int,
 	desc *description.Session,
 	generateRTPPackets bool,
 	decodeErrLogger logger.Writer,
 ) (*Stream, error) {
 	s := &Stream{
-		writeQueueSize: writeQueueSize,
-		desc:           desc,
-		bytesReceived:  new(uint64),
-		bytesSent:      new(uint64),
+		writeQueueSize:    writeQueueSize,
+		readerIdleTimeout: readerIdleTimeout,
+		desc:              desc,
+		decodeErrLogger:   decodeErrLogger,
+		bytesReceived:     new(uint64),
+		lastReceived:      new(int64),
+		bytesSent:         new(uint64),
 	}
 
 	s.streamMedias = make(map[*description.Media]*streamMedia)
@@ -67,11 +79,23 @@ func New(
 		}
 	}
 
+	if delay > 0 {
+		s.delay = &streamDelay{
+			delay:     delay,
+			queueSize: delayQueueSize(writeQueueSize, delay),
+			parent:    decodeErrLogger,
+		}
+		s.delay.initialize()
+	}
+
 	return s, nil
 }
 
 // Close closes all resources of the stream.
 func (s *Stream) Close() {
+	if s.delay != nil {
+		s.delay.close()
+	}
 	if s.rtspStream != nil {
 		s.rtspStream.Close()
 	}
@@ -90,6 +114,60 @@ func (s *Stream) BytesReceived() uint64 {
 	return atomic.LoadUint64(s.bytesReceived)
 }
 
+// LastReceived returns the time of the last received unit.
+// It returns a zero time if no unit has been received yet.
+func (s *Stream) LastReceived() time.Time {
+	v := atomic.LoadInt64(s.lastReceived)
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, v)
+}
+
+// SetMuted enables or disables the routing of medias of the given type to readers.
+// Readers are not disconnected, they just stop receiving data of muted medias.
+func (s *Stream) SetMuted(typ description.MediaType, muted bool) {
+	var v int32
+	if muted {
+		v = 1
+	}
+
+	for medi, sm := range s.streamMedias {
+		if medi.Type == typ {
+			atomic.StoreInt32(sm.muted, v)
+		}
+	}
+}
+
+// SetNTPFromPTS enables the computation of NTP timestamps from PTS.
+// The NTP timestamp of the first unit of each format is used as reference,
+// and NTP timestamps of following units are obtained by adding the elapsed PTS.
+// This removes the jitter caused by network and by the publisher
+// from NTP timestamps, that are used by recordings and by RTSP readers.
+// It must be called before writing any unit.
+func (s *Stream) SetNTPFromPTS(v bool) {
+	s.ntpFromPTS = v
+}
+
+// SetGOPCache enables the GOP cache, that stores units received since
+// the last key frame in order to send them to readers started with StartReaderFromGOPCache().
+// Cached units take at most maxSize bytes; bigger GOPs are not cached.
+// It must be called before writing any unit.
+func (s *Stream) SetGOPCache(maxSize uint64) {
+	s.gopCache = &gopCache{
+		maxSize: maxSize,
+		parent:  s.decodeErrLogger,
+	}
+}
+
+// GOPCacheSize returns the size of units stored in the GOP cache.
+func (s *Stream) GOPCacheSize() uint64 {
+	if s.gopCache == nil {
+		return 0
+	}
+	return s.gopCache.currentSize()
+}
+
 // BytesSent returns sent bytes.
 func (s *Stream) BytesSent() uint64 {
 	s.mutex.RLock()
@@ -111,6 +189,11 @@ func (s *Stream) BytesSent() uint64 {
 	return bytesSent
 }
 
+// FormatStats returns statistics of a format.
+func (s *Stream) FormatStats(medi *description.Media, forma format.Format) *FormatStats {
+	return s.streamMedias[medi].formats[forma].stats.get()
+}
+
 // RTSPStream returns the RTSP stream.
 func (s *Stream) RTSPStream(server *gortsplib.Server) *gortsplib.ServerStream {
 	s.mutex.Lock()
@@ -142,8 +225,9 @@ func (s *Stream) AddReader(reader Reader, medi *description.Media, forma format.
 	sr, ok := s.streamReaders[reader]
 	if !ok {
 		sr = &streamReader{
-			queueSize: s.writeQueueSize,
-			parent:    reader,
+			queueSize:   s.writeQueueSize,
+			idleTimeout: s.readerIdleTimeout,
+			parent:      reader,
 		}
 		sr.initialize()
 
@@ -180,8 +264,10 @@ func (s *Stream) StartReader(reader Reader) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	sr := s.streamReaders[reader]
+	s.startReaderInner(s.streamReaders[reader])
+}
 
+func (s *Stream) startReaderInner(sr *streamReader) {
 	sr.start()
 
 	for _, sm := range s.streamMedias {
@@ -197,6 +283,50 @@ func (s *Stream) StartReader(reader Reader) {
 	}
 }
 
+// StartReaderFromGOPCache starts a reader and sends it units stored in the GOP cache
+// before any other unit, in order to allow it to start decoding immediately.
+// If the GOP cache is disabled, it is equivalent to StartReader().
+// Used by all protocols except RTSP.
+func (s *Stream) StartReaderFromGOPCache(reader Reader) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sr := s.streamReaders[reader]
+
+	if s.gopCache != nil {
+		var cbs []func() error
+
+		for _, entry := range s.gopCache.get() {
+			cb, ok := entry.sf.pausedReaders[sr]
+			if !ok {
+				continue
+			}
+
+			u := entry.u
+			size := entry.size
+			cbs = append(cbs, func() error {
+				atomic.AddUint64(s.bytesSent, size)
+				return cb(u)
+			})
+		}
+
+		if len(cbs) != 0 {
+			// cached units are sent with a single callback in order not to fill the queue
+			sr.push(func() error {
+				for _, cb := range cbs {
+					err := cb()
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+	}
+
+	s.startReaderInner(sr)
+}
+
 // ReaderError returns whenever there's an error.
 func (s *Stream) ReaderError(reader Reader) chan error {
 	sr := s.streamReaders[reader]
@@ -231,6 +361,17 @@ func (s *Stream) WaitRunningReader() {
 
 // WriteUnit writes a Unit.
 func (s *Stream) WriteUnit(medi *description.Media, forma format.Format, u unit.Unit) {
+	if s.delay != nil {
+		s.delay.push(func() {
+			s.writeUnit(medi, forma, u)
+		})
+		return
+	}
+
+	s.writeUnit(medi, forma, u)
+}
+
+func (s *Stream) writeUnit(medi *description.Media, forma format.Format, u unit.Unit) {
 	sm := s.streamMedias[medi]
 	sf := sm.formats[forma]
 
@@ -247,6 +388,23 @@ func (s *Stream) WriteRTPPacket(
 	pkt *rtp.Packet,
 	ntp time.Time,
 	pts int64,
+) {
+	if s.delay != nil {
+		s.delay.push(func() {
+			s.writeRTPPacket(medi, forma, pkt, ntp, pts)
+		})
+		return
+	}
+
+	s.writeRTPPacket(medi, forma, pkt, ntp, pts)
+}
+
+func (s *Stream) writeRTPPacket(
+	medi *description.Media,
+	forma format.Format,
+	pkt *rtp.Packet,
+	ntp time.Time,
+	pts int64,
 ) {
 	sm := s.streamMedias[medi]
 	sf := sm.formats[forma]
@@ -256,3 +414,36 @@ func (s *Stream) WriteRTPPacket(
 
 	sf.writeRTPPacket(s, medi, pkt, ntp, pts)
 }
+
+// WriteEndOfStream notifies RTSP readers that the stream has ended cleanly,
+// by sending a RTCP BYE packet for each media.
+func (s *Stream) WriteEndOfStream() {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for medi, sm := range s.streamMedias {
+		var ssrcs []uint32
+		for _, sf := range sm.formats {
+			if ssrc := atomic.LoadInt64(sf.lastSSRC); ssrc >= 0 {
+				ssrcs = append(ssrcs, uint32(ssrc))
+			}
+		}
+
+		if ssrcs == nil {
+			continue
+		}
+
+		pkt := &rtcp.Goodbye{
+			Sources: ssrcs,
+			Reason:  "end of stream",
+		}
+
+		if s.rtspStream != nil {
+			s.rtspStream.WritePacketRTCP(medi, pkt) //nolint:errcheck
+		}
+
+		if s.rtspsStream != nil {
+			s.rtspsStream.WritePacketRTCP(medi, pkt) //nolint:errcheck
+		}
+	}
+}
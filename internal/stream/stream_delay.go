@@ -0,0 +1,87 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/ringbuffer"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+)
+
+func delayQueueSize(writeQueueSize int, delay time.Duration) int {
+	secs := int((delay + time.Second - 1) / time.Second)
+	n := 1
+	for n < writeQueueSize*secs {
+		n <<= 1
+	}
+	return n
+}
+
+type streamDelayEntry struct {
+	time time.Time
+	cb   func()
+}
+
+// streamDelay postpones writes to the stream by a fixed amount of time.
+type streamDelay struct {
+	delay     time.Duration
+	queueSize int
+	parent    logger.Writer
+
+	writeErrLogger logger.Writer
+	buffer         *ringbuffer.RingBuffer
+	terminate      chan struct{}
+	done           chan struct{}
+}
+
+func (d *streamDelay) initialize() {
+	d.writeErrLogger = logger.NewLimitedLogger(d.parent)
+	buffer, _ := ringbuffer.New(uint64(d.queueSize))
+	d.buffer = buffer
+	d.terminate = make(chan struct{})
+	d.done = make(chan struct{})
+
+	go d.run()
+}
+
+func (d *streamDelay) close() {
+	close(d.terminate)
+	d.buffer.Close()
+	<-d.done
+}
+
+func (d *streamDelay) run() {
+	defer close(d.done)
+
+	for {
+		tmp, ok := d.buffer.Pull()
+		if !ok {
+			return
+		}
+		entry := tmp.(*streamDelayEntry)
+
+		wait := time.Until(entry.time.Add(d.delay))
+		if wait > 0 {
+			t := time.NewTimer(wait)
+
+			select {
+			case <-t.C:
+			case <-d.terminate:
+				t.Stop()
+				return
+			}
+		}
+
+		entry.cb()
+	}
+}
+
+func (d *streamDelay) push(cb func()) {
+	ok := d.buffer.Push(&streamDelayEntry{
+		time: time.Now(),
+		cb:   cb,
+	})
+	if !ok {
+		d.writeErrLogger.Log(logger.Warn, "delay buffer is full")
+	}
+}
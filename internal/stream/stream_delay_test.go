@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+type nilLogger struct{}
+
+func (nilLogger) Log(logger.Level, string, ...interface{}) {
+}
+
+func TestDelayQueueSize(t *testing.T) {
+	require.Equal(t, 512, delayQueueSize(512, 500*time.Millisecond))
+	require.Equal(t, 16384, delayQueueSize(512, 30*time.Second))
+}
+
+func TestStreamDelay(t *testing.T) {
+	medi := &description.Media{
+		Type: description.MediaTypeVideo,
+		Formats: []format.Format{&format.H264{
+			PayloadTyp:        96,
+			PacketizationMode: 1,
+		}},
+	}
+
+	strm, err := New(
+		512,
+		0,
+		200*time.Millisecond,
+		1460,
+		&description.Session{Medias: []*description.Media{medi}},
+		true,
+		nilLogger{},
+	)
+	require.NoError(t, err)
+	defer strm.Close()
+
+	received := make(chan time.Time, 1)
+
+	r := nilLogger{}
+	strm.AddReader(r, medi, medi.Formats[0], func(_ unit.Unit) error {
+		received <- time.Now()
+		return nil
+	})
+	strm.StartReader(r)
+	defer strm.RemoveReader(r)
+
+	start := time.Now()
+
+	strm.WriteUnit(medi, medi.Formats[0], &unit.H264{
+		Base: unit.Base{
+			NTP: start,
+		},
+		AU: [][]byte{{5, 1}},
+	})
+
+	require.GreaterOrEqual(t, (<-received).Sub(start), 200*time.Millisecond)
+}
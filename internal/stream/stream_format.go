@@ -13,6 +13,18 @@ import (
 	"github.com/bluenviron/mediamtx/internal/unit"
 )
 
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func timestampToDuration(t int64, clockRate int) time.Duration {
+	return time.Duration(float64(t) / float64(clockRate) * float64(time.Second))
+}
+
+type ntpSetter interface {
+	SetNTP(time.Time)
+}
+
 func unitSize(u unit.Unit) uint64 {
 	n := uint64(0)
 	for _, pkt := range u.GetRTPPackets() {
@@ -28,6 +40,11 @@ type streamFormat struct {
 	decodeErrLogger    logger.Writer
 
 	proc           formatprocessor.Processor
+	stats          *streamFormatStats
+	lastSSRC       *int64
+	ntpRefSet      bool
+	ntpRef         time.Time
+	ptsRef         int64
 	pausedReaders  map[*streamReader]ReadFunc
 	runningReaders map[*streamReader]ReadFunc
 }
@@ -35,6 +52,8 @@ type streamFormat struct {
 func (sf *streamFormat) initialize() error {
 	sf.pausedReaders = make(map[*streamReader]ReadFunc)
 	sf.runningReaders = make(map[*streamReader]ReadFunc)
+	sf.stats = &streamFormatStats{clockRate: sf.format.ClockRate()}
+	sf.lastSSRC = int64Ptr(-1)
 
 	var err error
 	sf.proc, err = formatprocessor.New(sf.udpMaxPayloadSize, sf.format, sf.generateRTPPackets)
@@ -89,10 +108,39 @@ func (sf *streamFormat) writeRTPPacket(
 	sf.writeUnitInner(s, medi, u)
 }
 
+func (sf *streamFormat) setNTPFromPTS(u unit.Unit) {
+	if !sf.ntpRefSet {
+		sf.ntpRefSet = true
+		sf.ntpRef = u.GetNTP()
+		sf.ptsRef = u.GetPTS()
+		return
+	}
+
+	if nu, ok := u.(ntpSetter); ok {
+		nu.SetNTP(sf.ntpRef.Add(timestampToDuration(u.GetPTS()-sf.ptsRef, sf.format.ClockRate())))
+	}
+}
+
 func (sf *streamFormat) writeUnitInner(s *Stream, medi *description.Media, u unit.Unit) {
+	if s.ntpFromPTS {
+		sf.setNTPFromPTS(u)
+	}
+
 	size := unitSize(u)
 
 	atomic.AddUint64(s.bytesReceived, size)
+	atomic.StoreInt64(s.lastReceived, time.Now().UnixNano())
+
+	sf.stats.update(u, size)
+
+	if pkts := u.GetRTPPackets(); len(pkts) != 0 {
+		atomic.StoreInt64(sf.lastSSRC, int64(pkts[len(pkts)-1].SSRC))
+	}
+
+	// muted units are received but not routed to readers
+	if atomic.LoadInt32(s.streamMedias[medi].muted) != 0 {
+		return
+	}
 
 	if s.rtspStream != nil {
 		for _, pkt := range u.GetRTPPackets() {
@@ -106,6 +154,10 @@ func (sf *streamFormat) writeUnitInner(s *Stream, medi *description.Media, u uni
 		}
 	}
 
+	if s.gopCache != nil {
+		s.gopCache.push(sf, u, size)
+	}
+
 	for sr, cb := range sf.runningReaders {
 		ccb := cb
 		sr.push(func() error {
@@ -0,0 +1,166 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h265"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+const (
+	// difference between consecutive timestamps that is considered a jump.
+	timestampJumpThreshold = 5 * time.Second
+
+	// period over which the bitrate is measured.
+	bitrateWindow = 1 * time.Second
+)
+
+// FormatStats contains statistics of a format.
+type FormatStats struct {
+	Units            uint64
+	KeyFrames        uint64
+	LastKeyFrameTime *time.Time
+	LastKeyFrameSize uint64
+	LastGOPUnits     uint64
+	AverageGOPUnits  float64
+	TimestampJumps   uint64
+	Bitrate          uint64
+	LastPTS          int64
+}
+
+func auSize(au [][]byte) uint64 {
+	n := uint64(0)
+	for _, nalu := range au {
+		n += uint64(len(nalu))
+	}
+	return n
+}
+
+// keyFrameInfo returns whether a unit is empty, whether it contains a key frame and the size of the key frame.
+func keyFrameInfo(u unit.Unit) (bool, bool, uint64) {
+	switch tu := u.(type) {
+	case *unit.H264:
+		if tu.AU == nil {
+			return true, false, 0
+		}
+		if h264.IDRPresent(tu.AU) {
+			return false, true, auSize(tu.AU)
+		}
+		return false, false, 0
+
+	case *unit.H265:
+		if tu.AU == nil {
+			return true, false, 0
+		}
+		if h265.IsRandomAccess(tu.AU) {
+			return false, true, auSize(tu.AU)
+		}
+		return false, false, 0
+	}
+
+	return false, false, 0
+}
+
+type streamFormatStats struct {
+	clockRate int
+
+	mutex              sync.Mutex
+	units              uint64
+	keyFrames          uint64
+	lastKeyFrameTime   time.Time
+	lastKeyFrameSize   uint64
+	unitsSinceKeyFrame uint64
+	lastGOPUnits       uint64
+	gopUnits           uint64
+	gops               uint64
+	lastPTS            int64
+	timestampJumps     uint64
+	bitrateWindowStart time.Time
+	bitrateWindowBytes uint64
+	bitrate            uint64
+}
+
+func (st *streamFormatStats) update(u unit.Unit, size uint64) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	st.updateBitrate(size)
+
+	empty, isKeyFrame, keyFrameSize := keyFrameInfo(u)
+	if empty {
+		return
+	}
+
+	if st.units != 0 && st.clockRate != 0 {
+		diff := u.GetPTS() - st.lastPTS
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > int64(timestampJumpThreshold.Seconds()*float64(st.clockRate)) {
+			st.timestampJumps++
+		}
+	}
+	st.lastPTS = u.GetPTS()
+	st.units++
+
+	if isKeyFrame {
+		if st.keyFrames != 0 {
+			st.lastGOPUnits = st.unitsSinceKeyFrame
+			st.gopUnits += st.unitsSinceKeyFrame
+			st.gops++
+		}
+
+		st.keyFrames++
+		st.lastKeyFrameTime = time.Now()
+		st.lastKeyFrameSize = keyFrameSize
+		st.unitsSinceKeyFrame = 0
+	}
+
+	st.unitsSinceKeyFrame++
+}
+
+func (st *streamFormatStats) updateBitrate(size uint64) {
+	now := time.Now()
+
+	if st.bitrateWindowStart.IsZero() {
+		st.bitrateWindowStart = now
+	}
+
+	st.bitrateWindowBytes += size
+
+	elapsed := now.Sub(st.bitrateWindowStart)
+	if elapsed >= bitrateWindow {
+		st.bitrate = uint64(float64(st.bitrateWindowBytes*8) / elapsed.Seconds())
+		st.bitrateWindowStart = now
+		st.bitrateWindowBytes = 0
+	}
+}
+
+func (st *streamFormatStats) get() *FormatStats {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	ret := &FormatStats{
+		Units:            st.units,
+		KeyFrames:        st.keyFrames,
+		LastKeyFrameSize: st.lastKeyFrameSize,
+		LastGOPUnits:     st.lastGOPUnits,
+		TimestampJumps:   st.timestampJumps,
+		Bitrate:          st.bitrate,
+		LastPTS:          st.lastPTS,
+	}
+
+	if st.keyFrames != 0 {
+		v := st.lastKeyFrameTime
+		ret.LastKeyFrameTime = &v
+	}
+
+	if st.gops != 0 {
+		ret.AverageGOPUnits = float64(st.gopUnits) / float64(st.gops)
+	}
+
+	return ret
+}
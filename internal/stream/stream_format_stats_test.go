@@ -0,0 +1,52 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func TestFormatStats(t *testing.T) {
+	st := &streamFormatStats{clockRate: 90000}
+
+	idr := [][]byte{{5, 1, 2, 3}}
+	nonIDR := [][]byte{{1, 2}}
+
+	for i, au := range [][][]byte{idr, nonIDR, nonIDR, idr, nonIDR, nil, idr} {
+		st.update(&unit.H264{
+			Base: unit.Base{PTS: int64(i) * 3000},
+			AU:   au,
+		}, 0)
+	}
+
+	st.update(&unit.H264{
+		Base: unit.Base{PTS: 100 * 90000},
+		AU:   nonIDR,
+	}, 0)
+
+	stats := st.get()
+	require.NotNil(t, stats.LastKeyFrameTime)
+	stats.LastKeyFrameTime = nil
+
+	require.Equal(t, &FormatStats{
+		Units:            7,
+		KeyFrames:        3,
+		LastKeyFrameSize: 4,
+		LastGOPUnits:     2,
+		AverageGOPUnits:  2.5,
+		TimestampJumps:   1,
+		LastPTS:          100 * 90000,
+	}, stats)
+}
+
+func TestFormatStatsBitrate(t *testing.T) {
+	st := &streamFormatStats{clockRate: 90000}
+	st.bitrateWindowStart = time.Now().Add(-2 * time.Second)
+
+	st.update(&unit.H264{AU: [][]byte{{1, 2}}}, 1000)
+
+	require.InDelta(t, 4000, float64(st.get().Bitrate), 100)
+}
@@ -0,0 +1,74 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/bluenviron/mediamtx/internal/logger"
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+type gopCacheEntry struct {
+	sf   *streamFormat
+	u    unit.Unit
+	size uint64
+}
+
+// gopCache stores units of all formats received since the last key frame,
+// in order to send them to readers that want to start from a key frame
+// without waiting for the next one.
+type gopCache struct {
+	maxSize uint64
+	parent  logger.Writer
+
+	mutex     sync.Mutex
+	keyFormat *streamFormat
+	entries   []gopCacheEntry
+	size      uint64
+}
+
+func (c *gopCache) push(sf *streamFormat, u unit.Unit, size uint64) {
+	empty, isKeyFrame, _ := keyFrameInfo(u)
+	if empty {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// the GOP is delimited by key frames of the first video format that provides them
+	if isKeyFrame && (c.keyFormat == nil || c.keyFormat == sf) {
+		c.keyFormat = sf
+		c.entries = nil
+		c.size = 0
+	} else if len(c.entries) == 0 {
+		return
+	}
+
+	if c.size+size > c.maxSize {
+		c.parent.Log(logger.Warn, "GOP is bigger than gopCacheMaxSize, discarding it")
+		c.entries = nil
+		c.size = 0
+		return
+	}
+
+	c.entries = append(c.entries, gopCacheEntry{
+		sf:   sf,
+		u:    u,
+		size: size,
+	})
+	c.size += size
+}
+
+func (c *gopCache) get() []gopCacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]gopCacheEntry(nil), c.entries...)
+}
+
+func (c *gopCache) currentSize() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.size
+}
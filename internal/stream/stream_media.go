@@ -9,6 +9,7 @@ import (
 
 type streamMedia struct {
 	formats map[format.Format]*streamFormat
+	muted   *int32
 }
 
 func newStreamMedia(udpMaxPayloadSize int,
@@ -18,6 +19,7 @@ func newStreamMedia(udpMaxPayloadSize int,
 ) (*streamMedia, error) {
 	sm := &streamMedia{
 		formats: make(map[format.Format]*streamFormat),
+		muted:   new(int32),
 	}
 
 	for _, forma := range medi.Formats {
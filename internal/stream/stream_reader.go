@@ -2,18 +2,24 @@ package stream
 
 import (
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/ringbuffer"
 	"github.com/bluenviron/mediamtx/internal/logger"
 )
 
 type streamReader struct {
-	queueSize int
-	parent    logger.Writer
+	queueSize   int
+	idleTimeout time.Duration
+	parent      logger.Writer
 
 	writeErrLogger logger.Writer
 	buffer         *ringbuffer.RingBuffer
 	started        bool
+	pending        int64
+	lastDelivery   int64
+	idle           int32
 
 	// out
 	err chan error
@@ -24,10 +30,12 @@ func (w *streamReader) initialize() {
 	buffer, _ := ringbuffer.New(uint64(w.queueSize))
 	w.buffer = buffer
 	w.err = make(chan error)
+	w.lastDelivery = time.Now().UnixNano()
 }
 
 func (w *streamReader) start() {
 	w.started = true
+	atomic.StoreInt64(&w.lastDelivery, time.Now().UnixNano())
 	go w.run()
 }
 
@@ -51,6 +59,9 @@ func (w *streamReader) runInner() error {
 	for {
 		cb, ok := w.buffer.Pull()
 		if !ok {
+			if atomic.LoadInt32(&w.idle) == 1 {
+				return fmt.Errorf("reader has not consumed data for more than %v", w.idleTimeout)
+			}
 			return fmt.Errorf("terminated")
 		}
 
@@ -58,12 +69,45 @@ func (w *streamReader) runInner() error {
 		if err != nil {
 			return err
 		}
+
+		atomic.StoreInt64(&w.lastDelivery, time.Now().UnixNano())
+		atomic.AddInt64(&w.pending, -1)
 	}
 }
 
 func (w *streamReader) push(cb func() error) {
+	if w.idleTimeout != 0 {
+		w.checkIdle()
+	}
+
+	if atomic.LoadInt32(&w.idle) == 1 {
+		return
+	}
+
+	atomic.AddInt64(&w.pending, 1)
+
 	ok := w.buffer.Push(cb)
 	if !ok {
+		atomic.AddInt64(&w.pending, -1)
 		w.writeErrLogger.Log(logger.Warn, "write queue is full")
 	}
 }
+
+// checkIdle closes the reader when it has pending data and
+// it hasn't completed a delivery for more than idleTimeout,
+// that means that the reader stopped consuming data.
+// Readers whose callback never blocks, like HLS muxers, are never idle.
+func (w *streamReader) checkIdle() {
+	now := time.Now().UnixNano()
+
+	// a reader that consumed all data is not idle, even if no data has been delivered recently.
+	if atomic.LoadInt64(&w.pending) == 0 {
+		atomic.StoreInt64(&w.lastDelivery, now)
+		return
+	}
+
+	if time.Duration(now-atomic.LoadInt64(&w.lastDelivery)) >= w.idleTimeout &&
+		atomic.CompareAndSwapInt32(&w.idle, 0, 1) {
+		w.buffer.Close()
+	}
+}
@@ -0,0 +1,251 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/mediamtx/internal/unit"
+)
+
+func TestStreamMuted(t *testing.T) {
+	medi := &description.Media{
+		Type: description.MediaTypeVideo,
+		Formats: []format.Format{&format.H264{
+			PayloadTyp:        96,
+			PacketizationMode: 1,
+		}},
+	}
+
+	strm, err := New(
+		512,
+		0,
+		0,
+		1460,
+		&description.Session{Medias: []*description.Media{medi}},
+		true,
+		nilLogger{},
+	)
+	require.NoError(t, err)
+	defer strm.Close()
+
+	received := make(chan int64, 10)
+
+	r := nilLogger{}
+	strm.AddReader(r, medi, medi.Formats[0], func(u unit.Unit) error {
+		received <- u.GetPTS()
+		return nil
+	})
+	strm.StartReader(r)
+	defer strm.RemoveReader(r)
+
+	write := func(pts int64) {
+		strm.WriteUnit(medi, medi.Formats[0], &unit.H264{
+			Base: unit.Base{
+				NTP: time.Now(),
+				PTS: pts,
+			},
+			AU: [][]byte{{5, 1}},
+		})
+	}
+
+	strm.SetMuted(description.MediaTypeVideo, true)
+	write(1)
+
+	strm.SetMuted(description.MediaTypeVideo, false)
+	write(2)
+
+	require.Equal(t, int64(2), <-received)
+	require.NotZero(t, strm.BytesReceived())
+}
+
+func TestStreamNTPFromPTS(t *testing.T) {
+	medi := &description.Media{
+		Type: description.MediaTypeVideo,
+		Formats: []format.Format{&format.H264{
+			PayloadTyp:        96,
+			PacketizationMode: 1,
+		}},
+	}
+
+	strm, err := New(
+		512,
+		0,
+		0,
+		1460,
+		&description.Session{Medias: []*description.Media{medi}},
+		true,
+		nilLogger{},
+	)
+	require.NoError(t, err)
+	defer strm.Close()
+
+	strm.SetNTPFromPTS(true)
+
+	received := make(chan time.Time, 10)
+
+	r := nilLogger{}
+	strm.AddReader(r, medi, medi.Formats[0], func(u unit.Unit) error {
+		received <- u.GetNTP()
+		return nil
+	})
+	strm.StartReader(r)
+	defer strm.RemoveReader(r)
+
+	ref := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, ntp := range []time.Time{
+		ref,
+		ref.Add(3 * time.Second), // jitter
+	} {
+		strm.WriteUnit(medi, medi.Formats[0], &unit.H264{
+			Base: unit.Base{
+				NTP: ntp,
+				PTS: 90000 + int64(i)*90000,
+			},
+			AU: [][]byte{{5, 1}},
+		})
+	}
+
+	require.Equal(t, ref, <-received)
+	require.Equal(t, ref.Add(1*time.Second), <-received)
+}
+
+func TestStreamGOPCache(t *testing.T) {
+	for _, ca := range []string{"cached", "too big"} {
+		t.Run(ca, func(t *testing.T) {
+			medi := &description.Media{
+				Type: description.MediaTypeVideo,
+				Formats: []format.Format{&format.H264{
+					PayloadTyp:        96,
+					PacketizationMode: 1,
+				}},
+			}
+
+			strm, err := New(
+				512,
+				0,
+				0,
+				1460,
+				&description.Session{Medias: []*description.Media{medi}},
+				true,
+				nilLogger{},
+			)
+			require.NoError(t, err)
+			defer strm.Close()
+
+			if ca == "cached" {
+				strm.SetGOPCache(1024 * 1024)
+			} else {
+				strm.SetGOPCache(20)
+			}
+
+			write := func(pts int64, nalu []byte) {
+				strm.WriteUnit(medi, medi.Formats[0], &unit.H264{
+					Base: unit.Base{
+						NTP: time.Now(),
+						PTS: pts,
+					},
+					AU: [][]byte{nalu},
+				})
+			}
+
+			write(1, []byte{1, 1}) // non-IDR, before the first key frame
+			write(2, []byte{5, 1}) // IDR
+			write(3, []byte{1, 2})
+
+			received := make(chan int64, 10)
+
+			r := nilLogger{}
+			strm.AddReader(r, medi, medi.Formats[0], func(u unit.Unit) error {
+				received <- u.GetPTS()
+				return nil
+			})
+			strm.StartReaderFromGOPCache(r)
+			defer strm.RemoveReader(r)
+
+			write(4, []byte{1, 3})
+
+			if ca == "cached" {
+				require.NotZero(t, strm.GOPCacheSize())
+				require.Equal(t, int64(2), <-received)
+				require.Equal(t, int64(3), <-received)
+			} else {
+				require.Zero(t, strm.GOPCacheSize())
+			}
+
+			require.Equal(t, int64(4), <-received)
+		})
+	}
+}
+
+type testReader struct {
+	nilLogger
+	name string
+}
+
+func TestStreamReaderIdle(t *testing.T) {
+	medi := &description.Media{
+		Type: description.MediaTypeVideo,
+		Formats: []format.Format{&format.H264{
+			PayloadTyp:        96,
+			PacketizationMode: 1,
+		}},
+	}
+
+	strm, err := New(
+		512,
+		200*time.Millisecond,
+		0,
+		1460,
+		&description.Session{Medias: []*description.Media{medi}},
+		true,
+		nilLogger{},
+	)
+	require.NoError(t, err)
+	defer strm.Close()
+
+	// this reader is slower than the idle timeout, without filling its queue.
+	stuck := &testReader{name: "stuck"}
+	strm.AddReader(stuck, medi, medi.Formats[0], func(_ unit.Unit) error {
+		time.Sleep(1 * time.Second)
+		return nil
+	})
+	strm.StartReader(stuck)
+	defer strm.RemoveReader(stuck)
+
+	// this reader consumes all data, that is sparse.
+	active := &testReader{name: "active"}
+	strm.AddReader(active, medi, medi.Formats[0], func(_ unit.Unit) error {
+		return nil
+	})
+	strm.StartReader(active)
+	defer strm.RemoveReader(active)
+
+	for i := 0; i < 4; i++ {
+		strm.WriteUnit(medi, medi.Formats[0], &unit.H264{
+			Base: unit.Base{
+				NTP: time.Now(),
+				PTS: int64(i),
+			},
+			AU: [][]byte{{5, 1}},
+		})
+		time.Sleep(300 * time.Millisecond)
+	}
+
+	select {
+	case err = <-strm.ReaderError(stuck):
+		require.EqualError(t, err, "reader has not consumed data for more than 200ms")
+	case <-time.After(2 * time.Second):
+		t.Errorf("stuck reader has not been closed")
+	}
+
+	select {
+	case err = <-strm.ReaderError(active):
+		t.Errorf("active reader has been closed: %v", err)
+	default:
+	}
+}
@@ -66,6 +66,8 @@ func (t *SourceTester) Log(_ logger.Level, _ string, _ ...interface{}) {
 func (t *SourceTester) SetReady(req defs.PathSourceStaticSetReadyReq) defs.PathSourceStaticSetReadyRes {
 	t.stream, _ = stream.New(
 		512,
+		0,
+		0,
 		1460,
 		req.Desc,
 		req.GenerateRTPPackets,
@@ -28,6 +28,18 @@ func TestAPIDocs(t *testing.T) {
 		yamlKey  string
 		goStruct interface{}
 	}{
+		{
+			"DryRun",
+			defs.APIDryRun{},
+		},
+		{
+			"AuthToken",
+			defs.APIAuthToken{},
+		},
+		{
+			"AuthTokenSignReq",
+			defs.APIAuthTokenSignReq{},
+		},
 		{
 			"AuthInternalUser",
 			conf.AuthInternalUser{},
@@ -44,6 +56,14 @@ func TestAPIDocs(t *testing.T) {
 			"PathConf",
 			conf.Path{},
 		},
+		{
+			"ConfSchema",
+			conf.Schema{},
+		},
+		{
+			"ConfSchemaField",
+			conf.SchemaField{},
+		},
 		{
 			"PathConfList",
 			defs.APIPathConfList{},
@@ -52,10 +72,42 @@ func TestAPIDocs(t *testing.T) {
 			"Path",
 			defs.APIPath{},
 		},
+		{
+			"PathRecorder",
+			defs.APIPathRecorder{},
+		},
+		{
+			"PathTrack",
+			defs.APIPathTrack{},
+		},
 		{
 			"PathList",
 			defs.APIPathList{},
 		},
+		{
+			"PathMuteReq",
+			defs.APIPathMuteReq{},
+		},
+		{
+			"PathMute",
+			defs.APIPathMute{},
+		},
+		{
+			"PathHealth",
+			defs.APIPathHealth{},
+		},
+		{
+			"PathHealthList",
+			defs.APIPathHealthList{},
+		},
+		{
+			"PathDebug",
+			defs.APIPathDebug{},
+		},
+		{
+			"PathDebugTrack",
+			defs.APIPathDebugTrack{},
+		},
 		{
 			"PathSource",
 			defs.APIPathSourceOrReader{},
@@ -64,6 +116,10 @@ func TestAPIDocs(t *testing.T) {
 			"PathReader",
 			defs.APIPathSourceOrReader{},
 		},
+		{
+			"HLSSession",
+			defs.APIHLSSession{},
+		},
 		{
 			"HLSMuxer",
 			defs.APIHLSMuxer{},
@@ -84,6 +140,26 @@ func TestAPIDocs(t *testing.T) {
 			"RecordingSegment",
 			defs.APIRecordingSegment{},
 		},
+		{
+			"RecordingMatch",
+			defs.APIRecordingMatch{},
+		},
+		{
+			"RecordingMatchList",
+			defs.APIRecordingMatchList{},
+		},
+		{
+			"RecordingMatchSegment",
+			defs.APIRecordingMatchSegment{},
+		},
+		{
+			"ExternalCmd",
+			defs.APIExternalCmd{},
+		},
+		{
+			"ExternalCmdList",
+			defs.APIExternalCmdList{},
+		},
 		{
 			"RTMPConn",
 			defs.APIRTMPConn{},
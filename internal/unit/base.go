@@ -27,3 +27,18 @@ func (u *Base) GetNTP() time.Time {
 func (u *Base) GetPTS() int64 {
 	return u.PTS
 }
+
+// SetNTP sets the NTP timestamp of the unit.
+func (u *Base) SetNTP(v time.Time) {
+	u.NTP = v
+}
+
+// SetPTS sets the PTS of the unit.
+func (u *Base) SetPTS(v int64) {
+	u.PTS = v
+}
+
+// SetRTPPackets sets the RTP packets of the unit.
+func (u *Base) SetRTPPackets(v []*rtp.Packet) {
+	u.RTPPackets = v
+}